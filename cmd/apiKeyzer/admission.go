@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/admission"
+	"github.com/Xplo8E/APIKeyzer/internal/detector"
+	"github.com/Xplo8E/APIKeyzer/internal/output"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+// Admission policies selectable with --policy
+const (
+	policyWarn         = "warn"          // admit everything, warning about keys
+	policyDenyValid    = "deny-valid"    // reject objects holding keys confirmed to be valid
+	policyDenyDetected = "deny-detected" // reject objects holding any detectable key
+)
+
+var (
+	admissionListen   string
+	admissionTLSCert  string
+	admissionTLSKey   string
+	admissionPolicy   string
+	admissionValidate bool
+	admissionTimeout  time.Duration
+)
+
+func newAdmissionCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "admission",
+		Short: "Run a Kubernetes validating admission webhook that checks manifests for API keys",
+		Long: `
+Runs a Kubernetes validating admission webhook on POST /validate. Secrets (data
+and stringData), ConfigMaps and the containers of Pods and workloads (literal
+env values, command and args), as well as the annotations of every object, are
+searched for detectable API keys, which are validated live unless --validate=false.
+
+Policies (--policy):
+  deny-valid      reject objects holding keys confirmed to be valid (default)
+  deny-detected   reject objects holding any detectable key
+  warn            admit everything
+
+Keys that are not rejected are returned as warnings, which kubectl prints. Keys
+are always masked in responses. Every key found is also written in the selected
+output format, with the object, field and user in its metadata.
+
+The API server only calls webhooks over HTTPS, so --tls-cert and --tls-key are
+required; the certificate must be valid for the service name and its CA given
+as caBundle in the ValidatingWebhookConfiguration. The admission timeout of
+that configuration (timeoutSeconds, at most 30) should exceed --review-timeout.
+
+Billable endpoints are only called with --yes; keys of services whose every
+endpoint is billable are then reported as not validated.
+
+Examples:
+  apiKeyzer admission --tls-cert tls.crt --tls-key tls.key
+  apiKeyzer admission --tls-cert tls.crt --tls-key tls.key --policy deny-detected --validate=false
+  apiKeyzer admission --tls-cert tls.crt --tls-key tls.key --policy warn --output json --store apikeyzer.db`,
+		Args: cobra.NoArgs,
+		Run:  runAdmission,
+	}
+
+	cmd.Flags().StringVar(&admissionListen, "listen", "0.0.0.0:8443", "Address to listen on")
+	cmd.Flags().StringVar(&admissionTLSCert, "tls-cert", "", "TLS certificate file (PEM) of the webhook")
+	cmd.Flags().StringVar(&admissionTLSKey, "tls-key", "", "Private key file (PEM) of --tls-cert")
+	cmd.Flags().StringVar(&admissionPolicy, "policy", policyDenyValid, "When to reject an object: deny-valid, deny-detected or warn")
+	cmd.Flags().BoolVar(&admissionValidate, "validate", true, "Validate the keys found against the provider APIs")
+	cmd.Flags().DurationVar(&admissionTimeout, "review-timeout", 8*time.Second, "Time to spend validating the keys of one object; keys not validated in time are warned about")
+
+	return cmd
+}
+
+func runAdmission(cmd *cobra.Command, args []string) {
+	switch admissionPolicy {
+	case policyWarn, policyDenyDetected:
+	case policyDenyValid:
+		if !admissionValidate {
+			fmt.Fprintln(os.Stderr, "Error: --policy deny-valid requires validation; use deny-detected with --validate=false")
+			os.Exit(exitError)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "Error: invalid --policy %q: expected deny-valid, deny-detected or warn\n", admissionPolicy)
+		os.Exit(exitError)
+	}
+	if admissionTLSCert == "" || admissionTLSKey == "" {
+		fmt.Fprintln(os.Stderr, "Error: --tls-cert and --tls-key are required, the API server only calls webhooks over HTTPS")
+		os.Exit(exitError)
+	}
+
+	keyDetector, validationManager := setup()
+	writer := newResultWriter()
+	defer closeWriter(writer)
+	defer closeStore()
+
+	// Billable probes are confirmed up front since reviews cannot be answered from the terminal
+	if admissionValidate {
+		for _, service := range validationManager.GetSupportedServices() {
+			confirmBillable(context.Background(), validationManager, service)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	hook := &admissionHook{detector: keyDetector, validationManager: validationManager, writer: writer}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	mux.Handle("POST /validate", hook)
+
+	server := &http.Server{
+		Addr:              admissionListen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Fprintf(os.Stderr, "Admission webhook listening on https://%s/validate (policy %s)\n", admissionListen, admissionPolicy)
+	if err := server.ListenAndServeTLS(admissionTLSCert, admissionTLSKey); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitError)
+	}
+}
+
+// admissionHook answers AdmissionReview requests according to --policy
+type admissionHook struct {
+	detector          *detector.KeyDetector
+	validationManager *validator.ValidationManager
+	writer            output.Writer // shared by concurrent reviews
+}
+
+func (h *admissionHook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	review, err := admission.ReadReview(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := admission.WriteResponse(w, review, h.review(r.Context(), review.Request)); err != nil {
+		slog.Warn("failed to write admission response", "error", err)
+	}
+}
+
+// review decides on a single admission request
+func (h *admissionHook) review(ctx context.Context, req *admission.Request) *admission.Response {
+	records, err := admission.Records(req)
+	if err != nil {
+		// Objects the API server accepted but we cannot read are admitted rather than blocking the cluster
+		slog.Warn("failed to read admitted object", "kind", req.Kind.Kind, "namespace", req.Namespace, "name", req.Name, "error", err)
+		return admission.Allow([]string{fmt.Sprintf("APIKeyzer could not inspect this %s: %v", req.Kind.Kind, err)})
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, admissionTimeout)
+	defer cancel()
+
+	var warnings, rejected []string
+	seen := make(map[string]bool)
+	for _, rec := range records {
+		service := h.detector.DetectService(rec.Key)
+		if service == "" || seen[rec.Key] {
+			continue
+		}
+		seen[rec.Key] = true
+
+		var result *validator.ValidationResult
+		if admissionValidate {
+			if _, exists := h.validationManager.GetValidator(service); exists {
+				result = checkRecord(ctx, h.detector, h.validationManager, rec)
+			}
+		}
+		validated := result != nil
+		if !validated {
+			result = validator.NewErrorResult(rec.Key, service, validator.ErrNoValidator)
+		}
+		writeRecordResult(h.writer, result, rec)
+
+		finding := fmt.Sprintf("%s %s in %s", service, output.MaskKey(rec.Key), rec.Metadata["field"])
+		switch {
+		case result.Valid:
+			finding = fmt.Sprintf("valid %s (%s risk)", finding, result.RiskLevel)
+		case !admissionValidate:
+		case !validated:
+			finding = fmt.Sprintf("unvalidated %s (no validator)", finding)
+		case result.ErrorCode != "":
+			finding = fmt.Sprintf("unvalidated %s (%s)", finding, result.ErrorCode)
+		case admissionPolicy != policyDenyDetected:
+			// Keys rejected by their provider are no exposure
+			continue
+		default:
+			finding = "invalid " + finding
+		}
+
+		if admissionPolicy == policyDenyDetected || (admissionPolicy == policyDenyValid && result.Valid) {
+			rejected = append(rejected, finding)
+		} else {
+			warnings = append(warnings, "APIKeyzer: "+finding)
+		}
+	}
+
+	if len(rejected) > 0 {
+		slog.Info("rejected object", "kind", req.Kind.Kind, "namespace", req.Namespace, "name", req.Name, "user", req.UserInfo.Username, "keys", len(rejected))
+		message := fmt.Sprintf("APIKeyzer: %s holds API keys, load them from a secret store instead: %s",
+			strings.ToLower(req.Kind.Kind), strings.Join(rejected, "; "))
+		return admission.Deny(message, warnings)
+	}
+	return admission.Allow(warnings)
+}
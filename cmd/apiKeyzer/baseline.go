@@ -0,0 +1,39 @@
+package main
+
+import (
+	"github.com/Xplo8E/APIKeyzer/internal/baseline"
+	"github.com/Xplo8E/APIKeyzer/internal/output"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// baselineWriter hides findings already accepted in a baseline, or records every
+// valid finding into it when the baseline is being updated
+type baselineWriter struct {
+	output.Writer
+	baseline *baseline.Baseline
+	filename string
+	update   bool
+}
+
+func (b *baselineWriter) WriteResult(result *validator.ValidationResult) error {
+	if b.update {
+		if result.Valid {
+			b.baseline.Add(result)
+		}
+		return b.Writer.WriteResult(result)
+	}
+	if b.baseline.Contains(result) {
+		return nil
+	}
+	return b.Writer.WriteResult(result)
+}
+
+func (b *baselineWriter) Close() error {
+	err := b.Writer.Close()
+	if b.update {
+		if saveErr := b.baseline.Save(b.filename); err == nil {
+			err = saveErr
+		}
+	}
+	return err
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/detector"
+	"github.com/Xplo8E/APIKeyzer/internal/input"
+	"github.com/Xplo8E/APIKeyzer/internal/output"
+	"github.com/Xplo8E/APIKeyzer/internal/transport"
+	"github.com/spf13/cobra"
+)
+
+var (
+	benchKeys        int
+	benchValidations int
+	benchCassette    string
+	benchLatency     time.Duration
+)
+
+func newBenchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bench",
+		Short: "Measure detection and simulated validation throughput",
+		Long: `
+Measures how fast keys are detected against the loaded patterns (--config or the
+embedded defaults) and how fast they are validated with --threads workers, so
+pattern and concurrency changes can be checked for regressions.
+
+Detection runs over the keys given with --key, --list or on stdin, or over a
+generated corpus of --keys keys: half of them random strings and half random
+keys matching each pattern in turn. Validation runs the real validators over up
+to --validations of the detected keys, but requests never leave the process:
+they are answered from a cassette (--cassette), a JSON array of recorded
+responses, after --latency:
+
+  [{"method": "GET", "url": "https://maps.googleapis.com/maps/api/geocode/json",
+    "status": 200, "body": "{\"status\": \"OK\"}", "latency_ms": 80}]
+
+URLs are matched without their query string. Requests without a recorded
+response are answered with a 403, like a provider rejecting the key. --rate,
+--delay and --timeout apply as in a real run; billable endpoints are called
+unless --safe-mode is set, since nothing is sent.
+
+Examples:
+  apiKeyzer bench
+  apiKeyzer bench --keys 1000000 --config custom-patterns.json
+  apiKeyzer bench -t 20 --cassette google.json --latency 120ms --output json`,
+		Args: cobra.NoArgs,
+		Run:  runBench,
+	}
+
+	cmd.Flags().IntVar(&benchKeys, "keys", 200000, "Number of generated keys to detect when no keys are given")
+	cmd.Flags().IntVar(&benchValidations, "validations", 500, "Number of detected keys to validate against the cassette")
+	cmd.Flags().StringVar(&benchCassette, "cassette", "", "JSON file of recorded provider responses to answer validation requests with")
+	cmd.Flags().DurationVar(&benchLatency, "latency", 50*time.Millisecond, "Simulated response time of requests without a recorded latency")
+
+	return cmd
+}
+
+// detectionBench is the outcome of the detection benchmark
+type detectionBench struct {
+	Patterns   int     `json:"patterns"`
+	Keys       int     `json:"keys"`
+	Detected   int     `json:"detected"`
+	Seconds    float64 `json:"seconds"`
+	KeysPerSec float64 `json:"keys_per_sec"`
+}
+
+// validationBench is the outcome of the simulated validation benchmark
+type validationBench struct {
+	Keys           int     `json:"keys"`
+	Threads        int     `json:"threads"`
+	Requests       int     `json:"requests"`
+	Valid          int     `json:"valid"`
+	Failed         int     `json:"failed"`
+	Seconds        float64 `json:"seconds"`
+	KeysPerSec     float64 `json:"keys_per_sec"`
+	RequestsPerSec float64 `json:"requests_per_sec"`
+	P50MS          float64 `json:"p50_ms"`
+	P95MS          float64 `json:"p95_ms"`
+}
+
+func runBench(cmd *cobra.Command, args []string) {
+	// Nothing reaches the providers, so there is nobody to bill and no need to ask
+	assumeYes = true
+	keyDetector, _ := setup()
+	defer closeStore()
+
+	var interactions []transport.Interaction
+	if benchCassette != "" {
+		var err error
+		if interactions, err = transport.LoadCassette(benchCassette); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitError)
+		}
+	}
+	replay := transport.NewReplay(interactions, benchLatency)
+	client, err := newClientOn(replay)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitError)
+	}
+	validationManager := newValidationManager(client)
+
+	keys := benchCorpus(keyDetector)
+	if len(keys) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: no keys to benchmark")
+		os.Exit(exitError)
+	}
+
+	// Detection
+	detection := detectionBench{Patterns: len(keyDetector.Patterns()), Keys: len(keys)}
+	var validatable []string
+	start := time.Now()
+	for _, key := range keys {
+		if service := keyDetector.DetectService(key); service != "" {
+			detection.Detected++
+			if _, ok := validationManager.GetValidator(service); ok && len(validatable) < benchValidations {
+				validatable = append(validatable, key)
+			}
+		}
+	}
+	detection.Seconds = time.Since(start).Seconds()
+	detection.KeysPerSec = float64(detection.Keys) / detection.Seconds
+
+	// Validation
+	validation := validationBench{Keys: len(validatable), Threads: max(threads, 1)}
+	if len(validatable) > 0 {
+		durations := make([]time.Duration, len(validatable))
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		jobs := make(chan int)
+		start = time.Now()
+		for w := 0; w < validation.Threads; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range jobs {
+					began := time.Now()
+					result := checkRecord(context.Background(), keyDetector, validationManager, input.Record{Key: validatable[i]})
+					durations[i] = time.Since(began)
+					mu.Lock()
+					if result.Valid {
+						validation.Valid++
+					} else if result.ErrorCode != "" {
+						validation.Failed++
+					}
+					mu.Unlock()
+				}
+			}()
+		}
+		for i := range validatable {
+			jobs <- i
+		}
+		close(jobs)
+		wg.Wait()
+
+		validation.Seconds = time.Since(start).Seconds()
+		validation.Requests = replay.Requests()
+		validation.KeysPerSec = float64(validation.Keys) / validation.Seconds
+		validation.RequestsPerSec = float64(validation.Requests) / validation.Seconds
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+		validation.P50MS = percentile(durations, 0.50)
+		validation.P95MS = percentile(durations, 0.95)
+	}
+
+	if output.IsStructured(outputFormat) {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(map[string]interface{}{"detection": detection, "validation": validation})
+		return
+	}
+	fmt.Printf("Detection:  %d keys against %d patterns in %.2fs, %.0f keys/s, %d detected\n",
+		detection.Keys, detection.Patterns, detection.Seconds, detection.KeysPerSec, detection.Detected)
+	if validation.Keys == 0 {
+		fmt.Println("Validation: skipped, no detected key has a validator")
+		return
+	}
+	fmt.Printf("Validation: %d keys with %d threads in %.2fs, %.1f keys/s, %d requests (%.1f/s), p50 %.0fms, p95 %.0fms, %d valid, %d failed\n",
+		validation.Keys, validation.Threads, validation.Seconds, validation.KeysPerSec, validation.Requests,
+		validation.RequestsPerSec, validation.P50MS, validation.P95MS, validation.Valid, validation.Failed)
+}
+
+// benchCorpus returns the keys given with --key, --list or on stdin, or generates --keys keys:
+// random strings alternating with random keys of each pattern in turn
+func benchCorpus(keyDetector *detector.KeyDetector) []string {
+	var keys []string
+	if len(inputFiles) > 0 || len(apiKeys) > 0 || input.IsStdinPipe() {
+		err := streamRecords(func(rec input.Record) {
+			keys = append(keys, rec.Key)
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitError)
+		}
+		return keys
+	}
+
+	// A fixed seed keeps the corpus identical between runs so results are comparable
+	rng := rand.New(rand.NewSource(1))
+	var samples []string
+	for _, pattern := range keyDetector.Patterns() {
+		if key, ok := detector.SampleKey(pattern, rng); ok {
+			samples = append(samples, key)
+		}
+	}
+	const noise = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_"
+	keys = make([]string, 0, benchKeys)
+	for i := 0; i < benchKeys; i++ {
+		if i%2 == 1 && len(samples) > 0 {
+			keys = append(keys, samples[(i/2)%len(samples)])
+			continue
+		}
+		key := make([]byte, 10+rng.Intn(60))
+		for j := range key {
+			key[j] = noise[rng.Intn(len(noise))]
+		}
+		keys = append(keys, string(key))
+	}
+	return keys
+}
+
+// percentile returns the p-th percentile of sorted durations in milliseconds
+func percentile(sorted []time.Duration, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(float64(len(sorted)-1) * p)
+	return float64(sorted[i].Microseconds()) / 1000
+}
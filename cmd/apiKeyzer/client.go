@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/output"
+	"github.com/Xplo8E/APIKeyzer/internal/transport"
+)
+
+// fetchHeaderTimeout bounds how long a server of scanned content may take to start answering
+const fetchHeaderTimeout = 30 * time.Second
+
+var (
+	proxyURL        string
+	httpVersion     string
+	maxIdleConns    int
+	tlsMinVersion   string
+	noKeepAlive     bool
+	requestTimeout  time.Duration
+	maxResponseSize int64
+	threads         int
+	safeMode        bool
+	requestRate     float64
+	requestDelay    time.Duration
+	delayJitter     float64
+	noThrottle      bool
+	maxBackoff      time.Duration
+	printRequests   bool
+	dryRun          bool
+	userAgents      []string
+	userAgentFile   string
+	extraHeaders    []string
+	// serviceHeaders holds the per-service headers from the settings file, by service ID
+	serviceHeaders map[string]http.Header
+)
+
+// newHTTPClient builds the HTTP client shared by all validators from --proxy, the transport
+// tuning flags, --timeout, --rate, --user-agent, --header, --delay, --max-backoff and --print-requests
+func newHTTPClient() (*http.Client, error) {
+	base, err := newBaseTransport()
+	if err != nil {
+		return nil, err
+	}
+	return newClientOn(base)
+}
+
+// newFetchClient builds the client that reads scanned content, such as bucket listings and
+// objects. It shares --proxy, the transport tuning, --user-agent and the pacing of the
+// validation client, but not --header, --print-requests and --dry-run, which are meant for
+// validation requests, nor --timeout, which would cut large downloads short; servers are given
+// fetchHeaderTimeout to start answering instead.
+func newFetchClient() (*http.Client, error) {
+	base, err := newBaseTransport()
+	if err != nil {
+		return nil, err
+	}
+	base.ResponseHeaderTimeout = fetchHeaderTimeout
+	agents, err := loadUserAgents()
+	if err != nil {
+		return nil, err
+	}
+
+	var rt http.RoundTripper = transport.NewLogger(base)
+	rt = transport.NewUserAgent(rt, agents)
+	if !noThrottle {
+		rt = transport.NewThrottle(rt, maxBackoff)
+	}
+	return &http.Client{Transport: rt}, nil
+}
+
+// newBaseTransport returns the connection pool sized to --threads, with --proxy and the
+// transport tuning flags applied
+func newBaseTransport() (*http.Transport, error) {
+	// One pool of keep-alive connections sized to --threads serves every validator
+	base := transport.NewPooled(threads)
+	tuning := transport.Tuning{
+		HTTPVersion:         httpVersion,
+		MaxIdleConnsPerHost: maxIdleConns,
+		TLSMinVersion:       tlsMinVersion,
+		DisableKeepAlives:   noKeepAlive,
+	}
+	if err := tuning.Apply(base); err != nil {
+		return nil, err
+	}
+	if proxyURL != "" {
+		proxy, err := url.Parse(proxyURL)
+		if err != nil || proxy.Host == "" {
+			return nil, fmt.Errorf("invalid --proxy URL %q", proxyURL)
+		}
+		base.Proxy = http.ProxyURL(proxy)
+	}
+	return base, nil
+}
+
+// newClientOn stacks the logging, header, timeout and pacing transports selected by the
+// flags on base, which sends the requests
+func newClientOn(base http.RoundTripper) (*http.Client, error) {
+	if requestRate < 0 {
+		return nil, fmt.Errorf("invalid --rate %v: must not be negative", requestRate)
+	}
+	if requestDelay < 0 {
+		return nil, fmt.Errorf("invalid --delay %v: must not be negative", requestDelay)
+	}
+	if delayJitter < 0 || delayJitter > 1 {
+		return nil, fmt.Errorf("invalid --jitter %v: must be between 0 and 1", delayJitter)
+	}
+	if maxBackoff <= 0 {
+		return nil, fmt.Errorf("invalid --max-backoff %v: must be positive", maxBackoff)
+	}
+	agents, err := loadUserAgents()
+	if err != nil {
+		return nil, err
+	}
+	headers, err := parseHeaders(extraHeaders)
+	if err != nil {
+		return nil, err
+	}
+
+	// The timeout sits below the rate limiter so waiting for a slot does not count against it
+	var rt http.RoundTripper = transport.NewLogger(base)
+	if printRequests || dryRun {
+		// Printed below the header transports so requests are shown exactly as sent
+		var mask func(string) string
+		if maskKeys {
+			mask = output.MaskKey
+		}
+		rt = transport.NewPrinter(rt, os.Stderr, !dryRun, mask)
+	}
+	// Headers sits below the user agent so --header "User-Agent: ..." takes precedence
+	rt = transport.NewHeaders(rt, headers)
+	rt = transport.NewUserAgent(rt, agents)
+	if requestTimeout > 0 {
+		rt = transport.NewTimeout(rt, requestTimeout)
+	}
+	if !noThrottle {
+		// Above the timeout too, so pausing for a provider's rate limit does not count against it
+		rt = transport.NewThrottle(rt, maxBackoff)
+	}
+	if requestRate > 0 {
+		rt = transport.NewRateLimiter(rt, requestRate)
+	}
+	if requestDelay > 0 {
+		rt = transport.NewDelay(rt, requestDelay, delayJitter)
+	}
+	return &http.Client{Transport: rt}, nil
+}
+
+// parseHeaders parses "Name: value" headers
+func parseHeaders(lines []string) (http.Header, error) {
+	headers := make(http.Header)
+	for _, line := range lines {
+		name, value, found := strings.Cut(line, ":")
+		name = strings.TrimSpace(name)
+		if !found || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("invalid header %q: expected \"Name: value\"", line)
+		}
+		headers.Add(name, strings.TrimSpace(value))
+	}
+	return headers, nil
+}
+
+// loadUserAgents returns the user agents from --user-agent and --user-agent-file,
+// defaulting to one identifying apiKeyzer instead of Go's
+func loadUserAgents() ([]string, error) {
+	agents := append([]string(nil), userAgents...)
+	if userAgentFile != "" {
+		content, err := os.ReadFile(userAgentFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read user agent file: %w", err)
+		}
+		for _, line := range strings.Split(string(content), "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+				agents = append(agents, line)
+			}
+		}
+	}
+	if len(agents) == 0 {
+		agents = []string{"APIKeyzer/" + version}
+	}
+	return agents, nil
+}
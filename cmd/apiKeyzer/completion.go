@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/Xplo8E/APIKeyzer/internal/output"
+	"github.com/Xplo8E/APIKeyzer/internal/sink"
+	"github.com/Xplo8E/APIKeyzer/internal/transport"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+	"github.com/Xplo8E/APIKeyzer/internal/validator/services"
+	"github.com/spf13/cobra"
+)
+
+func newCompletionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "completion bash|zsh|fish|powershell",
+		Short: "Generate the shell completion script",
+		Long: `
+Writes a completion script for the given shell to stdout. Flags such as --output,
+--fail-on and --min-risk complete their accepted values, and service IDs are
+completed for the services command.
+
+Examples:
+  source <(apiKeyzer completion bash)
+  apiKeyzer completion zsh > "${fpath[1]}/_apiKeyzer"
+  apiKeyzer completion fish > ~/.config/fish/completions/apiKeyzer.fish
+  apiKeyzer completion powershell | Out-String | Invoke-Expression`,
+		ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+		Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		DisableFlagsInUseLine: true,
+		Run:                   runCompletion,
+	}
+}
+
+func runCompletion(cmd *cobra.Command, args []string) {
+	var err error
+	switch args[0] {
+	case "bash":
+		err = rootCmd.GenBashCompletionV2(os.Stdout, true)
+	case "zsh":
+		err = rootCmd.GenZshCompletion(os.Stdout)
+	case "fish":
+		err = rootCmd.GenFishCompletion(os.Stdout, true)
+	case "powershell":
+		err = rootCmd.GenPowerShellCompletionWithDesc(os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitError)
+	}
+}
+
+// registerCompletions adds value completion to the flags that accept a fixed set of values
+func registerCompletions(cmd *cobra.Command) {
+	values := map[string][]string{
+		"output":       append(append([]string{}, output.Formats...), output.ReportFormats...),
+		"report":       output.ReportFormats,
+		"fail-on":      {"any", "never", "risk=low", "risk=medium", "risk=high"},
+		"min-risk":     {string(validator.RiskLevelLow), string(validator.RiskLevelMedium), string(validator.RiskLevelHigh)},
+		"webhook-mode": {sink.ModeFinding, sink.ModeSummary},
+		"log-format":   {"text", "json"},
+		"theme":        output.Themes,
+		"http-version": transport.HTTPVersions,
+		"google-apis":  services.GoogleAPIs(),
+	}
+	for name, completions := range values {
+		cmd.RegisterFlagCompletionFunc(name, cobra.FixedCompletions(completions, cobra.ShellCompDirectiveNoFileComp))
+	}
+
+	// URL schemes are prefixes, so no space is added after them
+	prefixes := map[string][]string{
+		"syslog": {"local", "udp://", "tcp://"},
+		"upload": {"s3://", "gs://"},
+	}
+	for name, completions := range prefixes {
+		cmd.RegisterFlagCompletionFunc(name, cobra.FixedCompletions(completions, cobra.ShellCompDirectiveNoFileComp|cobra.ShellCompDirectiveNoSpace))
+	}
+}
+
+// completeServiceIDs completes the canonical IDs of the registered validators
+func completeServiceIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var ids []string
+	for _, service := range initValidators().GetSupportedServices() {
+		ids = append(ids, validator.ServiceID(service)+"\t"+service)
+	}
+	sort.Strings(ids)
+	return ids, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+var assumeYes bool
+
+// billableConsent remembers, per service, whether billable probes were confirmed during this run
+var billableConsent = struct {
+	sync.Mutex
+	answers map[string]bool
+}{answers: make(map[string]bool)}
+
+// confirmBillable asks once per service before its billable endpoints are first called,
+// returning ctx with safe mode enabled when the user declines
+func confirmBillable(ctx context.Context, vm *validator.ValidationManager, service string) context.Context {
+	if assumeYes || safeMode || dryRun || validator.SafeMode(ctx) {
+		return ctx
+	}
+	endpoints := billableEndpoints(vm, service)
+	if len(endpoints) == 0 {
+		return ctx
+	}
+
+	// Holding the lock while prompting makes the other workers wait for the answer
+	billableConsent.Lock()
+	allowed, asked := billableConsent.answers[service]
+	if !asked {
+		allowed = askBillable(service, endpoints)
+		billableConsent.answers[service] = allowed
+	}
+	billableConsent.Unlock()
+
+	if !allowed {
+		return validator.WithSafeMode(ctx, true)
+	}
+	return ctx
+}
+
+// billableEndpoints returns the endpoints of a service's validator that may be billed to the key owner
+func billableEndpoints(vm *validator.ValidationManager, service string) []validator.Endpoint {
+	v, exists := vm.GetValidator(service)
+	if !exists {
+		return nil
+	}
+	lister, ok := v.(validator.EndpointLister)
+	if !ok {
+		return nil
+	}
+	var billable []validator.Endpoint
+	for _, endpoint := range lister.Endpoints() {
+		if endpoint.Billable {
+			billable = append(billable, endpoint)
+		}
+	}
+	return billable
+}
+
+// askBillable prompts on the terminal, which stays available even when keys are piped on stdin.
+// Without a terminal the billable endpoints are skipped, as in safe mode
+func askBillable(service string, endpoints []validator.Endpoint) bool {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		warnf("Skipping billable %s endpoints: no terminal to confirm them (pass --yes to call them)\n", service)
+		return false
+	}
+	defer tty.Close()
+
+	fmt.Fprintf(tty, "Validating %s keys calls %d endpoint(s) that may be billed to the key owner:\n", service, len(endpoints))
+	for _, endpoint := range endpoints {
+		fmt.Fprintf(tty, "  %s %s\n", endpoint.Method, endpoint.URL)
+	}
+	fmt.Fprint(tty, "Call them? [y/N] ")
+	answer, _ := bufio.NewReader(tty).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	warnf("Skipping billable %s endpoints\n", service)
+	return false
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/input"
+	"github.com/Xplo8E/APIKeyzer/internal/output"
+	"github.com/Xplo8E/APIKeyzer/internal/queue"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+// maxReconnectDelay caps the backoff between attempts to reconnect to the input queue
+const maxReconnectDelay = 30 * time.Second
+
+var (
+	daemonFrom  string
+	daemonTo    string
+	daemonGroup string
+)
+
+func newDaemonCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Validate keys consumed from a Redis list or NATS subject and publish the results",
+		Long: `
+Runs as a worker in a recon pipeline: messages are consumed from a Redis list or
+a NATS subject (--from), the keys in them are validated with --threads workers
+and every result is published as a JSON object, as with --output json, to
+another list or subject (--to). Results are also written in the selected output
+format, and --store, --mask, --only-valid, --min-risk and the result sinks apply.
+
+A message is one of:
+  a JSON object with a "key" field    its other fields are carried as metadata
+  a JSON object with a "content" field an artifact, such as a file or HTTP
+                                       response, searched for keys; "source"
+                                       names it and other fields are metadata
+  plain text                          searched for keys line by line
+
+Keys of services without a validator are skipped. Run several daemons on the
+same Redis list, or with the same --queue-group on a NATS subject, to share the
+work. Delivery is at most once: messages being validated when a worker stops
+are lost. The daemon reconnects to the input queue with backoff.
+
+Queue URLs:
+  redis://[:password@]host[:port]/[db/]list   (rediss:// for TLS)
+  nats://[user:password@]host[:port]/subject  (nats://token@host/subject for token auth)
+
+Examples:
+  apiKeyzer daemon --from redis://localhost/apikeyzer:keys --to redis://localhost/apikeyzer:results
+  apiKeyzer daemon --from nats://localhost/recon.keys --to nats://localhost/recon.results --queue-group apikeyzer -t 10 --only-valid`,
+		Args: cobra.NoArgs,
+		Run:  runDaemon,
+	}
+
+	cmd.Flags().StringVar(&daemonFrom, "from", "", "Redis list or NATS subject to consume keys and artifacts from (required)")
+	cmd.Flags().StringVar(&daemonTo, "to", "", "Redis list or NATS subject to publish results to")
+	cmd.Flags().StringVar(&daemonGroup, "queue-group", "", "NATS queue group shared by the workers consuming --from")
+	cmd.MarkFlagRequired("from")
+
+	return cmd
+}
+
+func runDaemon(cmd *cobra.Command, args []string) {
+	keyDetector, validationManager := setup()
+	defer closeStore()
+
+	// Billable probes are confirmed up front since messages cannot be answered from the terminal
+	for _, service := range validationManager.GetSupportedServices() {
+		confirmBillable(context.Background(), validationManager, service)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	runTarget = queue.Name(daemonFrom)
+	writer := newResultWriter()
+	if daemonTo != "" {
+		conn, err := queue.Dial(ctx, daemonTo, "")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitError)
+		}
+		opts := output.Options{OnlyValid: onlyValid, MinRisk: minRiskLevel, Mask: maskKeys}
+		writer = output.Synchronized(output.MultiWriter(output.Wrap(&queueWriter{conn: conn, target: daemonTo}, opts), writer))
+	}
+	defer closeWriter(writer)
+
+	// Validations run to completion on shutdown, so they use their own context
+	var wg sync.WaitGroup
+	records := make(chan input.Record)
+	for w := 0; w < max(threads, 1); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for rec := range records {
+				result := checkRecord(context.Background(), keyDetector, validationManager, rec)
+				writeRecordResult(writer, result, rec)
+			}
+		}()
+	}
+
+	consumeQueue(ctx, func(message []byte) {
+		recs, err := input.ParseMessage(message, runTarget)
+		if err != nil {
+			slog.Warn("skipping queue message", "error", err)
+			return
+		}
+		for _, rec := range recs {
+			service := keyDetector.DetectService(rec.Key)
+			if service == "" {
+				continue
+			}
+			if _, exists := validationManager.GetValidator(service); !exists {
+				continue
+			}
+			select {
+			case records <- rec:
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+
+	close(records)
+	wg.Wait()
+}
+
+// consumeQueue passes every message of --from to handle until ctx is done, reconnecting with backoff
+func consumeQueue(ctx context.Context, handle func([]byte)) {
+	var conn queue.Conn
+	delay := time.Second
+	for ctx.Err() == nil {
+		if conn == nil {
+			var err error
+			conn, err = queue.Dial(ctx, daemonFrom, daemonGroup)
+			if err != nil {
+				slog.Warn("failed to connect to queue, retrying", "queue", runTarget, "delay", delay, "error", err)
+				select {
+				case <-time.After(delay):
+				case <-ctx.Done():
+				}
+				delay = min(delay*2, maxReconnectDelay)
+				continue
+			}
+			delay = time.Second
+			warnf("Consuming %s\n", runTarget)
+		}
+
+		message, err := conn.Receive(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Warn("queue connection failed, reconnecting", "queue", runTarget, "error", err)
+			}
+			conn.Close()
+			conn = nil
+			continue
+		}
+		handle(message)
+	}
+	if conn != nil {
+		conn.Close()
+	}
+}
+
+// queueWriter publishes every result as a JSON object, reconnecting once when publishing fails
+type queueWriter struct {
+	conn   queue.Conn
+	target string
+}
+
+func (q *queueWriter) WriteResult(result *validator.ValidationResult) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	if err := q.conn.Publish(ctx, data); err == nil {
+		return nil
+	}
+	q.conn.Close()
+	conn, err := queue.Dial(ctx, q.target, "")
+	if err != nil {
+		return fmt.Errorf("failed to publish result: %w", err)
+	}
+	q.conn = conn
+	return q.conn.Publish(ctx, data)
+}
+
+func (q *queueWriter) Close() error {
+	return q.conn.Close()
+}
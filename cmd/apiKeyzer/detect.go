@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/Xplo8E/APIKeyzer/internal/detector"
+	"github.com/Xplo8E/APIKeyzer/internal/input"
+	"github.com/Xplo8E/APIKeyzer/internal/output"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+// detection is the detect command's result for a single key
+type detection struct {
+	Key        string              `json:"key"`
+	Service    string              `json:"service,omitempty"`
+	Confidence float64             `json:"confidence"`
+	Reasons    []string            `json:"reasons"`
+	Validator  bool                `json:"validator"`
+	Location   *validator.Location `json:"location,omitempty"`
+}
+
+func newDetectCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "detect [paths...]",
+		Short: "Identify the service of API keys without validating them",
+		Long: `
+Matches keys from --key, --list, stdin or the files under the given paths against
+the detection patterns and prints the service attributed to each one, with a
+confidence score and the reasons for it (shown with -v). No network requests are
+made, so it is safe for air-gapped triage and for testing custom patterns.
+
+Keys given directly are always reported; in scanned paths only candidates that
+match a pattern are.
+
+Examples:
+  apiKeyzer detect --key "YOUR-API-KEY" -v
+  apiKeyzer detect --list keys.txt --output json
+  apiKeyzer detect ./src --config custom-patterns.json`,
+		Run: runDetect,
+	}
+}
+
+func runDetect(cmd *cobra.Command, args []string) {
+	if len(args) == 0 && len(inputFiles) == 0 && len(apiKeys) == 0 && !input.IsStdinPipe() {
+		cmd.Help()
+		return
+	}
+
+	keyDetector, validationManager := setup()
+	defer closeStore()
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetEscapeHTML(false)
+	report := func(rec input.Record, onlyDetected bool) {
+		d := newDetection(keyDetector, validationManager, rec.Key)
+		if onlyDetected && d.Service == "" {
+			return
+		}
+		d.Location = recordLocation(rec)
+		if output.IsStructured(outputFormat) {
+			encoder.Encode(d)
+			return
+		}
+		printDetection(d)
+	}
+
+	if len(inputFiles) > 0 || len(apiKeys) > 0 || input.IsStdinPipe() {
+		err := streamRecords(func(rec input.Record) {
+			report(rec, false)
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitError)
+		}
+	}
+
+	if len(args) > 0 {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		fileScanner := input.NewFileScanner()
+		fileScanner.SetMaxLineSize(maxLineSize)
+		seen := make(map[string]bool)
+		for _, path := range args {
+			err := fileScanner.Scan(ctx, path, func(rec input.Record) {
+				id := fmt.Sprintf("%s\x00%s\x00%d", rec.Key, rec.Source, rec.Line)
+				if !seen[id] {
+					seen[id] = true
+					report(rec, true)
+				}
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error scanning %s: %v\n", path, err)
+				os.Exit(exitError)
+			}
+		}
+	}
+}
+
+// newDetection attributes a key to a service without validating it
+func newDetection(keyDetector *detector.KeyDetector, validationManager *validator.ValidationManager, key string) detection {
+	detailed := keyDetector.DetectServiceDetailed(key)
+	d := detection{
+		Key:        displayKey(key),
+		Service:    detailed.Service,
+		Confidence: detailed.Confidence,
+		Reasons:    detailed.Reasons,
+	}
+	_, d.Validator = validationManager.GetValidator(d.Service)
+	return d
+}
+
+// printDetection prints a detection for terminals, with its reasons in verbose mode
+func printDetection(d detection) {
+	location := ""
+	if d.Location != nil {
+		location = " (" + d.Location.String() + ")"
+	}
+	switch {
+	case d.Service == "":
+		fmt.Printf("%s %s%s\n", output.Yellow("[?] Unknown service:"), d.Key, location)
+	case d.Validator:
+		fmt.Printf("%s %s [%.2f]: %s%s\n", output.Green("[+]"), d.Service, d.Confidence, d.Key, location)
+	default:
+		fmt.Printf("%s %s [%.2f] (no validator): %s%s\n", output.Cyan("[~]"), d.Service, d.Confidence, d.Key, location)
+	}
+	if verbose {
+		for _, reason := range d.Reasons {
+			fmt.Printf("    - %s\n", reason)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/diff"
+	"github.com/Xplo8E/APIKeyzer/internal/output"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+func newDiffCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "diff <before.json> <after.json> | diff <after.json> --store <db>",
+		Short: "Compare two runs and report rotated and newly valid keys",
+		Long: `
+Compares two runs written with --output json, or a run against the latest results
+recorded in a --store database before it, and reports keys that became invalid
+(rotated), became valid, stayed valid or were not checked again.
+
+Examples:
+  apiKeyzer diff before.json after.json
+  apiKeyzer diff retest.json --store apikeyzer.db --output json`,
+		Args: cobra.RangeArgs(1, 2),
+		Run:  runDiff,
+	}
+}
+
+func runDiff(cmd *cobra.Command, args []string) {
+	after, err := diff.LoadRun(args[len(args)-1])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitError)
+	}
+
+	var before []*validator.ValidationResult
+	switch {
+	case len(args) == 2:
+		before, err = diff.LoadRun(args[0])
+	case storePath != "":
+		before, err = storedRun(after)
+	default:
+		err = fmt.Errorf("diff needs two runs, or one run and --store")
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitError)
+	}
+
+	// --fail-on only counts keys confirmed valid by the new run; rechecks that failed with an
+	// error are reported as not rechecked
+	changes := diff.Compare(before, after)
+	for _, change := range changes {
+		if change.Status == diff.StatusNewlyValid || change.Status == diff.StatusStillValid {
+			gate.observe(&validator.ValidationResult{Valid: true, RiskLevel: *change.After})
+		}
+	}
+
+	runTarget = args[len(args)-1]
+	out := openOutputFile()
+	defer closeOutputFile(out)
+
+	if outputFormat == output.FormatJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetEscapeHTML(false)
+		for _, change := range changes {
+			change.Key = displayKey(change.Key)
+			encoder.Encode(change)
+		}
+		return
+	}
+
+	counts := make(map[diff.Status]int)
+	for _, change := range changes {
+		counts[change.Status]++
+		if change.Status == diff.StatusUnchanged && !verbose {
+			continue
+		}
+		fmt.Fprintf(out, "%s %s %s %s%s\n", diffLabel(change.Status), change.FindingID, change.Service,
+			displayKey(change.Key), diffDetail(change))
+	}
+	fmt.Fprintf(out, "\n%d newly valid, %d still valid, %d rotated, %d not rechecked\n",
+		counts[diff.StatusNewlyValid], counts[diff.StatusStillValid], counts[diff.StatusRotated], counts[diff.StatusMissing])
+}
+
+// storedRun rebuilds the state before a run from the latest store entries older than it
+func storedRun(after []*validator.ValidationResult) ([]*validator.ValidationResult, error) {
+	openStore()
+	defer closeStore()
+
+	since := time.Now()
+	for _, result := range after {
+		if !result.ValidatedAt.IsZero() && result.ValidatedAt.Before(since) {
+			since = result.ValidatedAt
+		}
+	}
+
+	entries, err := resultStore.LatestBefore(context.Background(), since)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*validator.ValidationResult, 0, len(entries))
+	for _, entry := range entries {
+		result := &validator.ValidationResult{
+			FindingID:   entry.FindingID,
+			Valid:       entry.Valid,
+			Service:     entry.Service,
+			RiskLevel:   entry.RiskLevel,
+			ValidatedAt: entry.ValidatedAt,
+		}
+		if entry.Location != "" {
+			result.Location = &validator.Location{Path: entry.Location}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// diffLabel returns the colored label printed for a change status
+func diffLabel(status diff.Status) string {
+	label := "[" + strings.ToUpper(strings.ReplaceAll(string(status), "_", " ")) + "]"
+	switch status {
+	case diff.StatusNewlyValid, diff.StatusStillValid:
+		return output.Red(label)
+	case diff.StatusRotated:
+		return output.Green(label)
+	default:
+		return output.Yellow(label)
+	}
+}
+
+// diffDetail describes the risk change and location of a finding
+func diffDetail(change diff.Change) string {
+	var parts []string
+	switch {
+	case change.Before != nil && change.After != nil && *change.Before != *change.After:
+		parts = append(parts, fmt.Sprintf("risk %s -> %s", *change.Before, *change.After))
+	case change.After != nil:
+		parts = append(parts, fmt.Sprintf("risk %s", *change.After))
+	case change.Before != nil:
+		parts = append(parts, fmt.Sprintf("was %s risk", *change.Before))
+	}
+	if change.Location != nil {
+		parts = append(parts, change.Location.String())
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+)
+
+func newGenDocsCmd() *cobra.Command {
+	var dir string
+	cmd := &cobra.Command{
+		Use:    "gen-docs",
+		Short:  "Generate man pages and markdown docs for every command",
+		Hidden: true,
+		Args:   cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return genDocs(dir)
+		},
+	}
+	cmd.Flags().StringVar(&dir, "dir", "docs", "Directory to write the man/ and md/ docs to")
+	return cmd
+}
+
+// genDocs writes man pages to dir/man and markdown pages to dir/md
+func genDocs(dir string) error {
+	root := rootCmd
+	// The generated pages should not depend on the machine or date they were built on
+	root.DisableAutoGenTag = true
+
+	manDir, mdDir := dir+"/man", dir+"/md"
+	for _, d := range []string{manDir, mdDir} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			return fmt.Errorf("failed to create docs directory: %w", err)
+		}
+	}
+
+	header := &doc.GenManHeader{Title: "APIKEYZER", Section: "1", Source: "apiKeyzer " + version}
+	if err := doc.GenManTree(root, header, manDir); err != nil {
+		return fmt.Errorf("failed to generate man pages: %w", err)
+	}
+	if err := doc.GenMarkdownTree(root, mdDir); err != nil {
+		return fmt.Errorf("failed to generate markdown docs: %w", err)
+	}
+	return nil
+}
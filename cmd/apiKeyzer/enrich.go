@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Xplo8E/APIKeyzer/internal/input"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+var enrichFormat string
+
+func newEnrichCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "enrich [report]",
+		Short: "Validate secrets from a TruffleHog or Gitleaks report and re-emit it with results",
+		Long: `
+Reads a Gitleaks JSON report or TruffleHog JSON-lines output (from a file or stdin),
+validates every reported secret and writes the same report back with "service",
+"verified", "permissions" and "risk_level" fields added to each finding.
+
+Examples:
+  apiKeyzer enrich gitleaks-report.json
+  trufflehog filesystem . --json | apiKeyzer enrich --format trufflehog`,
+		Args: cobra.MaximumNArgs(1),
+		Run:  runEnrich,
+	}
+
+	cmd.Flags().StringVarP(&enrichFormat, "format", "f", "", "Report format: gitleaks or trufflehog (detected automatically if not provided)")
+	cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"gitleaks", "trufflehog"}, cobra.ShellCompDirectiveNoFileComp))
+
+	return cmd
+}
+
+func runEnrich(cmd *cobra.Command, args []string) {
+	var data []byte
+	var err error
+
+	switch {
+	case len(args) == 1:
+		data, err = os.ReadFile(args[0])
+	case input.IsStdinPipe():
+		data, err = io.ReadAll(os.Stdin)
+	default:
+		cmd.Help()
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading report: %v\n", err)
+		os.Exit(exitError)
+	}
+
+	runTarget = "report"
+	if len(args) == 1 {
+		runTarget = args[0]
+	}
+	report, err := input.ParseReport(data, enrichFormat)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitError)
+	}
+
+	keyDetector, validationManager := setup()
+	defer closeStore()
+	// Findings still unchecked when --max-duration elapses are reported unverified
+	ctx, cancel := withMaxDuration(context.Background())
+	defer cancel()
+	cache := make(map[string]*validator.ValidationResult)
+
+	for _, finding := range report.Findings {
+		secret := report.Secret(finding)
+		finding["verified"] = false
+		if secret == "" {
+			continue
+		}
+
+		service := keyDetector.DetectService(secret)
+		if service == "" {
+			continue
+		}
+		finding["service"] = service
+
+		result, cached := cache[secret]
+		if !cached {
+			result, err = validateKey(ctx, validationManager, service, input.Record{Key: secret})
+			if err != nil {
+				finding["validation_error"] = err.Error()
+				continue
+			}
+			cache[secret] = result
+		}
+
+		gate.observe(result)
+		finding["verified"] = result.Valid
+		finding["finding_id"] = result.FindingID
+		finding["permissions"] = result.Permissions
+		finding["risk_level"] = result.RiskLevel
+	}
+
+	encoded, err := report.Encode()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error encoding report: %v\n", err)
+		os.Exit(exitError)
+	}
+	out := openOutputFile()
+	if _, err := out.Write(encoded); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing report: %v\n", err)
+		os.Exit(exitError)
+	}
+	closeOutputFile(out)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Xplo8E/APIKeyzer/internal/output"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// Process exit codes
+const (
+	exitOK       = 0 // no findings at or above the --fail-on threshold
+	exitFindings = 1 // valid keys at or above the --fail-on threshold were found
+	exitError    = 2 // runtime or usage error
+)
+
+// failGate records valid findings and decides whether the run should fail
+type failGate struct {
+	threshold validator.RiskLevel // empty disables failing on findings
+	failed    bool
+}
+
+var gate = failGate{threshold: validator.RiskLevelLow}
+
+// parseFailOn parses a --fail-on value such as "risk=medium", "high", "any" or "never"
+func parseFailOn(value string) (validator.RiskLevel, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch value {
+	case "any", "valid":
+		return validator.RiskLevelLow, nil
+	case "never", "none":
+		return "", nil
+	}
+
+	level, err := validator.ParseRiskLevel(strings.TrimPrefix(value, "risk="))
+	if err != nil {
+		return "", fmt.Errorf("invalid --fail-on value: %w", err)
+	}
+	return level, nil
+}
+
+// observe marks the run as failed if result is a valid key at or above the threshold
+func (g *failGate) observe(result *validator.ValidationResult) {
+	if g.threshold != "" && result.Valid && result.RiskLevel.Rank() >= g.threshold.Rank() {
+		g.failed = true
+	}
+}
+
+// exitCode returns the process exit code for a run that finished without errors
+func (g *failGate) exitCode() int {
+	if g.failed {
+		return exitFindings
+	}
+	return exitOK
+}
+
+// gateWriter feeds every result to the fail gate before writing it
+type gateWriter struct {
+	output.Writer
+	gate *failGate
+}
+
+func (g *gateWriter) WriteResult(result *validator.ValidationResult) error {
+	g.gate.observe(result)
+	return g.Writer.WriteResult(result)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Xplo8E/APIKeyzer/internal/firebase"
+	"github.com/Xplo8E/APIKeyzer/internal/input"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+var firebaseConfig firebase.Config
+
+func newFirebaseCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "firebase [config-file]",
+		Short: "Audit a Firebase project for open signup and world-readable data",
+		Long: `
+Checks the Firebase project of an app configuration for the misconfigurations
+that its public API key and project ID expose, reporting each one as a separate
+finding:
+
+  Firebase Open Signup         anyone can create an account (email or anonymous)
+  Firebase Realtime Database   the database can be read without signing in
+  Firebase Firestore           collections can be read without signing in
+  Firebase Storage             the bucket can be listed without signing in
+
+The configuration is read from a google-services.json file or a JavaScript
+firebaseConfig blob (from a file or stdin), or given with --api-key and
+--project; flags override the values of the file. Accounts created by the
+signup check are deleted right away. The signup check needs the API key.
+
+Examples:
+  apiKeyzer firebase google-services.json
+  apiKeyzer firebase --api-key AIza... --project my-app
+  curl -s https://example.com/main.js | apiKeyzer firebase --output json`,
+		Args: cobra.MaximumNArgs(1),
+		Run:  runFirebase,
+	}
+
+	cmd.Flags().StringVar(&firebaseConfig.APIKey, "api-key", "", "Web API key of the Firebase app")
+	cmd.Flags().StringVar(&firebaseConfig.ProjectID, "project", "", "Firebase project ID")
+	cmd.Flags().StringVar(&firebaseConfig.DatabaseURL, "database-url", "", "Realtime Database URL (default https://<project>-default-rtdb.firebaseio.com)")
+	cmd.Flags().StringVar(&firebaseConfig.StorageBucket, "bucket", "", "Storage bucket (default <project>.appspot.com, then <project>.firebasestorage.app)")
+
+	return cmd
+}
+
+func runFirebase(cmd *cobra.Command, args []string) {
+	var data []byte
+	var err error
+	switch {
+	case len(args) == 1:
+		data, err = os.ReadFile(args[0])
+	case firebaseConfig.ProjectID == "" && input.IsStdinPipe():
+		data, err = io.ReadAll(os.Stdin)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading config: %v\n", err)
+		os.Exit(exitError)
+	}
+
+	cfg := &firebase.Config{}
+	if data != nil {
+		if cfg, err = firebase.ParseConfig(data); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitError)
+		}
+	}
+	// Flags override the values of the config file
+	for field, value := range map[*string]string{
+		&cfg.APIKey:        firebaseConfig.APIKey,
+		&cfg.ProjectID:     firebaseConfig.ProjectID,
+		&cfg.DatabaseURL:   firebaseConfig.DatabaseURL,
+		&cfg.StorageBucket: firebaseConfig.StorageBucket,
+	} {
+		if value != "" {
+			*field = value
+		}
+	}
+	if cfg.Check() != nil {
+		cmd.Help()
+		return
+	}
+
+	client, err := newHTTPClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitError)
+	}
+	ctx, cancel := withMaxDuration(validator.WithMaxResponseSize(context.Background(), maxResponseSize))
+	defer cancel()
+
+	runTarget = cfg.ProjectID
+	writer := newResultWriter()
+	defer closeWriter(writer)
+	for _, result := range firebase.NewAuditor(client).Audit(ctx, cfg) {
+		if err := writer.WriteResult(result); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing result: %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/Xplo8E/APIKeyzer/internal/detector"
+	"github.com/Xplo8E/APIKeyzer/internal/github"
+	"github.com/Xplo8E/APIKeyzer/internal/input"
+	"github.com/Xplo8E/APIKeyzer/internal/output"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// maxWebhookSize bounds webhook deliveries; GitHub caps payloads at 25 MB
+const maxWebhookSize = 25 << 20
+
+// maxCheckSummary is the size limit GitHub puts on the summary of a check run
+const maxCheckSummary = 65535
+
+// truncatedNote ends a summary cut down to maxCheckSummary
+const truncatedNote = "\n\n(truncated)"
+
+// checkRunName is the name the check runs are shown under on commits and pull requests
+const checkRunName = "APIKeyzer"
+
+var (
+	githubWebhookSecret string
+	githubAppID         string
+	githubAppKey        string
+	githubToken         string
+	githubAPIURL        string
+)
+
+// githubHook scans the changes of push and pull request webhooks and reports them as check runs
+type githubHook struct {
+	ctx               context.Context
+	secret            []byte
+	client            *github.Client
+	detector          *detector.KeyDetector
+	validationManager *validator.ValidationManager
+	wg                sync.WaitGroup
+}
+
+// newGitHubHook returns nil when no webhook secret is configured
+func newGitHubHook(ctx context.Context, keyDetector *detector.KeyDetector, validationManager *validator.ValidationManager) (*githubHook, error) {
+	secret := githubWebhookSecret
+	if secret == "" {
+		secret = os.Getenv("APIKEYZER_GITHUB_WEBHOOK_SECRET")
+	}
+	if secret == "" {
+		return nil, nil
+	}
+
+	client := github.NewClient(githubAPIURL, version)
+	token := githubToken
+	if token == "" {
+		token = os.Getenv("GITHUB_TOKEN")
+	}
+	client.SetToken(token)
+	if githubAppID != "" || githubAppKey != "" {
+		if githubAppID == "" || githubAppKey == "" {
+			return nil, fmt.Errorf("--github-app-id and --github-app-key must be set together")
+		}
+		key, err := os.ReadFile(githubAppKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read GitHub App key: %w", err)
+		}
+		if err := client.SetApp(githubAppID, key); err != nil {
+			return nil, err
+		}
+	} else if token == "" {
+		return nil, fmt.Errorf("the GitHub webhook requires --github-app-id and --github-app-key, or --github-token")
+	}
+
+	return &githubHook{
+		ctx:               ctx,
+		secret:            []byte(secret),
+		client:            client,
+		detector:          keyDetector,
+		validationManager: validationManager,
+	}, nil
+}
+
+// ServeHTTP accepts a delivery and scans it in the background, since GitHub gives up after 10 seconds
+func (h *githubHook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookSize))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := github.VerifySignature(h.secret, r.Header.Get(github.SignatureHeader), body); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	event, err := github.ParseEvent(r.Header.Get(github.EventHeader), body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if event == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	delivery := r.Header.Get(github.DeliveryHeader)
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		if err := h.scan(event); err != nil {
+			slog.Error("GitHub webhook scan failed", "delivery", delivery, "repo", event.Repo.FullName(), "commit", event.Head, "error", err)
+		}
+	}()
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Wait blocks until the scans of accepted deliveries have finished
+func (h *githubHook) Wait() {
+	h.wg.Wait()
+}
+
+// scan validates the keys added by an event and reports them on a check run
+func (h *githubHook) scan(event *github.Event) error {
+	ctx := h.ctx
+	runID, err := h.client.StartCheckRun(ctx, event, checkRunName)
+	if err != nil {
+		return fmt.Errorf("failed to create check run: %w", err)
+	}
+
+	conclusion, out, scanErr := h.check(ctx, event)
+	if scanErr != nil {
+		// Check runs must be completed, so a failed scan is reported rather than left pending
+		conclusion = "neutral"
+		out = github.CheckOutput{Title: "Scan failed", Summary: fmt.Sprintf("The changes could not be scanned: %v", scanErr)}
+	}
+	// The scan context may be cancelled on shutdown, but the run should still be completed
+	if err := h.client.CompleteCheckRun(context.WithoutCancel(ctx), event, runID, conclusion, out); err != nil {
+		return fmt.Errorf("failed to complete check run: %w", err)
+	}
+	return scanErr
+}
+
+// check scans the lines added by an event and builds the check run conclusion and output
+func (h *githubHook) check(ctx context.Context, event *github.Event) (string, github.CheckOutput, error) {
+	files, err := h.client.ChangedFiles(ctx, event)
+	if err != nil {
+		return "", github.CheckOutput{}, err
+	}
+
+	// Keys are always masked since check runs are visible to anyone who can read the repository
+	var summary bytes.Buffer
+	markdown, err := output.New(output.FormatMarkdown, &summary, output.Options{ToolVersion: version})
+	if err != nil {
+		return "", github.CheckOutput{}, err
+	}
+	collector := &checkRunWriter{summary: markdown}
+	opts := output.Options{OnlyValid: onlyValid, MinRisk: minRiskLevel, Mask: true}
+	processor := newScanProcessor(h.detector, h.validationManager, output.Wrap(collector, opts))
+
+	for _, file := range files {
+		if file.Status == "removed" || file.Patch == "" {
+			continue
+		}
+		for _, line := range github.AddedLines(file.Patch) {
+			for _, candidate := range input.ExtractCandidates(line.Text) {
+				if ctx.Err() != nil {
+					return "", github.CheckOutput{}, ctx.Err()
+				}
+				processor.process(ctx, input.Record{Key: candidate.Value, Source: file.Filename, Line: line.Number, Column: candidate.Column})
+			}
+		}
+	}
+	if err := markdown.Close(); err != nil {
+		return "", github.CheckOutput{}, err
+	}
+
+	out := github.CheckOutput{Annotations: collector.annotations, Summary: summary.String()}
+	if len(out.Summary) > maxCheckSummary {
+		out.Summary = strings.ToValidUTF8(out.Summary[:maxCheckSummary-len(truncatedNote)], "") + truncatedNote
+	}
+	switch {
+	case collector.valid > 0:
+		out.Title = fmt.Sprintf("%d valid key(s) in %d file(s)", collector.valid, len(files))
+		return "failure", out, nil
+	case len(collector.annotations) > 0:
+		out.Title = fmt.Sprintf("%d key(s) could not be validated", len(collector.annotations))
+		return "neutral", out, nil
+	}
+	out.Title = "No valid keys found"
+	if out.Summary == "" {
+		out.Summary = fmt.Sprintf("No valid API keys were found in the lines added to %d file(s).", len(files))
+	}
+	return "success", out, nil
+}
+
+// checkRunWriter turns masked results into check run annotations and a Markdown summary
+type checkRunWriter struct {
+	summary     output.Writer
+	annotations []github.Annotation
+	valid       int
+}
+
+func (c *checkRunWriter) WriteResult(result *validator.ValidationResult) error {
+	if result.Location == nil {
+		return nil
+	}
+	annotation := github.Annotation{
+		Path:      result.Location.Path,
+		StartLine: result.Location.Line,
+		EndLine:   result.Location.Line,
+	}
+	switch {
+	case result.Valid:
+		c.valid++
+		annotation.Level = "failure"
+		annotation.Title = fmt.Sprintf("Valid %s", result.Service)
+		annotation.Message = fmt.Sprintf("%s is a valid %s (%s risk)", result.Key, result.Service, result.RiskLevel)
+		if len(result.Permissions) > 0 {
+			annotation.Message += "\nAccepted by: " + strings.Join(result.Permissions, ", ")
+		}
+		annotation.Message += "\nRevoke or restrict the key and remove it from the repository."
+	case result.ErrorCode != "":
+		annotation.Level = "warning"
+		annotation.Title = fmt.Sprintf("Could not validate key (%s)", result.ErrorCode)
+		annotation.Message = fmt.Sprintf("%s could not be validated: %s", result.Key, result.ErrorStr)
+	default:
+		return nil
+	}
+	c.annotations = append(c.annotations, annotation)
+	return c.summary.WriteResult(result)
+}
+
+func (c *checkRunWriter) Close() error {
+	return nil
+}
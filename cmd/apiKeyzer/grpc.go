@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/Xplo8E/APIKeyzer/internal/detector"
+	"github.com/Xplo8E/APIKeyzer/internal/input"
+	"github.com/Xplo8E/APIKeyzer/internal/output"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+	apikeyzerv1 "github.com/Xplo8E/APIKeyzer/proto/apikeyzer/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var grpcAddr string
+
+// keyServer implements the gRPC KeyService with the same checks as the HTTP API
+type keyServer struct {
+	apikeyzerv1.UnimplementedKeyServiceServer
+	detector          *detector.KeyDetector
+	validationManager *validator.ValidationManager
+}
+
+func newGRPCServer(keyDetector *detector.KeyDetector, validationManager *validator.ValidationManager) *grpc.Server {
+	server := grpc.NewServer()
+	apikeyzerv1.RegisterKeyServiceServer(server, &keyServer{detector: keyDetector, validationManager: validationManager})
+	return server
+}
+
+func (s *keyServer) Detect(ctx context.Context, req *apikeyzerv1.DetectRequest) (*apikeyzerv1.DetectResponse, error) {
+	if len(req.GetKeys()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no keys in request")
+	}
+	resp := &apikeyzerv1.DetectResponse{}
+	for _, key := range req.GetKeys() {
+		d := newDetection(s.detector, s.validationManager, key)
+		resp.Detections = append(resp.Detections, &apikeyzerv1.Detection{
+			Key:        d.Key,
+			Service:    d.Service,
+			Confidence: d.Confidence,
+			Reasons:    d.Reasons,
+			Validator:  d.Validator,
+		})
+	}
+	return resp, nil
+}
+
+func (s *keyServer) Validate(req *apikeyzerv1.ValidateRequest, stream grpc.ServerStreamingServer[apikeyzerv1.ValidationResult]) error {
+	if len(req.GetKeys()) == 0 {
+		return status.Error(codes.InvalidArgument, "no keys in request")
+	}
+	ctx := stream.Context()
+	opts := output.Options{Verbose: verbose, ToolVersion: version, OnlyValid: onlyValid, MinRisk: minRiskLevel, Mask: maskKeys}
+	writer := output.Wrap(&streamWriter{stream: stream}, opts)
+
+	// Up to --threads keys are validated at once; results are sent as they complete
+	var mu sync.Mutex
+	var sendErr error
+	var wg sync.WaitGroup
+	jobs := make(chan string)
+	for w := 0; w < max(threads, 1); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range jobs {
+				result := checkRecord(ctx, s.detector, s.validationManager, input.Record{Key: key})
+				mu.Lock()
+				if sendErr == nil {
+					sendErr = writer.WriteResult(result)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+send:
+	for _, key := range req.GetKeys() {
+		select {
+		case jobs <- key:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if sendErr != nil {
+		return sendErr
+	}
+	return ctx.Err()
+}
+
+func (s *keyServer) ListServices(ctx context.Context, req *apikeyzerv1.ListServicesRequest) (*apikeyzerv1.ListServicesResponse, error) {
+	names := s.validationManager.GetSupportedServices()
+	sort.Strings(names)
+	resp := &apikeyzerv1.ListServicesResponse{}
+	for _, info := range newServiceInfos(s.detector, s.validationManager, names) {
+		service := &apikeyzerv1.Service{Id: info.ID, Name: info.Service, Method: info.Method}
+		for _, endpoint := range info.Endpoints {
+			service.Endpoints = append(service.Endpoints, &apikeyzerv1.Endpoint{
+				Method:   endpoint.Method,
+				Url:      endpoint.URL,
+				Billable: endpoint.Billable,
+			})
+		}
+		resp.Services = append(resp.Services, service)
+	}
+	return resp, nil
+}
+
+// streamWriter sends results on a Validate stream, so the output options can wrap it
+type streamWriter struct {
+	stream grpc.ServerStreamingServer[apikeyzerv1.ValidationResult]
+}
+
+func (w *streamWriter) WriteResult(result *validator.ValidationResult) error {
+	return w.stream.Send(resultToProto(result))
+}
+
+func (w *streamWriter) Close() error {
+	return nil
+}
+
+// resultToProto converts a result to its gRPC message
+func resultToProto(result *validator.ValidationResult) *apikeyzerv1.ValidationResult {
+	msg := &apikeyzerv1.ValidationResult{
+		FindingId:   result.FindingID,
+		Key:         result.Key,
+		Valid:       result.Valid,
+		Service:     result.Service,
+		Permissions: result.Permissions,
+		RiskLevel:   string(result.RiskLevel),
+		Error:       result.ErrorStr,
+		ErrorCode:   string(result.ErrorCode),
+		ValidatedAt: timestamppb.New(result.ValidatedAt),
+		Poc:         result.PoC,
+		Cwe:         result.CWE,
+		Owasp:       result.OWASP,
+		References:  result.References,
+	}
+	// Details only hold JSON-like values; anything else is left out rather than failing the result
+	if details, err := structpb.NewStruct(result.Details); err == nil && len(result.Details) > 0 {
+		msg.Details = details
+	}
+	return msg
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/Xplo8E/APIKeyzer/internal/input"
+	"github.com/Xplo8E/APIKeyzer/internal/output"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+func newInspectCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "inspect [key]",
+		Short: "Walk through the detection and validation of a single key step by step",
+		Long: `
+Interactively walks through a single key: the services whose patterns match it,
+the confidence of the detection and why, the endpoints that validation will call,
+and then the verdict of each endpoint as it is probed.
+
+The key is asked for when it is not given as an argument. With --yes the
+detected service is validated without asking, which is handy for demos.
+
+Examples:
+  apiKeyzer inspect
+  apiKeyzer inspect "YOUR-API-KEY"
+  apiKeyzer inspect "YOUR-API-KEY" --safe-mode`,
+		Args: cobra.MaximumNArgs(1),
+		Run:  runInspect,
+	}
+}
+
+// prompter asks questions on stdin, falling back to the defaults at end of input
+type prompter struct {
+	in *bufio.Reader
+}
+
+// ask prints a question and returns the trimmed answer
+func (p *prompter) ask(question string) string {
+	fmt.Print(question)
+	answer, err := p.in.ReadString('\n')
+	if err == io.EOF && answer == "" {
+		fmt.Println()
+	}
+	return strings.TrimSpace(answer)
+}
+
+// confirm asks a yes/no question, answered yes by --yes
+func (p *prompter) confirm(question string) bool {
+	if assumeYes {
+		return true
+	}
+	switch strings.ToLower(p.ask(question + " [y/N] ")) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
+func runInspect(cmd *cobra.Command, args []string) {
+	keyDetector, validationManager := setup()
+	defer closeStore()
+	p := &prompter{in: bufio.NewReader(os.Stdin)}
+
+	key := ""
+	if len(args) > 0 {
+		key = args[0]
+	} else {
+		key = p.ask("API key: ")
+	}
+	key = strings.TrimSpace(key)
+	if key == "" {
+		fmt.Fprintln(os.Stderr, "Error: no key given")
+		os.Exit(exitError)
+	}
+
+	// Step 1: every pattern matching the key, and why the first one wins
+	fmt.Println(output.Cyan("Step 1/3: Detection"))
+	candidates := keyDetector.Candidates(key)
+	if len(candidates) == 0 {
+		fmt.Println("  No detection pattern matches this key, so it cannot be validated.")
+		return
+	}
+	detailed := keyDetector.DetectServiceDetailed(key)
+	fmt.Printf("  %s matches %d pattern(s):\n", displayKey(key), len(candidates))
+	var validatable []string
+	for _, service := range candidates {
+		note := "no validator"
+		if _, exists := validationManager.GetValidator(service); exists {
+			validatable = append(validatable, service)
+			note = fmt.Sprintf("validator [%d]", len(validatable))
+		}
+		fmt.Printf("    - %s (%s)\n", service, note)
+	}
+	fmt.Printf("  Detected as %s with confidence %.2f:\n", output.Green(detailed.Service), detailed.Confidence)
+	for _, reason := range detailed.Reasons {
+		fmt.Printf("    - %s\n", reason)
+	}
+	if len(validatable) == 0 {
+		fmt.Println("  None of the matching services has a validator.")
+		return
+	}
+
+	// Step 2: pick the service and show the traffic validation will generate
+	fmt.Println()
+	fmt.Println(output.Cyan("Step 2/3: Endpoints"))
+	service := validatable[0]
+	if len(validatable) > 1 && !assumeYes {
+		answer := p.ask(fmt.Sprintf("  Validator to use [1-%d, default 1]: ", len(validatable)))
+		if answer != "" {
+			choice, err := strconv.Atoi(answer)
+			if err != nil || choice < 1 || choice > len(validatable) {
+				fmt.Fprintf(os.Stderr, "Error: invalid choice %q\n", answer)
+				os.Exit(exitError)
+			}
+			service = validatable[choice-1]
+		}
+	}
+	v, _ := validationManager.GetValidator(service)
+	lister, ok := v.(validator.EndpointLister)
+	if !ok {
+		fmt.Printf("  The %s validator does not list the endpoints it calls.\n", service)
+	} else {
+		fmt.Printf("  Validating as %s calls:\n", service)
+		for _, endpoint := range lister.Endpoints() {
+			note := ""
+			if endpoint.Billable {
+				note = output.Yellow(" (billable)")
+				if safeMode {
+					note = output.Yellow(" (billable, skipped in safe mode)")
+				}
+			}
+			fmt.Printf("    %s %s%s\n", endpoint.Method, endpoint.URL, note)
+		}
+	}
+	if !p.confirm("  Validate the key now?") {
+		return
+	}
+
+	// The endpoints were just confirmed, so validation does not ask again
+	billableConsent.Lock()
+	billableConsent.answers[service] = true
+	billableConsent.Unlock()
+
+	// Step 3: validate, printing each endpoint's verdict as it arrives
+	fmt.Println()
+	fmt.Println(output.Cyan("Step 3/3: Validation"))
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ctx = validator.WithProbeHook(ctx, printProbe)
+
+	result, err := validateKey(ctx, validationManager, service, input.Record{Key: key})
+	if err != nil {
+		result = validator.NewErrorResult(key, service, err)
+	}
+
+	writer, err := output.New(output.FormatText, os.Stdout, output.Options{Verbose: true, ToolVersion: version, Mask: maskKeys})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitError)
+	}
+	writer.WriteResult(result)
+	writer.Close()
+}
+
+// printProbe prints the verdict of a single endpoint
+func printProbe(probe validator.ProbeResult) {
+	target := probe.Endpoint.Method + " " + probe.Endpoint.URL
+	switch {
+	case probe.Skipped:
+		fmt.Printf("  %s %s: skipped, billable endpoint in safe mode\n", output.Cyan("[~]"), target)
+	case probe.Err != nil:
+		fmt.Printf("  %s %s: %v\n", output.Yellow("[!]"), target, probe.Err)
+	case probe.Vulnerable:
+		fmt.Printf("  %s %s: HTTP %d, key is accepted\n", output.Red("[+]"), target, probe.StatusCode)
+	default:
+		fmt.Printf("  %s %s: HTTP %d, key is rejected\n", output.Green("[-]"), target, probe.StatusCode)
+	}
+}
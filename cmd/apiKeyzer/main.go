@@ -1,15 +1,28 @@
 package main
 
 import (
-	"bufio"
+	"bytes"
 	"context"
 	"embed"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"os/signal"
+	"slices"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unicode"
 
+	"github.com/Xplo8E/APIKeyzer/internal/baseline"
 	"github.com/Xplo8E/APIKeyzer/internal/detector"
 	"github.com/Xplo8E/APIKeyzer/internal/input"
+	"github.com/Xplo8E/APIKeyzer/internal/output"
+	"github.com/Xplo8E/APIKeyzer/internal/secrets"
+	"github.com/Xplo8E/APIKeyzer/internal/sink"
+	"github.com/Xplo8E/APIKeyzer/internal/transport"
 	"github.com/Xplo8E/APIKeyzer/internal/validator"
 	"github.com/Xplo8E/APIKeyzer/internal/validator/services"
 	"github.com/spf13/cobra"
@@ -21,29 +34,35 @@ import (
 var embeddedConfig embed.FS
 
 var (
-	inputFile  string
-	apiKey     string
-	verbose    bool
-	configFile string
-	rootCmd    *cobra.Command
+	inputFiles   []string
+	apiKeys      []string
+	verbose      bool
+	outputFormat string
+	reportFormat string
+	outputFile   string
+	formatTmpl   string
+	sortOrder    string
+	onlyValid    bool
+	noColor      bool
+	failOn       string
+	noProgress   bool
+	minRisk      string
+	minRiskLevel validator.RiskLevel
+	maskKeys     bool
+	showSecrets  bool
+	baselineFile string
+	updateBase   bool
+	configFile   string
+	maxLineSize  int
+	dedupeWindow int
+	maxDuration  time.Duration
+	keyBudget    time.Duration
+	noBanner     bool
+	themeName    string
+	googleAPIs   []string
+	rootCmd      *cobra.Command
 )
 
-var (
-	Red    = Color("\033[1;31m%s\033[0m")
-	Green  = Color("\033[1;32m%s\033[0m")
-	Yellow = Color("\033[1;33m%s\033[0m")
-	Blue   = Color("\033[1;34m%s\033[0m")
-	Cyan   = Color("\033[1;36m%s\033[0m")
-)
-
-func Color(colorString string) func(...interface{}) string {
-	sprint := func(args ...interface{}) string {
-		return fmt.Sprintf(colorString,
-			fmt.Sprint(args...))
-	}
-	return sprint
-}
-
 var version = "v1.0"
 var banner = fmt.Sprintf(`
   ___  ______ _____ _   __                        
@@ -56,186 +75,741 @@ var banner = fmt.Sprintf(`
                                |___/  %s
                                       @Xplo8E`, version)
 
+var bannerOnce sync.Once
+
+// show_banner prints the banner to stderr, unless --no-banner is set or stdout is
+// piped to another program
 func show_banner() {
-	fmt.Println(Blue(banner))
+	if noBanner || !output.IsTerminal(os.Stdout) {
+		return
+	}
+	bannerOnce.Do(func() {
+		fmt.Fprintln(os.Stderr, output.Blue(banner))
+	})
 }
 
 func init() {
-	show_banner()
 	rootCmd = &cobra.Command{
-		Use:   "apiKeyzer",
+		Use:   "apiKeyzer [keys...]",
 		Short: "APIKeyzer - API Key Detection and Validation Tool",
 		Long: `
 Examples:
+  apiKeyzer "YOUR-API-KEY" "ANOTHER-API-KEY"
   apiKeyzer --key "YOUR-API-KEY"
+  apiKeyzer --key "KEY-1" --key "KEY-2"
   apiKeyzer --list keys.txt
+  apiKeyzer --list keys.txt --list more-keys.txt --key "YOUR-API-KEY"
   cat keys.txt | apiKeyzer
-  apiKeyzer --key "YOUR-API-KEY" --config custom-patterns.json`,
-		Run: runValidation,
+  cat findings.jsonl | apiKeyzer
+  apiKeyzer --key "YOUR-API-KEY" --config custom-patterns.json
+  apiKeyzer --list keys.txt --output table
+  apiKeyzer --list keys.txt --output json | jq 'select(.valid)'
+  apiKeyzer scan . --output sarif > apikeyzer.sarif
+  apiKeyzer --list keys.txt --report md > report.md
+  apiKeyzer scan . --output junit > apikeyzer-junit.xml
+  apiKeyzer scan . --output defectdojo --mask > defectdojo.json
+  apiKeyzer --list keys.txt --output json -o results.json
+  cat keys.txt | apiKeyzer --only-valid | notify
+  apiKeyzer scan . --min-risk medium
+  apiKeyzer --list keys.txt --format-template '{{if .Valid}}[{{.RiskLevel}}] {{.Service}} {{.Key}}{{end}}'`,
+		Args:          rootArgs,
+		Run:           runValidation,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := applySettings(cmd); err != nil {
+				return err
+			}
+			if err := setupLogging(); err != nil {
+				return err
+			}
+
+			threshold, err := parseFailOn(failOn)
+			if err != nil {
+				return err
+			}
+			gate.threshold = threshold
+
+			if minRisk != "" {
+				if minRiskLevel, err = validator.ParseRiskLevel(minRisk); err != nil {
+					return fmt.Errorf("invalid --min-risk value: %w", err)
+				}
+			}
+
+			// The report command renders a report unless another format was asked for
+			if cmd.Name() == "report" && reportFormat == "" && !cmd.Flags().Changed("output") && formatTmpl == "" {
+				reportFormat = output.FormatMarkdown
+			}
+			if reportFormat != "" {
+				if !slices.Contains(output.ReportFormats, reportFormat) {
+					return fmt.Errorf("unsupported report format: %s", reportFormat)
+				}
+				outputFormat = reportFormat
+			}
+			// Reports are shared and archived, so they are masked unless explicitly overridden
+			if reportFormat != "" {
+				maskKeys = true
+			}
+			if showSecrets {
+				maskKeys = false
+			}
+
+			if uploadDest != "" && outputFile == "" {
+				return fmt.Errorf("--upload requires --output-file")
+			}
+			if updateBase && baselineFile == "" {
+				return fmt.Errorf("--update-baseline requires --baseline")
+			}
+
+			if formatTmpl != "" {
+				if strings.HasPrefix(formatTmpl, "@") {
+					content, err := os.ReadFile(strings.TrimPrefix(formatTmpl, "@"))
+					if err != nil {
+						return fmt.Errorf("failed to read template file: %w", err)
+					}
+					formatTmpl = string(content)
+				}
+				outputFormat = output.FormatTemplate
+			}
+
+			if len(secretRefs) > 0 {
+				if secretChecker, err = secrets.NewChecker(secretRefs); err != nil {
+					return err
+				}
+			}
+
+			if err := output.SetTheme(themeName); err != nil {
+				return err
+			}
+			configureColor()
+
+			switch {
+			case isScriptCommand(cmd):
+				// Completion scripts and completion requests are read by the shell
+				output.SetColor(false)
+			case outputFile != "":
+				// Results go to the file, so only human-facing output reaches the terminal
+				output.SetColor(false)
+				show_banner()
+			case output.IsStructured(outputFormat), onlyValid:
+				// Keep stdout clean for machine-readable formats and pipelines
+				output.SetColor(false)
+			default:
+				show_banner()
+			}
+			return nil
+		},
 	}
+	rootCmd.SuggestionsMinimumDistance = 2
+	defaultHelp := rootCmd.HelpFunc()
+	rootCmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
+		configureColor()
+		show_banner()
+		defaultHelp(cmd, args)
+	})
+	rootCmd.AddCommand(newValidateCmd())
+	rootCmd.AddCommand(newDetectCmd())
+	rootCmd.AddCommand(newInspectCmd())
+	rootCmd.AddCommand(newScanCmd())
+	rootCmd.AddCommand(newServeCmd())
+	rootCmd.AddCommand(newMCPCmd())
+	rootCmd.AddCommand(newProxyCmd())
+	rootCmd.AddCommand(newAdmissionCmd())
+	rootCmd.AddCommand(newDaemonCmd())
+	rootCmd.AddCommand(newPatternsCmd())
+	rootCmd.AddCommand(newServicesCmd())
+	rootCmd.AddCommand(newReportCmd())
+	rootCmd.AddCommand(newCompletionCmd())
+	rootCmd.AddCommand(newVersionCmd())
+	rootCmd.AddCommand(newGenDocsCmd())
+	rootCmd.AddCommand(newEnrichCmd())
+	rootCmd.AddCommand(newHistoryCmd())
+	rootCmd.AddCommand(newDiffCmd())
+	rootCmd.AddCommand(newBenchCmd())
+	rootCmd.AddCommand(newFirebaseCmd())
 
 	// Add flags
-	rootCmd.PersistentFlags().StringVarP(&inputFile, "list", "l", "", "File containing API keys (one per line)")
-	rootCmd.PersistentFlags().StringVarP(&apiKey, "key", "k", "", "Single API key to validate")
-	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
+	rootCmd.PersistentFlags().StringArrayVarP(&inputFiles, "list", "l", nil, "File containing API keys, one per line (repeatable)")
+	rootCmd.PersistentFlags().StringArrayVarP(&apiKeys, "key", "k", nil, "API key to validate (repeatable)")
+	rootCmd.PersistentFlags().CountVarP(&verbosity, "verbose", "v", "Verbose output and logging; repeat (-vv) for debug logs")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "Format of the logs written to stderr: text or json")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "Also append all logs, including every request sent, to this file at debug level")
 	rootCmd.PersistentFlags().StringVarP(&configFile, "config", "c", "", "Path to patterns configuration file (default will be used if not provided)")
+	rootCmd.PersistentFlags().StringVar(&outputFormat, "output", output.FormatText, "Output format: "+strings.Join(output.Formats, ", "))
+	rootCmd.PersistentFlags().StringVarP(&outputFile, "output-file", "o", "", "Write results to this file in the selected format instead of stdout")
+	rootCmd.PersistentFlags().StringVar(&sortOrder, "sort", output.SortInput, "Order of the results: "+strings.Join(output.SortOrders, ", ")+" (service and risk hold results until the run ends)")
+	rootCmd.PersistentFlags().StringVar(&formatTmpl, "format-template", "", "Go text/template applied to each result (use @file to read it from a file)")
+	rootCmd.PersistentFlags().BoolVar(&onlyValid, "only-valid", false, "Only output confirmed-valid keys (bare keys, one per line, in text format) and suppress warnings")
+	rootCmd.PersistentFlags().BoolVar(&noBanner, "no-banner", false, "Do not print the banner (it is never printed when stdout is piped or a structured format is selected)")
+	rootCmd.PersistentFlags().StringVar(&themeName, "theme", output.ThemeDefault, "Output theme: "+strings.Join(output.Themes, ", ")+" (ascii prints nothing but plain ASCII)")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored output (also disabled by NO_COLOR or when stdout is not a terminal)")
+	rootCmd.PersistentFlags().StringVar(&failOn, "fail-on", "any", "Exit with code 1 when valid keys are found: any, risk=low|medium|high, or never")
+	rootCmd.PersistentFlags().BoolVar(&noProgress, "no-progress", false, "Hide the progress bar shown on stderr when it is a terminal")
+	rootCmd.PersistentFlags().StringVar(&minRisk, "min-risk", "", "Only report valid keys at or above this risk level: low, medium, high")
+	rootCmd.PersistentFlags().BoolVar(&maskKeys, "mask", false, "Redact the middle of keys in all output (default for --report)")
+	rootCmd.PersistentFlags().BoolVar(&showSecrets, "show-secrets", false, "Print full keys even where masking is the default")
+	rootCmd.PersistentFlags().StringVar(&baselineFile, "baseline", "", "Only report findings that are not in this baseline file")
+	rootCmd.PersistentFlags().BoolVar(&updateBase, "update-baseline", false, "Record all valid findings into the --baseline file instead of filtering them")
+	rootCmd.PersistentFlags().StringVar(&storePath, "store", "", "Record every validation in this SQLite database")
+	rootCmd.PersistentFlags().DurationVar(&recheckAfter, "recheck-after", 0, "Reuse results from --store that are younger than this (e.g. 24h) instead of revalidating")
+	rootCmd.PersistentFlags().StringVar(&webhookURL, "webhook", "", "POST each valid finding as JSON to this URL")
+	rootCmd.PersistentFlags().StringVar(&webhookSecret, "webhook-secret", "", "Sign webhook payloads with HMAC-SHA256 using this secret (or set APIKEYZER_WEBHOOK_SECRET)")
+	rootCmd.PersistentFlags().StringVar(&webhookMode, "webhook-mode", sink.ModeFinding, "Webhook delivery: finding (one POST per valid key) or summary (one POST per run)")
+	rootCmd.PersistentFlags().StringVar(&slackURL, "slack-webhook", "", "Send an alert with the masked key to this Slack incoming webhook for every valid key")
+	rootCmd.PersistentFlags().StringVar(&discordURL, "discord-webhook", "", "Send an alert with the masked key to this Discord webhook for every valid key")
+	rootCmd.PersistentFlags().StringVar(&esURL, "es-url", "", "Index every result into this Elasticsearch cluster (API key from ELASTICSEARCH_API_KEY)")
+	rootCmd.PersistentFlags().StringVar(&esIndex, "es-index", "apikeyzer", "Elasticsearch index for --es-url")
+	rootCmd.PersistentFlags().StringVar(&splunkURL, "splunk-url", "", "Send every result to this Splunk HTTP Event Collector")
+	rootCmd.PersistentFlags().StringVar(&splunkToken, "splunk-token", "", "Splunk HEC token (or set SPLUNK_HEC_TOKEN)")
+	rootCmd.PersistentFlags().StringVar(&splunkIndex, "splunk-index", "", "Splunk index for --splunk-url (default: the token's index)")
+	rootCmd.PersistentFlags().StringVar(&splunkSourcetype, "splunk-sourcetype", "apikeyzer", "Splunk sourcetype for --splunk-url")
+	rootCmd.PersistentFlags().StringVar(&syslogTarget, "syslog", "", "Send valid findings to syslog as RFC 5424 messages: local, udp://host:port or tcp://host:port")
+	rootCmd.PersistentFlags().StringVar(&jiraURL, "jira-url", "", "Open a Jira Cloud issue for every valid key without an open issue (credentials from JIRA_EMAIL and JIRA_API_TOKEN)")
+	rootCmd.PersistentFlags().StringVar(&jiraProject, "jira-project", "", "Jira project key for --jira-url")
+	rootCmd.PersistentFlags().StringVar(&jiraIssueType, "jira-issue-type", "Bug", "Jira issue type for --jira-url")
+	rootCmd.PersistentFlags().StringVar(&serviceNowURL, "servicenow-url", "", "Open a ServiceNow incident for every valid key without an active incident (credentials from SERVICENOW_USERNAME and SERVICENOW_PASSWORD)")
+	rootCmd.PersistentFlags().StringVar(&uploadDest, "upload", "", "Upload --output-file to s3://bucket/key or gs://bucket/object after the run (Go template: {{.Date}}, {{.Time}}, {{.Target}}, {{.Format}})")
+	rootCmd.PersistentFlags().StringVar(&reportFormat, "report", "", "Write a summary report instead of per-key results: "+strings.Join(output.ReportFormats, ", "))
+	rootCmd.PersistentFlags().IntVar(&maxLineSize, "max-line-size", input.DefaultMaxLineSize, "Longest input line in bytes; longer lines are skipped with a warning")
+	rootCmd.PersistentFlags().IntVar(&dedupeWindow, "dedupe-window", input.DefaultDedupeWindow, "Skip keys repeated within this many distinct keys of --list and stdin input (0 remembers every key)")
+	rootCmd.PersistentFlags().StringVar(&proxyURL, "proxy", "", "Send validation requests through this proxy (http://, https:// or socks5://)")
+	rootCmd.PersistentFlags().StringVar(&httpVersion, "http-version", transport.HTTPAuto, "HTTP version of validation requests: auto (HTTP/2 where supported), 1.1 or 2 (fail without HTTP/2)")
+	rootCmd.PersistentFlags().IntVar(&maxIdleConns, "max-idle-conns", 0, "Idle keep-alive connections kept open per provider (0 for --threads, at least 4)")
+	rootCmd.PersistentFlags().StringVar(&tlsMinVersion, "tls-min-version", "", "Oldest TLS version accepted from providers: 1.0, 1.1, 1.2 or 1.3 (default 1.2)")
+	rootCmd.PersistentFlags().BoolVar(&noKeepAlive, "no-keep-alive", false, "Open a new connection for every validation request")
+	rootCmd.PersistentFlags().DurationVar(&requestTimeout, "timeout", 10*time.Second, "Timeout for each validation request")
+	rootCmd.PersistentFlags().DurationVar(&keyBudget, "key-budget", 0, "Time allowed to validate one key across all its endpoints, after which it is reported as DEADLINE (0 for no limit)")
+	rootCmd.PersistentFlags().DurationVar(&maxDuration, "max-duration", 0, "Stop validating after this long and report the results so far (0 for no limit)")
+	rootCmd.PersistentFlags().Int64Var(&maxResponseSize, "max-response-size", validator.DefaultMaxResponseSize, "Largest provider response in bytes read while validating; larger responses fail the request")
+	rootCmd.PersistentFlags().IntVarP(&threads, "threads", "t", 1, "Number of keys to validate concurrently")
+	rootCmd.PersistentFlags().Float64Var(&requestRate, "rate", 0, "Maximum validation requests per second across all validators (0 for no limit)")
+	rootCmd.PersistentFlags().DurationVar(&requestDelay, "delay", 0, "Wait this long between validation requests, varied randomly by --jitter (e.g. 3s)")
+	rootCmd.PersistentFlags().Float64Var(&delayJitter, "jitter", 0.5, "Random variation of --delay as a fraction of it (0.5 waits between 50% and 150%)")
+	rootCmd.PersistentFlags().BoolVar(&noThrottle, "no-throttle", false, "Do not slow down when providers signal rate limiting with Retry-After or X-RateLimit-* headers")
+	rootCmd.PersistentFlags().DurationVar(&maxBackoff, "max-backoff", time.Minute, "Longest pause of a service's requests when its provider is rate limiting")
+	rootCmd.PersistentFlags().BoolVar(&safeMode, "safe-mode", false, "Never call endpoints that may be billed to the key owner")
+	rootCmd.PersistentFlags().StringSliceVar(&googleAPIs, "google-apis", nil, "Google APIs to probe Google API keys against (default all): "+strings.Join(services.GoogleAPIs(), ", "))
+	rootCmd.PersistentFlags().StringArrayVar(&userAgents, "user-agent", nil, "User-Agent for validation requests (repeatable; several are rotated per request)")
+	rootCmd.PersistentFlags().StringArrayVarP(&extraHeaders, "header", "H", nil, "Add this \"Name: value\" header to every validation request (repeatable)")
+	rootCmd.PersistentFlags().StringVar(&userAgentFile, "user-agent-file", "", "File of user agents, one per line, rotated per validation request")
+	rootCmd.PersistentFlags().BoolVar(&printRequests, "print-requests", false, "Print every validation request to stderr, including the key, before sending it")
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Print the validation requests instead of sending them")
+	rootCmd.PersistentFlags().BoolVarP(&assumeYes, "yes", "y", false, "Call endpoints that may be billed to the key owner without asking for confirmation")
+	rootCmd.PersistentFlags().StringArrayVar(&secretRefs, "secret-ref", nil, "Compare valid keys of a service with its stored credential: ID=vault://path#field, ID=aws-sm://secret-id#field or ID=op://vault/item/field (repeatable)")
+	rootCmd.PersistentFlags().StringVar(&settingsFile, "settings", "", "YAML file with default flag values (default: ~/.config/apikeyzer/config.yaml)")
+	registerCompletions(rootCmd)
 }
 
 func main() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
-	}
-}
-
-func processStdin() ([]string, error) {
-	seen := make(map[string]bool)
-	var keys []string
-	scanner := bufio.NewScanner(os.Stdin)
-	for scanner.Scan() {
-		key := strings.TrimSpace(scanner.Text())
-		if key != "" && !seen[key] {
-			seen[key] = true
-			keys = append(keys, key)
-		}
+		os.Exit(exitError)
 	}
-	return keys, scanner.Err()
+	os.Exit(gate.exitCode())
 }
 
-func processFile(filename string) ([]string, error) {
-	file, err := os.Open(filename)
+func initValidators() *validator.ValidationManager {
+	client, err := newHTTPClient()
 	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %w", err)
-	}
-	defer file.Close()
-
-	seen := make(map[string]bool)
-	var keys []string
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		key := strings.TrimSpace(scanner.Text())
-		if key != "" && !seen[key] {
-			seen[key] = true
-			keys = append(keys, key)
-		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitError)
 	}
-	return keys, scanner.Err()
+	return newValidationManager(client)
 }
 
-func initValidators() *validator.ValidationManager {
+// newValidationManager registers every validator on a manager sending requests with client
+func newValidationManager(client *http.Client) *validator.ValidationManager {
 	vm := validator.NewValidationManager()
+	vm.SetClient(client)
+	vm.SetSafeMode(safeMode)
+	vm.SetMaxResponseSize(maxResponseSize)
 
 	// Register Google Maps validator
-	vm.RegisterValidator(services.NewGoogleMapsValidator())
+	googleMaps := services.NewGoogleMapsValidator(vm.Client())
+	if err := googleMaps.SetAPIs(googleAPIs); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid --google-apis: %v\n", err)
+		os.Exit(exitError)
+	}
+	vm.RegisterValidator(googleMaps)
+	vm.RegisterValidator(services.NewGitHubAppValidator(vm.Client()))
+	vm.RegisterValidator(services.NewGitHubInstallationTokenValidator(vm.Client()))
+	vm.RegisterValidator(services.NewSlackTokenValidator(vm.Client()))
+	vm.RegisterValidator(services.NewSlackWebhookValidator(vm.Client()))
+	vm.RegisterValidator(services.NewStripeValidator(vm.Client()))
+	vm.RegisterValidator(services.NewSquareValidator(vm.Client()))
+	vm.RegisterValidator(services.NewPayPalValidator(vm.Client()))
+	vm.RegisterValidator(services.NewBraintreeValidator(vm.Client()))
+	vm.RegisterValidator(services.NewPlaidValidator(vm.Client()))
+	vm.RegisterValidator(services.NewSendGridValidator(vm.Client()))
+	vm.RegisterValidator(services.NewMailgunValidator(vm.Client()))
+	vm.RegisterValidator(services.NewMailchimpValidator(vm.Client()))
+	vm.RegisterValidator(services.NewSparkPostValidator(vm.Client()))
+	vm.RegisterValidator(services.NewOpenAIValidator(vm.Client()))
+	vm.RegisterValidator(services.NewAnthropicValidator(vm.Client()))
+	vm.RegisterValidator(services.NewAzureOpenAIValidator(vm.Client()))
+	vm.RegisterValidator(services.NewCohereValidator(vm.Client()))
+	vm.RegisterValidator(services.NewReplicateValidator(vm.Client()))
+	vm.RegisterValidator(services.NewGroqValidator(vm.Client()))
+	vm.RegisterValidator(services.NewMistralValidator(vm.Client()))
+	vm.RegisterValidator(services.NewFCMServerKeyValidator(vm.Client()))
+	vm.RegisterValidator(services.NewAzureADValidator(vm.Client()))
+	vm.RegisterValidator(services.NewAzureStorageKeyValidator(vm.Client()))
+	vm.RegisterValidator(services.NewAzureStorageSASValidator(vm.Client()))
+	vm.RegisterValidator(services.NewCloudflareTokenValidator(vm.Client()))
+	vm.RegisterValidator(services.NewCloudflareGlobalKeyValidator(vm.Client()))
 
 	return vm
 }
 
-func runValidation(cmd *cobra.Command, args []string) {
-
-	var configContent []byte
-	var err error
-
+// loadConfig returns the patterns configuration from --config or the embedded default
+func loadConfig() ([]byte, error) {
 	if configFile != "" {
-		configContent, err = os.ReadFile(configFile)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error loading config file '%s': %v\n", configFile, err)
-			os.Exit(1)
-		}
-	} else {
-		configContent, err = embeddedConfig.ReadFile("config/patterns.json")
+		configContent, err := os.ReadFile(configFile)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error reading embedded config: %v\n", err)
-			os.Exit(1)
+			return nil, fmt.Errorf("error loading config file '%s': %w", configFile, err)
 		}
+		return configContent, nil
 	}
 
-	// Initialize validators
-	validationManager := initValidators()
+	configContent, err := embeddedConfig.ReadFile("config/patterns.json")
+	if err != nil {
+		return nil, fmt.Errorf("error reading embedded config: %w", err)
+	}
+	return configContent, nil
+}
 
-	// Initialize input parser
-	parser := input.NewParser(verbose)
+// setup loads the pattern configuration and initializes the detector and validators
+func setup() (*detector.KeyDetector, *validator.ValidationManager) {
+	configContent, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitError)
+	}
 
 	// Initialize detector
-	detector, err := detector.NewKeyDetector(configContent)
+	keyDetector, err := detector.NewKeyDetector(configContent)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading config file '%s': %v\n", configFile, err)
-		os.Exit(1)
+		os.Exit(exitError)
 	}
+	openStore()
 
-	detector.SetVerbose(verbose)
-
-	var keys []string
+	// Initialize validators
+	return keyDetector, initValidators()
+}
 
-	// Handle different input methods
-	switch {
-	case input.IsStdinPipe():
-		keys, err = parser.FromStdin()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+// streamRecords passes the keys given with --key, --list and on stdin to fn as they are read,
+// skipping duplicates, so key lists of any size are validated without being loaded in memory.
+// The --list files are checked up front so a mistyped name fails before any key is validated.
+func streamRecords(fn func(input.Record)) error {
+	for _, filename := range inputFiles {
+		if _, err := os.Stat(filename); err != nil {
+			return fmt.Errorf("failed to open file: %w", err)
 		}
+	}
 
-	case inputFile != "" && apiKey != "":
-		fmt.Println("Error: Cannot use both --list and --key simultaneously")
-		os.Exit(1)
+	parser := input.NewParser()
+	parser.SetMaxLineSize(maxLineSize)
+	parser.SetDedupeWindow(dedupeWindow)
 
-	case inputFile != "":
-		keys, err = parser.FromFile(inputFile)
+	if input.IsStdinPipe() {
+		if err := parser.ScanStdin(fn); err != nil {
+			return err
+		}
+	}
+	for _, filename := range inputFiles {
+		if err := parser.ScanFile(filename, fn); err != nil {
+			return err
+		}
+	}
+	parser.ScanKeys(apiKeys, fn)
+	if skipped := parser.Skipped(); skipped > 0 {
+		warnf("Warning: skipped %d line(s) longer than %d bytes\n", skipped, maxLineSize)
+	}
+	return nil
+}
+
+// countRecords returns an upper bound on the number of keys given with --key and --list,
+// counting lines without keeping them, or 0 when keys are read from stdin
+func countRecords() int {
+	if input.IsStdinPipe() {
+		return 0
+	}
+	total := len(apiKeys)
+	buf := make([]byte, 64*1024)
+	for _, filename := range inputFiles {
+		file, err := os.Open(filename)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			continue
+		}
+		lastNewline := true
+		for {
+			n, err := file.Read(buf)
+			total += bytes.Count(buf[:n], []byte{'\n'})
+			if n > 0 {
+				lastNewline = buf[n-1] == '\n'
+			}
+			if err != nil {
+				break
+			}
 		}
+		if !lastNewline {
+			total++
+		}
+		file.Close()
+	}
+	return total
+}
 
-	case apiKey != "":
-		keys = parser.FromSingle(apiKey)
+func newValidateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate [keys...]",
+		Short: "Detect and validate API keys from arguments, --key, --list or stdin",
+		Long: `
+Validates keys given as arguments, with --key, --list or on stdin. Running
+apiKeyzer without a subcommand does the same.
 
-	default:
-		if !input.IsStdinPipe() {
-			// fmt.Println("Error: Either --list or --key must be provided, or pipe data through stdin")
-			cmd.Help()
-			// os.Exit(1)
-		}
+Examples:
+  apiKeyzer validate "YOUR-API-KEY" "ANOTHER-API-KEY"
+  apiKeyzer validate --key "YOUR-API-KEY"
+  apiKeyzer validate --list keys.txt --output json
+  cat keys.txt | apiKeyzer validate --only-valid`,
+		Args: cobra.ArbitraryArgs,
+		Run:  runValidation,
 	}
+}
 
-	// Process the keys
-	for _, key := range keys {
-		// Detect service first
-		service := detector.DetectService(key)
-		if service == "" {
-			fmt.Printf("Unknown service for key: %s\n", key)
-			continue
+// rootArgs accepts keys as positional arguments, while still reporting mistyped subcommands
+// such as "apiKeyzer scna" instead of validating them as keys
+func rootArgs(cmd *cobra.Command, args []string) error {
+	for _, arg := range args {
+		isWord := strings.IndexFunc(arg, func(r rune) bool { return !unicode.IsLower(r) && r != '-' }) == -1
+		if suggestions := cmd.SuggestionsFor(arg); isWord && len(suggestions) > 0 {
+			return fmt.Errorf("unknown command %q for %q\n\nDid you mean this?\n\t%s", arg, cmd.CommandPath(), strings.Join(suggestions, "\n\t"))
 		}
+	}
+	return nil
+}
 
-		// Validate the key
-		result, err := validationManager.ValidateKey(context.Background(), service, key)
-		if err != nil {
-			fmt.Printf("Error validating key %s: %v\n", key, Yellow(err))
-			continue
+func runValidation(cmd *cobra.Command, args []string) {
+	// Positional arguments are keys, just like --key
+	apiKeys = append(apiKeys, args...)
+	if len(inputFiles) == 0 && len(apiKeys) == 0 && !input.IsStdinPipe() {
+		cmd.Help()
+		return
+	}
+
+	detector, validationManager := setup()
+
+	runTarget = "keys"
+	if len(inputFiles) > 0 {
+		runTarget = inputFiles[0]
+	}
+
+	writer := newResultWriter()
+	defer closeWriter(writer)
+	defer closeStore()
+
+	var progress *output.Progress
+	if !noProgress && output.IsTerminal(os.Stderr) {
+		progress = newProgress(countRecords())
+	}
+	defer progress.Finish()
+	// The bar is erased before each result only when the result is printed to the same terminal;
+	// otherwise clearing would redraw it for every key
+	clearProgress := outputFile == "" && output.IsTerminal(os.Stdout)
+
+	// Validate up to --threads keys at once while the input is still being read. Results are
+	// written in input order from this goroutine since writers are not safe for concurrent use;
+	// pending bounds how far reading runs ahead of writing, so memory use stays constant.
+	type job struct {
+		rec  input.Record
+		done chan *validator.ValidationResult
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ctx, cancel := withMaxDuration(ctx)
+	defer cancel()
+	workers := max(threads, 1)
+	jobs := make(chan *job)
+	pending := make(chan *job, 2*workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			for j := range jobs {
+				j.done <- checkRecord(ctx, detector, validationManager, j.rec)
+			}
+		}()
+	}
+	var readErr error
+	go func() {
+		readErr = streamRecords(func(rec input.Record) {
+			// Past --max-duration the rest of the input is read but no longer validated
+			if ctx.Err() != nil {
+				return
+			}
+			j := &job{rec: rec, done: make(chan *validator.ValidationResult, 1)}
+			pending <- j
+			jobs <- j
+		})
+		close(jobs)
+		close(pending)
+	}()
+
+	written := 0
+	write := func(j *job) {
+		result := <-j.done
+		// Keys cut short by an interrupt are left out rather than reported as failures
+		if ctx.Err() != nil && !deadlineReached(ctx) {
+			return
+		}
+		if clearProgress {
+			progress.Clear()
 		}
+		writeRecordResult(writer, result, j.rec)
+		progress.Increment()
+		written++
+	}
+	for reading := true; reading; {
+		select {
+		case j, ok := <-pending:
+			if !ok {
+				reading = false
+				break
+			}
+			write(j)
+		case <-ctx.Done():
+			if !deadlineReached(ctx) {
+				// Interrupted: the results written so far are flushed, including any held for --sort
+				progress.Finish()
+				closeWriter(writer)
+				closeStore()
+				warnf("Interrupted after %d key(s)\n", written)
+				os.Exit(exitError)
+			}
+			// Keys already queued are reported, cut short; the rest of the input, which may be an
+			// endless stdin, is left unread
+			for queued := true; queued; {
+				select {
+				case j, ok := <-pending:
+					if queued = ok; ok {
+						write(j)
+					}
+				default:
+					queued = false
+				}
+			}
+			progress.Finish()
+			warnf("Stopped after %d key(s): --max-duration of %v reached\n", written, maxDuration)
+			return
+		}
+	}
+
+	// Keys read before the failure were still validated and reported
+	if readErr != nil {
+		progress.Finish()
+		closeWriter(writer)
+		closeStore()
+		fmt.Fprintf(os.Stderr, "Error: %v\n", readErr)
+		os.Exit(exitError)
+	}
+}
+
+// writeRecordResult attaches the location and metadata of rec to its result and writes it
+func writeRecordResult(writer output.Writer, result *validator.ValidationResult, rec input.Record) {
+	result.Location = recordLocation(rec)
+	result.Metadata = rec.Metadata
+	if err := writer.WriteResult(result); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing result: %v\n", err)
+	}
+}
 
-		// Print results
-		printValidationResult(result, key)
+// withMaxDuration bounds ctx by --max-duration, if set; keys being validated when it elapses
+// are reported with the DEADLINE error code
+func withMaxDuration(ctx context.Context) (context.Context, context.CancelFunc) {
+	if maxDuration <= 0 {
+		return context.WithCancel(ctx)
 	}
+	return context.WithTimeoutCause(ctx, maxDuration, fmt.Errorf("%w: --max-duration of %v reached", validator.ErrDeadline, maxDuration))
 }
 
-func printValidationResult(result *validator.ValidationResult, key string) {
-	if result.Valid {
-		// fmt.Printf("\n[+] Valid key for %s!\n", result.Service)
-		fmt.Println(Red("[+] Vulnerable API Key: "), key)
-		// fmt.Printf("[-] Vulnerable APIs:\n")
-		// for _, perm := range result.Permissions {
-		// 	fmt.Printf("    - %s\n", perm)
-		// }
-		// fmt.Printf("[-] Risk Level: %s\n", result.RiskLevel)
+// deadlineReached reports whether ctx ended because --max-duration elapsed
+func deadlineReached(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), validator.ErrDeadline)
+}
+
+// checkRecord detects the service of a record and validates its key; failures are
+// returned as results carrying an error code so pipelines can route them
+func checkRecord(ctx context.Context, keyDetector *detector.KeyDetector, validationManager *validator.ValidationManager, rec input.Record) *validator.ValidationResult {
+	rec.Key = endpointKey(keyDetector, rec)
+	service := recordService(keyDetector, rec)
+	if service == "" {
+		return validator.NewErrorResult(rec.Key, "", validator.ErrDetectionFailed)
+	}
+	result, err := validateKey(ctx, validationManager, service, rec)
+	if err != nil {
+		return validator.NewErrorResult(rec.Key, service, err)
+	}
+	return result
+}
+
+// endpointKey returns the key of rec, prefixed with the "endpoint" field of its JSONL record
+// when the two together form a credential, as an Azure OpenAI resource and its key do
+func endpointKey(keyDetector *detector.KeyDetector, rec input.Record) string {
+	endpoint, _ := rec.Metadata["endpoint"].(string)
+	if endpoint == "" || strings.Contains(rec.Key, "://") {
+		return rec.Key
+	}
+	combined := strings.TrimRight(strings.TrimSpace(endpoint), "/") + ":" + rec.Key
+	if keyDetector.DetectService(combined) == "" {
+		return rec.Key
+	}
+	return combined
+}
+
+// recordService returns the service named by the "service" field of rec's JSONL record when the
+// key matches its pattern, so keys of a format several services share reach the right validator,
+// and the detected service otherwise
+func recordService(keyDetector *detector.KeyDetector, rec input.Record) string {
+	if service, _ := rec.Metadata["service"].(string); service != "" && keyDetector.Matches(service, rec.Key) {
+		return service
+	}
+	return keyDetector.DetectService(rec.Key)
+}
+
+// isScriptCommand reports whether cmd writes output that is consumed by the shell
+func isScriptCommand(cmd *cobra.Command) bool {
+	switch cmd.Name() {
+	case "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return true
+	}
+	return false
+}
+
+// newProgress returns a progress bar on stderr, or nil when stderr is not a terminal,
+// --no-progress is set or there is too little work to be worth showing one
+func newProgress(total int) *output.Progress {
+	if noProgress || total < 2 || !output.IsTerminal(os.Stderr) || output.IsPlainTheme(themeName) {
+		return nil
+	}
+	return output.NewProgress(os.Stderr, total)
+}
+
+// configureColor enables colors only when stdout is a terminal and neither
+// --no-color nor NO_COLOR asks otherwise
+func configureColor() {
+	output.SetColor(!noColor && output.ColorSupported(os.Stdout))
+}
+
+// warnf prints a non-fatal diagnostic to stderr unless --only-valid silences it
+func warnf(format string, a ...interface{}) {
+	if onlyValid && !verbose {
+		return
+	}
+	fmt.Fprintf(os.Stderr, format, a...)
+}
+
+// displayKey returns key as it may be shown in diagnostics, masked when --mask is active
+func displayKey(key string) string {
+	if maskKeys {
+		return output.MaskKey(key)
+	}
+	return key
+}
+
+// recordLocation returns where a record was found, or nil if the source is unknown
+func recordLocation(rec input.Record) *validator.Location {
+	if rec.Source == "" {
+		return nil
+	}
+	return &validator.Location{
+		Path:   rec.Source,
+		Line:   rec.Line,
+		Column: rec.Column,
+	}
+}
+
+// fileResultWriter closes the output file once the wrapped writer has flushed
+type fileResultWriter struct {
+	output.Writer
+	file *os.File
+}
+
+func (f *fileResultWriter) Close() error {
+	err := f.Writer.Close()
+	if closeErr := f.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// openOutputFile creates --output-file, or returns stdout when it is not given, for commands
+// that write their own format rather than results
+func openOutputFile() *os.File {
+	if outputFile == "" {
+		return os.Stdout
+	}
+	file, err := os.Create(outputFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating output file: %v\n", err)
+		os.Exit(exitError)
+	}
+	return file
+}
+
+// closeOutputFile closes a file opened with openOutputFile and uploads it when --upload is set
+func closeOutputFile(file *os.File) {
+	if file == os.Stdout {
+		return
+	}
+	if err := file.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing results: %v\n", err)
+		os.Exit(exitError)
+	}
+	uploadOutput()
+}
+
+// newResultWriter creates the writer for the format selected with --output,
+// writing to --output-file when given and to stdout otherwise
+func newResultWriter() output.Writer {
+	opts := output.Options{Verbose: verbose, ToolVersion: version, Template: formatTmpl, OnlyValid: onlyValid, MinRisk: minRiskLevel, Mask: maskKeys, Sort: sortOrder}
+
+	var writer output.Writer
+	if outputFile == "" {
+		formatWriter, err := output.New(outputFormat, os.Stdout, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitError)
+		}
+		writer = formatWriter
 	} else {
-		fmt.Printf("\n[-] Invalid key for %s: %s\n", result.Service, result.ErrorStr)
+		file, err := os.Create(outputFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating output file: %v\n", err)
+			os.Exit(exitError)
+		}
+		formatWriter, err := output.New(outputFormat, file, opts)
+		if err != nil {
+			file.Close()
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitError)
+		}
+		writer = &fileResultWriter{Writer: formatWriter, file: file}
+	}
+
+	if sinks := newSinks(opts); len(sinks) > 0 {
+		writer = output.MultiWriter(append([]output.Writer{writer}, sinks...)...)
 	}
 
-	if verbose {
-		fmt.Printf("\nDetails:\n")
-		for endpoint, details := range result.Details {
-			fmt.Printf("  %s: %v\n", endpoint, details)
+	// The gate sits behind the baseline so accepted findings do not fail the run
+	writer = &gateWriter{Writer: writer, gate: &gate}
+
+	if baselineFile != "" {
+		known, err := baseline.Load(baselineFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitError)
 		}
+		writer = &baselineWriter{Writer: writer, baseline: known, filename: baselineFile, update: updateBase}
 	}
+	// Servers and daemons write results from every worker goroutine
+	return output.Synchronized(writer)
 }
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/detector"
+	"github.com/Xplo8E/APIKeyzer/internal/input"
+	"github.com/Xplo8E/APIKeyzer/internal/output"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/spf13/cobra"
+)
+
+var (
+	mcpListen        string
+	mcpAllowBillable bool
+	mcpAllowServices []string
+	mcpMaxKeys       int
+)
+
+// errServiceNotAllowed is reported for keys of services outside --allow-service
+var errServiceNotAllowed = errors.New("service is not allowed by the server policy")
+
+// mcpKeysInput is the input of the detect and validate tools
+type mcpKeysInput struct {
+	Keys []string `json:"keys" jsonschema:"the API keys to check"`
+}
+
+// mcpDetectOutput is the output of the detect tool
+type mcpDetectOutput struct {
+	Detections []detection `json:"detections"`
+}
+
+// mcpValidateOutput is the output of the validate tool
+type mcpValidateOutput struct {
+	Results []*validator.ValidationResult `json:"results"`
+}
+
+// mcpServicesOutput is the output of the list_services tool
+type mcpServicesOutput struct {
+	Services []serviceInfo `json:"services"`
+}
+
+func newMCPCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mcp",
+		Short: "Serve detection and validation as Model Context Protocol tools",
+		Long: `
+Runs a Model Context Protocol (MCP) server, so LLM-based agents can call
+APIKeyzer as tools:
+
+  detect          identify the service of keys without sending any request
+  validate        validate keys against their provider
+  list_services   services with a validator and the endpoints they call
+
+The server speaks MCP over stdin and stdout, as started by an MCP client, or
+over streamable HTTP with --listen.
+
+The policy is enforced by the server whatever the agent asks for:
+  - safe mode is always on, so billable endpoints are never called, unless
+    --allow-billable is set
+  - with --allow-service, only keys of those services are validated
+  - a call checks at most --max-keys keys
+  - keys are masked in every result unless --show-secrets is set
+
+--rate, --delay, --timeout, --proxy and --store apply as for other commands.
+
+Examples:
+  apiKeyzer mcp
+  apiKeyzer mcp --allow-service google-safe-browsing-api-key --rate 2
+  apiKeyzer mcp --listen 127.0.0.1:8765`,
+		Args: cobra.NoArgs,
+		Run:  runMCP,
+	}
+
+	cmd.Flags().StringVar(&mcpListen, "listen", "", "Serve MCP over streamable HTTP on this address instead of stdio")
+	cmd.Flags().BoolVar(&mcpAllowBillable, "allow-billable", false, "Let validation call endpoints that may be billed to the key owner")
+	cmd.Flags().StringArrayVar(&mcpAllowServices, "allow-service", nil, "Only validate keys of this service ID (repeatable; see 'apiKeyzer services')")
+	cmd.Flags().IntVar(&mcpMaxKeys, "max-keys", 20, "Most keys accepted by a single tool call")
+
+	return cmd
+}
+
+func runMCP(cmd *cobra.Command, args []string) {
+	keyDetector, validationManager := setup()
+	defer closeStore()
+
+	allowed := selectServices(validationManager.GetSupportedServices(), mcpAllowServices)
+	// Nobody can answer a prompt here: billable endpoints are allowed up front or never called
+	if mcpAllowBillable {
+		assumeYes = true
+	}
+
+	server := newMCPServer(keyDetector, validationManager, allowed)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if mcpListen == "" {
+		if err := server.Run(ctx, &mcp.StdioTransport{}); err != nil && ctx.Err() == nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitError)
+		}
+		return
+	}
+
+	httpServer := &http.Server{
+		Addr:              mcpListen,
+		Handler:           mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		httpServer.Shutdown(shutdownCtx)
+	}()
+	fmt.Fprintf(os.Stderr, "Serving MCP on http://%s\n", mcpListen)
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitError)
+	}
+}
+
+// newMCPServer registers the APIKeyzer tools on an MCP server.
+// When allowed is not empty only keys of those services are validated.
+func newMCPServer(keyDetector *detector.KeyDetector, validationManager *validator.ValidationManager, allowed []string) *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: "apikeyzer", Version: version}, &mcp.ServerOptions{
+		Instructions: "Use detect to identify the service of a suspected API key without contacting anyone, " +
+			"and validate only for keys you are authorized to test. Validation sends requests to the provider.",
+	})
+	openWorld := true
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "detect",
+		Description: "Identify the service of API keys from their format, with a confidence and the reasons. Sends no requests.",
+		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true, IdempotentHint: true},
+	}, func(ctx context.Context, req *mcp.CallToolRequest, in mcpKeysInput) (*mcp.CallToolResult, mcpDetectOutput, error) {
+		var out mcpDetectOutput
+		if err := checkMCPKeys(in.Keys); err != nil {
+			return nil, out, err
+		}
+		for _, key := range in.Keys {
+			d := newDetection(keyDetector, validationManager, key)
+			if !showSecrets {
+				d.Key = output.MaskKey(d.Key)
+			}
+			out.Detections = append(out.Detections, d)
+		}
+		return nil, out, nil
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "validate",
+		Description: "Validate API keys against their provider and report whether each is live, what it can access and its risk. " +
+			"Sends requests to the provider; only use it on keys you are authorized to test.",
+		Annotations: &mcp.ToolAnnotations{OpenWorldHint: &openWorld},
+	}, func(ctx context.Context, req *mcp.CallToolRequest, in mcpKeysInput) (*mcp.CallToolResult, mcpValidateOutput, error) {
+		var out mcpValidateOutput
+		if err := checkMCPKeys(in.Keys); err != nil {
+			return nil, out, err
+		}
+		ctx = validator.WithSafeMode(ctx, !mcpAllowBillable)
+		opts := output.Options{Mask: !showSecrets}
+		writer := output.Wrap(&collectWriter{results: &out.Results}, opts)
+		for _, key := range in.Keys {
+			writer.WriteResult(checkMCPKey(ctx, keyDetector, validationManager, allowed, key))
+		}
+		return nil, out, nil
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "list_services",
+		Description: "List the services APIKeyzer can validate, with the endpoints each validation calls and whether they are billable.",
+		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true, IdempotentHint: true},
+	}, func(ctx context.Context, req *mcp.CallToolRequest, in struct{}) (*mcp.CallToolResult, mcpServicesOutput, error) {
+		names := allowed
+		if len(names) == 0 {
+			names = validationManager.GetSupportedServices()
+		}
+		names = slices.Sorted(slices.Values(names))
+		return nil, mcpServicesOutput{Services: newServiceInfos(keyDetector, validationManager, names)}, nil
+	})
+
+	return server
+}
+
+// checkMCPKeys rejects tool calls without keys or with more than --max-keys
+func checkMCPKeys(keys []string) error {
+	if len(keys) == 0 {
+		return errors.New("no keys given")
+	}
+	if mcpMaxKeys > 0 && len(keys) > mcpMaxKeys {
+		return fmt.Errorf("%d keys given, the server accepts at most %d per call", len(keys), mcpMaxKeys)
+	}
+	return nil
+}
+
+// checkMCPKey validates a key unless its service is not among the allowed ones
+func checkMCPKey(ctx context.Context, keyDetector *detector.KeyDetector, validationManager *validator.ValidationManager, allowed []string, key string) *validator.ValidationResult {
+	if len(allowed) > 0 {
+		service := keyDetector.DetectService(key)
+		if service != "" && !slices.Contains(allowed, service) {
+			return validator.NewErrorResult(key, service, errServiceNotAllowed)
+		}
+	}
+	return checkRecord(ctx, keyDetector, validationManager, input.Record{Key: key})
+}
+
+// collectWriter appends results to a slice, so the output options can wrap it
+type collectWriter struct {
+	results *[]*validator.ValidationResult
+}
+
+func (c *collectWriter) WriteResult(result *validator.ValidationResult) error {
+	*c.results = append(*c.results, result)
+	return nil
+}
+
+func (c *collectWriter) Close() error {
+	return nil
+}
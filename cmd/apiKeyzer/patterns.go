@@ -0,0 +1,192 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Xplo8E/APIKeyzer/internal/detector"
+	"github.com/Xplo8E/APIKeyzer/internal/output"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+func newPatternsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "patterns",
+		Short: "List the detection patterns in matching order",
+		Long: `
+Lists the patterns from --config (or the embedded defaults) in the order they are
+tried; the first name of the first matching pattern is the detected service and
+the number of further names sharing the pattern is shown in parentheses.
+
+Examples:
+  apiKeyzer patterns
+  apiKeyzer patterns --config custom-patterns.json --output json`,
+		Args: cobra.NoArgs,
+		Run:  runPatterns,
+	}
+}
+
+func runPatterns(cmd *cobra.Command, args []string) {
+	keyDetector, validationManager := setup()
+	defer closeStore()
+
+	patterns := keyDetector.Patterns()
+	if output.IsStructured(outputFormat) {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(patterns)
+		return
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SERVICE\tVALIDATOR\tREGEX")
+	for _, pattern := range patterns {
+		_, hasValidator := validationManager.GetValidator(pattern.Name[0])
+		name := pattern.Name[0]
+		if aliases := len(pattern.Name) - 1; aliases > 0 {
+			name += fmt.Sprintf(" (+%d)", aliases)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", name, yesNo(hasValidator), pattern.Regex)
+	}
+	tw.Flush()
+}
+
+// serviceInfo describes a service with a registered validator
+type serviceInfo struct {
+	ID        string               `json:"id"`
+	Service   string               `json:"service"`
+	Method    string               `json:"method"`
+	Patterns  []string             `json:"patterns"`
+	Endpoints []validator.Endpoint `json:"endpoints,omitempty"`
+}
+
+// billable summarizes whether any endpoint of a service is billable
+func (s serviceInfo) billable() string {
+	if s.Endpoints == nil {
+		return "unknown"
+	}
+	for _, endpoint := range s.Endpoints {
+		if endpoint.Billable {
+			return "yes"
+		}
+	}
+	return "no"
+}
+
+func newServicesCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "services [id...]",
+		Short: "List the services that keys can be validated for",
+		Long: `
+Lists every registered validator with its canonical ID, validation method, the
+number of detection patterns that attribute keys to it and whether validating a
+key calls endpoints that may be billed to the key owner.
+
+Examples:
+  apiKeyzer services
+  apiKeyzer services google-safe-browsing-api-key --output json`,
+		ValidArgsFunction: completeServiceIDs,
+		Run:               runServices,
+	}
+}
+
+func runServices(cmd *cobra.Command, args []string) {
+	keyDetector, validationManager := setup()
+	defer closeStore()
+
+	names := validationManager.GetSupportedServices()
+	sort.Strings(names)
+	if len(args) > 0 {
+		names = selectServices(names, args)
+	}
+	infos := newServiceInfos(keyDetector, validationManager, names)
+
+	if output.IsStructured(outputFormat) {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(infos)
+		return
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSERVICE\tMETHOD\tPATTERNS\tENDPOINTS\tBILLABLE")
+	for _, info := range infos {
+		endpoints := "-"
+		if info.Endpoints != nil {
+			endpoints = fmt.Sprint(len(info.Endpoints))
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\n", info.ID, info.Service, strings.ToUpper(info.Method),
+			len(info.Patterns), endpoints, info.billable())
+	}
+	tw.Flush()
+
+	// Validators without a pattern can never be reached from detection
+	for _, info := range infos {
+		if len(info.Patterns) == 0 {
+			warnf("Warning: no detection pattern attributes keys to %s\n", info.Service)
+		}
+	}
+}
+
+// newServiceInfos describes the validators of the named services
+func newServiceInfos(keyDetector *detector.KeyDetector, validationManager *validator.ValidationManager, names []string) []serviceInfo {
+	patterns := make(map[string][]string)
+	for _, pattern := range keyDetector.Patterns() {
+		patterns[pattern.Name[0]] = append(patterns[pattern.Name[0]], pattern.Regex)
+	}
+
+	infos := make([]serviceInfo, 0, len(names))
+	for _, name := range names {
+		v, _ := validationManager.GetValidator(name)
+		info := serviceInfo{
+			ID:       validator.ServiceID(name),
+			Service:  name,
+			Method:   string(v.GetValidationMethod()),
+			Patterns: patterns[name],
+		}
+		if lister, ok := v.(validator.EndpointLister); ok {
+			info.Endpoints = lister.Endpoints()
+		}
+		if info.Patterns == nil {
+			info.Patterns = []string{}
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// selectServices returns the services named by ids, which may be canonical IDs or
+// service names, exiting when one of them is not registered
+func selectServices(names, ids []string) []string {
+	var selected []string
+	for _, id := range ids {
+		found := false
+		for _, name := range names {
+			if id == validator.ServiceID(name) || strings.EqualFold(id, name) {
+				selected = append(selected, name)
+				found = true
+				break
+			}
+		}
+		if !found {
+			fmt.Fprintf(os.Stderr, "Error: no validator for service %q\n", id)
+			os.Exit(exitError)
+		}
+	}
+	return selected
+}
+
+// yesNo renders a boolean for table cells
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
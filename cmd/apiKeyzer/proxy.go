@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/input"
+	"github.com/Xplo8E/APIKeyzer/internal/proxy"
+	"github.com/spf13/cobra"
+)
+
+// proxyQueueSize bounds the keys waiting for validation; keys found while it is full are dropped
+const proxyQueueSize = 1024
+
+var (
+	proxyListen      string
+	proxyUpstream    string
+	proxyCACert      string
+	proxyCAKey       string
+	proxyNoIntercept bool
+	proxyInsecure    bool
+	proxyMaxBody     int64
+)
+
+func newProxyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "proxy",
+		Short: "Run an HTTP proxy that passively finds and validates keys in the traffic",
+		Long: `
+Runs an HTTP proxy that passes traffic through unchanged while looking for API
+keys in request URLs, headers and bodies and in response headers and bodies.
+Keys that match a service with a validator are validated out-of-band, once
+each, and reported in the selected output format as they are confirmed.
+
+HTTPS is intercepted with certificates issued by a local CA, which is created
+on first use (by default next to the settings file, see --ca-cert) and must be
+trusted by the client: import it into Burp, ZAP or the browser. With
+--no-intercept HTTPS is tunnelled without being inspected.
+
+To sit behind Burp or ZAP, set APIKeyzer as their upstream proxy. To sit in
+front of them, chain to them with --upstream; as they intercept HTTPS too, add
+--insecure unless their CA is trusted by the system.
+
+Examples:
+  apiKeyzer proxy
+  apiKeyzer proxy --listen 127.0.0.1:8888 --upstream http://127.0.0.1:8080 --insecure
+  apiKeyzer proxy --output json -o findings.jsonl --only-valid`,
+		Args: cobra.NoArgs,
+		Run:  runProxy,
+	}
+
+	cmd.Flags().StringVar(&proxyListen, "listen", "127.0.0.1:8888", "Address to listen on")
+	cmd.Flags().StringVar(&proxyUpstream, "upstream", "", "Forward all traffic through this HTTP proxy (e.g. http://127.0.0.1:8080 for Burp)")
+	cmd.Flags().StringVar(&proxyCACert, "ca-cert", "", "CA certificate for intercepting HTTPS, created with --ca-key if missing (default: proxy-ca.pem next to the settings file)")
+	cmd.Flags().StringVar(&proxyCAKey, "ca-key", "", "Private key of --ca-cert (default: proxy-ca-key.pem next to the settings file)")
+	cmd.Flags().BoolVar(&proxyNoIntercept, "no-intercept", false, "Tunnel HTTPS without inspecting it")
+	cmd.Flags().BoolVar(&proxyInsecure, "insecure", false, "Do not verify the certificates of origins, as needed behind an intercepting upstream proxy")
+	cmd.Flags().Int64Var(&proxyMaxBody, "max-body", proxy.DefaultMaxBody, "Bytes of each request and response body to inspect")
+
+	return cmd
+}
+
+func runProxy(cmd *cobra.Command, args []string) {
+	keyDetector, validationManager := setup()
+	writer := newResultWriter()
+	defer closeWriter(writer)
+	defer closeStore()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Candidates are filtered while the traffic is served; validation happens on a single worker
+	queue := make(chan input.Record, proxyQueueSize)
+	var queued sync.Map
+	p := proxy.New(func(rec input.Record) {
+		service := keyDetector.DetectService(rec.Key)
+		if service == "" {
+			return
+		}
+		if _, exists := validationManager.GetValidator(service); !exists {
+			return
+		}
+		if _, seen := queued.LoadOrStore(rec.Key, true); seen {
+			return
+		}
+		select {
+		case queue <- rec:
+		default:
+			queued.Delete(rec.Key)
+			slog.Warn("validation queue is full, dropping key", "url", rec.Source)
+		}
+	})
+
+	if proxyUpstream != "" {
+		if err := p.SetUpstream(proxyUpstream); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitError)
+		}
+	}
+	p.SetInsecure(proxyInsecure)
+	p.SetMaxBody(proxyMaxBody)
+
+	if !proxyNoIntercept {
+		dir := filepath.Dir(defaultSettingsFile())
+		if proxyCACert == "" {
+			proxyCACert = filepath.Join(dir, "proxy-ca.pem")
+		}
+		if proxyCAKey == "" {
+			proxyCAKey = filepath.Join(dir, "proxy-ca-key.pem")
+		}
+		ca, created, err := proxy.LoadOrCreateCA(proxyCACert, proxyCAKey)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitError)
+		}
+		if created {
+			fmt.Fprintf(os.Stderr, "Created a CA for intercepting HTTPS: trust %s in the client\n", proxyCACert)
+		}
+		p.SetCA(ca)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case rec := <-queue:
+				writeRecordResult(writer, checkRecord(ctx, keyDetector, validationManager, rec), rec)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	server := &http.Server{
+		Addr:              proxyListen,
+		Handler:           p,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Fprintf(os.Stderr, "Proxy listening on http://%s\n", proxyListen)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitError)
+	}
+	<-done
+}
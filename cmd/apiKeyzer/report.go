@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Xplo8E/APIKeyzer/internal/diff"
+	"github.com/Xplo8E/APIKeyzer/internal/output"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+var nucleiTemplateDir string
+
+func newReportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report <results.json>...",
+		Short: "Render saved results as a report or in another output format",
+		Long: `
+Re-renders runs written with --output json without validating the keys again.
+A masked Markdown report is written unless --report or --output selects another
+format; --only-valid, --min-risk, --baseline and the result sinks apply as usual.
+
+With --nuclei-templates a self-contained nuclei template is also written for
+every valid key, replaying the requests that confirmed it, so it can be
+re-verified later with nuclei (nuclei -t DIR). The key is held in the api_key
+template variable; with --mask it is left out and must be passed with -var.
+
+Examples:
+  apiKeyzer report results.json > report.md
+  apiKeyzer report monday.json tuesday.json --output sarif -o apikeyzer.sarif
+  apiKeyzer report results.json --nuclei-templates retest/ --output nuclei -o findings.jsonl`,
+		Args: cobra.MinimumNArgs(1),
+		Run:  runReport,
+	}
+
+	cmd.Flags().StringVar(&nucleiTemplateDir, "nuclei-templates", "", "Also write a nuclei retest template for every valid key into this directory")
+
+	return cmd
+}
+
+func runReport(cmd *cobra.Command, args []string) {
+	var results []*validator.ValidationResult
+	for _, filename := range args {
+		run, err := diff.LoadRun(filename)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitError)
+		}
+		results = append(results, run...)
+	}
+
+	runTarget = args[0]
+	writer := newResultWriter()
+	if nucleiTemplateDir != "" {
+		templates, err := output.NewNucleiTemplateWriter(nucleiTemplateDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitError)
+		}
+		opts := output.Options{OnlyValid: onlyValid, MinRisk: minRiskLevel, Mask: maskKeys}
+		writer = output.MultiWriter(writer, output.Wrap(templates, opts))
+	}
+	defer closeWriter(writer)
+
+	for _, result := range results {
+		if err := writer.WriteResult(result); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing result: %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/Xplo8E/APIKeyzer/internal/detector"
+	"github.com/Xplo8E/APIKeyzer/internal/input"
+	"github.com/Xplo8E/APIKeyzer/internal/output"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	bucketTarget    string
+	maxObjectSize   int64
+	includePatterns []string
+	excludePatterns []string
+	maxFileSize     int64
+	scanBinary      bool
+	watchMode       bool
+	scanEnv         bool
+	envFiles        []string
+	scanStaged      bool
+	allowlistFile   string
+)
+
+func newScanCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "scan [paths...]",
+		Short: "Scan content sources for API keys and validate them",
+		Long: `
+Examples:
+  apiKeyzer scan ./src
+  apiKeyzer scan . --exclude node_modules --exclude "*.min.js"
+  apiKeyzer scan . --include "**/*.env" --include "*.json"
+  apiKeyzer scan /srv/drop --watch
+  apiKeyzer scan --staged
+  apiKeyzer scan --env
+  apiKeyzer scan --env-file .env --env-file <(docker inspect my-container)
+  apiKeyzer scan --bucket my-public-bucket
+  apiKeyzer scan --bucket gs://my-public-bucket
+  apiKeyzer scan --bucket https://my-bucket.nyc3.digitaloceanspaces.com/`,
+		Run: runScan,
+	}
+
+	cmd.Flags().StringVarP(&bucketTarget, "bucket", "b", "", "Public S3/GCS bucket name or listing URL to scan")
+	cmd.Flags().Int64Var(&maxObjectSize, "max-object-size", input.DefaultMaxObjectSize, "Skip bucket objects larger than this many bytes")
+	cmd.Flags().BoolVar(&scanStaged, "staged", false, "Scan the lines added by the changes staged in the current git repository (for pre-commit hooks)")
+	cmd.Flags().StringVar(&allowlistFile, "allowlist", "", "Skip keys listed in this allowlist file (default: "+input.AllowlistFileName+" in the current directory, if present)")
+	cmd.Flags().BoolVar(&scanEnv, "env", false, "Scan the environment variables of the current process")
+	cmd.Flags().StringArrayVar(&envFiles, "env-file", nil, "Scan an env dump (KEY=VALUE lines) or docker inspect output (repeatable)")
+	cmd.Flags().BoolVarP(&watchMode, "watch", "w", false, "Keep watching the scanned paths and report new valid keys as files change")
+	cmd.Flags().StringSliceVar(&includePatterns, "include", nil, "Only scan files matching these glob patterns (repeatable)")
+	cmd.Flags().Int64Var(&maxFileSize, "max-file-size", input.DefaultMaxFileSize, "Skip files larger than this many bytes (0 for no limit)")
+	cmd.Flags().BoolVar(&scanBinary, "scan-binary", false, "Extract printable strings from binary files instead of skipping them")
+	cmd.Flags().StringSliceVar(&excludePatterns, "exclude", nil, "Skip files and directories matching these glob patterns (repeatable, added to .apikeyzerignore)")
+
+	return cmd
+}
+
+// scanProcessor validates candidate keys found during scans, caching results per key
+type scanProcessor struct {
+	detector          *detector.KeyDetector
+	validationManager *validator.ValidationManager
+	writer            output.Writer
+	allowlist         *input.Allowlist
+	cache             map[string]*validator.ValidationResult
+	reported          map[string]bool
+	onlyNewValid      bool
+}
+
+func newScanProcessor(d *detector.KeyDetector, vm *validator.ValidationManager, w output.Writer) *scanProcessor {
+	return &scanProcessor{
+		detector:          d,
+		validationManager: vm,
+		writer:            w,
+		cache:             make(map[string]*validator.ValidationResult),
+		reported:          make(map[string]bool),
+	}
+}
+
+// process validates a candidate if it matches a service that has a validator
+func (p *scanProcessor) process(ctx context.Context, rec input.Record) {
+	if p.allowlist.Allows(rec) {
+		slog.Debug("allowlisted", "source", rec.Source, "line", rec.Line)
+		return
+	}
+	service := p.detector.DetectService(rec.Key)
+	if service == "" {
+		return
+	}
+	if _, exists := p.validationManager.GetValidator(service); !exists {
+		return
+	}
+
+	result, cached := p.cache[rec.Key]
+	if !cached {
+		var err error
+		result, err = validateKey(ctx, p.validationManager, service, rec)
+		if err != nil {
+			result = validator.NewErrorResult(rec.Key, service, err)
+		} else {
+			p.cache[rec.Key] = result
+		}
+	}
+
+	// In watch mode only valid keys not yet reported for this source are printed
+	reportKey := rec.Source + "\x00" + rec.Key
+	if p.onlyNewValid && (!result.Valid || p.reported[reportKey]) {
+		return
+	}
+	p.reported[reportKey] = true
+
+	// Results are cached per key, so each occurrence gets its own copy with its location
+	found := *result
+	found.Location = recordLocation(rec)
+	if err := p.writer.WriteResult(&found); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing result: %v\n", err)
+	}
+}
+
+func runScan(cmd *cobra.Command, args []string) {
+	if bucketTarget == "" && len(args) == 0 && !scanEnv && len(envFiles) == 0 && !scanStaged {
+		cmd.Help()
+		return
+	}
+
+	if watchMode && len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: --watch requires at least one path to scan")
+		os.Exit(exitError)
+	}
+
+	keyDetector, validationManager := setup()
+
+	switch {
+	case len(args) > 0:
+		runTarget = args[0]
+	case bucketTarget != "":
+		runTarget = bucketTarget
+	case scanStaged:
+		runTarget = "staged"
+	default:
+		runTarget = "env"
+	}
+	writer := newResultWriter()
+	defer closeWriter(writer)
+	defer closeStore()
+	processor := newScanProcessor(keyDetector, validationManager, writer)
+	processor.allowlist = loadAllowlist()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ctx, cancel := withMaxDuration(ctx)
+	defer cancel()
+	handle := func(rec input.Record) {
+		// Past --max-duration or an interrupt the sources stop at their next file or object; keys
+		// found until then are skipped
+		if ctx.Err() != nil {
+			return
+		}
+		processor.process(ctx, rec)
+	}
+	// fail reports a scan error, or ends the run with the results so far when it was interrupted
+	// or --max-duration elapsed
+	fail := func(message string, err error) {
+		if ctx.Err() != nil && !deadlineReached(ctx) {
+			// Interrupted: the results written so far are flushed, including any held for --sort
+			closeWriter(writer)
+			closeStore()
+			warnf("Stopped scanning: interrupted\n")
+			os.Exit(exitError)
+		}
+		if deadlineReached(ctx) {
+			warnf("Stopped scanning: --max-duration of %v reached\n", maxDuration)
+			closeWriter(writer)
+			closeStore()
+			os.Exit(gate.exitCode())
+		}
+		fmt.Fprintf(os.Stderr, "%s: %v\n", message, err)
+		os.Exit(exitError)
+	}
+
+	if scanStaged {
+		if err := input.ScanStaged(ctx, handle); err != nil {
+			fail("Error", err)
+		}
+	}
+
+	if scanEnv {
+		for _, rec := range input.FromEnviron(os.Environ(), "env") {
+			handle(rec)
+		}
+	}
+
+	for _, filename := range envFiles {
+		records, err := input.FromEnvFile(filename)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitError)
+		}
+		for _, rec := range records {
+			handle(rec)
+		}
+	}
+
+	var fileScanner *input.FileScanner
+	if len(args) > 0 {
+		fileScanner = input.NewFileScanner()
+		fileScanner.SetInclude(includePatterns)
+		fileScanner.SetExclude(excludePatterns)
+		fileScanner.SetMaxLineSize(maxLineSize)
+		fileScanner.SetMaxFileSize(maxFileSize)
+		fileScanner.SetScanBinary(scanBinary)
+
+		for _, path := range args {
+			if err := fileScanner.Scan(ctx, path, handle); err != nil {
+				fail("Error scanning "+path, err)
+			}
+		}
+	}
+
+	if bucketTarget != "" {
+		client, err := newFetchClient()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitError)
+		}
+		bucketScanner := input.NewBucketScanner(client)
+		bucketScanner.SetMaxObjectSize(maxObjectSize)
+		bucketScanner.SetMaxLineSize(maxLineSize)
+
+		if err := bucketScanner.Scan(ctx, bucketTarget, handle); err != nil {
+			fail("Error scanning bucket", err)
+		}
+	}
+
+	if watchMode {
+		warnf("Watching %d path(s) for changes, press Ctrl+C to stop\n", len(args))
+		processor.onlyNewValid = true
+		if err := fileScanner.Watch(ctx, args, handle); err != nil {
+			fail("Error watching", err)
+		}
+	}
+}
+
+// loadAllowlist reads the --allowlist file, or the default allowlist when it exists
+func loadAllowlist() *input.Allowlist {
+	filename := allowlistFile
+	if filename == "" {
+		if _, err := os.Stat(input.AllowlistFileName); err != nil {
+			return nil
+		}
+		filename = input.AllowlistFileName
+	}
+	allowlist, err := input.LoadAllowlist(filename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitError)
+	}
+	return allowlist
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Xplo8E/APIKeyzer/internal/secrets"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+var (
+	secretRefs    []string
+	secretChecker *secrets.Checker
+)
+
+// crossCheckSecret compares a valid key with the credential stored for its service in the
+// secret managers given with --secret-ref, adding the outcome to the result details
+func crossCheckSecret(ctx context.Context, service string, result *validator.ValidationResult) {
+	id := validator.ServiceID(service)
+	if secretChecker == nil || !result.Valid || !secretChecker.Has(id) {
+		return
+	}
+	check := secretChecker.Check(ctx, id, result.Key)
+	for _, err := range check.Errors {
+		slog.Warn("failed to read stored credential", "service", id, "error", err)
+	}
+	if result.Details == nil {
+		result.Details = make(map[string]interface{})
+	}
+	result.Details[secrets.DetailsKey] = check.Details()
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/detector"
+	"github.com/Xplo8E/APIKeyzer/internal/github"
+	"github.com/Xplo8E/APIKeyzer/internal/input"
+	"github.com/Xplo8E/APIKeyzer/internal/output"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+// maxRequestSize bounds the body of serve API requests
+const maxRequestSize = 1 << 20
+
+var listenAddr string
+
+// keysRequest is the body accepted by the detect and validate endpoints
+type keysRequest struct {
+	Key  string   `json:"key"`
+	Keys []string `json:"keys"`
+}
+
+func newServeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve detection and validation over an HTTP API",
+		Long: `
+Starts an HTTP server exposing:
+
+  GET  /healthz    liveness check
+  GET  /services   services with a validator
+  POST /detect     {"keys": [...]} -> one {"key", "service", "confidence", ...} object per line
+  POST /validate   {"keys": [...]} -> one result per line, as with --output json
+
+With --github-webhook-secret (or APIKEYZER_GITHUB_WEBHOOK_SECRET) the server also
+accepts GitHub push and pull_request webhooks on POST /github/webhook. The lines
+added by each push or pull request are scanned, the keys found are validated and
+the findings are reported as an "APIKeyzer" check run on the head commit, with an
+annotation on every line holding a key. Keys are always masked in check runs.
+Authenticate as a GitHub App with --github-app-id and --github-app-key (the app
+needs read access to contents and pull requests and write access to checks), or
+with an installation token in --github-token or GITHUB_TOKEN.
+
+With --grpc the same checks are also served over gRPC, as defined by
+proto/apikeyzer/v1/apikeyzer.proto; Validate streams results as they complete.
+
+--mask, --only-valid, --min-risk and --store apply to every request.
+
+Examples:
+  apiKeyzer serve
+  apiKeyzer serve --listen 0.0.0.0:8080 --mask --store apikeyzer.db
+  apiKeyzer serve --grpc 127.0.0.1:9090 --threads 20
+  apiKeyzer serve --listen 0.0.0.0:8080 --github-webhook-secret "$SECRET" --github-app-id 123456 --github-app-key app.pem`,
+		Args: cobra.NoArgs,
+		Run:  runServe,
+	}
+
+	cmd.Flags().StringVar(&listenAddr, "listen", "127.0.0.1:8080", "Address to listen on")
+	cmd.Flags().StringVar(&grpcAddr, "grpc", "", "Also serve the gRPC API on this address (e.g. 127.0.0.1:9090)")
+	cmd.Flags().StringVar(&githubWebhookSecret, "github-webhook-secret", "", "Accept GitHub webhooks signed with this secret on /github/webhook (or APIKEYZER_GITHUB_WEBHOOK_SECRET)")
+	cmd.Flags().StringVar(&githubAppID, "github-app-id", "", "ID of the GitHub App that receives the webhooks")
+	cmd.Flags().StringVar(&githubAppKey, "github-app-key", "", "Private key file (PEM) of the GitHub App")
+	cmd.Flags().StringVar(&githubToken, "github-token", "", "Installation token used instead of a GitHub App (or GITHUB_TOKEN)")
+	cmd.Flags().StringVar(&githubAPIURL, "github-api-url", github.DefaultAPIURL, "GitHub API URL, e.g. https://HOST/api/v3 for GitHub Enterprise Server")
+
+	return cmd
+}
+
+func runServe(cmd *cobra.Command, args []string) {
+	keyDetector, validationManager := setup()
+	defer closeStore()
+
+	// Billable probes are confirmed up front since requests cannot be answered from the terminal
+	for _, service := range validationManager.GetSupportedServices() {
+		confirmBillable(context.Background(), validationManager, service)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("GET /services", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(validationManager.GetSupportedServices())
+	})
+	mux.HandleFunc("POST /detect", func(w http.ResponseWriter, r *http.Request) {
+		keys, ok := decodeKeys(w, r)
+		if !ok {
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		for _, key := range keys {
+			encoder.Encode(newDetection(keyDetector, validationManager, key))
+		}
+	})
+	mux.HandleFunc("POST /validate", func(w http.ResponseWriter, r *http.Request) {
+		keys, ok := decodeKeys(w, r)
+		if !ok {
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		serveValidation(r.Context(), w, keyDetector, validationManager, keys)
+	})
+
+	hook, err := newGitHubHook(ctx, keyDetector, validationManager)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitError)
+	}
+	if hook != nil {
+		mux.Handle("POST /github/webhook", hook)
+	}
+
+	server := &http.Server{
+		Addr:              listenAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	if grpcAddr != "" {
+		listener, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitError)
+		}
+		grpcServer := newGRPCServer(keyDetector, validationManager)
+		go func() {
+			<-ctx.Done()
+			grpcServer.GracefulStop()
+		}()
+		go func() {
+			if err := grpcServer.Serve(listener); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(exitError)
+			}
+		}()
+		fmt.Fprintf(os.Stderr, "Serving gRPC on %s\n", grpcAddr)
+	}
+
+	fmt.Fprintf(os.Stderr, "Listening on http://%s\n", listenAddr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitError)
+	}
+	if hook != nil {
+		hook.Wait()
+	}
+}
+
+// decodeKeys reads the keys of a detect or validate request, answering 400 when there are none
+func decodeKeys(w http.ResponseWriter, r *http.Request) ([]string, bool) {
+	var req keysRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+		return nil, false
+	}
+	keys := req.Keys
+	if req.Key != "" {
+		keys = append(keys, req.Key)
+	}
+	if len(keys) == 0 {
+		http.Error(w, "no keys in request", http.StatusBadRequest)
+		return nil, false
+	}
+	return keys, true
+}
+
+// serveValidation validates keys for a validate request, streaming one JSON result per line
+func serveValidation(ctx context.Context, w http.ResponseWriter, keyDetector *detector.KeyDetector, validationManager *validator.ValidationManager, keys []string) {
+	opts := output.Options{Verbose: verbose, ToolVersion: version, OnlyValid: onlyValid, MinRisk: minRiskLevel, Mask: maskKeys}
+	writer, err := output.New(output.FormatJSON, w, opts)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer writer.Close()
+
+	flusher, _ := w.(http.Flusher)
+	for _, key := range keys {
+		result := checkRecord(ctx, keyDetector, validationManager, input.Record{Key: key})
+		if err := writer.WriteResult(result); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+var settingsFile string
+
+// defaultSettingsFile returns ~/.config/apikeyzer/config.yaml, honoring XDG_CONFIG_HOME
+func defaultSettingsFile() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "apikeyzer", "config.yaml")
+}
+
+// applySettings loads the settings file and uses its values as defaults for every
+// flag of cmd that was not set on the command line. Keys are flag names, e.g.
+//
+//	output: json
+//	proxy: http://127.0.0.1:8080
+//	threads: 10
+//	safe-mode: true
+//
+// Headers for a single service's validation requests are set by service ID:
+//
+//	service-headers:
+//	  google-maps:
+//	    X-Gateway-Token: secret
+func applySettings(cmd *cobra.Command) error {
+	filename, explicit := settingsFile, settingsFile != ""
+	if !explicit {
+		filename = defaultSettingsFile()
+	}
+	if filename == "" {
+		return nil
+	}
+
+	v := viper.New()
+	v.SetConfigFile(filename)
+	v.SetConfigType("yaml")
+	if err := v.ReadInConfig(); err != nil {
+		// The default file is optional; an explicitly requested one is not
+		if !explicit && errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read settings file %s: %w", filename, err)
+	}
+
+	var errs []error
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if flag.Changed || flag.Name == "settings" || !v.IsSet(flag.Name) {
+			return
+		}
+		if err := setFlagValue(flag, v.Get(flag.Name)); err != nil {
+			errs = append(errs, fmt.Errorf("invalid %q in %s: %w", flag.Name, filename, err))
+		}
+	})
+	headers, err := settingsServiceHeaders(v)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid \"service-headers\" in %s: %w", filename, err))
+	}
+	serviceHeaders = headers
+
+	for _, key := range v.AllKeys() {
+		if strings.HasPrefix(key, "service-headers.") {
+			continue
+		}
+		if cmd.Flags().Lookup(key) == nil && rootCmd.PersistentFlags().Lookup(key) == nil && !isCommandFlag(key) {
+			warnf("Warning: unknown setting %q in %s\n", key, filename)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// settingsServiceHeaders reads the service-headers map of service IDs to header names and values
+func settingsServiceHeaders(v *viper.Viper) (map[string]http.Header, error) {
+	if !v.IsSet("service-headers") {
+		return nil, nil
+	}
+	services, ok := v.Get("service-headers").(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected a map of service IDs to headers")
+	}
+	headers := make(map[string]http.Header, len(services))
+	for id, values := range services {
+		fields, ok := values.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected a map of header names to values for %s", id)
+		}
+		headers[id] = make(http.Header, len(fields))
+		for name, value := range fields {
+			headers[id].Set(name, fmt.Sprint(value))
+		}
+	}
+	return headers, nil
+}
+
+// setFlagValue sets a flag from a settings value; lists replace repeatable flags
+func setFlagValue(flag *pflag.Flag, value interface{}) error {
+	if list, ok := value.([]interface{}); ok {
+		items := make([]string, 0, len(list))
+		for _, item := range list {
+			items = append(items, fmt.Sprint(item))
+		}
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			return slice.Replace(items)
+		}
+		return flag.Value.Set(strings.Join(items, ","))
+	}
+	// verbose: true reads naturally in YAML even though -v is a counter
+	if enabled, ok := value.(bool); ok && flag.Value.Type() == "count" {
+		if enabled {
+			value = 1
+		} else {
+			value = 0
+		}
+	}
+	return flag.Value.Set(fmt.Sprint(value))
+}
+
+// isCommandFlag reports whether name is a flag of any subcommand, so settings for
+// other commands are not reported as unknown
+func isCommandFlag(name string) bool {
+	for _, cmd := range rootCmd.Commands() {
+		if cmd.Flags().Lookup(name) != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/output"
+	"github.com/Xplo8E/APIKeyzer/internal/sink"
+)
+
+var (
+	webhookURL    string
+	webhookSecret string
+	webhookMode   string
+	slackURL      string
+	discordURL    string
+
+	esURL            string
+	esIndex          string
+	splunkURL        string
+	splunkToken      string
+	splunkIndex      string
+	splunkSourcetype string
+
+	syslogTarget string
+
+	jiraURL       string
+	jiraProject   string
+	jiraIssueType string
+	serviceNowURL string
+
+	uploadDest string
+	runTarget  string // names the scanned target in upload destinations
+	runStarted = time.Now()
+)
+
+// newSinks creates the external destinations that receive results alongside the selected output
+func newSinks(opts output.Options) []output.Writer {
+	var sinks []output.Writer
+
+	if webhookURL != "" {
+		secret := webhookSecret
+		if secret == "" {
+			secret = os.Getenv("APIKEYZER_WEBHOOK_SECRET")
+		}
+		webhook, err := sink.NewWebhook(webhookURL, secret, webhookMode, version)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitError)
+		}
+		sinks = append(sinks, output.Wrap(webhook, opts))
+	}
+
+	if slackURL != "" {
+		sinks = append(sinks, output.Wrap(sink.NewSlackNotifier(slackURL, version), opts))
+	}
+	if discordURL != "" {
+		sinks = append(sinks, output.Wrap(sink.NewDiscordNotifier(discordURL, version), opts))
+	}
+
+	if esURL != "" {
+		sinks = append(sinks, output.Wrap(sink.NewElasticsearch(esURL, esIndex, os.Getenv("ELASTICSEARCH_API_KEY"), version), opts))
+	}
+	if splunkURL != "" {
+		token := splunkToken
+		if token == "" {
+			token = os.Getenv("SPLUNK_HEC_TOKEN")
+		}
+		if token == "" {
+			fmt.Fprintln(os.Stderr, "Error: --splunk-url requires --splunk-token or SPLUNK_HEC_TOKEN")
+			os.Exit(exitError)
+		}
+		host, _ := os.Hostname()
+		sinks = append(sinks, output.Wrap(sink.NewSplunkHEC(splunkURL, token, splunkIndex, splunkSourcetype, host, version), opts))
+	}
+
+	if syslogTarget != "" {
+		syslog, err := sink.NewSyslog(syslogTarget)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitError)
+		}
+		sinks = append(sinks, output.Wrap(syslog, opts))
+	}
+
+	if jiraURL != "" {
+		email, token := os.Getenv("JIRA_EMAIL"), os.Getenv("JIRA_API_TOKEN")
+		if jiraProject == "" || email == "" || token == "" {
+			fmt.Fprintln(os.Stderr, "Error: --jira-url requires --jira-project, JIRA_EMAIL and JIRA_API_TOKEN")
+			os.Exit(exitError)
+		}
+		sinks = append(sinks, output.Wrap(sink.NewJira(jiraURL, jiraProject, jiraIssueType, email, token, version), opts))
+	}
+	if serviceNowURL != "" {
+		username, password := os.Getenv("SERVICENOW_USERNAME"), os.Getenv("SERVICENOW_PASSWORD")
+		if username == "" || password == "" {
+			fmt.Fprintln(os.Stderr, "Error: --servicenow-url requires SERVICENOW_USERNAME and SERVICENOW_PASSWORD")
+			os.Exit(exitError)
+		}
+		sinks = append(sinks, output.Wrap(sink.NewServiceNow(serviceNowURL, username, password, version), opts))
+	}
+
+	return sinks
+}
+
+// closeWriter flushes the result writer, reports delivery errors from buffered sinks
+// and uploads the finished --output-file when --upload is set
+func closeWriter(writer output.Writer) {
+	if err := writer.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing results: %v\n", err)
+	}
+	uploadOutput()
+}
+
+// uploadOutput uploads the finished --output-file when --upload is set
+func uploadOutput() {
+	if uploadDest == "" {
+		return
+	}
+	dest, err := sink.ExpandDestination(uploadDest, sink.NewUploadName(runStarted, runTarget, outputFormat))
+	if err == nil {
+		err = sink.Upload(context.Background(), outputFile, dest)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error uploading results: %v\n", err)
+		os.Exit(exitError)
+	}
+	slog.Info("uploaded results", "file", outputFile, "destination", dest)
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/input"
+	"github.com/Xplo8E/APIKeyzer/internal/store"
+	"github.com/Xplo8E/APIKeyzer/internal/transport"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	storePath    string
+	recheckAfter time.Duration
+	historyLimit int
+	resultStore  *store.Store
+)
+
+// openStore opens the --store database, if one was requested
+func openStore() {
+	if storePath == "" || resultStore != nil {
+		return
+	}
+	s, err := store.Open(storePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitError)
+	}
+	resultStore = s
+}
+
+// closeStore closes the --store database, if one is open
+func closeStore() {
+	if resultStore != nil {
+		resultStore.Close()
+		resultStore = nil
+	}
+}
+
+// validateKey validates the key of rec, reusing a stored result younger than --recheck-after
+// and recording every fresh validation in the store; failed checks are not recorded.
+// Valid keys are compared with the credentials in the secret managers given with --secret-ref.
+func validateKey(ctx context.Context, vm *validator.ValidationManager, service string, rec input.Record) (*validator.ValidationResult, error) {
+	if resultStore != nil && recheckAfter > 0 {
+		latest, err := resultStore.Latest(ctx, service, rec.Key)
+		if err != nil {
+			warnf("Error reading store: %v\n", err)
+		} else if latest != nil && time.Since(latest.ValidatedAt) < recheckAfter {
+			slog.Info("using stored result", "finding_id", latest.FindingID, "validated_at", latest.ValidatedAt.Format(time.RFC3339))
+			result := latest.Result(rec.Key)
+			crossCheckSecret(ctx, service, result)
+			return result, nil
+		}
+	}
+
+	ctx = transport.WithSecrets(ctx, rec.Key)
+	ctx = transport.WithService(ctx, validator.ServiceID(service))
+	if headers := serviceHeaders[validator.ServiceID(service)]; headers != nil {
+		ctx = transport.WithHeaders(ctx, headers)
+	}
+	if keyBudget > 0 {
+		// One key with many slow endpoints cannot hold up the rest of the run
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, keyBudget, fmt.Errorf("%w: --key-budget of %v spent", validator.ErrDeadline, keyBudget))
+		defer cancel()
+	}
+	result, err := vm.ValidateKey(confirmBillable(ctx, vm, service), service, rec.Key)
+	if err != nil {
+		return nil, err
+	}
+	crossCheckSecret(ctx, service, result)
+
+	if resultStore != nil && result.ErrorCode == "" {
+		stored := *result
+		stored.Location = recordLocation(rec)
+		if err := resultStore.Record(ctx, &stored); err != nil {
+			warnf("Error: %v\n", err)
+		}
+	}
+	return result, nil
+}
+
+func newHistoryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history [finding-id]",
+		Short: "Show validation history recorded with --store",
+		Long: `
+Examples:
+  apiKeyzer history --store apikeyzer.db
+  apiKeyzer history AKZ-41FDD2D4637F --store apikeyzer.db`,
+		Args: cobra.MaximumNArgs(1),
+		Run:  runHistory,
+	}
+	cmd.Flags().IntVarP(&historyLimit, "limit", "n", 50, "Maximum number of validations to show (0 for all)")
+	return cmd
+}
+
+func runHistory(cmd *cobra.Command, args []string) {
+	if storePath == "" {
+		fmt.Fprintln(os.Stderr, "Error: history requires --store")
+		os.Exit(exitError)
+	}
+	openStore()
+	defer closeStore()
+
+	var findingID string
+	if len(args) == 1 {
+		findingID = args[0]
+	}
+
+	entries, err := resultStore.History(context.Background(), findingID, historyLimit)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitError)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VALIDATED\tFINDING\tSERVICE\tVALID\tRISK\tLOCATION")
+	for _, entry := range entries {
+		location := entry.Location
+		if location == "" {
+			location = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%t\t%s\t%s\n", entry.ValidatedAt.Local().Format(time.DateTime),
+			entry.FindingID, entry.Service, entry.Valid, entry.RiskLevel, location)
+	}
+	w.Flush()
+}
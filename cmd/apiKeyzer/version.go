@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// Build metadata, set with -ldflags "-X main.commit=... -X main.buildDate=..."
+var (
+	commit    = ""
+	buildDate = ""
+)
+
+// latestReleaseURL is the GitHub API endpoint queried by version --check
+const latestReleaseURL = "https://api.github.com/repos/Xplo8E/APIKeyzer/releases/latest"
+
+var checkUpdate bool
+
+// buildInfo describes the running binary
+type buildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildDate string `json:"build_date,omitempty"`
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+	Latest    string `json:"latest,omitempty"`
+	UpdateURL string `json:"update_url,omitempty"`
+}
+
+func newVersionCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Print version and build information",
+		Long: `
+Prints the version, commit, build date and Go version of the binary. With --check
+the latest GitHub release is looked up and reported when it is newer.
+
+Examples:
+  apiKeyzer version
+  apiKeyzer version --check --output json`,
+		Args: cobra.NoArgs,
+		Run:  runVersion,
+	}
+
+	cmd.Flags().BoolVar(&checkUpdate, "check", false, "Check GitHub releases for a newer version")
+
+	return cmd
+}
+
+func runVersion(cmd *cobra.Command, args []string) {
+	info := currentBuild()
+
+	var checkErr error
+	if checkUpdate {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		latest, url, err := latestRelease(ctx)
+		if err != nil {
+			checkErr = err
+		} else if newerVersion(latest, info.Version) {
+			info.Latest = latest
+			info.UpdateURL = url
+		}
+	}
+
+	if output.IsStructured(outputFormat) {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(info)
+	} else {
+		fmt.Printf("Version:    %s\n", info.Version)
+		if info.Commit != "" {
+			fmt.Printf("Commit:     %s\n", info.Commit)
+		}
+		if info.BuildDate != "" {
+			fmt.Printf("Build date: %s\n", info.BuildDate)
+		}
+		fmt.Printf("Go version: %s\n", info.GoVersion)
+		fmt.Printf("Platform:   %s\n", info.Platform)
+		if checkUpdate && checkErr == nil {
+			if info.Latest != "" {
+				fmt.Printf("\n%s %s is available: %s\n", output.Yellow("Update:"), info.Latest, info.UpdateURL)
+			} else {
+				fmt.Println("\nYou are running the latest version.")
+			}
+		}
+	}
+
+	if checkErr != nil {
+		fmt.Fprintf(os.Stderr, "Error checking for updates: %v\n", checkErr)
+		os.Exit(exitError)
+	}
+}
+
+// currentBuild returns the build metadata, falling back to the VCS information Go
+// embeds in binaries built from a checkout when it was not set at link time
+func currentBuild() buildInfo {
+	info := buildInfo{
+		Version:   version,
+		Commit:    commit,
+		BuildDate: buildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range bi.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = setting.Value
+			case setting.Key == "vcs.time" && info.BuildDate == "":
+				info.BuildDate = setting.Value
+			}
+		}
+	}
+	return info
+}
+
+// latestRelease returns the tag and page URL of the latest GitHub release
+func latestRelease(ctx context.Context) (string, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, latestReleaseURL, nil)
+	if err != nil {
+		return "", "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("User-Agent", "APIKeyzer/"+version)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to query releases: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("failed to query releases: %s", resp.Status)
+	}
+
+	var release struct {
+		TagName string `json:"tag_name"`
+		HTMLURL string `json:"html_url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return "", "", fmt.Errorf("failed to decode release: %w", err)
+	}
+	return release.TagName, release.HTMLURL, nil
+}
+
+// newerVersion reports whether version a is newer than b, comparing dotted
+// numeric components such as v1.2.3; pre-release suffixes are ignored
+func newerVersion(a, b string) bool {
+	pa, pb := versionParts(a), versionParts(b)
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x != y {
+			return x > y
+		}
+	}
+	return false
+}
+
+// versionParts splits "v1.2.3-rc1" into [1 2 3]
+func versionParts(v string) []int {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	var parts []int
+	for _, field := range strings.Split(v, ".") {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+	}
+	return parts
+}
@@ -0,0 +1,187 @@
+package admission
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Xplo8E/APIKeyzer/internal/input"
+)
+
+// Field is a string value of a manifest, named by its path in the object
+type Field struct {
+	Path  string
+	Value string
+}
+
+// object holds the parts of Secrets, ConfigMaps and workloads that can carry keys
+type object struct {
+	Metadata struct {
+		Annotations map[string]string `json:"annotations"`
+	} `json:"metadata"`
+	Data       map[string]string `json:"data"`
+	StringData map[string]string `json:"stringData"`
+	BinaryData map[string]string `json:"binaryData"`
+	Spec       json.RawMessage   `json:"spec"`
+}
+
+type podSpec struct {
+	InitContainers []container `json:"initContainers"`
+	Containers     []container `json:"containers"`
+}
+
+type container struct {
+	Name    string   `json:"name"`
+	Command []string `json:"command"`
+	Args    []string `json:"args"`
+	Env     []struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"env"`
+}
+
+// Fields returns the string values of a Secret, ConfigMap, Pod or workload manifest that may hold keys:
+// data and annotations, and the commands, arguments and literal environment variables of containers.
+// Base64 data of Secrets and binary ConfigMap data are decoded; binary content is skipped.
+func Fields(kind string, raw json.RawMessage) ([]Field, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var obj object
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", kind, err)
+	}
+
+	var fields []Field
+	add := func(path, value string) {
+		if value != "" {
+			fields = append(fields, Field{Path: path, Value: value})
+		}
+	}
+	addDecoded := func(path, encoded string) {
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || !utf8.Valid(decoded) || input.IsBinary(decoded) {
+			return
+		}
+		add(path, string(decoded))
+	}
+
+	for _, name := range sortedKeys(obj.Metadata.Annotations) {
+		add("metadata.annotations."+name, obj.Metadata.Annotations[name])
+	}
+
+	switch kind {
+	case "Secret":
+		for _, name := range sortedKeys(obj.Data) {
+			addDecoded("data."+name, obj.Data[name])
+		}
+		for _, name := range sortedKeys(obj.StringData) {
+			add("stringData."+name, obj.StringData[name])
+		}
+	case "ConfigMap":
+		for _, name := range sortedKeys(obj.Data) {
+			add("data."+name, obj.Data[name])
+		}
+		for _, name := range sortedKeys(obj.BinaryData) {
+			addDecoded("binaryData."+name, obj.BinaryData[name])
+		}
+	default:
+		specPath, spec, err := findPodSpec(kind, obj.Spec)
+		if err != nil {
+			return nil, err
+		}
+		if spec == nil {
+			break
+		}
+		for _, group := range []struct {
+			name       string
+			containers []container
+		}{{"initContainers", spec.InitContainers}, {"containers", spec.Containers}} {
+			for _, c := range group.containers {
+				prefix := fmt.Sprintf("%s.%s[%s]", specPath, group.name, c.Name)
+				for _, env := range c.Env {
+					add(prefix+".env."+env.Name, env.Value)
+				}
+				add(prefix+".command", strings.Join(c.Command, " "))
+				add(prefix+".args", strings.Join(c.Args, " "))
+			}
+		}
+	}
+	return fields, nil
+}
+
+// findPodSpec returns the pod template of a workload with its path, or nil for other kinds
+func findPodSpec(kind string, spec json.RawMessage) (string, *podSpec, error) {
+	var path []string
+	switch kind {
+	case "Pod":
+	case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "ReplicationController", "Job":
+		path = []string{"template", "spec"}
+	case "CronJob":
+		path = []string{"jobTemplate", "spec", "template", "spec"}
+	default:
+		return "", nil, nil
+	}
+
+	raw := spec
+	for _, name := range path {
+		var parent map[string]json.RawMessage
+		if len(raw) == 0 || json.Unmarshal(raw, &parent) != nil {
+			return "", nil, nil
+		}
+		raw = parent[name]
+	}
+	if len(raw) == 0 {
+		return "", nil, nil
+	}
+	var pod podSpec
+	if err := json.Unmarshal(raw, &pod); err != nil {
+		return "", nil, fmt.Errorf("failed to parse pod spec of %s: %w", kind, err)
+	}
+	return strings.Join(append([]string{"spec"}, path...), "."), &pod, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Records returns the candidate keys in the object of an admission request. The source names
+// the object as namespace/kind/name and the metadata records the field and the requesting user.
+func Records(req *Request) ([]input.Record, error) {
+	fields, err := Fields(req.Kind.Kind, req.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	source := strings.ToLower(req.Kind.Kind) + "/" + req.Name
+	if req.Namespace != "" {
+		source = req.Namespace + "/" + source
+	}
+	var records []input.Record
+	for _, field := range fields {
+		for i, line := range strings.Split(field.Value, "\n") {
+			for _, candidate := range input.ExtractCandidates(line) {
+				records = append(records, input.Record{
+					Key:    candidate.Value,
+					Source: source,
+					Line:   i + 1,
+					Column: candidate.Column,
+					Metadata: map[string]interface{}{
+						"field":     field.Path,
+						"operation": req.Operation,
+						"user":      req.UserInfo.Username,
+					},
+				})
+			}
+		}
+	}
+	return records, nil
+}
@@ -0,0 +1,97 @@
+package admission
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// MaxReviewSize bounds the body of an AdmissionReview, leaving room for the largest objects etcd accepts
+const MaxReviewSize = 4 << 20
+
+// Review is an admission.k8s.io/v1 AdmissionReview, limited to the fields the webhook uses
+type Review struct {
+	APIVersion string    `json:"apiVersion"`
+	Kind       string    `json:"kind"`
+	Request    *Request  `json:"request,omitempty"`
+	Response   *Response `json:"response,omitempty"`
+}
+
+// Request is the object submitted for admission
+type Request struct {
+	UID       string           `json:"uid"`
+	Kind      GroupVersionKind `json:"kind"`
+	Namespace string           `json:"namespace,omitempty"`
+	Name      string           `json:"name,omitempty"`
+	Operation string           `json:"operation"`
+	UserInfo  UserInfo         `json:"userInfo"`
+	Object    json.RawMessage  `json:"object,omitempty"`
+	DryRun    bool             `json:"dryRun,omitempty"`
+}
+
+// GroupVersionKind identifies the type of the submitted object
+type GroupVersionKind struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+}
+
+// UserInfo is the user that submitted the object
+type UserInfo struct {
+	Username string `json:"username"`
+}
+
+// Response is the admission decision; warnings are shown to the client, e.g. by kubectl
+type Response struct {
+	UID      string   `json:"uid"`
+	Allowed  bool     `json:"allowed"`
+	Status   *Status  `json:"status,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Status explains a rejection
+type Status struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// ReadReview decodes the AdmissionReview in a webhook request
+func ReadReview(r io.Reader) (*Review, error) {
+	var review Review
+	if err := json.NewDecoder(io.LimitReader(r, MaxReviewSize)).Decode(&review); err != nil {
+		return nil, fmt.Errorf("invalid AdmissionReview: %w", err)
+	}
+	if review.Request == nil {
+		return nil, errors.New("AdmissionReview has no request")
+	}
+	return &review, nil
+}
+
+// WriteResponse answers a review with the decision, echoing its API version and request UID
+func WriteResponse(w http.ResponseWriter, review *Review, response *Response) error {
+	response.UID = review.Request.UID
+	answer := Review{APIVersion: review.APIVersion, Kind: "AdmissionReview", Response: response}
+	if answer.APIVersion == "" {
+		answer.APIVersion = "admission.k8s.io/v1"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(answer)
+}
+
+// Allow returns a response admitting the object, with optional warnings
+func Allow(warnings []string) *Response {
+	return &Response{Allowed: true, Warnings: warnings}
+}
+
+// Deny returns a response rejecting the object with a message and optional warnings
+func Deny(message string, warnings []string) *Response {
+	return &Response{
+		Allowed:  false,
+		Status:   &Status{Code: http.StatusForbidden, Message: message},
+		Warnings: warnings,
+	}
+}
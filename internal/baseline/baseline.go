@@ -0,0 +1,116 @@
+package baseline
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// formatVersion is the version written to new baseline files
+const formatVersion = 1
+
+// Entry is an accepted finding. Only a hash of the key is stored so the baseline
+// file can be committed without leaking the keys it lists.
+type Entry struct {
+	FindingID string    `json:"finding_id"`
+	Service   string    `json:"service"`
+	KeyHash   string    `json:"key_hash"`
+	Location  string    `json:"location,omitempty"`
+	AddedAt   time.Time `json:"added_at"`
+}
+
+// Baseline is a set of known findings that are not reported again
+type Baseline struct {
+	Version  int      `json:"version"`
+	Findings []*Entry `json:"findings"`
+
+	index map[string]*Entry
+}
+
+// New creates an empty baseline
+func New() *Baseline {
+	return &Baseline{Version: formatVersion, index: make(map[string]*Entry)}
+}
+
+// Load reads a baseline file; a missing file yields an empty baseline
+func Load(filename string) (*Baseline, error) {
+	data, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return New(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+
+	b := New()
+	if err := json.Unmarshal(data, b); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s: %w", filename, err)
+	}
+	for _, entry := range b.Findings {
+		b.index[entryKey(entry.Service, entry.KeyHash)] = entry
+	}
+	return b, nil
+}
+
+// HashKey returns the hash under which a key is stored in a baseline
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// entryKey identifies a finding by service and key hash
+func entryKey(service, keyHash string) string {
+	return service + "\x00" + keyHash
+}
+
+// Contains reports whether the result's key is already accepted in the baseline
+func (b *Baseline) Contains(result *validator.ValidationResult) bool {
+	_, ok := b.index[entryKey(result.Service, HashKey(result.Key))]
+	return ok
+}
+
+// Add records the result's key as accepted; it returns false if it was already present
+func (b *Baseline) Add(result *validator.ValidationResult) bool {
+	keyHash := HashKey(result.Key)
+	if _, ok := b.index[entryKey(result.Service, keyHash)]; ok {
+		return false
+	}
+
+	entry := &Entry{
+		FindingID: validator.FindingID(result.Service, result.Key),
+		Service:   result.Service,
+		KeyHash:   keyHash,
+		AddedAt:   time.Now().UTC(),
+	}
+	if result.Location != nil {
+		entry.Location = result.Location.String()
+	}
+	b.index[entryKey(result.Service, keyHash)] = entry
+	b.Findings = append(b.Findings, entry)
+	return true
+}
+
+// Save writes the baseline to filename, sorted by finding ID for stable diffs
+func (b *Baseline) Save(filename string) error {
+	sort.Slice(b.Findings, func(i, j int) bool {
+		return b.Findings[i].FindingID < b.Findings[j].FindingID
+	})
+	if b.Findings == nil {
+		b.Findings = []*Entry{}
+	}
+
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode baseline: %w", err)
+	}
+	if err := os.WriteFile(filename, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	return nil
+}
@@ -3,18 +3,14 @@ package detector
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
+	"math"
 	"os"
 	"regexp"
+	"regexp/syntax"
+	"strings"
 )
 
-// Add this at the top with other type declarations
-var verbose bool
-
-// Add this function to set verbose mode
-func SetVerbose(v bool) {
-	verbose = v
-}
-
 // Pattern represents the new pattern structure
 type Pattern struct {
 	Name  []string `json:"Name"`
@@ -25,7 +21,8 @@ type Pattern struct {
 type KeyDetector struct {
 	patterns []Pattern
 	compiled map[string]*regexp.Regexp
-	verbose  bool
+	regexes  []*regexp.Regexp // per pattern, in matching order
+	index    *patternIndex
 }
 
 // Add new type for confidence calculation
@@ -35,6 +32,12 @@ type matchConfidence struct {
 	reason     string
 }
 
+// Matches reports whether key matches the pattern of service
+func (d *KeyDetector) Matches(service, key string) bool {
+	re, ok := d.compiled[service]
+	return ok && re.MatchString(key)
+}
+
 // DetectServiceDetailed returns detailed information about the key detection
 type DetectionResult struct {
 	Service    string   `json:"service"`
@@ -67,51 +70,156 @@ func NewKeyDetector(configData []byte) (*KeyDetector, error) {
 		return nil, fmt.Errorf("failed to parse config data: %w", err)
 	}
 
-	// Compile all regex patterns
+	// Compile each distinct regex once, however many patterns and aliases share it
 	compiled := make(map[string]*regexp.Regexp)
-	for _, pattern := range patterns {
-		re, err := regexp.Compile(pattern.Regex)
-		if err != nil {
-			return nil, fmt.Errorf("invalid regex pattern for %s: %w", pattern.Name[0], err)
+	byRegex := make(map[string]*regexp.Regexp)
+	regexes := make([]*regexp.Regexp, len(patterns))
+	sources := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		if len(pattern.Name) == 0 {
+			return nil, fmt.Errorf("pattern %d must have at least one name", i+1)
+		}
+		re, ok := byRegex[pattern.Regex]
+		if !ok {
+			var err error
+			re, err = regexp.Compile(pattern.Regex)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regex pattern for %s: %w", pattern.Name[0], err)
+			}
+			byRegex[pattern.Regex] = re
 		}
 		for _, name := range pattern.Name {
 			compiled[name] = re
 		}
+		regexes[i] = re
+		sources[i] = pattern.Regex
 	}
 
 	return &KeyDetector{
 		patterns: patterns,
 		compiled: compiled,
+		regexes:  regexes,
+		index:    newPatternIndex(sources),
 	}, nil
 }
 
 // DetectService identifies the service based on the API key pattern
 func (d *KeyDetector) DetectService(key string) string {
-	for _, pattern := range d.patterns {
-		re := d.compiled[pattern.Name[0]]
-		if re.MatchString(key) {
-			if d.verbose {
-				fmt.Printf("Detected service: %s\n", pattern.Name[0])
-			}
-			return pattern.Name[0]
+	for _, i := range d.index.candidates(key) {
+		if d.regexes[i].MatchString(key) {
+			slog.Debug("detected service", "service", d.patterns[i].Name[0])
+			return d.patterns[i].Name[0]
 		}
 	}
 	return ""
 }
 
-// SetVerbose enables or disables verbose output
-func (d *KeyDetector) SetVerbose(verbose bool) {
-	d.verbose = verbose
+// Patterns returns the configured patterns in matching order
+func (d *KeyDetector) Patterns() []Pattern {
+	return d.patterns
 }
 
-// DetectServiceDetailed returns detailed information about the key detection
+// Candidates returns every service whose pattern matches the key, in matching order
+func (d *KeyDetector) Candidates(key string) []string {
+	var matches []string
+	for _, i := range d.index.candidates(key) {
+		if d.regexes[i].MatchString(key) {
+			matches = append(matches, d.patterns[i].Name[0])
+		}
+	}
+	return matches
+}
+
+// DetectServiceDetailed returns the detected service along with a confidence score
+// and the reasons behind it, without making any network calls
 func (d *KeyDetector) DetectServiceDetailed(key string) DetectionResult {
-	service := d.DetectService(key)
-	return DetectionResult{
-		Service:    service,
-		Confidence: 1.0, // Assuming full confidence for detected service
-		Reasons:    []string{fmt.Sprintf("Detected service: %s", service)},
+	matches := d.Candidates(key)
+	if len(matches) == 0 {
+		return DetectionResult{Reasons: []string{"no pattern matches the key"}}
+	}
+
+	service := matches[0]
+	result := DetectionResult{Service: service}
+	regex := d.compiled[service].String()
+	result.Reasons = append(result.Reasons, fmt.Sprintf("matches the %s pattern %s", service, regex))
+
+	// Patterns anchored on a literal prefix (e.g. "AIza") are far more specific than
+	// patterns that only constrain the length and character set of the key
+	confidence := 0.6
+	specific := d.isSpecific(service)
+	if specific {
+		confidence = 0.95
+		result.Reasons = append(result.Reasons, fmt.Sprintf("pattern requires the literal prefix %q", literalPrefix(regex)))
+	} else {
+		result.Reasons = append(result.Reasons, "pattern only constrains the length and character set")
+	}
+
+	if others := matches[1:]; len(others) > 0 {
+		// Other matches only compete when they are as specific as the detected pattern
+		competing := 1
+		for _, other := range others {
+			if d.isSpecific(other) == specific {
+				competing++
+			}
+		}
+		confidence = confidence/float64(competing) - 0.05
+		shown := others
+		if len(shown) > 3 {
+			shown = shown[:3]
+		}
+		reason := fmt.Sprintf("also matches %d other pattern(s): %s", len(others), strings.Join(shown, ", "))
+		if len(others) > len(shown) {
+			reason += ", ..."
+		}
+		result.Reasons = append(result.Reasons, reason)
+	}
+
+	result.Confidence = math.Round(confidence*100) / 100
+	return result
+}
+
+// isSpecific reports whether a service's pattern requires a literal prefix of at least three characters
+func (d *KeyDetector) isSpecific(service string) bool {
+	return len(literalPrefix(d.compiled[service].String())) >= 3
+}
+
+// literalPrefix returns the literal text a pattern requires at the start of the key,
+// skipping anchors, leading whitespace and capture groups
+func literalPrefix(regex string) string {
+	re, err := syntax.Parse(regex, syntax.Perl)
+	if err != nil {
+		return ""
+	}
+	var prefix strings.Builder
+	var walk func(*syntax.Regexp) bool
+	walk = func(re *syntax.Regexp) bool {
+		switch re.Op {
+		case syntax.OpBeginLine, syntax.OpBeginText, syntax.OpEmptyMatch:
+			return true
+		case syntax.OpStar:
+			// Optional leading whitespace such as \s* before the key
+			return prefix.Len() == 0
+		case syntax.OpCapture:
+			return walk(re.Sub[0])
+		case syntax.OpConcat:
+			for _, sub := range re.Sub {
+				if !walk(sub) {
+					return false
+				}
+			}
+			return true
+		case syntax.OpLiteral:
+			if re.Flags&syntax.FoldCase != 0 {
+				return false
+			}
+			prefix.WriteString(string(re.Rune))
+			return true
+		default:
+			return false
+		}
 	}
+	walk(re)
+	return prefix.String()
 }
 
 func loadPatterns(configPath string) ([]Pattern, error) {
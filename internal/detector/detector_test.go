@@ -0,0 +1,85 @@
+package detector
+
+import (
+	"os"
+	"slices"
+	"testing"
+)
+
+// newBuiltinDetector loads the patterns embedded in the apiKeyzer binary
+func newBuiltinDetector(t *testing.T) *KeyDetector {
+	t.Helper()
+	data, err := os.ReadFile("../../cmd/apiKeyzer/config/patterns.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := NewKeyDetector(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+// Patterns matching any token of a given length and character set come after those of the
+// services that already claimed such tokens, so keys keep being detected as before
+func TestBuiltinPatternOrder(t *testing.T) {
+	d := newBuiltinDetector(t)
+
+	tests := []struct {
+		name      string
+		key       string
+		want      string
+		candidate string // a service listed after want, still reachable with a JSONL "service" field
+		before    string // a service the candidate must be listed ahead of
+	}{
+		{
+			name:      "32 lowercase hex",
+			key:       "0123456789abcdef0123456789abcdef",
+			want:      "API Bible API Key",
+			candidate: "Mistral API Key",
+		},
+		{
+			name:      "32 mixed case",
+			key:       "Zx8Qm2Lp5Rt7Vw1Yb4Nc6Df9Gh3Jk0Ts",
+			want:      "1Forge API Key",
+			candidate: "Mistral API Key",
+		},
+		{
+			name:      "Cloudflare token of letters and digits",
+			key:       "Zx8Qm2Lp5Rt7Vw1Yb4Nc6Df9Gh3Jk0TsA1B2C3D4",
+			want:      "AdotpAPet Client Secret",
+			candidate: "Cloudflare API Token",
+			before:    "Cohere API Key",
+		},
+		{
+			name:      "Cloudflare token with dashes",
+			key:       "Zx8Qm2Lp5Rt7Vw1Yb4Nc6Df9Gh3Jk0Ts-1B2_3D4",
+			want:      "Blitapp API Key",
+			candidate: "Cloudflare API Token",
+		},
+		{
+			name:      "40 lowercase hex",
+			key:       "da39a3ee5e6b4b0d3255bfef95601890afd80709",
+			want:      "AdotpAPet Client Secret",
+			candidate: "SparkPost API Key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := d.DetectService(tt.key); got != tt.want {
+				t.Errorf("DetectService = %q, want %q", got, tt.want)
+			}
+			candidates := d.Candidates(tt.key)
+			if tt.candidate != "" && !slices.Contains(candidates, tt.candidate) {
+				t.Errorf("candidates %v do not include %q", candidates, tt.candidate)
+			}
+			if tt.candidate != "" && !d.Matches(tt.candidate, tt.key) {
+				t.Errorf("key does not match the %s pattern", tt.candidate)
+			}
+			if tt.before != "" && slices.Index(candidates, tt.candidate) > slices.Index(candidates, tt.before) {
+				t.Errorf("candidates %v list %q ahead of %q", candidates, tt.before, tt.candidate)
+			}
+		})
+	}
+}
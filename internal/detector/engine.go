@@ -0,0 +1,254 @@
+package detector
+
+import (
+	"regexp/syntax"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// spaceChars are the characters matched by \s, which patterns allow before the key
+const spaceChars = "\t\n\f\r "
+
+// prefilter holds cheap necessary conditions for a pattern to match a key, derived from its
+// regex, so most patterns are ruled out without running the regex at all
+type prefilter struct {
+	anchored  bool   // the pattern only matches at the start of the key; otherwise no filtering applies
+	skipSpace bool   // the pattern allows leading whitespace, which is trimmed before the checks
+	prefix    string // literal text the key must start with
+	minLen    int    // bounds on the length of the key in runes; maxLen is -1 when unbounded
+	maxLen    int
+}
+
+// newPrefilter analyses a regex of the form ^[\s*]<key>[$|\z]
+func newPrefilter(regex string) prefilter {
+	re, err := syntax.Parse(regex, syntax.Perl)
+	if err != nil {
+		return prefilter{}
+	}
+	subs := []*syntax.Regexp{re}
+	if re.Op == syntax.OpConcat {
+		subs = re.Sub
+	}
+
+	var f prefilter
+	if len(subs) == 0 || (subs[0].Op != syntax.OpBeginText && subs[0].Op != syntax.OpBeginLine) {
+		return f
+	}
+	subs = subs[1:]
+	if len(subs) > 0 && subs[0].Op == syntax.OpStar {
+		if !isSpaceClass(subs[0].Sub[0]) {
+			return f
+		}
+		f.skipSpace = true
+		subs = subs[1:]
+	}
+	f.anchored = true
+
+	// Without an end anchor the key may run on past the pattern
+	endAnchored := len(subs) > 0 && (subs[len(subs)-1].Op == syntax.OpEndText || subs[len(subs)-1].Op == syntax.OpEndLine)
+	body := &syntax.Regexp{Op: syntax.OpConcat, Sub: subs}
+	var prefix strings.Builder
+	requiredPrefix(body, &prefix)
+	f.prefix = prefix.String()
+	f.minLen, f.maxLen = lengthBounds(body)
+	if !endAnchored {
+		f.maxLen = -1
+	}
+	return f
+}
+
+// allows reports whether the prefilter lets key through to the regex
+func (f prefilter) allows(key string) bool {
+	if !f.anchored {
+		return true
+	}
+	if f.skipSpace {
+		key = strings.TrimLeft(key, spaceChars)
+	}
+	if !strings.HasPrefix(key, f.prefix) {
+		return false
+	}
+	n := utf8.RuneCountInString(key)
+	return n >= f.minLen && (f.maxLen < 0 || n <= f.maxLen)
+}
+
+// requiredPrefix writes the literal text every match of re starts with to prefix,
+// reporting whether the text after it may continue the prefix
+func requiredPrefix(re *syntax.Regexp, prefix *strings.Builder) bool {
+	switch re.Op {
+	case syntax.OpCapture:
+		return requiredPrefix(re.Sub[0], prefix)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if !requiredPrefix(sub, prefix) {
+				return false
+			}
+		}
+		return true
+	case syntax.OpLiteral:
+		if re.Flags&syntax.FoldCase != 0 {
+			return false
+		}
+		prefix.WriteString(string(re.Rune))
+		return true
+	default:
+		return false
+	}
+}
+
+// isSpaceClass reports whether re only matches characters of \s
+func isSpaceClass(re *syntax.Regexp) bool {
+	if re.Op != syntax.OpCharClass {
+		return false
+	}
+	for i := 0; i < len(re.Rune); i += 2 {
+		for r := re.Rune[i]; r <= re.Rune[i+1]; r++ {
+			if !strings.ContainsRune(spaceChars, r) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// lengthBounds returns the shortest and longest text re matches in runes; max is -1 when unbounded
+func lengthBounds(re *syntax.Regexp) (int, int) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return len(re.Rune), len(re.Rune)
+	case syntax.OpCharClass, syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return 1, 1
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText,
+		syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return 0, 0
+	case syntax.OpCapture:
+		return lengthBounds(re.Sub[0])
+	case syntax.OpConcat:
+		minLen, maxLen := 0, 0
+		for _, sub := range re.Sub {
+			lo, hi := lengthBounds(sub)
+			minLen += lo
+			if maxLen >= 0 {
+				maxLen = hi + maxLen
+				if hi < 0 {
+					maxLen = -1
+				}
+			}
+		}
+		return minLen, maxLen
+	case syntax.OpAlternate:
+		minLen, maxLen := -1, 0
+		for _, sub := range re.Sub {
+			lo, hi := lengthBounds(sub)
+			if minLen < 0 || lo < minLen {
+				minLen = lo
+			}
+			if maxLen >= 0 && (hi < 0 || hi > maxLen) {
+				maxLen = hi
+			}
+		}
+		return max(minLen, 0), maxLen
+	case syntax.OpQuest:
+		_, hi := lengthBounds(re.Sub[0])
+		return 0, hi
+	case syntax.OpStar:
+		return 0, -1
+	case syntax.OpPlus:
+		lo, _ := lengthBounds(re.Sub[0])
+		return lo, -1
+	case syntax.OpRepeat:
+		lo, hi := lengthBounds(re.Sub[0])
+		if re.Max < 0 || hi < 0 {
+			return lo * re.Min, -1
+		}
+		return lo * re.Min, hi * re.Max
+	default:
+		return 0, -1
+	}
+}
+
+// trieNode indexes patterns by the literal prefix they require
+type trieNode struct {
+	children map[byte]*trieNode
+	patterns []int
+}
+
+// patternIndex finds the patterns that may match a key in a single pass over it: patterns of a
+// fixed length are looked up by the key's length, patterns with a literal prefix by walking a
+// trie along the key, and only the remaining patterns are tried one by one
+type patternIndex struct {
+	filters  []prefilter
+	byLength map[int][]int
+	trie     *trieNode
+	rest     []int
+}
+
+func newPatternIndex(regexes []string) *patternIndex {
+	idx := &patternIndex{
+		filters:  make([]prefilter, len(regexes)),
+		byLength: make(map[int][]int),
+		trie:     &trieNode{},
+	}
+	for i, regex := range regexes {
+		f := newPrefilter(regex)
+		idx.filters[i] = f
+		switch {
+		case f.anchored && f.maxLen == f.minLen:
+			idx.byLength[f.minLen] = append(idx.byLength[f.minLen], i)
+		case f.anchored && f.prefix != "":
+			node := idx.trie
+			for j := 0; j < len(f.prefix); j++ {
+				child := node.children[f.prefix[j]]
+				if child == nil {
+					if node.children == nil {
+						node.children = make(map[byte]*trieNode)
+					}
+					child = &trieNode{}
+					node.children[f.prefix[j]] = child
+				}
+				node = child
+			}
+			node.patterns = append(node.patterns, i)
+		default:
+			idx.rest = append(idx.rest, i)
+		}
+	}
+	return idx
+}
+
+// candidates returns the indexes of the patterns that may match key, in pattern order.
+// Every returned pattern passed its prefilter; only its regex remains to be checked.
+func (idx *patternIndex) candidates(key string) []int {
+	trimmed := strings.TrimLeft(key, spaceChars)
+	found := append([]int{}, idx.rest...)
+	found = append(found, idx.byLength[utf8.RuneCountInString(key)]...)
+	if trimmed != key {
+		found = append(found, idx.byLength[utf8.RuneCountInString(trimmed)]...)
+	}
+	for _, text := range []string{key, trimmed} {
+		node := idx.trie
+		for i := 0; node != nil; i++ {
+			found = append(found, node.patterns...)
+			if i == len(text) {
+				break
+			}
+			node = node.children[text[i]]
+		}
+		if trimmed == key {
+			break
+		}
+	}
+
+	sort.Ints(found)
+	n, last := 0, -1
+	for _, p := range found {
+		if p == last || !idx.filters[p].allows(key) {
+			continue
+		}
+		last = p
+		found[n] = p
+		n++
+	}
+	return found[:n]
+}
@@ -0,0 +1,88 @@
+package detector
+
+import (
+	"math/rand"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+)
+
+// sampleAlphabet fills wildcards in generated keys
+const sampleAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+
+// maxSampleRepeat bounds unbounded repetitions in generated keys
+const maxSampleRepeat = 16
+
+// SampleKey generates a random key matching the pattern, to benchmark detection without
+// real keys. It reports false for patterns it cannot generate a match for.
+func SampleKey(pattern Pattern, rng *rand.Rand) (string, bool) {
+	re, err := syntax.Parse(pattern.Regex, syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	var b strings.Builder
+	sample(re, rng, &b)
+	key := b.String()
+	compiled, err := regexp.Compile(pattern.Regex)
+	if err != nil || !compiled.MatchString(key) {
+		return "", false
+	}
+	return key, true
+}
+
+func sample(re *syntax.Regexp, rng *rand.Rand, b *strings.Builder) {
+	repeat := func(lo, hi int) {
+		if hi < 0 {
+			hi = lo + maxSampleRepeat
+		}
+		for n := lo + rng.Intn(hi-lo+1); n > 0; n-- {
+			sample(re.Sub[0], rng, b)
+		}
+	}
+
+	switch re.Op {
+	case syntax.OpLiteral:
+		b.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		b.WriteRune(sampleRune(re.Rune, rng))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		b.WriteByte(sampleAlphabet[rng.Intn(len(sampleAlphabet))])
+	case syntax.OpCapture:
+		sample(re.Sub[0], rng, b)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			sample(sub, rng, b)
+		}
+	case syntax.OpAlternate:
+		sample(re.Sub[rng.Intn(len(re.Sub))], rng, b)
+	case syntax.OpQuest:
+		repeat(0, 1)
+	case syntax.OpStar:
+		// Keys are generated without the leading whitespace patterns tolerate
+		if !isSpaceClass(re.Sub[0]) {
+			repeat(0, -1)
+		}
+	case syntax.OpPlus:
+		repeat(1, -1)
+	case syntax.OpRepeat:
+		repeat(re.Min, re.Max)
+	}
+}
+
+// sampleRune picks a rune of a character class, preferring printable ASCII
+func sampleRune(ranges []rune, rng *rand.Rand) rune {
+	var printable []rune
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := max(ranges[i], '!'), min(ranges[i+1], '~')
+		for r := lo; r <= hi; r++ {
+			printable = append(printable, r)
+		}
+	}
+	if len(printable) > 0 {
+		return printable[rng.Intn(len(printable))]
+	}
+	if len(ranges) == 0 {
+		return 'x'
+	}
+	return ranges[0]
+}
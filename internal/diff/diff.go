@@ -0,0 +1,159 @@
+package diff
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// Status describes how a finding changed between two runs
+type Status string
+
+const (
+	StatusNewlyValid Status = "newly_valid"   // invalid or absent before, valid now
+	StatusRotated    Status = "rotated"       // valid before, invalid now
+	StatusStillValid Status = "still_valid"   // valid in both runs
+	StatusMissing    Status = "not_rechecked" // valid before, not checked or not confirmed in the new run
+	StatusUnchanged  Status = "unchanged"     // invalid in both runs or only present as invalid
+)
+
+// Change is the comparison of one finding across two runs
+type Change struct {
+	FindingID string               `json:"finding_id"`
+	Service   string               `json:"service"`
+	Key       string               `json:"key"`
+	Status    Status               `json:"status"`
+	Before    *validator.RiskLevel `json:"risk_before,omitempty"`
+	After     *validator.RiskLevel `json:"risk_after,omitempty"`
+	Location  *validator.Location  `json:"location,omitempty"`
+}
+
+// LoadRun reads the results of a run written with --output json (JSON lines) or as a JSON array
+func LoadRun(filename string) ([]*validator.ValidationResult, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run: %w", err)
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		var results []*validator.ValidationResult
+		if err := json.Unmarshal(trimmed, &results); err != nil {
+			return nil, fmt.Errorf("failed to parse run %s: %w", filename, err)
+		}
+		return results, nil
+	}
+
+	var results []*validator.ValidationResult
+	scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+	scanner.Buffer(nil, 64<<20)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		var result validator.ValidationResult
+		if err := json.Unmarshal(text, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse run %s line %d: %w", filename, line, err)
+		}
+		results = append(results, &result)
+	}
+	return results, scanner.Err()
+}
+
+// identity returns the finding ID of a result, which survives masking, falling back to service and key
+func identity(result *validator.ValidationResult) string {
+	if result.FindingID != "" {
+		return result.FindingID
+	}
+	return validator.FindingID(result.Service, result.Key)
+}
+
+// checked reports whether a result is a verdict on the key rather than an error such as a
+// rate limit or timeout
+func checked(result *validator.ValidationResult) bool {
+	return result != nil && result.ErrorCode == ""
+}
+
+// index maps results by finding, letting a valid result win over an invalid one and a
+// verdict win over an error
+func index(results []*validator.ValidationResult) map[string]*validator.ValidationResult {
+	byID := make(map[string]*validator.ValidationResult, len(results))
+	for _, result := range results {
+		id := identity(result)
+		if existing, ok := byID[id]; ok && (existing.Valid || checked(existing) && !checked(result)) {
+			continue
+		}
+		byID[id] = result
+	}
+	return byID
+}
+
+// Compare reports how every finding in before and after changed, sorted by status and ID
+func Compare(before, after []*validator.ValidationResult) []Change {
+	old, current := index(before), index(after)
+
+	ids := make(map[string]bool, len(old)+len(current))
+	for id := range old {
+		ids[id] = true
+	}
+	for id := range current {
+		ids[id] = true
+	}
+
+	changes := make([]Change, 0, len(ids))
+	for id := range ids {
+		prev, now := old[id], current[id]
+		ref := now
+		if ref == nil {
+			ref = prev
+		}
+		change := Change{FindingID: id, Service: ref.Service, Key: ref.Key, Location: ref.Location}
+
+		if prev != nil && prev.Valid {
+			risk := prev.RiskLevel
+			change.Before = &risk
+		}
+		if now != nil && now.Valid {
+			risk := now.RiskLevel
+			change.After = &risk
+		}
+
+		// A recheck that failed with an error says nothing about the key, so it keeps its previous risk
+		switch {
+		case change.Before == nil && change.After != nil:
+			change.Status = StatusNewlyValid
+		case change.Before != nil && !checked(now):
+			change.Status = StatusMissing
+		case change.Before != nil && change.After == nil:
+			change.Status = StatusRotated
+		case change.Before != nil:
+			change.Status = StatusStillValid
+		default:
+			change.Status = StatusUnchanged
+		}
+		changes = append(changes, change)
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Status != changes[j].Status {
+			return statusOrder[changes[i].Status] < statusOrder[changes[j].Status]
+		}
+		return changes[i].FindingID < changes[j].FindingID
+	})
+	return changes
+}
+
+// statusOrder lists the most actionable changes first
+var statusOrder = map[Status]int{
+	StatusNewlyValid: 0,
+	StatusStillValid: 1,
+	StatusRotated:    2,
+	StatusMissing:    3,
+	StatusUnchanged:  4,
+}
@@ -0,0 +1,136 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+func TestCompare(t *testing.T) {
+	const service = "GitHub"
+	valid := func(key string, risk validator.RiskLevel) *validator.ValidationResult {
+		return &validator.ValidationResult{Service: service, Key: key, Valid: true, RiskLevel: risk}
+	}
+	invalid := func(key string) *validator.ValidationResult {
+		return &validator.ValidationResult{Service: service, Key: key}
+	}
+	errored := func(key string, code validator.ErrorCode) *validator.ValidationResult {
+		return &validator.ValidationResult{Service: service, Key: key, ErrorCode: code}
+	}
+	masked := func(result *validator.ValidationResult) *validator.ValidationResult {
+		result.FindingID = validator.FindingID(result.Service, result.Key)
+		result.Key = result.Key[:4] + "****"
+		return result
+	}
+
+	tests := []struct {
+		name   string
+		before []*validator.ValidationResult
+		after  []*validator.ValidationResult
+		status Status
+		risk   validator.RiskLevel // risk after, or before when the key is no longer confirmed valid
+	}{
+		{
+			name:   "rotated",
+			before: []*validator.ValidationResult{valid("ghp_one", validator.RiskLevelHigh)},
+			after:  []*validator.ValidationResult{invalid("ghp_one")},
+			status: StatusRotated,
+			risk:   validator.RiskLevelHigh,
+		},
+		{
+			name:   "rate limited",
+			before: []*validator.ValidationResult{valid("ghp_one", validator.RiskLevelHigh)},
+			after:  []*validator.ValidationResult{errored("ghp_one", validator.ErrCodeRateLimited)},
+			status: StatusMissing,
+			risk:   validator.RiskLevelHigh,
+		},
+		{
+			name:   "network error",
+			before: []*validator.ValidationResult{valid("ghp_one", validator.RiskLevelMedium)},
+			after:  []*validator.ValidationResult{errored("ghp_one", validator.ErrCodeNetwork)},
+			status: StatusMissing,
+			risk:   validator.RiskLevelMedium,
+		},
+		{
+			name:   "verdict wins over error",
+			before: []*validator.ValidationResult{valid("ghp_one", validator.RiskLevelHigh)},
+			after:  []*validator.ValidationResult{invalid("ghp_one"), errored("ghp_one", validator.ErrCodeDeadline)},
+			status: StatusRotated,
+			risk:   validator.RiskLevelHigh,
+		},
+		{
+			name:   "not rechecked",
+			before: []*validator.ValidationResult{valid("ghp_one", validator.RiskLevelLow)},
+			status: StatusMissing,
+			risk:   validator.RiskLevelLow,
+		},
+		{
+			name:   "newly valid",
+			before: []*validator.ValidationResult{invalid("ghp_one")},
+			after:  []*validator.ValidationResult{valid("ghp_one", validator.RiskLevelHigh)},
+			status: StatusNewlyValid,
+			risk:   validator.RiskLevelHigh,
+		},
+		{
+			name:   "errored before, valid now",
+			before: []*validator.ValidationResult{errored("ghp_one", validator.ErrCodeServiceDown)},
+			after:  []*validator.ValidationResult{valid("ghp_one", validator.RiskLevelHigh)},
+			status: StatusNewlyValid,
+			risk:   validator.RiskLevelHigh,
+		},
+		{
+			name:   "invalid before, errored now",
+			before: []*validator.ValidationResult{invalid("ghp_one")},
+			after:  []*validator.ValidationResult{errored("ghp_one", validator.ErrCodeSafeMode)},
+			status: StatusUnchanged,
+		},
+		{
+			name:   "valid wins over invalid",
+			before: []*validator.ValidationResult{valid("ghp_one", validator.RiskLevelHigh)},
+			after:  []*validator.ValidationResult{valid("ghp_one", validator.RiskLevelMedium), invalid("ghp_one")},
+			status: StatusStillValid,
+			risk:   validator.RiskLevelMedium,
+		},
+		{
+			name:   "masked before",
+			before: []*validator.ValidationResult{masked(valid("ghp_one", validator.RiskLevelHigh))},
+			after:  []*validator.ValidationResult{invalid("ghp_one")},
+			status: StatusRotated,
+			risk:   validator.RiskLevelHigh,
+		},
+		{
+			name:   "masked in both runs",
+			before: []*validator.ValidationResult{masked(valid("ghp_one", validator.RiskLevelHigh))},
+			after:  []*validator.ValidationResult{masked(valid("ghp_one", validator.RiskLevelHigh))},
+			status: StatusStillValid,
+			risk:   validator.RiskLevelHigh,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := Compare(tt.before, tt.after)
+			if len(changes) != 1 {
+				t.Fatalf("got %d changes, want 1: %+v", len(changes), changes)
+			}
+			change := changes[0]
+			if change.FindingID != validator.FindingID(service, "ghp_one") {
+				t.Errorf("finding ID = %s, want the ID of the unmasked key", change.FindingID)
+			}
+			if change.Status != tt.status {
+				t.Errorf("status = %s, want %s", change.Status, tt.status)
+			}
+
+			risk := change.After
+			if risk == nil {
+				risk = change.Before
+			}
+			switch {
+			case tt.risk == "" && risk != nil:
+				t.Errorf("risk = %s, want none", *risk)
+			case tt.risk != "" && (risk == nil || *risk != tt.risk):
+				t.Errorf("risk = %v, want %s", risk, tt.risk)
+			}
+		})
+	}
+}
@@ -0,0 +1,306 @@
+package firebase
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// Services under which the misconfigurations are reported, one result each
+const (
+	ServiceSignup    = "Firebase Open Signup"
+	ServiceDatabase  = "Firebase Realtime Database"
+	ServiceFirestore = "Firebase Firestore"
+	ServiceStorage   = "Firebase Storage"
+)
+
+// identityToolkit is the Firebase Authentication REST API
+const identityToolkit = "https://identitytoolkit.googleapis.com/v1/"
+
+// Auditor checks a Firebase project for access its security rules and settings leave open
+// to anyone holding the app's public configuration
+type Auditor struct {
+	client *http.Client
+}
+
+// NewAuditor creates an auditor sending its requests with client
+func NewAuditor(client *http.Client) *Auditor {
+	return &Auditor{client: client}
+}
+
+// response is an answer read in full
+type response struct {
+	StatusCode int
+	Body       []byte
+}
+
+// do sends a request with an optional JSON body. Throttling and outages are returned as
+// errors, since they say nothing about the project.
+func (a *Auditor) do(ctx context.Context, method, rawURL string, body interface{}) (*response, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, validator.RequestError(err)
+	}
+	defer resp.Body.Close()
+	content, err := validator.ReadResponse(ctx, resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if err := validator.ClassifyResponse(resp.StatusCode, content); err != nil {
+		return nil, err
+	}
+	return &response{StatusCode: resp.StatusCode, Body: content}, nil
+}
+
+// Audit runs every check the config allows and returns one result per check. A valid result
+// is a misconfiguration; the signup check needs the API key and is skipped without one.
+func (a *Auditor) Audit(ctx context.Context, cfg *Config) []*validator.ValidationResult {
+	var results []*validator.ValidationResult
+	if cfg.APIKey != "" {
+		results = append(results, a.checkSignup(ctx, cfg))
+	}
+	results = append(results, a.checkDatabase(ctx, cfg), a.checkFirestore(ctx, cfg), a.checkStorage(ctx, cfg))
+	for _, result := range results {
+		result.Classify()
+	}
+	return results
+}
+
+// newResult starts the result of a check of resource
+func newResult(service, resource string) *validator.ValidationResult {
+	return &validator.ValidationResult{
+		Key:         resource,
+		Service:     service,
+		ValidatedAt: time.Now(),
+		Details:     make(map[string]interface{}),
+	}
+}
+
+// closed marks result as a check that found nothing open, giving reason as its error
+func closed(result *validator.ValidationResult, reason string) *validator.ValidationResult {
+	result.Valid = false
+	result.RiskLevel = validator.RiskLevelLow
+	result.Error = validator.ErrInvalidKey
+	result.ErrorStr = reason
+	return result
+}
+
+// failed returns the result of a check that could not be completed
+func failed(result *validator.ValidationResult, err error) *validator.ValidationResult {
+	failure := validator.NewErrorResult(result.Key, result.Service, err)
+	failure.Details = result.Details
+	return failure
+}
+
+// identityError returns the error code of an Identity Toolkit answer, such as ADMIN_ONLY_OPERATION
+func identityError(body []byte) string {
+	var answer struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	json.Unmarshal(body, &answer)
+	code, _, _ := strings.Cut(answer.Error.Message, " ")
+	return code
+}
+
+// checkSignup tries to create an email/password account, then an anonymous one, deleting any
+// account created. Either lets anyone pass security rules that only require a signed-in user.
+func (a *Auditor) checkSignup(ctx context.Context, cfg *Config) *validator.ValidationResult {
+	result := newResult(ServiceSignup, cfg.APIKey)
+	signUp := identityToolkit + "accounts:signUp?key=" + url.QueryEscape(cfg.APIKey)
+
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return failed(result, fmt.Errorf("failed to generate test account: %w", err))
+	}
+	attempts := []struct {
+		Method string
+		Body   map[string]interface{}
+	}{
+		{"email", map[string]interface{}{
+			"email":             "apikeyzer-" + hex.EncodeToString(suffix) + "@example.com",
+			"password":          hex.EncodeToString(suffix) + "Aa1!",
+			"returnSecureToken": true,
+		}},
+		{"anonymous", map[string]interface{}{"returnSecureToken": true}},
+	}
+	for _, attempt := range attempts {
+		resp, err := a.do(ctx, http.MethodPost, signUp, attempt.Body)
+		if err != nil {
+			return failed(result, err)
+		}
+		switch code := identityError(resp.Body); {
+		case resp.StatusCode == http.StatusOK:
+			var account struct {
+				IDToken string `json:"idToken"`
+			}
+			json.Unmarshal(resp.Body, &account)
+			result.Valid = true
+			result.RiskLevel = validator.RiskLevelHigh
+			result.Permissions = append(result.Permissions, attempt.Method+" signup")
+			result.Details["signup_method"] = attempt.Method
+			result.Details["test_account_deleted"] = a.deleteAccount(ctx, cfg.APIKey, account.IDToken)
+			return result
+		case code == "API_KEY_INVALID" || strings.Contains(string(resp.Body), "API key not valid"):
+			return failed(result, fmt.Errorf("%w: the API key was rejected", validator.ErrInvalidKey))
+		case code == "OPERATION_NOT_ALLOWED", code == "ADMIN_ONLY_OPERATION":
+			result.Details[attempt.Method] = code
+		case resp.StatusCode == http.StatusForbidden:
+			return closed(result, "Firebase Authentication is not enabled for the key")
+		default:
+			return failed(result, fmt.Errorf("%w: unexpected status %d (%s)", validator.ErrValidationError, resp.StatusCode, code))
+		}
+	}
+	return closed(result, "signup is disabled for every method tried")
+}
+
+// deleteAccount deletes the account of a signup test and reports whether it succeeded
+func (a *Auditor) deleteAccount(ctx context.Context, apiKey, idToken string) bool {
+	if idToken == "" {
+		return false
+	}
+	resp, err := a.do(ctx, http.MethodPost, identityToolkit+"accounts:delete?key="+url.QueryEscape(apiKey), map[string]string{"idToken": idToken})
+	return err == nil && resp.StatusCode == http.StatusOK
+}
+
+// checkDatabase reads the root of the Realtime Database without credentials, asking only for
+// its top-level keys
+func (a *Auditor) checkDatabase(ctx context.Context, cfg *Config) *validator.ValidationResult {
+	databaseURL := strings.TrimRight(cfg.DatabaseURL, "/")
+	if databaseURL == "" {
+		databaseURL = "https://" + cfg.ProjectID + "-default-rtdb.firebaseio.com"
+	}
+	result := newResult(ServiceDatabase, databaseURL)
+
+	resp, err := a.do(ctx, http.MethodGet, databaseURL+"/.json?shallow=true", nil)
+	if err != nil {
+		return failed(result, err)
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return closed(result, "security rules deny unauthenticated reads")
+	case http.StatusNotFound, http.StatusPaymentRequired, http.StatusLocked:
+		return closed(result, "no active Realtime Database at this URL")
+	default:
+		return failed(result, fmt.Errorf("%w: unexpected status %d", validator.ErrValidationError, resp.StatusCode))
+	}
+
+	result.Valid = true
+	result.RiskLevel = validator.RiskLevelHigh
+	result.Permissions = []string{"read"}
+	var root map[string]interface{}
+	if json.Unmarshal(resp.Body, &root) == nil {
+		keys := make([]string, 0, len(root))
+		for key := range root {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		result.Details["top_level_keys"] = keys
+	}
+	return result
+}
+
+// checkFirestore reads a collection that does not exist; rules that allow it allow reading
+// every collection of the default database
+func (a *Auditor) checkFirestore(ctx context.Context, cfg *Config) *validator.ValidationResult {
+	documents := "https://firestore.googleapis.com/v1/projects/" + url.PathEscape(cfg.ProjectID) + "/databases/(default)/documents"
+	result := newResult(ServiceFirestore, documents)
+
+	probeURL := documents + "/apikeyzer?pageSize=1"
+	if cfg.APIKey != "" {
+		probeURL += "&key=" + url.QueryEscape(cfg.APIKey)
+	}
+	resp, err := a.do(ctx, http.MethodGet, probeURL, nil)
+	if err != nil {
+		return failed(result, err)
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return closed(result, "security rules deny unauthenticated reads")
+	case http.StatusNotFound, http.StatusBadRequest:
+		// Projects without a Firestore database, or with one in Datastore mode
+		return closed(result, "no Firestore database in native mode")
+	default:
+		return failed(result, fmt.Errorf("%w: unexpected status %d", validator.ErrValidationError, resp.StatusCode))
+	}
+	result.Valid = true
+	result.RiskLevel = validator.RiskLevelHigh
+	result.Permissions = []string{"read any collection"}
+	return result
+}
+
+// checkStorage lists the objects of the configured bucket, or of the project's default buckets
+func (a *Auditor) checkStorage(ctx context.Context, cfg *Config) *validator.ValidationResult {
+	buckets := []string{cfg.ProjectID + ".appspot.com", cfg.ProjectID + ".firebasestorage.app"}
+	if cfg.StorageBucket != "" {
+		buckets = []string{strings.TrimPrefix(cfg.StorageBucket, "gs://")}
+	}
+
+	var result *validator.ValidationResult
+	for _, bucket := range buckets {
+		listURL := "https://firebasestorage.googleapis.com/v0/b/" + url.PathEscape(bucket) + "/o"
+		result = newResult(ServiceStorage, listURL)
+		resp, err := a.do(ctx, http.MethodGet, listURL+"?maxResults=10", nil)
+		if err != nil {
+			return failed(result, err)
+		}
+		switch resp.StatusCode {
+		case http.StatusOK:
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return closed(result, "security rules deny unauthenticated listing")
+		case http.StatusNotFound, http.StatusBadRequest:
+			continue
+		default:
+			return failed(result, fmt.Errorf("%w: unexpected status %d", validator.ErrValidationError, resp.StatusCode))
+		}
+
+		var objects struct {
+			Prefixes []string `json:"prefixes"`
+			Items    []struct {
+				Name string `json:"name"`
+			} `json:"items"`
+		}
+		json.Unmarshal(resp.Body, &objects)
+		names := append([]string{}, objects.Prefixes...)
+		for _, item := range objects.Items {
+			names = append(names, item.Name)
+		}
+		result.Valid = true
+		result.RiskLevel = validator.RiskLevelHigh
+		result.Permissions = []string{"list"}
+		result.Details["bucket"] = bucket
+		result.Details["sample_objects"] = names
+		return result
+	}
+	return closed(result, "no Firebase Storage bucket found")
+}
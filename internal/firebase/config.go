@@ -0,0 +1,78 @@
+package firebase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Config holds the parts of a Firebase app configuration the audit needs
+type Config struct {
+	APIKey        string
+	ProjectID     string
+	DatabaseURL   string // Realtime Database URL; defaults to the project's default database
+	StorageBucket string // Cloud Storage bucket; defaults to the project's default buckets
+}
+
+// jsConfigField matches a field of a JavaScript firebaseConfig object, with or without quoted names
+var jsConfigField = regexp.MustCompile(`["']?(apiKey|projectId|databaseURL|storageBucket)["']?\s*:\s*["']([^"']+)["']`)
+
+// googleServices is the part of an Android google-services.json file read by the audit
+type googleServices struct {
+	ProjectInfo struct {
+		ProjectID     string `json:"project_id"`
+		FirebaseURL   string `json:"firebase_url"`
+		StorageBucket string `json:"storage_bucket"`
+	} `json:"project_info"`
+	Client []struct {
+		APIKey []struct {
+			CurrentKey string `json:"current_key"`
+		} `json:"api_key"`
+	} `json:"client"`
+}
+
+// ParseConfig reads a google-services.json file or a JavaScript Firebase config blob
+// (const firebaseConfig = { apiKey: "...", projectId: "...", ... })
+func ParseConfig(data []byte) (*Config, error) {
+	var cfg Config
+	var services googleServices
+	if err := json.Unmarshal(data, &services); err == nil && services.ProjectInfo.ProjectID != "" {
+		cfg.ProjectID = services.ProjectInfo.ProjectID
+		cfg.DatabaseURL = services.ProjectInfo.FirebaseURL
+		cfg.StorageBucket = services.ProjectInfo.StorageBucket
+		for _, client := range services.Client {
+			for _, key := range client.APIKey {
+				if cfg.APIKey == "" {
+					cfg.APIKey = key.CurrentKey
+				}
+			}
+		}
+	} else {
+		for _, match := range jsConfigField.FindAllStringSubmatch(string(data), -1) {
+			switch match[1] {
+			case "apiKey":
+				cfg.APIKey = match[2]
+			case "projectId":
+				cfg.ProjectID = match[2]
+			case "databaseURL":
+				cfg.DatabaseURL = match[2]
+			case "storageBucket":
+				cfg.StorageBucket = match[2]
+			}
+		}
+	}
+	if err := cfg.Check(); err != nil {
+		return nil, fmt.Errorf("invalid Firebase config: %w", err)
+	}
+	return &cfg, nil
+}
+
+// Check reports whether the config names a project, the one field every check needs
+func (c *Config) Check() error {
+	if strings.TrimSpace(c.ProjectID) == "" {
+		return errors.New("no project ID")
+	}
+	return nil
+}
@@ -0,0 +1,312 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultAPIURL is the REST API of github.com; GitHub Enterprise Server uses https://HOST/api/v3
+const DefaultAPIURL = "https://api.github.com"
+
+// maxAnnotations is the number of annotations the Checks API accepts per request
+const maxAnnotations = 50
+
+// Client talks to the GitHub REST API as a GitHub App installation or with a fixed token
+type Client struct {
+	baseURL string
+	version string
+	client  *http.Client
+
+	token string
+
+	appID  string
+	appKey *rsa.PrivateKey
+
+	mu     sync.Mutex
+	tokens map[int64]installationToken
+}
+
+// installationToken is a cached token of a GitHub App installation
+type installationToken struct {
+	token   string
+	expires time.Time
+}
+
+// File is a file changed by a push or pull request.
+// Patch is empty for binary files and diffs GitHub considers too large to show.
+type File struct {
+	Filename string `json:"filename"`
+	Status   string `json:"status"`
+	Patch    string `json:"patch"`
+}
+
+// Annotation points a check run at a line of a file
+type Annotation struct {
+	Path      string `json:"path"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+	Level     string `json:"annotation_level"` // notice, warning or failure
+	Title     string `json:"title,omitempty"`
+	Message   string `json:"message"`
+}
+
+// CheckOutput is the title, summary and annotations shown on a check run
+type CheckOutput struct {
+	Title       string       `json:"title"`
+	Summary     string       `json:"summary"`
+	Annotations []Annotation `json:"annotations,omitempty"`
+}
+
+// NewClient creates a client for the API at baseURL
+func NewClient(baseURL, version string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultAPIURL
+	}
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		version: version,
+		client:  &http.Client{Timeout: 30 * time.Second},
+		tokens:  make(map[int64]installationToken),
+	}
+}
+
+// SetToken sets a token used when an event has no installation or no app is configured
+func (c *Client) SetToken(token string) {
+	c.token = token
+}
+
+// SetApp authenticates as the GitHub App appID, signing with its PEM encoded private key
+func (c *Client) SetApp(appID string, keyPEM []byte) error {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return errors.New("GitHub App private key is not PEM encoded")
+	}
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		parsed, pkcs8Err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if pkcs8Err != nil {
+			return fmt.Errorf("failed to parse GitHub App private key: %w", err)
+		}
+		rsaKey, ok := parsed.(*rsa.PrivateKey)
+		if !ok {
+			return errors.New("GitHub App private key is not an RSA key")
+		}
+		key = rsaKey
+	}
+	c.appID = appID
+	c.appKey = key
+	return nil
+}
+
+// ChangedFiles lists the files changed by an event: the files of the pull request,
+// the comparison of the pushed range, or the head commit of a new branch
+func (c *Client) ChangedFiles(ctx context.Context, e *Event) ([]File, error) {
+	token, err := c.tokenFor(ctx, e.InstallationID)
+	if err != nil {
+		return nil, err
+	}
+	repo := "/repos/" + e.Repo.FullName()
+
+	if e.PullRequest > 0 {
+		// The files API returns at most 3000 files, 100 per page
+		var files []File
+		for page := 1; page <= 30; page++ {
+			var batch []File
+			path := fmt.Sprintf("%s/pulls/%d/files?per_page=100&page=%d", repo, e.PullRequest, page)
+			if err := c.do(ctx, http.MethodGet, path, token, nil, &batch); err != nil {
+				return nil, err
+			}
+			files = append(files, batch...)
+			if len(batch) < 100 {
+				break
+			}
+		}
+		return files, nil
+	}
+
+	var changes struct {
+		Files []File `json:"files"`
+	}
+	path := repo + "/commits/" + e.Head
+	if e.Base != "" {
+		path = repo + "/compare/" + e.Base + "..." + e.Head
+	}
+	if err := c.do(ctx, http.MethodGet, path, token, nil, &changes); err != nil {
+		return nil, err
+	}
+	return changes.Files, nil
+}
+
+// StartCheckRun creates an in progress check run named name on the head commit of an event
+func (c *Client) StartCheckRun(ctx context.Context, e *Event, name string) (int64, error) {
+	token, err := c.tokenFor(ctx, e.InstallationID)
+	if err != nil {
+		return 0, err
+	}
+	body := map[string]interface{}{
+		"name":     name,
+		"head_sha": e.Head,
+		"status":   "in_progress",
+	}
+	var run struct {
+		ID int64 `json:"id"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/repos/"+e.Repo.FullName()+"/check-runs", token, body, &run); err != nil {
+		return 0, err
+	}
+	return run.ID, nil
+}
+
+// CompleteCheckRun finishes a check run with a conclusion such as success, failure or neutral.
+// Annotations beyond the per-request limit are added with further updates.
+func (c *Client) CompleteCheckRun(ctx context.Context, e *Event, id int64, conclusion string, out CheckOutput) error {
+	token, err := c.tokenFor(ctx, e.InstallationID)
+	if err != nil {
+		return err
+	}
+	path := fmt.Sprintf("/repos/%s/check-runs/%d", e.Repo.FullName(), id)
+
+	annotations := out.Annotations
+	for first := true; first || len(annotations) > 0; first = false {
+		batch := annotations
+		if len(batch) > maxAnnotations {
+			batch = batch[:maxAnnotations]
+		}
+		annotations = annotations[len(batch):]
+
+		update := map[string]interface{}{
+			"output": CheckOutput{Title: out.Title, Summary: out.Summary, Annotations: batch},
+		}
+		if first {
+			update["status"] = "completed"
+			update["conclusion"] = conclusion
+			update["completed_at"] = time.Now().UTC().Format(time.RFC3339)
+		}
+		if err := c.do(ctx, http.MethodPatch, path, token, update, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tokenFor returns a token for the installation, falling back to the fixed token
+func (c *Client) tokenFor(ctx context.Context, installationID int64) (string, error) {
+	if c.appKey == nil || installationID == 0 {
+		if c.token == "" {
+			return "", errors.New("no GitHub credentials: set a GitHub App or a token")
+		}
+		return c.token, nil
+	}
+
+	c.mu.Lock()
+	cached, ok := c.tokens[installationID]
+	c.mu.Unlock()
+	if ok && time.Until(cached.expires) > time.Minute {
+		return cached.token, nil
+	}
+
+	jwt, err := c.appJWT()
+	if err != nil {
+		return "", err
+	}
+	var resp struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	path := fmt.Sprintf("/app/installations/%d/access_tokens", installationID)
+	if err := c.do(ctx, http.MethodPost, path, jwt, nil, &resp); err != nil {
+		return "", fmt.Errorf("failed to get installation token: %w", err)
+	}
+
+	c.mu.Lock()
+	c.tokens[installationID] = installationToken{token: resp.Token, expires: resp.ExpiresAt}
+	c.mu.Unlock()
+	return resp.Token, nil
+}
+
+// appJWT returns the short-lived RS256 token that authenticates as the app itself
+func (c *Client) appJWT() (string, error) {
+	now := time.Now()
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	claims, err := json.Marshal(map[string]interface{}{
+		// Backdated to allow for clock drift, as GitHub recommends
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": c.appID,
+	})
+	if err != nil {
+		return "", err
+	}
+	unsigned := header + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, c.appKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign GitHub App token: %w", err)
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// do sends an API request, encoding body and decoding the response into out when set
+func (c *Client) do(ctx context.Context, method, path, token string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	req.Header.Set("User-Agent", "APIKeyzer/"+c.version)
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("GitHub request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	content, err := io.ReadAll(io.LimitReader(resp.Body, 32<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read GitHub response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(content, &apiErr) == nil && apiErr.Message != "" {
+			return fmt.Errorf("%s %s: %s (%s)", method, path, resp.Status, apiErr.Message)
+		}
+		return fmt.Errorf("%s %s: %s", method, path, resp.Status)
+	}
+	if out != nil {
+		if err := json.Unmarshal(content, out); err != nil {
+			return fmt.Errorf("failed to parse GitHub response: %w", err)
+		}
+	}
+	return nil
+}
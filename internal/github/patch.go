@@ -0,0 +1,50 @@
+package github
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Line is a line added by a change, numbered as in the new version of the file
+type Line struct {
+	Number int
+	Text   string
+}
+
+// AddedLines returns the lines added by a unified diff patch of a single file,
+// as found in the patch field of the pull request files and compare APIs
+func AddedLines(patch string) []Line {
+	var lines []Line
+	number := 0
+	for _, text := range strings.Split(patch, "\n") {
+		switch {
+		case strings.HasPrefix(text, "@@"):
+			number = hunkStart(text)
+		case strings.HasPrefix(text, "+"):
+			lines = append(lines, Line{Number: number, Text: strings.TrimSuffix(text[1:], "\r")})
+			number++
+		case strings.HasPrefix(text, "-"), strings.HasPrefix(text, `\`):
+			// removed lines and "\ No newline at end of file" do not exist in the new file
+		default:
+			number++
+		}
+	}
+	return lines
+}
+
+// hunkStart returns the first new-file line of a "@@ -a,b +c,d @@" hunk header
+func hunkStart(header string) int {
+	_, rest, ok := strings.Cut(header, " +")
+	if !ok {
+		return 0
+	}
+	end := strings.IndexAny(rest, ", ")
+	if end < 0 {
+		return 0
+	}
+	start, err := strconv.Atoi(rest[:end])
+	if err != nil {
+		return 0
+	}
+	return start
+}
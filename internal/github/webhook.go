@@ -0,0 +1,131 @@
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Headers sent by GitHub with every webhook delivery
+const (
+	EventHeader     = "X-GitHub-Event"
+	DeliveryHeader  = "X-GitHub-Delivery"
+	SignatureHeader = "X-Hub-Signature-256"
+)
+
+// ErrBadSignature is returned when a delivery is not signed with the webhook secret
+var ErrBadSignature = errors.New("webhook signature does not match")
+
+// VerifySignature checks the X-Hub-Signature-256 header of a delivery against its body
+func VerifySignature(secret []byte, signature string, body []byte) error {
+	digest, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return ErrBadSignature
+	}
+	got, err := hex.DecodeString(digest)
+	if err != nil {
+		return ErrBadSignature
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// Repository identifies the repository an event belongs to
+type Repository struct {
+	Owner string
+	Name  string
+}
+
+// FullName returns the owner/name form of the repository
+func (r Repository) FullName() string {
+	return r.Owner + "/" + r.Name
+}
+
+// Event is a push or pull request delivery reduced to what a scan needs:
+// the commit range to compare and the commit the check run is attached to
+type Event struct {
+	Repo           Repository
+	InstallationID int64
+	Base           string // commit the changes are compared against; empty for a new branch
+	Head           string // commit that receives the check run
+	PullRequest    int    // pull request number, zero for pushes
+}
+
+// payload holds the fields shared by push and pull_request payloads
+type payload struct {
+	Action     string `json:"action"`
+	Before     string `json:"before"`
+	After      string `json:"after"`
+	Deleted    bool   `json:"deleted"`
+	Repository struct {
+		Name  string `json:"name"`
+		Owner struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+	} `json:"repository"`
+	Installation struct {
+		ID int64 `json:"id"`
+	} `json:"installation"`
+	PullRequest struct {
+		Number int `json:"number"`
+		Head   struct {
+			SHA string `json:"sha"`
+		} `json:"head"`
+		Base struct {
+			SHA string `json:"sha"`
+		} `json:"base"`
+	} `json:"pull_request"`
+}
+
+// zeroSHA is sent as the before commit of a newly created branch
+const zeroSHA = "0000000000000000000000000000000000000000"
+
+// ParseEvent decodes a push or pull_request delivery.
+// It returns nil without error for events and actions that introduce no new commits,
+// such as ping, branch deletions or closed pull requests.
+func ParseEvent(event string, body []byte) (*Event, error) {
+	if event != "push" && event != "pull_request" {
+		return nil, nil
+	}
+
+	var p payload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse %s payload: %w", event, err)
+	}
+	e := &Event{
+		Repo:           Repository{Owner: p.Repository.Owner.Login, Name: p.Repository.Name},
+		InstallationID: p.Installation.ID,
+	}
+	if e.Repo.Owner == "" || e.Repo.Name == "" {
+		return nil, fmt.Errorf("%s payload has no repository", event)
+	}
+
+	if event == "push" {
+		if p.Deleted || p.After == "" || p.After == zeroSHA {
+			return nil, nil
+		}
+		e.Head = p.After
+		if p.Before != zeroSHA {
+			e.Base = p.Before
+		}
+		return e, nil
+	}
+
+	switch p.Action {
+	case "opened", "synchronize", "reopened":
+	default:
+		return nil, nil
+	}
+	e.PullRequest = p.PullRequest.Number
+	e.Head = p.PullRequest.Head.SHA
+	e.Base = p.PullRequest.Base.SHA
+	return e, nil
+}
@@ -0,0 +1,98 @@
+package input
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// AllowlistFileName is the allowlist read from the current directory when none is given
+const AllowlistFileName = ".apikeyzerallow"
+
+// IgnoreComment marks a line whose candidate keys are not reported, e.g. "key = '...' # apikeyzer:ignore"
+const IgnoreComment = "apikeyzer:ignore"
+
+// Allowlist lists known fake or accepted keys that scans skip before validating them
+type Allowlist struct {
+	dir    string
+	hashes map[string]bool
+	paths  []string
+}
+
+// KeyHash returns the allowlist entry for a key, its hex SHA-256 prefixed with "sha256:"
+func KeyHash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// LoadAllowlist reads an allowlist file with one entry per line:
+//
+//	sha256:<hex>       a key, by the SHA-256 of its value
+//	path:<glob>        every key in files matching the glob, relative to the allowlist's directory
+//
+// Blank lines and lines starting with '#' are ignored.
+func LoadAllowlist(filename string) (*Allowlist, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open allowlist: %w", err)
+	}
+	defer file.Close()
+
+	dir, err := filepath.Abs(filepath.Dir(filename))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve allowlist directory: %w", err)
+	}
+	allowlist := &Allowlist{dir: dir, hashes: make(map[string]bool)}
+
+	scanner := bufio.NewScanner(file)
+	number := 0
+	for scanner.Scan() {
+		number++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		kind, value, _ := strings.Cut(line, ":")
+		value = strings.TrimSpace(value)
+		switch {
+		case kind == "sha256" && len(value) == sha256.Size*2:
+			allowlist.hashes["sha256:"+strings.ToLower(value)] = true
+		case kind == "path" && value != "":
+			allowlist.paths = append(allowlist.paths, value)
+		default:
+			return nil, fmt.Errorf("%s:%d: invalid allowlist entry %q: expected sha256:<hex> or path:<glob>", filename, number, line)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading allowlist: %w", err)
+	}
+	return allowlist, nil
+}
+
+// Allows reports whether the record's key or source is on the allowlist. A nil allowlist allows nothing.
+func (a *Allowlist) Allows(rec Record) bool {
+	if a == nil {
+		return false
+	}
+	if a.hashes[KeyHash(rec.Key)] {
+		return true
+	}
+	if len(a.paths) == 0 || rec.Source == "" {
+		return false
+	}
+
+	source, err := filepath.Abs(rec.Source)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(a.dir, source)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	return matchAny(a.paths, filepath.ToSlash(rel))
+}
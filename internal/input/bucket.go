@@ -0,0 +1,318 @@
+package input
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// DefaultMaxObjectSize is the largest bucket object that will be downloaded
+const DefaultMaxObjectSize = 10 << 20
+
+// maxListingSize bounds a single page of a bucket listing; S3 and GCS pages of 1000 objects
+// are well below it
+const maxListingSize = 32 << 20
+
+// binaryExtensions lists object extensions that are never scanned
+var binaryExtensions = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".webp": true, ".ico": true,
+	".bmp": true, ".tiff": true, ".mp3": true, ".mp4": true, ".mov": true, ".avi": true,
+	".zip": true, ".gz": true, ".tgz": true, ".bz2": true, ".xz": true, ".7z": true,
+	".rar": true, ".tar": true, ".pdf": true, ".exe": true, ".dll": true, ".so": true,
+	".bin": true, ".woff": true, ".woff2": true, ".ttf": true, ".eot": true, ".jar": true,
+	".class": true, ".apk": true, ".ipa": true, ".dmg": true, ".iso": true,
+}
+
+// BucketObject is a single object returned by a bucket listing
+type BucketObject struct {
+	Name string
+	Size int64
+	URL  string
+}
+
+// BucketScanner enumerates publicly accessible S3/GCS buckets and scans their objects for keys
+type BucketScanner struct {
+	client        *http.Client
+	maxObjectSize int64
+	maxLineSize   int
+}
+
+// NewBucketScanner creates a new BucketScanner instance listing and downloading with client
+func NewBucketScanner(client *http.Client) *BucketScanner {
+	return &BucketScanner{
+		client:        client,
+		maxObjectSize: DefaultMaxObjectSize,
+		maxLineSize:   DefaultMaxLineSize,
+	}
+}
+
+// SetMaxObjectSize limits the size of objects that will be downloaded
+func (b *BucketScanner) SetMaxObjectSize(size int64) {
+	b.maxObjectSize = size
+}
+
+// SetMaxLineSize sets the longest line scanned within an object
+func (b *BucketScanner) SetMaxLineSize(size int) {
+	b.maxLineSize = size
+}
+
+// s3ListResult mirrors the ListObjectsV2 XML response
+type s3ListResult struct {
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+	Contents              []struct {
+		Key  string `xml:"Key"`
+		Size int64  `xml:"Size"`
+	} `xml:"Contents"`
+}
+
+// gcsListResult mirrors the GCS JSON API objects.list response
+type gcsListResult struct {
+	NextPageToken string `json:"nextPageToken"`
+	Items         []struct {
+		Name string `json:"name"`
+		Size string `json:"size"`
+	} `json:"items"`
+}
+
+// Scan lists every object in target and reports candidate keys found in text-like objects.
+// target may be a bare bucket name, an s3:// or gs:// URL, or an S3-compatible listing URL.
+func (b *BucketScanner) Scan(ctx context.Context, target string, fn func(Record)) error {
+	objects, err := b.List(ctx, target)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("listed bucket", "bucket", target, "objects", len(objects))
+
+	for _, obj := range objects {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if binaryExtensions[strings.ToLower(path.Ext(obj.Name))] {
+			continue
+		}
+		if b.maxObjectSize > 0 && obj.Size > b.maxObjectSize {
+			slog.Info("skipping large object", "object", obj.Name, "size", obj.Size)
+			continue
+		}
+		if err := b.scanObject(ctx, obj, fn); err != nil {
+			slog.Info("skipping object", "object", obj.Name, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// List enumerates the objects in target, following pagination
+func (b *BucketScanner) List(ctx context.Context, target string) ([]BucketObject, error) {
+	switch {
+	case strings.HasPrefix(target, "gs://"):
+		return b.listGCS(ctx, strings.TrimSuffix(strings.TrimPrefix(target, "gs://"), "/"))
+
+	case strings.HasPrefix(target, "s3://"):
+		name := strings.TrimSuffix(strings.TrimPrefix(target, "s3://"), "/")
+		return b.listS3(ctx, fmt.Sprintf("https://%s.s3.amazonaws.com/", name))
+
+	case strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://"):
+		u, err := url.Parse(target)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bucket URL: %w", err)
+		}
+		if u.Host == "storage.googleapis.com" {
+			return b.listGCS(ctx, strings.SplitN(strings.Trim(u.Path, "/"), "/", 2)[0])
+		}
+		u.RawQuery = ""
+		return b.listS3(ctx, u.String())
+
+	default:
+		objects, err := b.listS3(ctx, fmt.Sprintf("https://%s.s3.amazonaws.com/", target))
+		if err == nil {
+			return objects, nil
+		}
+		slog.Debug("S3 listing failed, trying GCS", "error", err)
+		return b.listGCS(ctx, target)
+	}
+}
+
+// listS3 pages through an S3-compatible ListObjectsV2 endpoint
+func (b *BucketScanner) listS3(ctx context.Context, base string) ([]BucketObject, error) {
+	base = strings.TrimSuffix(base, "/") + "/"
+	var objects []BucketObject
+	token := ""
+
+	for {
+		q := url.Values{}
+		q.Set("list-type", "2")
+		if token != "" {
+			q.Set("continuation-token", token)
+		}
+
+		body, err := b.get(ctx, base+"?"+q.Encode())
+		if err != nil {
+			return nil, err
+		}
+
+		var result s3ListResult
+		if err := xml.Unmarshal(body, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse bucket listing: %w", err)
+		}
+
+		for _, c := range result.Contents {
+			objects = append(objects, BucketObject{
+				Name: c.Key,
+				Size: c.Size,
+				URL:  base + escapeObjectName(c.Key),
+			})
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// listGCS pages through the GCS JSON API objects listing
+func (b *BucketScanner) listGCS(ctx context.Context, bucket string) ([]BucketObject, error) {
+	var objects []BucketObject
+	token := ""
+
+	for {
+		listURL := fmt.Sprintf("https://storage.googleapis.com/storage/v1/b/%s/o", url.PathEscape(bucket))
+		if token != "" {
+			listURL += "?pageToken=" + url.QueryEscape(token)
+		}
+
+		body, err := b.get(ctx, listURL)
+		if err != nil {
+			return nil, err
+		}
+
+		var result gcsListResult
+		if err := json.Unmarshal(body, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse bucket listing: %w", err)
+		}
+
+		for _, item := range result.Items {
+			var size int64
+			fmt.Sscan(item.Size, &size)
+			objects = append(objects, BucketObject{
+				Name: item.Name,
+				Size: size,
+				URL:  fmt.Sprintf("https://storage.googleapis.com/%s/%s", bucket, escapeObjectName(item.Name)),
+			})
+		}
+
+		if result.NextPageToken == "" {
+			return objects, nil
+		}
+		token = result.NextPageToken
+	}
+}
+
+// get fetches a listing page and returns its body
+func (b *BucketScanner) get(ctx context.Context, rawURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("bucket listing returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxListingSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxListingSize {
+		return nil, fmt.Errorf("bucket listing page larger than %d bytes", maxListingSize)
+	}
+	return body, nil
+}
+
+// scanObject streams a single object and reports candidate keys line by line
+func (b *BucketScanner) scanObject(ctx context.Context, obj BucketObject, fn func(Record)) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, obj.URL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("object returned status %d", resp.StatusCode)
+	}
+
+	// The listed size is only a claim; the body is cut off at --max-object-size whatever it says
+	var body io.Reader = resp.Body
+	if b.maxObjectSize > 0 {
+		body = io.LimitReader(resp.Body, b.maxObjectSize)
+	}
+	reader := bufio.NewReader(body)
+	head, _ := reader.Peek(512)
+	if !isTextContent(resp.Header.Get("Content-Type"), head) {
+		return fmt.Errorf("not a text object")
+	}
+
+	slog.Debug("scanning object", "object", obj.Name)
+
+	lines := NewLineReader(reader, b.maxLineSize)
+	for lines.Next() {
+		for _, candidate := range ExtractCandidates(lines.Text()) {
+			fn(Record{Key: candidate.Value, Source: obj.URL, Line: lines.LineNumber(), Column: candidate.Column})
+		}
+	}
+
+	if skipped := lines.Skipped(); skipped > 0 {
+		slog.Info("skipped overlong lines", "object", obj.Name, "lines", skipped)
+	}
+
+	return lines.Err()
+}
+
+// isTextContent decides from the content type and leading bytes whether an object is text
+func isTextContent(contentType string, head []byte) bool {
+	if contentType == "" || contentType == "application/octet-stream" || contentType == "binary/octet-stream" {
+		contentType = http.DetectContentType(head)
+	}
+	contentType = strings.ToLower(contentType)
+
+	if strings.HasPrefix(contentType, "text/") {
+		return true
+	}
+	for _, kind := range []string{"json", "xml", "javascript", "yaml", "x-sh", "x-www-form-urlencoded"} {
+		if strings.Contains(contentType, kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// escapeObjectName escapes each path segment of an object name
+func escapeObjectName(name string) string {
+	segments := strings.Split(name, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
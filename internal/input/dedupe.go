@@ -0,0 +1,47 @@
+package input
+
+import "hash/maphash"
+
+// DefaultDedupeWindow is how many distinct keys a Deduper remembers by default
+const DefaultDedupeWindow = 1 << 20
+
+// Deduper recognises keys that were already seen. It stores a 64-bit hash per key rather
+// than the key itself, and only the last window distinct keys, so memory stays bounded
+// however large the input is: duplicates further apart than the window pass again.
+type Deduper struct {
+	seed   maphash.Seed
+	window int
+	seen   map[uint64]struct{}
+	recent []uint64 // ring of the remembered hashes, oldest at next once full
+	next   int
+}
+
+// NewDeduper creates a Deduper remembering window distinct keys; window <= 0 remembers all of them
+func NewDeduper(window int) *Deduper {
+	return &Deduper{
+		seed:   maphash.MakeSeed(),
+		window: window,
+		seen:   make(map[uint64]struct{}),
+	}
+}
+
+// Seen reports whether key was seen recently, remembering it if not
+func (d *Deduper) Seen(key string) bool {
+	h := maphash.String(d.seed, key)
+	if _, ok := d.seen[h]; ok {
+		return true
+	}
+	d.seen[h] = struct{}{}
+	if d.window <= 0 {
+		return false
+	}
+
+	if len(d.recent) < d.window {
+		d.recent = append(d.recent, h)
+		return false
+	}
+	delete(d.seen, d.recent[d.next])
+	d.recent[d.next] = h
+	d.next = (d.next + 1) % d.window
+	return false
+}
@@ -0,0 +1,93 @@
+package input
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// dockerInspect mirrors the parts of `docker inspect` output that hold environment variables
+type dockerInspect struct {
+	Name   string `json:"Name"`
+	Config struct {
+		Env []string `json:"Env"`
+	} `json:"Config"`
+}
+
+// FromEnviron extracts candidate keys from KEY=VALUE pairs such as os.Environ().
+// Each record's source is "<prefix>:<NAME>" so the variable can be found again.
+func FromEnviron(environ []string, prefix string) []Record {
+	var records []Record
+	for _, entry := range environ {
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			continue
+		}
+		records = append(records, envValueRecords(value, prefix+":"+name, 0)...)
+	}
+	return records
+}
+
+// FromEnvFile extracts candidate keys from an env dump (KEY=VALUE lines, as written by
+// `env`, `printenv` or a .env file) or from `docker inspect` JSON output
+func FromEnvFile(filename string) ([]Record, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read env file: %w", err)
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		var containers []dockerInspect
+		if err := json.Unmarshal(trimmed, &containers); err != nil {
+			return nil, fmt.Errorf("failed to parse docker inspect output: %w", err)
+		}
+
+		var records []Record
+		for _, container := range containers {
+			name := strings.TrimPrefix(container.Name, "/")
+			records = append(records, FromEnviron(container.Config.Env, "docker:"+name)...)
+		}
+		return records, nil
+	}
+
+	var records []Record
+	lines := NewLineReader(bytes.NewReader(data), 0)
+	for lines.Next() {
+		line := strings.TrimSpace(lines.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		_, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		records = append(records, envValueRecords(value, filename, lines.LineNumber())...)
+	}
+
+	return records, lines.Err()
+}
+
+// envValueRecords returns the whole (unquoted) value as a candidate, followed by any
+// tokens embedded in it such as a key inside a connection string or URL
+func envValueRecords(value, source string, line int) []Record {
+	value = strings.Trim(strings.TrimSpace(value), `"'`)
+	if value == "" {
+		return nil
+	}
+
+	seen := map[string]bool{value: true}
+	records := []Record{{Key: value, Source: source, Line: line}}
+	for _, candidate := range ExtractCandidates(value) {
+		if seen[candidate.Value] {
+			continue
+		}
+		seen[candidate.Value] = true
+		records = append(records, Record{Key: candidate.Value, Source: source, Line: line})
+	}
+	return records
+}
@@ -0,0 +1,101 @@
+package input
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// minCandidateLength is the shortest token considered a possible key
+const minCandidateLength = 8
+
+// Candidate is a token that could be an API key and its position in the line
+type Candidate struct {
+	Value  string
+	Column int // 1-based byte offset of the token within its line
+}
+
+// compoundPatterns find credentials made of several tokens, which isDelimiter would split apart
+var compoundPatterns = []*regexp.Regexp{
+	// URLs carrying a secret in their path or query, and endpoint:key pairs (https://host/:key)
+	regexp.MustCompile(`https?://[^\s"'` + "`" + `,;()\[\]{}<>|\\]+`),
+	// Connection strings of ;-separated settings
+	regexp.MustCompile(`DefaultEndpointsProtocol=[^\s"'` + "`" + `,()\[\]{}<>|\\]+`),
+	// id:secret pairs and longer chains such as tenant:client:secret or email:key
+	regexp.MustCompile(`[a-zA-Z0-9._%+@-]+(?::[a-zA-Z0-9._~%+/=-]+)+`),
+}
+
+// isDelimiter reports whether r separates tokens in free-form text
+func isDelimiter(r rune) bool {
+	if unicode.IsSpace(r) {
+		return true
+	}
+	switch r {
+	case '"', '\'', '`', ',', ';', ':', '(', ')', '[', ']', '{', '}', '<', '>', '|', '&', '?', '=', '\\':
+		return true
+	}
+	return false
+}
+
+// ExtractCandidates splits a line of text into tokens that could be API keys
+func ExtractCandidates(line string) []Candidate {
+	var candidates []Candidate
+	start := -1
+
+	emit := func(end int) {
+		if start >= 0 && end-start >= minCandidateLength {
+			candidates = append(candidates, Candidate{Value: line[start:end], Column: start + 1})
+		}
+		start = -1
+	}
+
+	for i := 0; i < len(line); {
+		r, size := utf8.DecodeRuneInString(line[i:])
+		if isDelimiter(r) {
+			emit(i)
+		} else if start < 0 {
+			start = i
+		}
+		i += size
+	}
+	emit(len(line))
+
+	return append(candidates, extractCompounds(line)...)
+}
+
+// extractCompounds returns the URLs, connection strings and id:secret pairs of a line
+func extractCompounds(line string) []Candidate {
+	if !strings.Contains(line, ":") && !strings.Contains(line, "DefaultEndpointsProtocol=") {
+		return nil
+	}
+
+	var candidates []Candidate
+	var covered [][]int // spans already taken by an earlier pattern, so a URL is not split into pairs
+	for _, pattern := range compoundPatterns {
+		for _, span := range pattern.FindAllStringIndex(line, -1) {
+			start, end := span[0], span[1]
+			if overlaps(covered, start, end) {
+				continue
+			}
+			covered = append(covered, span)
+			// Trailing punctuation ends the sentence rather than the credential
+			value := strings.TrimRight(line[start:end], ".:")
+			if len(value) < minCandidateLength {
+				continue
+			}
+			candidates = append(candidates, Candidate{Value: value, Column: start + 1})
+		}
+	}
+	return candidates
+}
+
+// overlaps reports whether start:end overlaps one of spans
+func overlaps(spans [][]int, start, end int) bool {
+	for _, span := range spans {
+		if start < span[1] && span[0] < end {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package input
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/Xplo8E/APIKeyzer/internal/detector"
+)
+
+// TestScanCompoundCredentials scans a file holding credentials made of URLs and id:secret
+// pairs, which the token splitting of ExtractCandidates alone would cut apart
+func TestScanCompoundCredentials(t *testing.T) {
+	config, err := os.ReadFile("../../cmd/apiKeyzer/config/patterns.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDetector, err := detector.NewKeyDetector(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := make(map[string]Record)
+	err = NewFileScanner().Scan(context.Background(), "testdata/compound.txt", func(rec Record) {
+		if service := keyDetector.DetectService(rec.Key); service != "" {
+			found[service] = rec
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		service string
+		line    int
+		column  int
+	}{
+		{"Slack Webhook", 2, 19},
+		{"Azure Storage SAS URL", 3, 14},
+		{"Azure Storage Account Key", 4, 24},
+		{"Azure OpenAI API Key", 5, 14},
+		{"Azure AD Client Secret", 6, 27},
+		{"Firebase Cloud Messaging Server Key", 7, 18},
+		{"Cloudflare Global API Key", 8, 13},
+		{"PayPal Client Credentials", 9, 12},
+		{"Plaid API Credentials", 10, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.service, func(t *testing.T) {
+			rec, ok := found[tt.service]
+			if !ok {
+				t.Fatalf("no %s found in the scanned file", tt.service)
+			}
+			if rec.Line != tt.line || rec.Column != tt.column {
+				t.Errorf("found at %d:%d, want %d:%d", rec.Line, rec.Column, tt.line, tt.column)
+			}
+		})
+	}
+}
+
+func TestExtractCandidatesKeepsTokens(t *testing.T) {
+	candidates := ExtractCandidates(`token = "abcdefgh12345678" # see https://example.com/docs.`)
+
+	want := []Candidate{
+		{Value: "abcdefgh12345678", Column: 10},
+		{Value: "https://example.com/docs", Column: 34},
+	}
+	for _, c := range want {
+		if !containsCandidate(candidates, c) {
+			t.Errorf("candidates %+v do not include %+v", candidates, c)
+		}
+	}
+}
+
+func containsCandidate(candidates []Candidate, c Candidate) bool {
+	for _, candidate := range candidates {
+		if candidate == c {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,314 @@
+package input
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFileName is the per-directory file listing glob patterns to skip
+const IgnoreFileName = ".apikeyzerignore"
+
+// DefaultMaxFileSize is the largest file scanned by default
+const DefaultMaxFileSize = 10 << 20
+
+// sniffSize is how many leading bytes are inspected to classify a file as binary
+const sniffSize = 8000
+
+// FileScanner walks files and directories and reports candidate keys found in them
+type FileScanner struct {
+	include     []string
+	exclude     []string
+	maxLineSize int
+	maxFileSize int64
+	scanBinary  bool
+}
+
+// NewFileScanner creates a new FileScanner instance
+func NewFileScanner() *FileScanner {
+	return &FileScanner{
+		maxLineSize: DefaultMaxLineSize,
+		maxFileSize: DefaultMaxFileSize,
+	}
+}
+
+// SetInclude restricts scanning to files matching at least one of the glob patterns
+func (f *FileScanner) SetInclude(patterns []string) {
+	f.include = patterns
+}
+
+// SetExclude skips files and directories matching any of the glob patterns
+func (f *FileScanner) SetExclude(patterns []string) {
+	f.exclude = patterns
+}
+
+// SetMaxLineSize sets the longest line scanned within a file
+func (f *FileScanner) SetMaxLineSize(size int) {
+	f.maxLineSize = size
+}
+
+// SetMaxFileSize skips files larger than size bytes; 0 disables the limit
+func (f *FileScanner) SetMaxFileSize(size int64) {
+	f.maxFileSize = size
+}
+
+// SetScanBinary extracts printable strings from binary files instead of skipping them
+func (f *FileScanner) SetScanBinary(scan bool) {
+	f.scanBinary = scan
+}
+
+// Scan walks root (a file or directory) and calls fn for every candidate key.
+// Patterns from a .apikeyzerignore file at the root of a directory are added to the excludes.
+func (f *FileScanner) Scan(ctx context.Context, root string, fn func(Record)) error {
+	info, err := os.Stat(root)
+	if err != nil {
+		return fmt.Errorf("failed to access %s: %w", root, err)
+	}
+	if !info.IsDir() {
+		return f.scanFile(root, info.Size(), fn)
+	}
+
+	exclude, err := f.excludesFor(root)
+	if err != nil {
+		return err
+	}
+
+	return filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			slog.Info("skipping path", "path", p, "error", err)
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if !f.wanted(root, p, d.IsDir(), exclude) {
+			if d.IsDir() && p != root {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if err := f.scanFile(p, info.Size(), fn); err != nil {
+			slog.Info("skipping file", "path", p, "error", err)
+		}
+		return nil
+	})
+}
+
+// excludesFor combines the configured excludes with the .apikeyzerignore patterns of root
+func (f *FileScanner) excludesFor(root string) ([]string, error) {
+	ignored, err := LoadIgnoreFile(filepath.Join(root, IgnoreFileName))
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]string{}, f.exclude...), ignored...), nil
+}
+
+// wanted applies the include and exclude globs to p, a path below root.
+// Directories are only checked against the excludes.
+func (f *FileScanner) wanted(root, p string, isDir bool, exclude []string) bool {
+	rel, err := filepath.Rel(root, p)
+	if err != nil || rel == "." {
+		return isDir
+	}
+	rel = filepath.ToSlash(rel)
+
+	if matchAny(exclude, rel) {
+		slog.Debug("excluded", "path", p)
+		return false
+	}
+	if !isDir && len(f.include) > 0 && !matchAny(f.include, rel) {
+		return false
+	}
+	return true
+}
+
+// scanFile reads a single file line by line and reports candidate keys.
+// Binary files are skipped unless scanBinary is set, in which case their printable strings are scanned.
+func (f *FileScanner) scanFile(filename string, size int64, fn func(Record)) error {
+	if f.maxFileSize > 0 && size > f.maxFileSize {
+		return fmt.Errorf("file size %d exceeds limit of %d bytes", size, f.maxFileSize)
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReaderSize(file, sniffSize)
+	head, _ := reader.Peek(sniffSize)
+	if IsBinary(head) {
+		if !f.scanBinary {
+			return fmt.Errorf("binary file")
+		}
+		slog.Debug("extracting strings from binary file", "path", filename)
+		return extractStrings(reader, f.maxLineSize, func(s string) {
+			for _, candidate := range ExtractCandidates(s) {
+				fn(Record{Key: candidate.Value, Source: filename})
+			}
+		})
+	}
+
+	slog.Debug("scanning file", "path", filename)
+
+	lines := NewLineReader(reader, f.maxLineSize)
+	for lines.Next() {
+		if strings.Contains(lines.Text(), IgnoreComment) {
+			continue
+		}
+		for _, candidate := range ExtractCandidates(lines.Text()) {
+			fn(Record{Key: candidate.Value, Source: filename, Line: lines.LineNumber(), Column: candidate.Column})
+		}
+	}
+
+	if skipped := lines.Skipped(); skipped > 0 {
+		slog.Info("skipped overlong lines", "path", filename, "lines", skipped)
+	}
+
+	return lines.Err()
+}
+
+// IsBinary reports whether the leading bytes of a file look like binary content
+func IsBinary(head []byte) bool {
+	if len(head) == 0 {
+		return false
+	}
+	if bytes.IndexByte(head, 0) >= 0 {
+		return true
+	}
+	return !isTextContent("", head)
+}
+
+// extractStrings behaves like strings(1): it reports runs of printable ASCII
+// at least minCandidateLength bytes long, capping each run at maxLen bytes
+func extractStrings(r io.Reader, maxLen int, fn func(string)) error {
+	reader := bufio.NewReader(r)
+	var run []byte
+
+	flush := func() {
+		if len(run) >= minCandidateLength {
+			fn(string(run))
+		}
+		run = run[:0]
+	}
+
+	for {
+		b, err := reader.ReadByte()
+		if err != nil {
+			flush()
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		if b >= 0x20 && b < 0x7f || b == '\t' {
+			run = append(run, b)
+			if maxLen > 0 && len(run) >= maxLen {
+				flush()
+			}
+			continue
+		}
+		flush()
+	}
+}
+
+// LoadIgnoreFile reads glob patterns from an ignore file, one per line.
+// Blank lines and lines starting with '#' are ignored; a missing file yields no patterns.
+func LoadIgnoreFile(filename string) ([]string, error) {
+	file, err := os.Open(filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ignore file: %w", err)
+	}
+	defer file.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading ignore file: %w", err)
+	}
+
+	return patterns, nil
+}
+
+// matchAny reports whether the slash-separated relative path matches any pattern
+func matchAny(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		if matchGlob(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches a gitignore-style glob against a relative path. Patterns
+// without a slash match any single path element (e.g. "node_modules", "*.min.js");
+// patterns with a slash match the whole path and may use "**" for any number of directories.
+func matchGlob(pattern, rel string) bool {
+	pattern = strings.TrimSuffix(strings.TrimPrefix(pattern, "/"), "/")
+	if pattern == "" {
+		return false
+	}
+
+	if !strings.Contains(pattern, "/") {
+		for _, elem := range strings.Split(rel, "/") {
+			if ok, _ := path.Match(pattern, elem); ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(rel, "/"))
+}
+
+// matchSegments matches pattern segments against path segments, expanding "**".
+// A pattern that matches a leading directory also matches everything below it.
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return true
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], segments[1:])
+}
@@ -0,0 +1,115 @@
+package input
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+// DefaultMaxLineSize is the longest line read before it is skipped (64 MiB)
+const DefaultMaxLineSize = 64 << 20
+
+// LineReader reads newline-delimited lines on top of bufio.Reader. Unlike
+// bufio.Scanner it does not fail on long lines: lines up to maxLineSize are
+// returned whole and longer ones are drained and counted as skipped.
+type LineReader struct {
+	reader      *bufio.Reader
+	maxLineSize int
+	line        []byte
+	lineNum     int
+	skipped     int
+	err         error
+}
+
+// NewLineReader creates a LineReader; maxLineSize <= 0 selects DefaultMaxLineSize
+func NewLineReader(r io.Reader, maxLineSize int) *LineReader {
+	if maxLineSize <= 0 {
+		maxLineSize = DefaultMaxLineSize
+	}
+	return &LineReader{
+		reader:      bufio.NewReaderSize(r, 64*1024),
+		maxLineSize: maxLineSize,
+	}
+}
+
+// Next advances to the next line, returning false at EOF or on error
+func (l *LineReader) Next() bool {
+	if l.err != nil {
+		return false
+	}
+
+	for {
+		line, tooLong, err := l.readLine()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				l.err = err
+			}
+			if len(line) == 0 && !tooLong {
+				return false
+			}
+		}
+
+		l.lineNum++
+		if tooLong {
+			l.skipped++
+			if err != nil {
+				return false
+			}
+			continue
+		}
+
+		l.line = line
+		return true
+	}
+}
+
+// readLine reads one line without its terminator, discarding it if it is too long
+func (l *LineReader) readLine() ([]byte, bool, error) {
+	l.line = l.line[:0]
+	tooLong := false
+
+	for {
+		chunk, err := l.reader.ReadSlice('\n')
+		if !tooLong {
+			if len(l.line)+len(chunk) > l.maxLineSize+2 {
+				tooLong = true
+				l.line = l.line[:0]
+			} else {
+				l.line = append(l.line, chunk...)
+			}
+		}
+
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+
+		line := l.line
+		if n := len(line); n > 0 && line[n-1] == '\n' {
+			line = line[:n-1]
+			if n := len(line); n > 0 && line[n-1] == '\r' {
+				line = line[:n-1]
+			}
+		}
+		return line, tooLong, err
+	}
+}
+
+// Text returns the current line
+func (l *LineReader) Text() string {
+	return string(l.line)
+}
+
+// LineNumber returns the 1-based number of the current line
+func (l *LineReader) LineNumber() int {
+	return l.lineNum
+}
+
+// Skipped returns how many lines exceeded the maximum size and were dropped
+func (l *LineReader) Skipped() int {
+	return l.skipped
+}
+
+// Err returns the first non-EOF error encountered
+func (l *LineReader) Err() error {
+	return l.err
+}
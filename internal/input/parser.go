@@ -1,94 +1,157 @@
 package input
 
 import (
-	"bufio"
+	"encoding/json"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"strings"
 )
 
-// Parser handles different input methods for API keys
+// Parser handles different input methods for API keys. Keys are passed on as they are
+// read, so inputs of any size are processed in constant memory; duplicates are skipped
+// across all the inputs read by the same Parser.
 type Parser struct {
-	verbose bool
+	maxLineSize int
+	dedupe      *Deduper
+	skipped     int
 }
 
 // NewParser creates a new Parser instance
-func NewParser(verbose bool) *Parser {
+func NewParser() *Parser {
 	return &Parser{
-		verbose: verbose,
+		maxLineSize: DefaultMaxLineSize,
+		dedupe:      NewDeduper(DefaultDedupeWindow),
 	}
 }
 
-// FromStdin reads and deduplicates keys from standard input
-func (p *Parser) FromStdin() ([]string, error) {
-	if p.verbose {
-		fmt.Println("Reading keys from stdin...")
-	}
+// SetMaxLineSize sets the longest line that will be read; longer lines are skipped
+func (p *Parser) SetMaxLineSize(size int) {
+	p.maxLineSize = size
+}
 
-	seen := make(map[string]bool)
-	var keys []string
-	scanner := bufio.NewScanner(os.Stdin)
+// Skipped returns how many lines longer than the maximum line size were skipped so far
+func (p *Parser) Skipped() int {
+	return p.skipped
+}
 
-	for scanner.Scan() {
-		key := strings.TrimSpace(scanner.Text())
-		if key != "" && !seen[key] {
-			seen[key] = true
-			keys = append(keys, key)
-		}
-	}
+// SetDedupeWindow sets how many distinct keys are remembered to skip duplicates; 0 remembers all
+func (p *Parser) SetDedupeWindow(window int) {
+	p.dedupe = NewDeduper(window)
+}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading from stdin: %w", err)
-	}
+// ScanStdin passes the keys read from standard input to fn
+func (p *Parser) ScanStdin(fn func(Record)) error {
+	slog.Debug("reading keys from stdin")
 
-	if p.verbose {
-		fmt.Printf("Found %d unique keys from stdin\n", len(keys))
+	count, err := p.read(os.Stdin, "", fn)
+	if err != nil {
+		return fmt.Errorf("error reading from stdin: %w", err)
 	}
 
-	return keys, nil
+	slog.Info("read keys from stdin", "keys", count)
+	return nil
 }
 
-// FromFile reads and deduplicates keys from a file
-func (p *Parser) FromFile(filename string) ([]string, error) {
-	if p.verbose {
-		fmt.Printf("Reading keys from file: %s\n", filename)
-	}
+// ScanFile passes the keys read from a file to fn
+func (p *Parser) ScanFile(filename string, fn func(Record)) error {
+	slog.Debug("reading keys from file", "path", filename)
 
 	file, err := os.Open(filename)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %w", err)
+		return fmt.Errorf("failed to open file: %w", err)
 	}
 	defer file.Close()
 
-	seen := make(map[string]bool)
-	var keys []string
-	scanner := bufio.NewScanner(file)
+	count, err := p.read(file, filename, fn)
+	if err != nil {
+		return fmt.Errorf("error reading from file: %w", err)
+	}
+
+	slog.Info("read keys from file", "path", filename, "keys", count)
+	return nil
+}
 
-	for scanner.Scan() {
-		key := strings.TrimSpace(scanner.Text())
-		if key != "" && !seen[key] {
-			seen[key] = true
-			keys = append(keys, key)
+// ScanKeys passes the given keys to fn
+func (p *Parser) ScanKeys(keys []string, fn func(Record)) {
+	for _, key := range keys {
+		key = strings.TrimSpace(key)
+		if key != "" && !p.dedupe.Seen(key) {
+			fn(Record{Key: key})
 		}
 	}
+}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading from file: %w", err)
+// read passes the records of r to fn, one per line, and returns how many it passed.
+// Lines holding a JSON object are parsed as JSONL records and keep their fields as metadata;
+// repeated lines are skipped. source names the input for location tracking and may be empty.
+func (p *Parser) read(r io.Reader, source string, fn func(Record)) (int, error) {
+	count := 0
+	lines := NewLineReader(r, p.maxLineSize)
+
+	for lines.Next() {
+		raw := lines.Text()
+		line := strings.TrimSpace(raw)
+		if line == "" || p.dedupe.Seen(line) {
+			continue
+		}
+
+		if strings.HasPrefix(line, "{") {
+			record, err := parseJSONLine(line)
+			if err != nil {
+				slog.Debug("skipping JSON line", "error", err)
+				continue
+			}
+			fn(record)
+			count++
+			continue
+		}
+
+		record := Record{Key: line}
+		if source != "" {
+			record.Source = source
+			record.Line = lines.LineNumber()
+			record.Column = strings.Index(raw, line) + 1
+		}
+		fn(record)
+		count++
 	}
 
-	if p.verbose {
-		fmt.Printf("Found %d unique keys from file\n", len(keys))
+	if err := lines.Err(); err != nil {
+		return count, err
 	}
 
-	return keys, nil
+	if skipped := lines.Skipped(); skipped > 0 {
+		slog.Info("skipped overlong lines", "source", source, "lines", skipped)
+		p.skipped += skipped
+	}
+
+	return count, nil
 }
 
-// FromSingle creates a single-element slice from a key
-func (p *Parser) FromSingle(key string) []string {
-	if p.verbose {
-		fmt.Println("Processing single key")
+// parseJSONLine decodes a JSONL object with a "key" field into a Record
+func parseJSONLine(line string) (Record, error) {
+	var metadata map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &metadata); err != nil {
+		return Record{}, fmt.Errorf("invalid JSON: %w", err)
 	}
-	return []string{strings.TrimSpace(key)}
+
+	key, ok := metadata["key"].(string)
+	if !ok || strings.TrimSpace(key) == "" {
+		return Record{}, fmt.Errorf("missing \"key\" field")
+	}
+
+	source, _ := metadata["source"].(string)
+	lineNum, _ := metadata["line"].(float64)
+	column, _ := metadata["column"].(float64)
+	return Record{
+		Key:      strings.TrimSpace(key),
+		Source:   source,
+		Line:     int(lineNum),
+		Column:   int(column),
+		Metadata: metadata,
+	}, nil
 }
 
 // IsStdinPipe checks if input is being piped to stdin
@@ -96,3 +159,49 @@ func IsStdinPipe() bool {
 	stat, _ := os.Stdin.Stat()
 	return (stat.Mode() & os.ModeCharDevice) == 0
 }
+
+// ParseMessage reads the records of a queue message: a JSON object with a "key" field
+// (kept as metadata, as with JSONL input), a JSON object with a "content" field holding an
+// artifact such as a file or HTTP response, or plain text. Artifacts and plain text are
+// searched line by line for candidate keys. source names the queue for plain text messages.
+func ParseMessage(data []byte, source string) ([]Record, error) {
+	text := strings.TrimSpace(string(data))
+	if text == "" {
+		return nil, nil
+	}
+
+	if strings.HasPrefix(text, "{") {
+		var message map[string]interface{}
+		if err := json.Unmarshal([]byte(text), &message); err != nil {
+			return nil, fmt.Errorf("invalid JSON message: %w", err)
+		}
+		content, isArtifact := message["content"].(string)
+		if !isArtifact {
+			record, err := parseJSONLine(text)
+			if err != nil {
+				return nil, err
+			}
+			return []Record{record}, nil
+		}
+		delete(message, "content")
+		if len(message) == 0 {
+			message = nil
+		}
+		if artifactSource, ok := message["source"].(string); ok && artifactSource != "" {
+			source = artifactSource
+		}
+		return contentRecords(content, source, message), nil
+	}
+	return contentRecords(text, source, nil), nil
+}
+
+// contentRecords extracts the candidate keys of every line of content
+func contentRecords(content, source string, metadata map[string]interface{}) []Record {
+	var records []Record
+	for i, line := range strings.Split(content, "\n") {
+		for _, candidate := range ExtractCandidates(line) {
+			records = append(records, Record{Key: candidate.Value, Source: source, Line: i + 1, Column: candidate.Column, Metadata: metadata})
+		}
+	}
+	return records
+}
@@ -0,0 +1,12 @@
+package input
+
+// Record is a candidate API key together with where it was found.
+// Line and Column are 1-based and zero when unknown.
+// Metadata holds the original fields of JSONL input and is passed through unchanged.
+type Record struct {
+	Key      string
+	Source   string
+	Line     int
+	Column   int
+	Metadata map[string]interface{}
+}
@@ -0,0 +1,100 @@
+package input
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Supported third-party scanner report formats
+const (
+	ReportGitleaks   = "gitleaks"
+	ReportTruffleHog = "trufflehog"
+)
+
+// Report is a secret-scanner report whose findings are kept as raw objects
+// so they can be re-emitted with extra fields and nothing lost
+type Report struct {
+	Format   string
+	Findings []map[string]interface{}
+}
+
+// ParseReport decodes a Gitleaks JSON array or TruffleHog JSON-lines report.
+// format may be empty to detect the format from the content.
+func ParseReport(data []byte, format string) (*Report, error) {
+	trimmed := bytes.TrimSpace(data)
+	if format == "" {
+		if bytes.HasPrefix(trimmed, []byte("[")) {
+			format = ReportGitleaks
+		} else {
+			format = ReportTruffleHog
+		}
+	}
+
+	report := &Report{Format: format}
+
+	switch format {
+	case ReportGitleaks:
+		if len(trimmed) == 0 {
+			return report, nil
+		}
+		if err := json.Unmarshal(trimmed, &report.Findings); err != nil {
+			return nil, fmt.Errorf("failed to parse gitleaks report: %w", err)
+		}
+
+	case ReportTruffleHog:
+		lines := NewLineReader(bytes.NewReader(trimmed), 0)
+		for lines.Next() {
+			line := strings.TrimSpace(lines.Text())
+			if line == "" {
+				continue
+			}
+			var finding map[string]interface{}
+			if err := json.Unmarshal([]byte(line), &finding); err != nil {
+				return nil, fmt.Errorf("failed to parse trufflehog report line %d: %w", lines.LineNumber(), err)
+			}
+			report.Findings = append(report.Findings, finding)
+		}
+		if err := lines.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read trufflehog report: %w", err)
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported report format: %s", format)
+	}
+
+	return report, nil
+}
+
+// Secret returns the secret value reported by a finding
+func (r *Report) Secret(finding map[string]interface{}) string {
+	field := "Secret"
+	if r.Format == ReportTruffleHog {
+		field = "Raw"
+	}
+	secret, _ := finding[field].(string)
+	return strings.TrimSpace(secret)
+}
+
+// Encode serializes the report back into its original format
+func (r *Report) Encode() ([]byte, error) {
+	if r.Format == ReportGitleaks {
+		findings := r.Findings
+		if findings == nil {
+			findings = []map[string]interface{}{}
+		}
+		return json.MarshalIndent(findings, "", " ")
+	}
+
+	var buf bytes.Buffer
+	for _, finding := range r.Findings {
+		line, err := json.Marshal(finding)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,118 @@
+package input
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ScanStaged reports candidate keys on the lines added by the changes staged in the git
+// repository of the current directory, as a pre-commit hook sees them. Sources are paths
+// relative to the current directory. Lines holding IgnoreComment are skipped.
+func ScanStaged(ctx context.Context, fn func(Record)) error {
+	top, err := git(ctx, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return err
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	diff, err := git(ctx, "diff", "--cached", "--unified=0", "--no-color", "--no-ext-diff",
+		"--diff-filter=ACMR", "--src-prefix=a/", "--dst-prefix=b/")
+	if err != nil {
+		return err
+	}
+
+	return parseAddedLines(bytes.NewReader(diff), func(name string, number int, text string) {
+		if strings.Contains(text, IgnoreComment) {
+			return
+		}
+		source := filepath.Join(strings.TrimSpace(string(top)), filepath.FromSlash(name))
+		if rel, err := filepath.Rel(cwd, source); err == nil {
+			source = rel
+		}
+		for _, candidate := range ExtractCandidates(text) {
+			fn(Record{Key: candidate.Value, Source: source, Line: number, Column: candidate.Column})
+		}
+	})
+}
+
+// git runs a git command and returns its standard output
+func git(ctx context.Context, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("git %s failed: %s", args[0], message)
+		}
+		return nil, fmt.Errorf("git %s failed: %w", args[0], err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// parseAddedLines calls fn with every line added by a multi-file unified diff,
+// numbered as in the new version of its file
+func parseAddedLines(r io.Reader, fn func(name string, number int, text string)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), DefaultMaxLineSize)
+	name := ""
+	number := 0
+	inHunk := false
+	for scanner.Scan() {
+		text := scanner.Text()
+		switch {
+		case strings.HasPrefix(text, "diff --git "):
+			name, inHunk = "", false
+		case !inHunk && strings.HasPrefix(text, "+++ "):
+			name = strings.TrimPrefix(strings.TrimPrefix(text, "+++ "), "b/")
+			if unquoted, err := strconv.Unquote(name); err == nil {
+				name = strings.TrimPrefix(unquoted, "b/")
+			}
+		case strings.HasPrefix(text, "@@"):
+			number, inHunk = diffHunkStart(text), true
+		case !inHunk:
+			// file header lines such as "index ..." and "--- a/..."
+		case strings.HasPrefix(text, "+"):
+			if name != "" && name != "/dev/null" {
+				fn(name, number, strings.TrimSuffix(text[1:], "\r"))
+			}
+			number++
+		case strings.HasPrefix(text, "-"), strings.HasPrefix(text, `\`):
+			// removed lines and "\ No newline at end of file" do not exist in the new file
+		default:
+			number++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read staged changes: %w", err)
+	}
+	return nil
+}
+
+// diffHunkStart returns the first new-file line of a "@@ -a,b +c,d @@" hunk header
+func diffHunkStart(header string) int {
+	_, rest, ok := strings.Cut(header, " +")
+	if !ok {
+		return 0
+	}
+	end := strings.IndexAny(rest, ", ")
+	if end < 0 {
+		return 0
+	}
+	start, err := strconv.Atoi(rest[:end])
+	if err != nil {
+		return 0
+	}
+	return start
+}
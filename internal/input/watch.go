@@ -0,0 +1,162 @@
+package input
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is how long a file must be quiet before it is rescanned
+const watchDebounce = 500 * time.Millisecond
+
+// watchRoot is a scanned root together with its effective exclude patterns
+type watchRoot struct {
+	path    string
+	exclude []string
+}
+
+// Watch rescans files below roots whenever they are created or modified, until ctx is
+// cancelled. It does not perform an initial scan; call Scan first for existing content.
+func (f *FileScanner) Watch(ctx context.Context, roots []string, fn func(Record)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	var watched []watchRoot
+	for _, root := range roots {
+		root = filepath.Clean(root)
+		info, err := os.Stat(root)
+		if err != nil {
+			return fmt.Errorf("failed to access %s: %w", root, err)
+		}
+
+		if !info.IsDir() {
+			// Watch the parent directory so editors that replace the file are still seen
+			if err := watcher.Add(filepath.Dir(root)); err != nil {
+				return fmt.Errorf("failed to watch %s: %w", root, err)
+			}
+			watched = append(watched, watchRoot{path: root})
+			continue
+		}
+
+		exclude, err := f.excludesFor(root)
+		if err != nil {
+			return err
+		}
+		wr := watchRoot{path: root, exclude: exclude}
+		if err := f.watchTree(watcher, wr, root); err != nil {
+			return err
+		}
+		watched = append(watched, wr)
+	}
+
+	pending := make(map[string]time.Time)
+	ticker := time.NewTicker(watchDebounce / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			slog.Warn("watch error", "error", err)
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if !event.Has(fsnotify.Create) && !event.Has(fsnotify.Write) {
+				continue
+			}
+
+			root, matched := f.rootFor(watched, event.Name)
+			if !matched {
+				continue
+			}
+
+			info, err := os.Stat(event.Name)
+			if err != nil {
+				continue
+			}
+			if info.IsDir() {
+				if root.path != event.Name && f.wanted(root.path, event.Name, true, root.exclude) {
+					f.watchTree(watcher, root, event.Name)
+					// Files may have landed in the new directory before it was watched
+					filepath.WalkDir(event.Name, func(p string, d fs.DirEntry, err error) error {
+						if err == nil && d.Type().IsRegular() && f.wanted(root.path, p, false, root.exclude) {
+							pending[p] = time.Now()
+						}
+						return nil
+					})
+				}
+				continue
+			}
+			pending[event.Name] = time.Now()
+
+		case now := <-ticker.C:
+			for name, changed := range pending {
+				if now.Sub(changed) < watchDebounce {
+					continue
+				}
+				delete(pending, name)
+
+				info, err := os.Stat(name)
+				if err != nil || !info.Mode().IsRegular() {
+					continue
+				}
+				slog.Info("change detected", "path", name)
+				if err := f.scanFile(name, info.Size(), fn); err != nil {
+					slog.Info("skipping file", "path", name, "error", err)
+				}
+			}
+		}
+	}
+}
+
+// watchTree adds dir and every non-excluded directory below it to the watcher
+func (f *FileScanner) watchTree(watcher *fsnotify.Watcher, root watchRoot, dir string) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if !f.wanted(root.path, p, true, root.exclude) {
+			return filepath.SkipDir
+		}
+		if err := watcher.Add(p); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", p, err)
+		}
+		return nil
+	})
+}
+
+// rootFor finds the watched root an event path belongs to and checks it against the filters
+func (f *FileScanner) rootFor(roots []watchRoot, name string) (watchRoot, bool) {
+	for _, root := range roots {
+		if root.path == name {
+			return root, true
+		}
+		rel, err := filepath.Rel(root.path, name)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		info, err := os.Stat(name)
+		if err != nil {
+			return root, false
+		}
+		return root, f.wanted(root.path, name, info.IsDir(), root.exclude)
+	}
+	return watchRoot{}, false
+}
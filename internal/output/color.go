@@ -0,0 +1,52 @@
+package output
+
+import (
+	"fmt"
+	"os"
+)
+
+// Colors of the active theme, see SetTheme
+var (
+	Red    = Color("\033[1;31m%s\033[0m")
+	Green  = Color("\033[1;32m%s\033[0m")
+	Yellow = Color("\033[1;33m%s\033[0m")
+	Blue   = Color("\033[1;34m%s\033[0m")
+	Cyan   = Color("\033[1;36m%s\033[0m")
+)
+
+// colorEnabled controls whether Color functions emit ANSI escape codes
+var colorEnabled = true
+
+// SetColor enables or disables ANSI colors for all Color functions
+func SetColor(enabled bool) {
+	colorEnabled = enabled
+}
+
+// IsTerminal reports whether f is connected to a terminal
+func IsTerminal(f *os.File) bool {
+	stat, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return (stat.Mode() & os.ModeCharDevice) != 0
+}
+
+// ColorSupported reports whether ANSI colors should be written to f. It honors the
+// NO_COLOR convention (https://no-color.org) and TERM=dumb, and requires a terminal.
+func ColorSupported(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return IsTerminal(f)
+}
+
+func Color(colorString string) func(...interface{}) string {
+	sprint := func(args ...interface{}) string {
+		if !colorEnabled {
+			return fmt.Sprint(args...)
+		}
+		return fmt.Sprintf(colorString,
+			fmt.Sprint(args...))
+	}
+	return sprint
+}
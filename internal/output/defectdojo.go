@@ -0,0 +1,136 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// defectDojoReport is DefectDojo's "Generic Findings Import" JSON document
+type defectDojoReport struct {
+	Findings []defectDojoFinding `json:"findings"`
+}
+
+type defectDojoFinding struct {
+	Title            string   `json:"title"`
+	Description      string   `json:"description"`
+	Severity         string   `json:"severity"`
+	Date             string   `json:"date"`
+	CWE              int      `json:"cwe,omitempty"`
+	Mitigation       string   `json:"mitigation"`
+	Impact           string   `json:"impact,omitempty"`
+	References       string   `json:"references,omitempty"`
+	FilePath         string   `json:"file_path,omitempty"`
+	Line             int      `json:"line,omitempty"`
+	ComponentName    string   `json:"component_name"`
+	UniqueIDFromTool string   `json:"unique_id_from_tool,omitempty"`
+	VulnIDFromTool   string   `json:"vuln_id_from_tool"`
+	Tags             []string `json:"tags,omitempty"`
+	Active           bool     `json:"active"`
+	Verified         bool     `json:"verified"`
+	StaticFinding    bool     `json:"static_finding"`
+	DynamicFinding   bool     `json:"dynamic_finding"`
+}
+
+// defectDojoWriter collects valid keys and renders them as DefectDojo generic findings on Close
+type defectDojoWriter struct {
+	w        io.Writer
+	findings []defectDojoFinding
+}
+
+func newDefectDojoWriter(w io.Writer) *defectDojoWriter {
+	return &defectDojoWriter{w: w}
+}
+
+func (d *defectDojoWriter) WriteResult(result *validator.ValidationResult) error {
+	// Only confirmed exposures are imported as findings
+	if !result.Valid {
+		return nil
+	}
+
+	var description strings.Builder
+	fmt.Fprintf(&description, "A valid %s was found and confirmed against the provider's API.\n\n", result.Service)
+	fmt.Fprintf(&description, "**Key:** `%s`\n\n", result.Key)
+	if result.Location != nil {
+		fmt.Fprintf(&description, "**Location:** `%s`\n\n", result.Location)
+	}
+	if len(result.Permissions) > 0 {
+		fmt.Fprintf(&description, "**Vulnerable APIs:**\n\n")
+		for _, permission := range result.Permissions {
+			fmt.Fprintf(&description, "- %s\n", permission)
+		}
+		description.WriteString("\n")
+	}
+	if len(result.PoC) > 0 {
+		fmt.Fprintf(&description, "**Proof of concept:**\n\n```sh\n")
+		for _, endpoint := range pocEndpoints(result) {
+			fmt.Fprintf(&description, "%s\n", result.PoC[endpoint])
+		}
+		fmt.Fprintf(&description, "```\n")
+	}
+
+	finding := defectDojoFinding{
+		Title:            fmt.Sprintf("Exposed %s", result.Service),
+		Description:      strings.TrimSpace(description.String()),
+		Severity:         defectDojoSeverity(result.RiskLevel),
+		Date:             result.ValidatedAt.UTC().Format("2006-01-02"),
+		CWE:              defectDojoCWE(result.CWE),
+		Mitigation:       Remediation(result.Service),
+		References:       strings.Join(result.References, "\n"),
+		ComponentName:    result.Service,
+		UniqueIDFromTool: result.FindingID,
+		VulnIDFromTool:   ruleIDFor(result.Service),
+		Tags:             []string{"secret", "apikeyzer", string(result.RiskLevel)},
+		Active:           true,
+		Verified:         true,
+		StaticFinding:    result.Location != nil,
+		DynamicFinding:   true,
+	}
+	if len(result.Permissions) > 0 {
+		finding.Impact = fmt.Sprintf("The key grants access to %d API(s) on the owner's account.", len(result.Permissions))
+	}
+	if result.Location != nil {
+		finding.FilePath = result.Location.Path
+		finding.Line = result.Location.Line
+	}
+
+	d.findings = append(d.findings, finding)
+	return nil
+}
+
+func (d *defectDojoWriter) Close() error {
+	report := defectDojoReport{Findings: d.findings}
+	if report.Findings == nil {
+		report.Findings = []defectDojoFinding{}
+	}
+	encoder := json.NewEncoder(d.w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// defectDojoSeverity maps a risk level to a DefectDojo severity
+func defectDojoSeverity(risk validator.RiskLevel) string {
+	switch risk {
+	case validator.RiskLevelHigh:
+		return "High"
+	case validator.RiskLevelMedium:
+		return "Medium"
+	default:
+		return "Low"
+	}
+}
+
+// defectDojoCWE returns the number of the first CWE identifier, since DefectDojo takes a single integer
+func defectDojoCWE(cwes []string) int {
+	for _, cwe := range cwes {
+		if n, err := strconv.Atoi(strings.TrimPrefix(strings.ToUpper(cwe), "CWE-")); err == nil {
+			return n
+		}
+	}
+	return 0
+}
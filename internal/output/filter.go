@@ -0,0 +1,52 @@
+package output
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// filterWriter only passes results accepted by keep to the wrapped Writer
+type filterWriter struct {
+	Writer
+	keep func(result *validator.ValidationResult) bool
+}
+
+func (f *filterWriter) WriteResult(result *validator.ValidationResult) error {
+	if !f.keep(result) {
+		return nil
+	}
+	return f.Writer.WriteResult(result)
+}
+
+// resultFilter builds the filter for opts, or nil when every result is kept
+func resultFilter(opts Options) func(result *validator.ValidationResult) bool {
+	if !opts.OnlyValid && opts.MinRisk == "" {
+		return nil
+	}
+	return func(result *validator.ValidationResult) bool {
+		if opts.OnlyValid && !result.Valid {
+			return false
+		}
+		// Invalid keys carry no exposure, so the risk threshold only applies to valid ones
+		if opts.MinRisk != "" && result.Valid && result.RiskLevel.Rank() < opts.MinRisk.Rank() {
+			return false
+		}
+		return true
+	}
+}
+
+// keyWriter prints bare keys, one per line, for piping into other tools
+type keyWriter struct {
+	w io.Writer
+}
+
+func (k *keyWriter) WriteResult(result *validator.ValidationResult) error {
+	_, err := fmt.Fprintln(k.w, result.Key)
+	return err
+}
+
+func (k *keyWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,108 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// githubWriter emits GitHub Actions workflow commands, so findings show up as annotations
+// on the pull request, and appends a Markdown report to the job summary on Close.
+// Keys are always masked since annotations are visible to anyone who can read the repository
+type githubWriter struct {
+	w       io.Writer
+	summary Writer
+	file    *os.File
+}
+
+func newGitHubWriter(w io.Writer, opts Options) (*githubWriter, error) {
+	g := &githubWriter{w: w}
+	// GITHUB_STEP_SUMMARY is only set inside a workflow run
+	if path := os.Getenv("GITHUB_STEP_SUMMARY"); path != "" {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open job summary: %w", err)
+		}
+		g.file = file
+		g.summary = &maskWriter{Writer: newMarkdownWriter(file, opts)}
+	}
+	return g, nil
+}
+
+func (g *githubWriter) WriteResult(result *validator.ValidationResult) error {
+	if g.summary != nil {
+		if err := g.summary.WriteResult(result); err != nil {
+			return err
+		}
+	}
+
+	var level, title, message string
+	switch {
+	case result.Valid:
+		level = "error"
+		if result.RiskLevel == validator.RiskLevelLow {
+			level = "warning"
+		}
+		title = fmt.Sprintf("Valid %s", result.Service)
+		message = fmt.Sprintf("%s is a valid %s (%s risk)", MaskKey(result.Key), result.Service, result.RiskLevel)
+		if len(result.Permissions) > 0 {
+			message += "\nAccepted by: " + strings.Join(result.Permissions, ", ")
+		}
+		message += "\nRevoke or restrict the key and remove it from the repository."
+	case result.ErrorCode != "" && result.Location != nil:
+		// Keys found in files that could not be checked still deserve a look
+		level = "warning"
+		title = fmt.Sprintf("Could not validate key (%s)", result.ErrorCode)
+		message = fmt.Sprintf("%s could not be validated: %s", MaskKey(result.Key), strings.ReplaceAll(result.ErrorStr, result.Key, MaskKey(result.Key)))
+	default:
+		return nil
+	}
+
+	properties := []string{"title=" + escapeProperty(title)}
+	if result.Location != nil {
+		properties = append([]string{"file=" + escapeProperty(annotationPath(result.Location.Path))}, properties...)
+		if result.Location.Line > 0 {
+			properties = append(properties, fmt.Sprintf("line=%d", result.Location.Line))
+		}
+		if result.Location.Column > 0 {
+			properties = append(properties, fmt.Sprintf("col=%d", result.Location.Column))
+		}
+	}
+	_, err := fmt.Fprintf(g.w, "::%s %s::%s\n", level, strings.Join(properties, ","), escapeData(message))
+	return err
+}
+
+func (g *githubWriter) Close() error {
+	if g.summary == nil {
+		return nil
+	}
+	err := g.summary.Close()
+	if closeErr := g.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// annotationPath returns path relative to the checked out repository, as annotations require
+func annotationPath(path string) string {
+	if workspace := os.Getenv("GITHUB_WORKSPACE"); workspace != "" && filepath.IsAbs(path) {
+		if rel, err := filepath.Rel(workspace, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(path))
+}
+
+// escapeData escapes the message of a workflow command
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a property value of a workflow command
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
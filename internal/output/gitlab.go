@@ -0,0 +1,284 @@
+package output
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// gitLabSchemaVersion is the version of GitLab's security report schema the secret detection report follows
+const gitLabSchemaVersion = "15.0.7"
+
+// gitLabTimeFormat is the timestamp layout required by the security report schema
+const gitLabTimeFormat = "2006-01-02T15:04:05"
+
+// codeQualityIssue is an entry of GitLab's Code Quality report, a subset of the Code Climate format
+type codeQualityIssue struct {
+	Description string              `json:"description"`
+	CheckName   string              `json:"check_name"`
+	Fingerprint string              `json:"fingerprint"`
+	Severity    string              `json:"severity"`
+	Location    codeQualityLocation `json:"location"`
+}
+
+type codeQualityLocation struct {
+	Path  string           `json:"path"`
+	Lines codeQualityLines `json:"lines"`
+}
+
+type codeQualityLines struct {
+	Begin int `json:"begin"`
+}
+
+// codeQualityWriter collects valid keys found in files and renders them as a GitLab Code Quality
+// report on Close, which shows them in the merge request widget on every GitLab tier
+type codeQualityWriter struct {
+	w      io.Writer
+	issues []codeQualityIssue
+}
+
+func newCodeQualityWriter(w io.Writer) *codeQualityWriter {
+	return &codeQualityWriter{w: w}
+}
+
+func (c *codeQualityWriter) WriteResult(result *validator.ValidationResult) error {
+	// Code Quality issues must point at a file, so keys read from stdin or lists are left out
+	if !result.Valid || result.Location == nil || result.Location.Path == "" {
+		return nil
+	}
+
+	line := result.Location.Line
+	if line < 1 {
+		line = 1
+	}
+	description := fmt.Sprintf("Valid %s %s (%s risk)", result.Service, MaskKey(result.Key), result.RiskLevel)
+	if len(result.Permissions) > 0 {
+		description += ", accepted by " + strings.Join(result.Permissions, ", ")
+	}
+
+	c.issues = append(c.issues, codeQualityIssue{
+		Description: description + ". " + Remediation(result.Service),
+		CheckName:   ruleIDFor(result.Service),
+		Fingerprint: gitLabFingerprint(result),
+		Severity:    codeQualitySeverity(result.RiskLevel),
+		Location: codeQualityLocation{
+			Path:  artifactURI(result.Location.Path),
+			Lines: codeQualityLines{Begin: line},
+		},
+	})
+	return nil
+}
+
+func (c *codeQualityWriter) Close() error {
+	issues := c.issues
+	if issues == nil {
+		issues = []codeQualityIssue{}
+	}
+	encoder := json.NewEncoder(c.w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(issues)
+}
+
+// codeQualitySeverity maps a risk level to a Code Quality severity
+func codeQualitySeverity(risk validator.RiskLevel) string {
+	switch risk {
+	case validator.RiskLevelHigh:
+		return "critical"
+	case validator.RiskLevelMedium:
+		return "major"
+	default:
+		return "minor"
+	}
+}
+
+// secretDetectionReport is GitLab's secret detection security report (gl-secret-detection-report.json)
+type secretDetectionReport struct {
+	Version         string                   `json:"version"`
+	Vulnerabilities []secretDetectionFinding `json:"vulnerabilities"`
+	Scan            secretDetectionScan      `json:"scan"`
+}
+
+type secretDetectionFinding struct {
+	ID          string                      `json:"id"`
+	Name        string                      `json:"name"`
+	Description string                      `json:"description"`
+	Severity    string                      `json:"severity"`
+	Solution    string                      `json:"solution"`
+	Location    secretDetectionLocation     `json:"location"`
+	Identifiers []secretDetectionIdentifier `json:"identifiers"`
+	Links       []secretDetectionLink       `json:"links,omitempty"`
+}
+
+type secretDetectionLocation struct {
+	File      string                `json:"file"`
+	StartLine int                   `json:"start_line,omitempty"`
+	Commit    secretDetectionCommit `json:"commit"`
+}
+
+type secretDetectionCommit struct {
+	SHA string `json:"sha"`
+}
+
+type secretDetectionIdentifier struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	URL   string `json:"url,omitempty"`
+}
+
+type secretDetectionLink struct {
+	URL string `json:"url"`
+}
+
+type secretDetectionScan struct {
+	Analyzer  secretDetectionTool `json:"analyzer"`
+	Scanner   secretDetectionTool `json:"scanner"`
+	Type      string              `json:"type"`
+	StartTime string              `json:"start_time"`
+	EndTime   string              `json:"end_time"`
+	Status    string              `json:"status"`
+}
+
+type secretDetectionTool struct {
+	ID      string                `json:"id"`
+	Name    string                `json:"name"`
+	Version string                `json:"version"`
+	URL     string                `json:"url,omitempty"`
+	Vendor  secretDetectionVendor `json:"vendor"`
+}
+
+type secretDetectionVendor struct {
+	Name string `json:"name"`
+}
+
+// secretDetectionWriter collects valid keys and renders them as a GitLab secret detection report on Close,
+// so they appear in the merge request security widget and the vulnerability report
+type secretDetectionWriter struct {
+	w        io.Writer
+	opts     Options
+	started  time.Time
+	findings []secretDetectionFinding
+}
+
+func newSecretDetectionWriter(w io.Writer, opts Options) *secretDetectionWriter {
+	return &secretDetectionWriter{w: w, opts: opts, started: time.Now()}
+}
+
+func (s *secretDetectionWriter) WriteResult(result *validator.ValidationResult) error {
+	if !result.Valid {
+		return nil
+	}
+
+	description := fmt.Sprintf("A valid %s (%s) was found and confirmed against the provider's API.", result.Service, MaskKey(result.Key))
+	if len(result.Permissions) > 0 {
+		description += " It is accepted by: " + strings.Join(result.Permissions, ", ") + "."
+	}
+
+	finding := secretDetectionFinding{
+		ID:          gitLabUUID(gitLabFingerprint(result)),
+		Name:        fmt.Sprintf("Exposed %s", result.Service),
+		Description: description,
+		Severity:    secretDetectionSeverity(result.RiskLevel),
+		Solution:    Remediation(result.Service),
+		Location:    secretDetectionLocation{Commit: secretDetectionCommit{SHA: gitLabCommitSHA()}},
+		Identifiers: []secretDetectionIdentifier{{
+			Type:  "apikeyzer_rule_id",
+			Name:  result.Service,
+			Value: ruleIDFor(result.Service),
+		}},
+	}
+	if result.Location != nil {
+		finding.Location.File = artifactURI(result.Location.Path)
+		finding.Location.StartLine = result.Location.Line
+	}
+	for _, cwe := range result.CWE {
+		number := strings.TrimPrefix(strings.ToUpper(cwe), "CWE-")
+		finding.Identifiers = append(finding.Identifiers, secretDetectionIdentifier{
+			Type:  "cwe",
+			Name:  strings.ToUpper(cwe),
+			Value: number,
+			URL:   fmt.Sprintf("https://cwe.mitre.org/data/definitions/%s.html", number),
+		})
+	}
+	for _, reference := range result.References {
+		finding.Links = append(finding.Links, secretDetectionLink{URL: reference})
+	}
+
+	s.findings = append(s.findings, finding)
+	return nil
+}
+
+func (s *secretDetectionWriter) Close() error {
+	tool := secretDetectionTool{
+		ID:      "apikeyzer",
+		Name:    "APIKeyzer",
+		Version: s.opts.ToolVersion,
+		URL:     toolURI,
+		Vendor:  secretDetectionVendor{Name: "APIKeyzer"},
+	}
+	if tool.Version == "" {
+		tool.Version = "dev"
+	}
+	report := secretDetectionReport{
+		Version:         gitLabSchemaVersion,
+		Vulnerabilities: s.findings,
+		Scan: secretDetectionScan{
+			Analyzer:  tool,
+			Scanner:   tool,
+			Type:      "secret_detection",
+			StartTime: s.started.UTC().Format(gitLabTimeFormat),
+			EndTime:   time.Now().UTC().Format(gitLabTimeFormat),
+			Status:    "success",
+		},
+	}
+	if report.Vulnerabilities == nil {
+		report.Vulnerabilities = []secretDetectionFinding{}
+	}
+	encoder := json.NewEncoder(s.w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// secretDetectionSeverity maps a risk level to a security report severity
+func secretDetectionSeverity(risk validator.RiskLevel) string {
+	switch risk {
+	case validator.RiskLevelHigh:
+		return "Critical"
+	case validator.RiskLevelMedium:
+		return "High"
+	default:
+		return "Medium"
+	}
+}
+
+// gitLabFingerprint identifies a finding at its location, so GitLab can track it across pipelines
+func gitLabFingerprint(result *validator.ValidationResult) string {
+	id := result.FindingID
+	if result.Location != nil {
+		id += "\x00" + artifactURI(result.Location.Path) + fmt.Sprintf("\x00%d", result.Location.Line)
+	}
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:])
+}
+
+// gitLabUUID formats the first 16 bytes of a hex fingerprint as a UUID, the form of security report IDs
+func gitLabUUID(fingerprint string) string {
+	return fingerprint[0:8] + "-" + fingerprint[8:12] + "-" + fingerprint[12:16] + "-" + fingerprint[16:20] + "-" + fingerprint[20:32]
+}
+
+// gitLabCommitSHA returns the commit of the pipeline; the schema requires one even outside CI
+func gitLabCommitSHA() string {
+	if sha := os.Getenv("CI_COMMIT_SHA"); sha != "" {
+		return sha
+	}
+	return "0000000"
+}
@@ -0,0 +1,27 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// jsonWriter emits one JSON-encoded ValidationResult per line
+type jsonWriter struct {
+	encoder *json.Encoder
+}
+
+func newJSONWriter(w io.Writer) *jsonWriter {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return &jsonWriter{encoder: encoder}
+}
+
+func (j *jsonWriter) WriteResult(result *validator.ValidationResult) error {
+	return j.encoder.Encode(result)
+}
+
+func (j *jsonWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,148 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Line      int           `xml:"line,attr,omitempty"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Error     *junitFailure `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// junitWriter renders each validated key as a test case, grouped into one suite per
+// service; valid keys are failures so CI systems show exposures as failing tests
+type junitWriter struct {
+	w      io.Writer
+	suites []*junitTestSuite
+	index  map[string]*junitTestSuite
+}
+
+func newJUnitWriter(w io.Writer) *junitWriter {
+	return &junitWriter{
+		w:     w,
+		index: make(map[string]*junitTestSuite),
+	}
+}
+
+func (j *junitWriter) WriteResult(result *validator.ValidationResult) error {
+	suite, exists := j.index[result.Service]
+	if !exists {
+		suite = &junitTestSuite{Name: result.Service}
+		j.index[result.Service] = suite
+		j.suites = append(j.suites, suite)
+	}
+
+	testCase := junitTestCase{
+		Name:      result.Key,
+		ClassName: strings.TrimPrefix(ruleIDFor(result.Service), "apikeyzer/"),
+	}
+	if result.Location != nil {
+		testCase.Name = fmt.Sprintf("%s (%s)", result.Key, result.Location)
+		testCase.File = result.Location.Path
+		testCase.Line = result.Location.Line
+	}
+
+	suite.Tests++
+	if result.Valid {
+		suite.Failures++
+		var body strings.Builder
+		if result.FindingID != "" {
+			fmt.Fprintf(&body, "Finding ID: %s\n", result.FindingID)
+		}
+		fmt.Fprintf(&body, "Service: %s\nRisk level: %s\n", result.Service, result.RiskLevel)
+		if classification := classificationText(result); classification != "" {
+			fmt.Fprintf(&body, "Classification: %s\n", classification)
+		}
+		if result.Location != nil {
+			fmt.Fprintf(&body, "Location: %s\n", result.Location)
+		}
+		if len(result.Permissions) > 0 {
+			fmt.Fprintf(&body, "Vulnerable APIs:\n")
+			for _, permission := range result.Permissions {
+				fmt.Fprintf(&body, "  - %s\n", permission)
+			}
+		}
+		if len(result.PoC) > 0 {
+			fmt.Fprintf(&body, "Proof of concept:\n")
+			for _, endpoint := range pocEndpoints(result) {
+				fmt.Fprintf(&body, "  %s\n", result.PoC[endpoint])
+			}
+		}
+		fmt.Fprintf(&body, "Remediation: %s\n", Remediation(result.Service))
+		for _, reference := range result.References {
+			fmt.Fprintf(&body, "Reference: %s\n", reference)
+		}
+
+		testCase.Failure = &junitFailure{
+			Message: fmt.Sprintf("Valid %s exposed (risk: %s)", result.Service, result.RiskLevel),
+			Type:    string(result.RiskLevel),
+			Body:    body.String(),
+		}
+	} else if result.ErrorCode != "" {
+		// Keys that could not be validated are errors rather than passes
+		suite.Errors++
+		testCase.Error = &junitFailure{
+			Message: result.ErrorStr,
+			Type:    string(result.ErrorCode),
+		}
+	} else {
+		testCase.SystemOut = result.ErrorStr
+	}
+
+	suite.Cases = append(suite.Cases, testCase)
+	return nil
+}
+
+func (j *junitWriter) Close() error {
+	report := junitTestSuites{Name: "APIKeyzer"}
+	for _, suite := range j.suites {
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Errors += suite.Errors
+		report.Suites = append(report.Suites, *suite)
+	}
+
+	if _, err := io.WriteString(j.w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(j.w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(j.w, "\n")
+	return err
+}
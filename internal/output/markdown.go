@@ -0,0 +1,156 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// markdownWriter collects results and renders a Markdown report on Close
+type markdownWriter struct {
+	w       io.Writer
+	opts    Options
+	results []*validator.ValidationResult
+}
+
+func newMarkdownWriter(w io.Writer, opts Options) *markdownWriter {
+	return &markdownWriter{w: w, opts: opts}
+}
+
+func (m *markdownWriter) WriteResult(result *validator.ValidationResult) error {
+	m.results = append(m.results, result)
+	return nil
+}
+
+func (m *markdownWriter) Close() error {
+	var b strings.Builder
+	var findings []*validator.ValidationResult
+	risks := make(map[validator.RiskLevel]int)
+	errorCodes := make(map[validator.ErrorCode]int)
+	for _, result := range m.results {
+		switch {
+		case result.Valid:
+			findings = append(findings, result)
+			risks[result.RiskLevel]++
+		case result.ErrorCode != "":
+			errorCodes[result.ErrorCode]++
+		}
+	}
+
+	fmt.Fprintf(&b, "# APIKeyzer Report\n\n")
+	fmt.Fprintf(&b, "Generated %s by APIKeyzer %s\n\n", time.Now().UTC().Format(time.RFC1123), m.opts.ToolVersion)
+
+	fmt.Fprintf(&b, "## Summary\n\n")
+	fmt.Fprintf(&b, "| Metric | Count |\n|---|---|\n")
+	fmt.Fprintf(&b, "| Keys validated | %d |\n", len(m.results))
+	fmt.Fprintf(&b, "| Vulnerable keys | %d |\n", len(findings))
+	fmt.Fprintf(&b, "| High risk | %d |\n", risks[validator.RiskLevelHigh])
+	fmt.Fprintf(&b, "| Medium risk | %d |\n", risks[validator.RiskLevelMedium])
+	fmt.Fprintf(&b, "| Low risk | %d |\n", risks[validator.RiskLevelLow])
+	fmt.Fprintf(&b, "| Not validated | %d |\n\n", errorCount(errorCodes))
+
+	if len(errorCodes) > 0 {
+		codes := make([]string, 0, len(errorCodes))
+		for code := range errorCodes {
+			codes = append(codes, string(code))
+		}
+		sort.Strings(codes)
+		fmt.Fprintf(&b, "## Validation errors\n\n")
+		fmt.Fprintf(&b, "| Code | Count |\n|---|---|\n")
+		for _, code := range codes {
+			fmt.Fprintf(&b, "| %s | %d |\n", code, errorCodes[validator.ErrorCode(code)])
+		}
+		b.WriteString("\n")
+	}
+
+	if len(findings) == 0 {
+		fmt.Fprintf(&b, "No vulnerable keys were found.\n")
+		_, err := io.WriteString(m.w, b.String())
+		return err
+	}
+
+	fmt.Fprintf(&b, "## Findings\n\n")
+	fmt.Fprintf(&b, "| # | ID | Service | Key | Risk | Location |\n|---|---|---|---|---|---|\n")
+	for i, result := range findings {
+		fmt.Fprintf(&b, "| %d | %s | %s | `%s` | %s | %s |\n", i+1, escapeCell(result.FindingID),
+			escapeCell(result.Service), escapeCell(result.Key), result.RiskLevel, escapeCell(locationText(result)))
+	}
+	b.WriteString("\n")
+
+	fmt.Fprintf(&b, "## Details\n\n")
+	for i, result := range findings {
+		fmt.Fprintf(&b, "### %d. %s (%s risk)\n\n", i+1, result.Service, result.RiskLevel)
+		if result.FindingID != "" {
+			fmt.Fprintf(&b, "- **Finding ID:** %s\n", result.FindingID)
+		}
+		fmt.Fprintf(&b, "- **Key:** `%s`\n", result.Key)
+		if classification := classificationText(result); classification != "" {
+			fmt.Fprintf(&b, "- **Classification:** %s\n", classification)
+		}
+		if result.Location != nil {
+			fmt.Fprintf(&b, "- **Location:** `%s`\n", result.Location)
+		}
+		fmt.Fprintf(&b, "- **Validated at:** %s\n", result.ValidatedAt.UTC().Format(time.RFC3339))
+		if len(result.Permissions) > 0 {
+			fmt.Fprintf(&b, "- **Vulnerable APIs:**\n")
+			for _, permission := range result.Permissions {
+				fmt.Fprintf(&b, "  - %s\n", permission)
+			}
+		}
+		if len(result.PoC) > 0 {
+			fmt.Fprintf(&b, "\n**Proof of concept:**\n\n```sh\n")
+			for _, endpoint := range pocEndpoints(result) {
+				fmt.Fprintf(&b, "%s\n", result.PoC[endpoint])
+			}
+			fmt.Fprintf(&b, "```\n")
+		}
+		fmt.Fprintf(&b, "\n**Remediation:** %s\n\n", Remediation(result.Service))
+		if len(result.References) > 0 {
+			fmt.Fprintf(&b, "**References:**\n\n")
+			for _, reference := range result.References {
+				fmt.Fprintf(&b, "- %s\n", reference)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	_, err := io.WriteString(m.w, b.String())
+	return err
+}
+
+// errorCount totals the results that could not be validated
+func errorCount(codes map[validator.ErrorCode]int) int {
+	total := 0
+	for _, count := range codes {
+		total += count
+	}
+	return total
+}
+
+// locationText returns a result's location or a dash when it is unknown
+func locationText(result *validator.ValidationResult) string {
+	if result.Location == nil {
+		return "-"
+	}
+	return result.Location.String()
+}
+
+// pocEndpoints returns the endpoints of a result's PoC commands in a stable order
+func pocEndpoints(result *validator.ValidationResult) []string {
+	endpoints := make([]string, 0, len(result.PoC))
+	for endpoint := range result.PoC {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Strings(endpoints)
+	return endpoints
+}
+
+// escapeCell makes a value safe to place inside a Markdown table cell
+func escapeCell(value string) string {
+	value = strings.ReplaceAll(value, "|", "\\|")
+	return strings.ReplaceAll(value, "\n", " ")
+}
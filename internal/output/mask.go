@@ -0,0 +1,93 @@
+package output
+
+import (
+	"strings"
+
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// maskFill replaces the hidden middle of a masked key; the ascii theme changes it
+var maskFill = "••••••"
+
+// pocPlaceholder stands in for the key in PoC commands when masking, so they stay runnable
+const pocPlaceholder = "<API_KEY>"
+
+// MaskKey redacts the middle of a key, keeping just enough of both ends to recognise it
+// (AIza••••••wxyz). Short keys keep only their first two characters.
+func MaskKey(key string) string {
+	if len(key) < 12 {
+		if len(key) <= 2 {
+			return maskFill
+		}
+		return key[:2] + maskFill
+	}
+	return key[:4] + maskFill + key[len(key)-4:]
+}
+
+// maskWriter redacts the key wherever it appears in a result before writing it
+type maskWriter struct {
+	Writer
+}
+
+func (m *maskWriter) WriteResult(result *validator.ValidationResult) error {
+	masked := *result
+	masked.Key = MaskKey(result.Key)
+	masked.ErrorStr = result.ErrorStr
+	masked.Details, masked.Metadata = result.Details, result.Metadata
+	for _, secret := range secretsOf(result.Key) {
+		masked.ErrorStr = strings.ReplaceAll(masked.ErrorStr, secret, masked.Key)
+		if details, ok := redact(masked.Details, secret, masked.Key).(map[string]interface{}); ok {
+			masked.Details = details
+		}
+		if metadata, ok := redact(masked.Metadata, secret, masked.Key).(map[string]interface{}); ok {
+			masked.Metadata = metadata
+		}
+	}
+	if result.PoC != nil {
+		masked.PoC = make(map[string]string, len(result.PoC))
+		for endpoint, command := range result.PoC {
+			for _, secret := range secretsOf(result.Key) {
+				command = strings.ReplaceAll(command, secret, pocPlaceholder)
+			}
+			masked.PoC[endpoint] = command
+		}
+	}
+	return m.Writer.WriteResult(&masked)
+}
+
+// secretsOf returns what to redact of key: the key itself and, for keys given with their
+// endpoint or client ID (https://<host>:<key>, <tenant>:<client id>:<secret>), the part after
+// the last colon, which requests and JSONL input carry alone
+func secretsOf(key string) []string {
+	secrets := []string{key}
+	if i := strings.LastIndex(key, ":"); i > 0 && i < len(key)-1 && !strings.HasPrefix(key[i+1:], "//") {
+		secrets = append(secrets, key[i+1:])
+	}
+	return secrets
+}
+
+// redact returns a copy of v with every occurrence of key in strings and map keys replaced.
+// Request URLs in validation details embed the key, so these are redacted as well.
+func redact(v interface{}, key, masked string) interface{} {
+	switch value := v.(type) {
+	case string:
+		return strings.ReplaceAll(value, key, masked)
+	case map[string]interface{}:
+		if value == nil {
+			return value
+		}
+		copied := make(map[string]interface{}, len(value))
+		for k, item := range value {
+			copied[strings.ReplaceAll(k, key, masked)] = redact(item, key, masked)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(value))
+		for i, item := range value {
+			copied[i] = redact(item, key, masked)
+		}
+		return copied
+	default:
+		return v
+	}
+}
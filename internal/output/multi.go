@@ -0,0 +1,41 @@
+package output
+
+import (
+	"errors"
+
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// multiWriter duplicates results to several writers
+type multiWriter struct {
+	writers []Writer
+}
+
+// MultiWriter returns a Writer that writes every result to all writers, like io.MultiWriter.
+// Errors from individual writers are joined rather than stopping the others.
+func MultiWriter(writers ...Writer) Writer {
+	if len(writers) == 1 {
+		return writers[0]
+	}
+	return &multiWriter{writers: writers}
+}
+
+func (m *multiWriter) WriteResult(result *validator.ValidationResult) error {
+	var errs []error
+	for _, w := range m.writers {
+		if err := w.WriteResult(result); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *multiWriter) Close() error {
+	var errs []error
+	for _, w := range m.writers {
+		if err := w.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,369 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+	"gopkg.in/yaml.v3"
+)
+
+// nucleiKeyVariable is the template variable holding the key, so it can be overridden with -var
+const nucleiKeyVariable = "api_key"
+
+// nucleiRejections are phrases of provider responses refusing a key. Retest templates stop
+// matching when they appear, even if the endpoint still answers with its original status code.
+var nucleiRejections = []string{
+	"API key not valid",
+	"API_KEY_INVALID",
+	"REQUEST_DENIED",
+	"invalid_api_key",
+	"Invalid API Key",
+	"The provided API key is invalid",
+}
+
+// pocRequest is an HTTP request recovered from a PoC curl command
+type pocRequest struct {
+	Method  string
+	URL     string
+	Headers [][2]string
+	Body    string
+}
+
+// parseCurlCommand parses a command produced by validator.CurlCommand back into its request
+func parseCurlCommand(command string) (*pocRequest, error) {
+	words, err := shellWords(command)
+	if err != nil {
+		return nil, err
+	}
+	if len(words) == 0 || words[0] != "curl" {
+		return nil, fmt.Errorf("not a curl command: %q", command)
+	}
+
+	req := &pocRequest{Method: "GET"}
+	for i := 1; i < len(words); i++ {
+		switch words[i] {
+		case "-sS":
+		case "-X", "-H", "--data":
+			if i+1 >= len(words) {
+				return nil, fmt.Errorf("missing value for %s", words[i])
+			}
+			value := words[i+1]
+			i++
+			switch words[i-1] {
+			case "-X":
+				req.Method = value
+			case "-H":
+				name, val, _ := strings.Cut(value, ":")
+				req.Headers = append(req.Headers, [2]string{strings.TrimSpace(name), strings.TrimSpace(val)})
+			case "--data":
+				req.Body = value
+				if req.Method == "GET" {
+					req.Method = "POST"
+				}
+			}
+		default:
+			req.URL = words[i]
+		}
+	}
+	if req.URL == "" {
+		return nil, fmt.Errorf("no URL in curl command")
+	}
+	return req, nil
+}
+
+// shellWords splits a command whose arguments are plain words or single-quoted strings
+func shellWords(command string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord, quoted := false, false
+	for i := 0; i < len(command); i++ {
+		c := command[i]
+		switch {
+		case quoted:
+			if c == '\'' {
+				quoted = false
+			} else {
+				word.WriteByte(c)
+			}
+		case c == '\'':
+			quoted, inWord = true, true
+		case c == '\\' && i+1 < len(command):
+			i++
+			word.WriteByte(command[i])
+			inWord = true
+		case c == ' ':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("unterminated quote in %q", command)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// raw renders the request as it is sent on the wire
+func (r *pocRequest) raw() string {
+	u, err := url.Parse(r.URL)
+	if err != nil {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s HTTP/1.1\r\nHost: %s\r\n", r.Method, u.RequestURI(), u.Host)
+	for _, header := range r.Headers {
+		fmt.Fprintf(&b, "%s: %s\r\n", header[0], header[1])
+	}
+	b.WriteString("\r\n" + r.Body)
+	return b.String()
+}
+
+// nucleiInfo is the info block shared by nuclei templates and findings
+type nucleiInfo struct {
+	Name        string                 `json:"name" yaml:"name"`
+	Author      []string               `json:"author" yaml:"author,flow"`
+	Severity    string                 `json:"severity" yaml:"severity"`
+	Description string                 `json:"description" yaml:"description"`
+	Remediation string                 `json:"remediation,omitempty" yaml:"remediation,omitempty"`
+	Reference   []string               `json:"reference,omitempty" yaml:"reference,omitempty"`
+	Tags        []string               `json:"tags" yaml:"tags,flow"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+}
+
+// nucleiFinding is a line of nuclei's JSONL output
+type nucleiFinding struct {
+	TemplateID    string     `json:"template-id"`
+	Info          nucleiInfo `json:"info"`
+	Type          string     `json:"type"`
+	Host          string     `json:"host"`
+	MatchedAt     string     `json:"matched-at"`
+	Request       string     `json:"request"`
+	CurlCommand   string     `json:"curl-command"`
+	Timestamp     string     `json:"timestamp"`
+	MatcherStatus bool       `json:"matcher-status"`
+}
+
+// nucleiWriter writes a nuclei JSONL finding for every vulnerable endpoint of a valid key,
+// carrying the request that confirmed it so retest automation can replay it
+type nucleiWriter struct {
+	encoder *json.Encoder
+}
+
+func newNucleiWriter(w io.Writer) *nucleiWriter {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return &nucleiWriter{encoder: encoder}
+}
+
+func (n *nucleiWriter) WriteResult(result *validator.ValidationResult) error {
+	if !result.Valid {
+		return nil
+	}
+	for _, endpoint := range pocEndpoints(result) {
+		command := result.PoC[endpoint]
+		req, err := parseCurlCommand(command)
+		if err != nil {
+			return fmt.Errorf("failed to read PoC of %s: %w", endpoint, err)
+		}
+		u, err := url.Parse(req.URL)
+		if err != nil {
+			return fmt.Errorf("invalid PoC URL: %w", err)
+		}
+		finding := nucleiFinding{
+			TemplateID:    nucleiTemplateID(result),
+			Info:          newNucleiInfo(result),
+			Type:          "http",
+			Host:          u.Scheme + "://" + u.Host,
+			MatchedAt:     req.URL,
+			Request:       req.raw(),
+			CurlCommand:   command,
+			Timestamp:     result.ValidatedAt.UTC().Format(time.RFC3339),
+			MatcherStatus: true,
+		}
+		if err := n.encoder.Encode(finding); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *nucleiWriter) Close() error {
+	return nil
+}
+
+// nucleiTemplate is a self-contained nuclei template replaying the requests that confirmed a key
+type nucleiTemplate struct {
+	ID            string            `yaml:"id"`
+	Info          nucleiInfo        `yaml:"info"`
+	Variables     map[string]string `yaml:"variables,omitempty"`
+	SelfContained bool              `yaml:"self-contained"`
+	HTTP          []nucleiRequest   `yaml:"http"`
+}
+
+type nucleiRequest struct {
+	Method            string            `yaml:"method"`
+	Path              []string          `yaml:"path"`
+	Headers           map[string]string `yaml:"headers,omitempty"`
+	Body              string            `yaml:"body,omitempty"`
+	MatchersCondition string            `yaml:"matchers-condition"`
+	Matchers          []nucleiMatcher   `yaml:"matchers"`
+}
+
+type nucleiMatcher struct {
+	Type            string   `yaml:"type"`
+	Part            string   `yaml:"part,omitempty"`
+	Status          []int    `yaml:"status,omitempty,flow"`
+	Words           []string `yaml:"words,omitempty"`
+	Condition       string   `yaml:"condition,omitempty"`
+	CaseInsensitive bool     `yaml:"case-insensitive,omitempty"`
+	Negative        bool     `yaml:"negative,omitempty"`
+}
+
+// NucleiTemplateWriter writes a nuclei template for every valid key into a directory, one file
+// per finding named after its template ID. Each template replays the requests that confirmed
+// the key and matches while the endpoints still answer as they did.
+type NucleiTemplateWriter struct {
+	dir string
+}
+
+// NewNucleiTemplateWriter creates a writer for templates in dir, creating it if needed
+func NewNucleiTemplateWriter(dir string) (*NucleiTemplateWriter, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create template directory: %w", err)
+	}
+	return &NucleiTemplateWriter{dir: dir}, nil
+}
+
+func (t *NucleiTemplateWriter) WriteResult(result *validator.ValidationResult) error {
+	if !result.Valid || len(result.PoC) == 0 {
+		return nil
+	}
+
+	template := nucleiTemplate{
+		ID:            nucleiTemplateID(result),
+		Info:          newNucleiInfo(result),
+		SelfContained: true,
+	}
+	masked := false
+	for _, endpoint := range pocEndpoints(result) {
+		command := result.PoC[endpoint]
+		masked = masked || strings.Contains(command, pocPlaceholder)
+		// The key is referenced through a variable so the template can retest a rotated key with -var
+		command = strings.ReplaceAll(command, pocPlaceholder, "{{"+nucleiKeyVariable+"}}")
+		command = strings.ReplaceAll(command, result.Key, "{{"+nucleiKeyVariable+"}}")
+		req, err := parseCurlCommand(command)
+		if err != nil {
+			return fmt.Errorf("failed to read PoC of %s: %w", endpoint, err)
+		}
+
+		request := nucleiRequest{
+			Method:            req.Method,
+			Path:              []string{req.URL},
+			Body:              req.Body,
+			MatchersCondition: "and",
+			Matchers: []nucleiMatcher{
+				{Type: "status", Status: []int{endpointStatus(result, endpoint)}},
+				{Type: "word", Part: "body", Words: nucleiRejections, Condition: "or", CaseInsensitive: true, Negative: true},
+			},
+		}
+		if len(req.Headers) > 0 {
+			request.Headers = make(map[string]string, len(req.Headers))
+			for _, header := range req.Headers {
+				request.Headers[header[0]] = header[1]
+			}
+		}
+		template.HTTP = append(template.HTTP, request)
+	}
+	// Masked results lose the key, which then has to be passed with -var api_key=...
+	if !masked {
+		template.Variables = map[string]string{nucleiKeyVariable: result.Key}
+	}
+
+	content, err := yaml.Marshal(template)
+	if err != nil {
+		return fmt.Errorf("failed to render template: %w", err)
+	}
+	filename := filepath.Join(t.dir, template.ID+".yaml")
+	if err := os.WriteFile(filename, content, 0600); err != nil {
+		return fmt.Errorf("failed to write template: %w", err)
+	}
+	return nil
+}
+
+func (t *NucleiTemplateWriter) Close() error {
+	return nil
+}
+
+// nucleiTemplateID derives a template ID, which nuclei restricts to letters, digits and dashes, from the finding ID
+func nucleiTemplateID(result *validator.ValidationResult) string {
+	return "apikeyzer-" + strings.ToLower(result.FindingID)
+}
+
+// newNucleiInfo describes a valid key for nuclei
+func newNucleiInfo(result *validator.ValidationResult) nucleiInfo {
+	metadata := map[string]interface{}{
+		"finding_id":   result.FindingID,
+		"validated_at": result.ValidatedAt.UTC().Format(time.RFC3339),
+	}
+	if result.Location != nil {
+		metadata["location"] = result.Location.String()
+	}
+	description := fmt.Sprintf("Valid %s %s", result.Service, MaskKey(result.Key))
+	if len(result.Permissions) > 0 {
+		description += ", accepted by " + strings.Join(result.Permissions, ", ")
+	}
+	references := append([]string{}, result.References...)
+	sort.Strings(references)
+	return nucleiInfo{
+		Name:        fmt.Sprintf("Valid %s", result.Service),
+		Author:      []string{"apikeyzer"},
+		Severity:    nucleiSeverity(result.RiskLevel),
+		Description: description,
+		Remediation: Remediation(result.Service),
+		Reference:   references,
+		Tags:        []string{"apikeyzer", "exposure", "token", validator.ServiceID(result.Service)},
+		Metadata:    metadata,
+	}
+}
+
+// nucleiSeverity maps a risk level to a nuclei severity
+func nucleiSeverity(risk validator.RiskLevel) string {
+	switch risk {
+	case validator.RiskLevelHigh:
+		return "high"
+	case validator.RiskLevelMedium:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+// endpointStatus returns the status code an endpoint answered with when the key was confirmed,
+// as recorded in the result details, or 200
+func endpointStatus(result *validator.ValidationResult, endpoint string) int {
+	if details, ok := result.Details[endpoint].(map[string]interface{}); ok {
+		switch code := details["status_code"].(type) {
+		case int:
+			return code
+		case float64:
+			return int(code)
+		}
+	}
+	return 200
+}
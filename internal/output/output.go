@@ -0,0 +1,123 @@
+package output
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// Supported output formats
+const (
+	FormatText     = "text"
+	FormatJSON     = "json"
+	FormatSARIF    = "sarif"
+	FormatMarkdown = "md"
+	FormatJUnit    = "junit"
+	FormatTemplate = "template"
+	FormatTable    = "table"
+	FormatDojo     = "defectdojo"
+	FormatGitHub   = "github"
+	FormatGitLab   = "gitlab"
+	FormatGitLabSD = "gitlab-secrets"
+	FormatNuclei   = "nuclei"
+)
+
+// Formats lists every format accepted by New
+var Formats = []string{FormatText, FormatTable, FormatJSON, FormatSARIF, FormatJUnit, FormatDojo, FormatGitHub, FormatGitLab, FormatGitLabSD, FormatNuclei}
+
+// ReportFormats lists the summary report formats selectable with --report
+var ReportFormats = []string{FormatMarkdown}
+
+// Writer renders validation results in a specific format
+type Writer interface {
+	// WriteResult renders (or buffers) a single validation result
+	WriteResult(result *validator.ValidationResult) error
+
+	// Close flushes anything buffered by the writer
+	Close() error
+}
+
+// Options control how results are rendered
+type Options struct {
+	Verbose     bool
+	ToolVersion string
+
+	// Template is the text/template applied to each result by the template format
+	Template string
+
+	// OnlyValid drops invalid results; the text format then prints bare keys
+	OnlyValid bool
+
+	// MinRisk drops valid results assessed below this risk level
+	MinRisk validator.RiskLevel
+
+	// Mask redacts the middle of keys in every rendered result
+	Mask bool
+
+	// Sort buffers results until the writer is closed and renders them in this order
+	// (one of SortOrders); the default is input order
+	Sort string
+}
+
+// New creates a Writer for the given format writing to w. It is safe for concurrent use.
+func New(format string, w io.Writer, opts Options) (Writer, error) {
+	return newBufferedWriter(w, func(w io.Writer) (Writer, error) {
+		writer, err := newFormatWriter(format, w, opts)
+		if err != nil {
+			return nil, err
+		}
+		return newSortWriter(Wrap(writer, opts), opts.Sort)
+	})
+}
+
+// Wrap applies the masking and filtering options to any Writer, such as an external sink
+func Wrap(writer Writer, opts Options) Writer {
+	if opts.Mask {
+		writer = &maskWriter{Writer: writer}
+	}
+	if keep := resultFilter(opts); keep != nil {
+		writer = &filterWriter{Writer: writer, keep: keep}
+	}
+	return writer
+}
+
+// newFormatWriter creates the unfiltered Writer for a format
+func newFormatWriter(format string, w io.Writer, opts Options) (Writer, error) {
+	switch format {
+	case FormatText, "":
+		if opts.OnlyValid {
+			return &keyWriter{w: w}, nil
+		}
+		return &textWriter{w: w, opts: opts}, nil
+	case FormatTable:
+		return newTableWriter(w), nil
+	case FormatJSON:
+		return newJSONWriter(w), nil
+	case FormatSARIF:
+		return newSARIFWriter(w, opts), nil
+	case FormatTemplate:
+		return newTemplateWriter(w, opts.Template)
+	case FormatJUnit:
+		return newJUnitWriter(w), nil
+	case FormatDojo:
+		return newDefectDojoWriter(w), nil
+	case FormatGitHub:
+		return newGitHubWriter(w, opts)
+	case FormatGitLab:
+		return newCodeQualityWriter(w), nil
+	case FormatGitLabSD:
+		return newSecretDetectionWriter(w, opts), nil
+	case FormatNuclei:
+		return newNucleiWriter(w), nil
+	case FormatMarkdown, "markdown":
+		return newMarkdownWriter(w, opts), nil
+	default:
+		return nil, fmt.Errorf("unsupported output format: %s", format)
+	}
+}
+
+// IsStructured reports whether a format is meant for machines rather than terminals
+func IsStructured(format string) bool {
+	return format != FormatText && format != FormatTable && format != ""
+}
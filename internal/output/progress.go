@@ -0,0 +1,77 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// progressWidth is the number of cells in the progress bar
+const progressWidth = 30
+
+// progressInterval limits how often the progress bar is redrawn
+const progressInterval = 100 * time.Millisecond
+
+// Progress draws a single-line progress bar with rate and ETA, meant for a terminal on stderr.
+// A nil *Progress is valid and draws nothing, so callers need not check whether it is enabled.
+type Progress struct {
+	w        io.Writer
+	total    int
+	done     int
+	start    time.Time
+	lastDraw time.Time
+	visible  bool
+}
+
+// NewProgress creates a progress bar for total items writing to w
+func NewProgress(w io.Writer, total int) *Progress {
+	return &Progress{w: w, total: total, start: time.Now()}
+}
+
+// Increment marks one more item as processed and redraws the bar
+func (p *Progress) Increment() {
+	if p == nil {
+		return
+	}
+	p.done++
+	if p.done < p.total && time.Since(p.lastDraw) < progressInterval && p.visible {
+		return
+	}
+	p.draw()
+}
+
+// Clear erases the bar so other output can be written to the terminal; the next Increment redraws it
+func (p *Progress) Clear() {
+	if p == nil || !p.visible {
+		return
+	}
+	fmt.Fprint(p.w, "\r\033[K")
+	p.visible = false
+}
+
+// Finish erases the bar once all work is done
+func (p *Progress) Finish() {
+	p.Clear()
+}
+
+func (p *Progress) draw() {
+	elapsed := time.Since(p.start)
+	rate := float64(p.done) / elapsed.Seconds()
+
+	filled := progressWidth
+	if p.total > 0 && p.done < p.total {
+		filled = progressWidth * p.done / p.total
+	}
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", progressWidth-filled)
+
+	eta := "--"
+	if rate > 0 && p.done < p.total {
+		remaining := time.Duration(float64(p.total-p.done) / rate * float64(time.Second))
+		eta = remaining.Round(time.Second).String()
+	}
+
+	fmt.Fprintf(p.w, "\r\033[K[%s] %d/%d  %.1f keys/s  ETA %s", bar, p.done, p.total, rate, eta)
+	p.lastDraw = time.Now()
+	p.visible = true
+}
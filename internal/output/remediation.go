@@ -0,0 +1,115 @@
+package output
+
+import (
+	"strings"
+
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// defaultRemediation applies to services without specific guidance
+const defaultRemediation = "Revoke or rotate the key in the provider's console, remove it from the " +
+	"exposed location (including version control history) and load it from a secret store instead."
+
+// remediations holds per-service remediation guidance keyed by service name
+var remediations = map[string]string{
+	"Google Safe Browsing API Key": "Restrict the key in Google Cloud Console (APIs & Services > Credentials) " +
+		"to only the APIs it needs and to specific HTTP referrers, IP addresses or app signatures. " +
+		"If the key was exposed publicly, regenerate it and update the applications that use it.",
+	"GitHub App Private Key": "Generate a new private key in the app settings (Settings > Developer settings > GitHub Apps), " +
+		"deploy it and delete the exposed one; tokens minted with it stay valid for up to an hour. " +
+		"Review the audit logs of the accounts the app is installed on for activity by the app.",
+	"GitHub App Installation Token": "Revoke the token with DELETE /installation/token or wait for it to expire after an hour, " +
+		"then find how it leaked: installation tokens are usually exposed by CI logs or build artifacts. " +
+		"If the app's private key may have leaked along with it, rotate that key as well.",
+	"Slack Token": "Revoke the token with auth.revoke or by reinstalling the app (api.slack.com/apps > OAuth & Permissions), " +
+		"then check the workspace access logs for use of it. Trim the app's scopes to what it needs; " +
+		"tokens with admin scopes should never live outside a secret store.",
+	"Slack Webhook": "Remove the webhook from the app's Incoming Webhooks settings and create a new one, " +
+		"then review the channel for messages posted by others. Webhook URLs are credentials and belong in a secret store.",
+	"Stripe API Key": "Roll the key in the Stripe Dashboard (Developers > API keys), which can keep the old key working " +
+		"for a short grace period while deployments are updated. Review recent payouts, refunds and API request logs " +
+		"for unexpected activity, and use restricted keys with only the permissions each integration needs.",
+	"Square API Key": "Revoke the token in the Square Developer Dashboard: replace the personal access token of the " +
+		"application, or revoke the OAuth access of the seller. Request only the scopes each integration needs and " +
+		"review recent payments and refunds of the affected locations.",
+	"PayPal Client Credentials": "Generate a new secret for the app in the PayPal Developer Dashboard (Apps & Credentials), " +
+		"update the integrations that use it and delete the exposed secret. Review recent transactions, refunds and " +
+		"payouts of the account, and disable the app features the integration does not use.",
+	"Braintree API Key": "Create a new API key pair in the Braintree Control Panel (Settings > API), update the " +
+		"integrations and delete the exposed key; access tokens are revoked by the merchant from the connected " +
+		"apps of the account. Review recent transactions, refunds and vault access.",
+	"Plaid API Credentials": "Rotate the secret of each affected environment in the Plaid Dashboard (Developers > Keys) " +
+		"and update the backends that use it. Secrets only belong on servers; review the Items and access tokens " +
+		"created recently, since production credentials reach end users' bank data.",
+	"SendGrid API Key": "Delete the key in SendGrid (Settings > API Keys) and create a replacement with only the " +
+		"scopes the application needs. Check the activity feed for mail you did not send, since a leaked key with " +
+		"mail.send is commonly abused for phishing from your domains.",
+	"Mailgun API Key": "Delete the key in Mailgun (API Security) and create a new one, or use a domain sending key " +
+		"limited to the domain the application sends from. Review the sending logs of the listed domains for " +
+		"messages you did not send.",
+	"Mailchimp API Key": "Disable the key in Mailchimp (Profile > Extras > API keys) and create a new one for the " +
+		"integration. Check the account's recent campaigns and audience exports, since the key reaches every " +
+		"subscriber's contact details.",
+	"SparkPost API Key": "Delete the key in SparkPost (Configuration > API Keys) and create a new one with only the " +
+		"grants the integration needs, limited to the IP addresses it sends from. Review the message events of the " +
+		"sending domains for mail you did not send.",
+	"OpenAI API Key": "Revoke the key in the OpenAI dashboard (API keys) and create a project key with restricted " +
+		"permissions for the integration. Check the usage page for spend you do not recognise and set a monthly " +
+		"budget on the project.",
+	"Anthropic API Key": "Disable or delete the key in the Anthropic Console (API keys); keys pushed to public GitHub " +
+		"repositories may already have been revoked automatically. Issue a new key in a workspace with a spend " +
+		"limit and review the usage and cost reports for activity you do not recognise.",
+	"Azure OpenAI API Key": "Regenerate both keys of the resource (Keys and Endpoint in the Azure portal) and move " +
+		"callers to Microsoft Entra ID authentication, then disable local key authentication on the resource. " +
+		"Review the resource metrics for token usage you do not recognise.",
+	"Cohere API Key": "Delete the key in the Cohere dashboard (API Keys) and create a new one for the integration. " +
+		"Review the usage and billing pages for calls you do not recognise.",
+	"Replicate API Token": "Delete the token in Replicate (Account settings > API tokens) and create a new one. " +
+		"Review the predictions and trainings of the account for runs you did not start, and set a spend limit " +
+		"in the billing settings.",
+	"Groq API Key": "Delete the key in the GroqCloud console (API Keys) and create a new one for the integration. " +
+		"Review the usage page for requests you do not recognise and set spend limits if the organization is on " +
+		"a paid tier.",
+	"Mistral API Key": "Delete the key in La Plateforme (API Keys) and create a new one scoped to the workspace " +
+		"that needs it. Review the usage page for requests you do not recognise.",
+	"Firebase Cloud Messaging Server Key": "Delete the server key in the Firebase console (Project settings > Cloud " +
+		"Messaging) and disable the legacy Cloud Messaging API for the project. Send notifications through the " +
+		"FCM HTTP v1 API with a service account instead.",
+	"Firebase Open Signup": "Disable the sign-in providers the app does not use in Firebase Authentication, and turn " +
+		"off user sign-up in the Identity Platform settings if accounts are created by an admin. Do not rely on " +
+		"request.auth != null alone in security rules; check the user's ID or custom claims.",
+	"Firebase Realtime Database": "Replace the read rules of the database with rules that check request.auth " +
+		"and the path being read, then deploy them with the Firebase CLI. Review the data that was exposed.",
+	"Firebase Firestore": "Replace allow read: if true rules with rules that check request.auth and the document " +
+		"being read, then deploy them with the Firebase CLI. Review the collections that were exposed.",
+	"Firebase Storage": "Restrict the Storage security rules so objects can only be listed and read by the users " +
+		"who own them, then deploy them with the Firebase CLI. Review the objects that were exposed.",
+	"Azure AD Client Secret": "Delete the secret in Microsoft Entra ID (App registrations > Certificates & " +
+		"secrets) and add a new one, or switch the app to a certificate or managed identity. Review the sign-in " +
+		"logs of the service principal and remove application permissions the app does not need.",
+	"Azure Storage Account Key": "Rotate the key in the Azure portal (Storage account > Access keys), after " +
+		"moving applications to the other key, and update the connection strings that use it. Prefer Microsoft " +
+		"Entra ID authorization and disable Shared Key access on the account where possible.",
+	"Azure Storage SAS URL": "Revoke the SAS: delete or change its stored access policy, or rotate the account " +
+		"key (or the user delegation key) it was signed with. Issue new SAS tokens with the fewest permissions " +
+		"and a short expiry.",
+	"Cloudflare API Token": "Roll or delete the token in the Cloudflare dashboard (My Profile > API Tokens, or " +
+		"Manage Account > API Tokens for account tokens). Recreate it with only the permissions and zones it " +
+		"needs, an IP filter and an expiry, and review the audit log for changes made with it.",
+	"Cloudflare Global API Key": "Change the Global API Key in the Cloudflare dashboard (My Profile > API Tokens). " +
+		"Move integrations to scoped API tokens, enable two-factor authentication and review the audit log of " +
+		"every account of the user.",
+}
+
+// classificationText joins a result's CWE and OWASP categories, e.g. "CWE-798, A07:2021"
+func classificationText(result *validator.ValidationResult) string {
+	return strings.Join(append(append([]string{}, result.CWE...), result.OWASP...), ", ")
+}
+
+// Remediation returns remediation guidance for a service
+func Remediation(service string) string {
+	if text, ok := remediations[service]; ok {
+		return text
+	}
+	return defaultRemediation
+}
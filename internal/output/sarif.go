@@ -0,0 +1,299 @@
+package output
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	toolURI      = "https://github.com/Xplo8E/APIKeyzer"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool        sarifTool         `json:"tool"`
+	Invocations []sarifInvocation `json:"invocations,omitempty"`
+	Results     []sarifResult     `json:"results"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	ToolExecutionNotifications []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+type sarifNotification struct {
+	Descriptor sarifDescriptor        `json:"descriptor"`
+	Level      string                 `json:"level"`
+	Message    sarifMessage           `json:"message"`
+	Locations  []sarifLocation        `json:"locations,omitempty"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifDescriptor struct {
+	ID string `json:"id"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string                 `json:"id"`
+	Name                 string                 `json:"name"`
+	ShortDescription     sarifMessage           `json:"shortDescription"`
+	HelpURI              string                 `json:"helpUri,omitempty"`
+	DefaultConfiguration sarifRuleConfig        `json:"defaultConfiguration"`
+	Properties           map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifRuleConfig struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string                 `json:"ruleId"`
+	RuleIndex           int                    `json:"ruleIndex"`
+	Level               string                 `json:"level"`
+	Message             sarifMessage           `json:"message"`
+	Locations           []sarifLocation        `json:"locations,omitempty"`
+	PartialFingerprints map[string]string      `json:"partialFingerprints"`
+	Properties          map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// sarifWriter collects valid keys and renders them as a SARIF 2.1.0 log on Close;
+// keys that could not be validated become tool execution notifications
+type sarifWriter struct {
+	w             io.Writer
+	opts          Options
+	rules         []sarifRule
+	ruleIndex     map[string]int
+	results       []sarifResult
+	notifications []sarifNotification
+}
+
+func newSARIFWriter(w io.Writer, opts Options) *sarifWriter {
+	return &sarifWriter{
+		w:         w,
+		opts:      opts,
+		ruleIndex: make(map[string]int),
+	}
+}
+
+func (s *sarifWriter) WriteResult(result *validator.ValidationResult) error {
+	if result.ErrorCode != "" {
+		notification := sarifNotification{
+			Descriptor: sarifDescriptor{ID: string(result.ErrorCode)},
+			Level:      "warning",
+			Message:    sarifMessage{Text: result.ErrorStr},
+			Properties: map[string]interface{}{"service": result.Service},
+		}
+		if location := sarifLocationFor(result); location != nil {
+			notification.Locations = []sarifLocation{*location}
+		}
+		s.notifications = append(s.notifications, notification)
+		return nil
+	}
+
+	// Only confirmed exposures are findings; invalid keys are not reported
+	if !result.Valid {
+		return nil
+	}
+
+	level := sarifLevel(result.RiskLevel)
+	ruleID := ruleIDFor(result.Service)
+	index, exists := s.ruleIndex[ruleID]
+	if !exists {
+		index = len(s.rules)
+		s.ruleIndex[ruleID] = index
+		rule := sarifRule{
+			ID:                   ruleID,
+			Name:                 result.Service,
+			ShortDescription:     sarifMessage{Text: fmt.Sprintf("Valid %s exposed", result.Service)},
+			DefaultConfiguration: sarifRuleConfig{Level: level},
+			Properties: map[string]interface{}{
+				"security-severity": securitySeverity(result.RiskLevel),
+				"tags":              sarifTags(result),
+			},
+		}
+		if len(result.References) > 0 {
+			rule.HelpURI = result.References[0]
+		}
+		s.rules = append(s.rules, rule)
+	}
+
+	sum := sha256.Sum256([]byte(result.Key))
+	entry := sarifResult{
+		RuleID:    ruleID,
+		RuleIndex: index,
+		Level:     level,
+		Message: sarifMessage{
+			Text: fmt.Sprintf("Valid %s %s (risk: %s)", result.Service, result.Key, result.RiskLevel),
+		},
+		PartialFingerprints: map[string]string{
+			"secretHash/v1": hex.EncodeToString(sum[:]),
+		},
+		Properties: map[string]interface{}{
+			"finding_id":  result.FindingID,
+			"risk_level":  result.RiskLevel,
+			"permissions": result.Permissions,
+			"cwe":         result.CWE,
+			"owasp":       result.OWASP,
+		},
+	}
+
+	if location := sarifLocationFor(result); location != nil {
+		entry.Locations = []sarifLocation{*location}
+	}
+
+	s.results = append(s.results, entry)
+	return nil
+}
+
+// sarifLocationFor converts a result's location, returning nil when it is unknown
+func sarifLocationFor(result *validator.ValidationResult) *sarifLocation {
+	if result.Location == nil {
+		return nil
+	}
+	location := &sarifLocation{
+		PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: artifactURI(result.Location.Path)},
+		},
+	}
+	if result.Location.Line > 0 {
+		location.PhysicalLocation.Region = &sarifRegion{
+			StartLine:   result.Location.Line,
+			StartColumn: result.Location.Column,
+		}
+	}
+	return location
+}
+
+func (s *sarifWriter) Close() error {
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "APIKeyzer",
+				Version:        s.opts.ToolVersion,
+				InformationURI: toolURI,
+				Rules:          s.rules,
+			}},
+			Results: s.results,
+		}},
+	}
+	if len(s.notifications) > 0 {
+		log.Runs[0].Invocations = []sarifInvocation{{
+			ExecutionSuccessful:        true,
+			ToolExecutionNotifications: s.notifications,
+		}}
+	}
+	if log.Runs[0].Tool.Driver.Rules == nil {
+		log.Runs[0].Tool.Driver.Rules = []sarifRule{}
+	}
+	if log.Runs[0].Results == nil {
+		log.Runs[0].Results = []sarifResult{}
+	}
+
+	encoder := json.NewEncoder(s.w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(log)
+}
+
+// sarifLevel maps a risk level to a SARIF result level
+func sarifLevel(risk validator.RiskLevel) string {
+	switch risk {
+	case validator.RiskLevelHigh:
+		return "error"
+	case validator.RiskLevelMedium:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// securitySeverity maps a risk level to the CVSS-like score used by GitHub Code Scanning
+func securitySeverity(risk validator.RiskLevel) string {
+	switch risk {
+	case validator.RiskLevelHigh:
+		return "8.0"
+	case validator.RiskLevelMedium:
+		return "5.0"
+	default:
+		return "2.0"
+	}
+}
+
+// sarifTags returns rule tags, using GitHub's external/cwe convention for CWE identifiers
+func sarifTags(result *validator.ValidationResult) []string {
+	tags := []string{"security", "secret"}
+	for _, cwe := range result.CWE {
+		tags = append(tags, "external/cwe/"+strings.ToLower(cwe))
+	}
+	for _, category := range result.OWASP {
+		tags = append(tags, "external/owasp/"+strings.ToLower(category))
+	}
+	return tags
+}
+
+// ruleIDFor turns a service name into a stable rule identifier
+func ruleIDFor(service string) string {
+	return "apikeyzer/" + validator.ServiceID(service)
+}
+
+// artifactURI makes file paths relative to the working directory so SARIF consumers
+// can map them onto the repository; other sources (URLs, env:NAME) are kept as-is
+func artifactURI(path string) string {
+	if filepath.IsAbs(path) {
+		if cwd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(cwd, path); err == nil && !strings.HasPrefix(rel, "..") {
+				path = rel
+			}
+		}
+	}
+	return filepath.ToSlash(path)
+}
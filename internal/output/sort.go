@@ -0,0 +1,77 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// Result orders selectable with Options.Sort
+const (
+	SortInput   = "input"   // the order keys were read in
+	SortService = "service" // by service, then finding ID
+	SortRisk    = "risk"    // valid keys first, highest risk first, then by service
+)
+
+// SortOrders lists every order accepted by Options.Sort
+var SortOrders = []string{SortInput, SortService, SortRisk}
+
+// sortWriter buffers every result and writes them to the wrapped Writer in order when closed.
+// Ties keep their input order, so output is stable across runs of the same input.
+type sortWriter struct {
+	Writer
+	less    func(a, b *validator.ValidationResult) bool
+	results []*validator.ValidationResult
+}
+
+// newSortWriter wraps writer to sort its results by order, or returns it as is for input order
+func newSortWriter(writer Writer, order string) (Writer, error) {
+	var less func(a, b *validator.ValidationResult) bool
+	switch order {
+	case SortInput, "":
+		return writer, nil
+	case SortService:
+		less = func(a, b *validator.ValidationResult) bool {
+			if a.Service != b.Service {
+				return a.Service < b.Service
+			}
+			return a.FindingID < b.FindingID
+		}
+	case SortRisk:
+		less = func(a, b *validator.ValidationResult) bool {
+			if a.Valid != b.Valid {
+				return a.Valid
+			}
+			if ra, rb := a.RiskLevel.Rank(), b.RiskLevel.Rank(); ra != rb {
+				return ra > rb
+			}
+			if a.Service != b.Service {
+				return a.Service < b.Service
+			}
+			return a.FindingID < b.FindingID
+		}
+	default:
+		return nil, fmt.Errorf("unsupported sort order: %s (expected %s)", order, strings.Join(SortOrders, ", "))
+	}
+	return &sortWriter{Writer: writer, less: less}, nil
+}
+
+func (s *sortWriter) WriteResult(result *validator.ValidationResult) error {
+	s.results = append(s.results, result)
+	return nil
+}
+
+func (s *sortWriter) Close() error {
+	sort.SliceStable(s.results, func(i, j int) bool {
+		return s.less(s.results[i], s.results[j])
+	})
+	for _, result := range s.results {
+		if err := s.Writer.WriteResult(result); err != nil {
+			s.Writer.Close()
+			return err
+		}
+	}
+	return s.Writer.Close()
+}
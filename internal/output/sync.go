@@ -0,0 +1,72 @@
+package output
+
+import (
+	"bufio"
+	"io"
+	"sync"
+
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// resultBufferSize holds any single rendered result, so each one reaches the output in one write
+const resultBufferSize = 64 * 1024
+
+// syncWriter serializes results written by concurrent goroutines. Results are rendered into a
+// buffer that is flushed once each result is complete, so output shared with other writers, such
+// as a terminal, never shows a result cut mid-line; whatever is still buffered is flushed on Close.
+type syncWriter struct {
+	mu     sync.Mutex
+	writer Writer
+	buf    *bufio.Writer // nil when the wrapped writer does its own output
+	closed bool
+}
+
+// Synchronized returns a Writer that is safe for concurrent use, wrapping writer unless it
+// already is. Close may be called more than once; only the first call closes writer.
+func Synchronized(writer Writer) Writer {
+	if _, ok := writer.(*syncWriter); ok {
+		return writer
+	}
+	return &syncWriter{writer: writer}
+}
+
+// newBufferedWriter creates the Writer built by build around a buffer over w, synchronized
+// and flushing after every result
+func newBufferedWriter(w io.Writer, build func(io.Writer) (Writer, error)) (Writer, error) {
+	buf := bufio.NewWriterSize(w, resultBufferSize)
+	writer, err := build(buf)
+	if err != nil {
+		return nil, err
+	}
+	return &syncWriter{writer: writer, buf: buf}, nil
+}
+
+func (s *syncWriter) WriteResult(result *validator.ValidationResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.writer.WriteResult(result); err != nil {
+		return err
+	}
+	return s.flush()
+}
+
+func (s *syncWriter) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	err := s.writer.Close()
+	if flushErr := s.flush(); err == nil {
+		err = flushErr
+	}
+	return err
+}
+
+func (s *syncWriter) flush() error {
+	if s.buf == nil {
+		return nil
+	}
+	return s.buf.Flush()
+}
@@ -0,0 +1,84 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// tableWriter collects results and prints them grouped by service with aligned columns on Close
+type tableWriter struct {
+	w        io.Writer
+	services []string
+	groups   map[string][]*validator.ValidationResult
+}
+
+func newTableWriter(w io.Writer) *tableWriter {
+	return &tableWriter{w: w, groups: make(map[string][]*validator.ValidationResult)}
+}
+
+func (t *tableWriter) WriteResult(result *validator.ValidationResult) error {
+	if _, exists := t.groups[result.Service]; !exists {
+		t.services = append(t.services, result.Service)
+	}
+	t.groups[result.Service] = append(t.groups[result.Service], result)
+	return nil
+}
+
+func (t *tableWriter) Close() error {
+	for i, service := range t.services {
+		results := t.groups[service]
+		valid := 0
+		for _, result := range results {
+			if result.Valid {
+				valid++
+			}
+		}
+
+		if i > 0 {
+			fmt.Fprintln(t.w)
+		}
+		name := service
+		if name == "" {
+			name = "Unknown service"
+		}
+		fmt.Fprintf(t.w, "%s (%d keys, %d valid)\n", Cyan(name), len(results), valid)
+
+		// Colors are kept out of the cells since escape codes would break the alignment
+		tw := tabwriter.NewWriter(t.w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "  KEY\tVALID\tRISK\tTOP PERMISSION\tLOCATION")
+		for _, result := range results {
+			risk, permission := "-", "-"
+			if result.Valid {
+				risk = string(result.RiskLevel)
+				if len(result.Permissions) > 0 {
+					permission = result.Permissions[0]
+					if more := len(result.Permissions) - 1; more > 0 {
+						permission += fmt.Sprintf(" (+%d)", more)
+					}
+				}
+			}
+			fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\n", result.Key, validText(result), risk, permission,
+				strings.ReplaceAll(locationText(result), "\t", " "))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validText renders the VALID cell, showing the error code for keys that could not be validated
+func validText(result *validator.ValidationResult) string {
+	switch {
+	case result.Valid:
+		return "yes"
+	case result.ErrorCode != "":
+		return string(result.ErrorCode)
+	default:
+		return "no"
+	}
+}
@@ -0,0 +1,51 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// templateFuncs are the helper functions available to --format-template
+var templateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"upper": func(v interface{}) string { return strings.ToUpper(fmt.Sprint(v)) },
+	"lower": func(v interface{}) string { return strings.ToLower(fmt.Sprint(v)) },
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// templateWriter executes a user-supplied text/template once per result
+type templateWriter struct {
+	w    io.Writer
+	tmpl *template.Template
+}
+
+func newTemplateWriter(w io.Writer, text string) (*templateWriter, error) {
+	if text == "" {
+		return nil, fmt.Errorf("template output requires a template")
+	}
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+
+	tmpl, err := template.New("result").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output template: %w", err)
+	}
+	return &templateWriter{w: w, tmpl: tmpl}, nil
+}
+
+func (t *templateWriter) WriteResult(result *validator.ValidationResult) error {
+	return t.tmpl.Execute(t.w, result)
+}
+
+func (t *templateWriter) Close() error {
+	return nil
+}
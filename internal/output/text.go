@@ -0,0 +1,81 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/Xplo8E/APIKeyzer/internal/secrets"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// textWriter prints human-readable, colored results
+type textWriter struct {
+	w    io.Writer
+	opts Options
+}
+
+func (t *textWriter) WriteResult(result *validator.ValidationResult) error {
+	switch {
+	case result.Valid:
+		fmt.Fprintln(t.w, Red("[+] Vulnerable API Key: "), result.Key)
+	case result.ErrorCode != "":
+		fmt.Fprintf(t.w, "\n%s %s: %s\n", Yellow("[!] Could not validate key ("+string(result.ErrorCode)+")"), result.Key, result.ErrorStr)
+	default:
+		fmt.Fprintf(t.w, "\n[-] Invalid key for %s: %s\n", result.Service, result.ErrorStr)
+	}
+
+	if result.Valid && result.FindingID != "" {
+		fmt.Fprintf(t.w, "    Finding: %s", result.FindingID)
+		if classification := classificationText(result); classification != "" {
+			fmt.Fprintf(t.w, " (%s)", classification)
+		}
+		fmt.Fprintln(t.w)
+	}
+	if result.Location != nil {
+		fmt.Fprintf(t.w, "    Found in: %s\n", result.Location)
+	}
+	if stored := storedCredentialText(result); stored != "" {
+		fmt.Fprintf(t.w, "    Stored credential: %s\n", stored)
+	}
+	if len(result.Metadata) > 0 {
+		if metadata, err := json.Marshal(result.Metadata); err == nil {
+			fmt.Fprintf(t.w, "    Metadata: %s\n", metadata)
+		}
+	}
+
+	if t.opts.Verbose {
+		fmt.Fprintf(t.w, "\nDetails:\n")
+		for endpoint, details := range result.Details {
+			fmt.Fprintf(t.w, "  %s: %v\n", endpoint, details)
+		}
+		if len(result.PoC) > 0 {
+			fmt.Fprintf(t.w, "\nProof of concept:\n")
+			for _, endpoint := range pocEndpoints(result) {
+				fmt.Fprintf(t.w, "  %s\n", result.PoC[endpoint])
+			}
+		}
+	}
+
+	return nil
+}
+
+func (t *textWriter) Close() error {
+	return nil
+}
+
+// storedCredentialText describes the secret manager comparison of a valid key, if one was made
+func storedCredentialText(result *validator.ValidationResult) string {
+	details, ok := result.Details[secrets.DetailsKey].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	switch secrets.Status(fmt.Sprint(details["status"])) {
+	case secrets.StatusCurrent:
+		return Red(fmt.Sprintf("matches %v, this is the live credential", details["matched"]))
+	case secrets.StatusRotated:
+		return Yellow("differs from the stored one, the key was rotated but not revoked")
+	default:
+		return "could not be compared with the secret manager"
+	}
+}
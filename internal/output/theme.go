@@ -0,0 +1,60 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Theme names accepted by SetTheme
+const (
+	ThemeDefault = "default"
+	ThemeLight   = "light"
+	ThemeMono    = "mono"
+	ThemeASCII   = "ascii"
+)
+
+// Themes lists the supported theme names
+var Themes = []string{ThemeDefault, ThemeLight, ThemeMono, ThemeASCII}
+
+// theme holds the color formats and symbols used in human-readable output
+type theme struct {
+	red, green, yellow, blue, cyan string
+	maskFill                       string
+}
+
+var themes = map[string]theme{
+	// Bold colors for dark terminal backgrounds
+	ThemeDefault: {
+		red: "\033[1;31m%s\033[0m", green: "\033[1;32m%s\033[0m", yellow: "\033[1;33m%s\033[0m",
+		blue: "\033[1;34m%s\033[0m", cyan: "\033[1;36m%s\033[0m", maskFill: "••••••",
+	},
+	// Plain colors that stay readable on light backgrounds, where yellow and cyan wash out
+	ThemeLight: {
+		red: "\033[31m%s\033[0m", green: "\033[32m%s\033[0m", yellow: "\033[35m%s\033[0m",
+		blue: "\033[34m%s\033[0m", cyan: "\033[34m%s\033[0m", maskFill: "••••••",
+	},
+	// No colors
+	ThemeMono: {
+		red: "%s", green: "%s", yellow: "%s", blue: "%s", cyan: "%s", maskFill: "••••••",
+	},
+	// Nothing but printable ASCII, for terminals and ticket systems that mangle anything else
+	ThemeASCII: {
+		red: "%s", green: "%s", yellow: "%s", blue: "%s", cyan: "%s", maskFill: "******",
+	},
+}
+
+// SetTheme switches the colors and symbols of all human-readable output
+func SetTheme(name string) error {
+	t, ok := themes[name]
+	if !ok {
+		return fmt.Errorf("unknown theme %q (expected %s)", name, strings.Join(Themes, ", "))
+	}
+	Red, Green, Yellow, Blue, Cyan = Color(t.red), Color(t.green), Color(t.yellow), Color(t.blue), Color(t.cyan)
+	maskFill = t.maskFill
+	return nil
+}
+
+// IsPlainTheme reports whether a theme forbids ANSI escape codes, including cursor movement
+func IsPlainTheme(name string) bool {
+	return name == ThemeMono || name == ThemeASCII
+}
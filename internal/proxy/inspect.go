@@ -0,0 +1,68 @@
+package proxy
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Xplo8E/APIKeyzer/internal/input"
+)
+
+// inspectRequest reports the candidate keys in the path, query, headers and body of a request
+func (p *Proxy) inspectRequest(req *http.Request, body []byte) {
+	for _, segment := range strings.Split(req.URL.Path, "/") {
+		p.inspectText(req, "request path", segment, 0)
+	}
+	for name, values := range req.URL.Query() {
+		for _, value := range values {
+			p.inspectText(req, "query parameter "+name, value, 0)
+		}
+	}
+	p.inspectHeaders(req, "request header ", req.Header)
+	p.inspectBody(req, "request body", body)
+}
+
+// inspectResponse reports the candidate keys in the headers and body of a response,
+// such as keys embedded in JavaScript bundles or returned by configuration endpoints
+func (p *Proxy) inspectResponse(req *http.Request, resp *http.Response, body []byte) {
+	p.inspectHeaders(req, "response header ", resp.Header)
+	p.inspectBody(req, "response body", body)
+}
+
+func (p *Proxy) inspectHeaders(req *http.Request, prefix string, header http.Header) {
+	for name, values := range header {
+		for _, value := range values {
+			p.inspectText(req, prefix+name, value, 0)
+		}
+	}
+}
+
+// inspectBody reports candidates line by line, skipping binary content
+func (p *Proxy) inspectBody(req *http.Request, where string, body []byte) {
+	if len(body) == 0 || input.IsBinary(body[:min(len(body), 512)]) {
+		return
+	}
+	for i, line := range strings.Split(string(body), "\n") {
+		p.inspectText(req, where, line, i+1)
+	}
+}
+
+// inspectText reports every candidate in text, located by the request URL without its query string
+func (p *Proxy) inspectText(req *http.Request, where, text string, line int) {
+	location := req.URL.Scheme + "://" + req.URL.Host + req.URL.Path
+	for _, candidate := range input.ExtractCandidates(text) {
+		rec := input.Record{
+			Key:    candidate.Value,
+			Source: location,
+			Metadata: map[string]interface{}{
+				"method":   req.Method,
+				"url":      location,
+				"found_in": where,
+			},
+		}
+		if line > 0 {
+			rec.Line = line
+			rec.Column = candidate.Column
+		}
+		p.found(rec)
+	}
+}
@@ -0,0 +1,327 @@
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/input"
+)
+
+// DefaultMaxBody is how much of each request and response body is inspected
+const DefaultMaxBody = 1 << 20
+
+// dialTimeout bounds connecting to origins and to the upstream proxy
+const dialTimeout = 30 * time.Second
+
+// hopHeaders are meaningful only for a single connection and are not forwarded
+var hopHeaders = []string{
+	"Connection", "Proxy-Connection", "Keep-Alive", "Proxy-Authenticate", "Proxy-Authorization",
+	"Te", "Trailer", "Transfer-Encoding", "Upgrade",
+}
+
+// Proxy is an HTTP proxy that passes traffic through unchanged while looking for keys
+// in URLs, headers and bodies. HTTPS is intercepted when a CA is set and tunnelled otherwise.
+type Proxy struct {
+	transport *http.Transport
+	upstream  *url.URL
+	ca        *CA
+	maxBody   int64
+	found     func(input.Record)
+}
+
+// New creates a proxy reporting every candidate key to found.
+// found is called from the goroutines serving connections, so it must be quick and safe for concurrent use.
+func New(found func(input.Record)) *Proxy {
+	return &Proxy{
+		transport: &http.Transport{
+			DialContext:         (&net.Dialer{Timeout: dialTimeout, KeepAlive: 30 * time.Second}).DialContext,
+			ForceAttemptHTTP2:   true,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+		maxBody: DefaultMaxBody,
+		found:   found,
+	}
+}
+
+// SetUpstream chains all traffic through another HTTP proxy, such as Burp or ZAP
+func (p *Proxy) SetUpstream(upstream string) error {
+	u, err := url.Parse(upstream)
+	if err != nil || u.Scheme != "http" || u.Host == "" {
+		return fmt.Errorf("invalid upstream proxy %q: expected http://host:port", upstream)
+	}
+	p.upstream = u
+	p.transport.Proxy = http.ProxyURL(u)
+	return nil
+}
+
+// SetCA intercepts HTTPS with certificates issued by ca
+func (p *Proxy) SetCA(ca *CA) {
+	p.ca = ca
+}
+
+// SetInsecure disables verification of origin certificates, as needed when the
+// upstream proxy intercepts HTTPS itself
+func (p *Proxy) SetInsecure(insecure bool) {
+	p.transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: insecure}
+}
+
+// SetMaxBody sets how many bytes of each body are inspected; the rest is passed through unread
+func (p *Proxy) SetMaxBody(size int64) {
+	p.maxBody = size
+}
+
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodConnect {
+		p.handleConnect(w, r)
+		return
+	}
+	if !r.URL.IsAbs() {
+		http.Error(w, "this is a proxy: send absolute URLs or CONNECT", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := p.forward(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	removeHopHeaders(resp.Header)
+	for name, values := range resp.Header {
+		w.Header()[name] = values
+	}
+	w.WriteHeader(resp.StatusCode)
+	capture := &limitedBuffer{max: p.maxBody}
+	io.Copy(w, io.TeeReader(resp.Body, capture))
+	p.inspectResponse(r, resp, capture.Bytes())
+}
+
+// forward sends a proxied request to its origin after inspecting it
+func (p *Proxy) forward(r *http.Request) (*http.Response, error) {
+	out := r.Clone(r.Context())
+	out.RequestURI = ""
+	removeHopHeaders(out.Header)
+	// Letting the transport negotiate compression means bodies arrive decompressed and can be inspected
+	out.Header.Del("Accept-Encoding")
+
+	var head []byte
+	if r.Body != nil && r.Body != http.NoBody {
+		var err error
+		head, err = io.ReadAll(io.LimitReader(r.Body, p.maxBody))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		out.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(head), r.Body), r.Body}
+	}
+	p.inspectRequest(r, head)
+
+	return p.transport.RoundTrip(out)
+}
+
+// handleConnect intercepts or tunnels a CONNECT request
+func (p *Proxy) handleConnect(w http.ResponseWriter, r *http.Request) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "connection cannot be hijacked", http.StatusInternalServerError)
+		return
+	}
+
+	var server net.Conn
+	if p.ca == nil {
+		var err error
+		if server, err = p.dial(r.Host); err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+	}
+
+	conn, buffered, err := hijacker.Hijack()
+	if err != nil {
+		if server != nil {
+			server.Close()
+		}
+		return
+	}
+	client := &bufferedConn{Conn: conn, r: buffered.Reader}
+	if _, err := io.WriteString(client, "HTTP/1.1 200 Connection Established\r\n\r\n"); err != nil {
+		client.Close()
+		if server != nil {
+			server.Close()
+		}
+		return
+	}
+
+	if server != nil {
+		tunnel(client, server)
+		return
+	}
+	p.intercept(client, r.Host)
+}
+
+// intercept terminates TLS for host and serves the requests sent over the connection
+func (p *Proxy) intercept(conn net.Conn, host string) {
+	hostname, _, err := net.SplitHostPort(host)
+	if err != nil {
+		hostname = host
+	}
+	tlsConn := tls.Server(conn, &tls.Config{
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			if hello.ServerName != "" {
+				return p.ca.certFor(hello.ServerName)
+			}
+			return p.ca.certFor(hostname)
+		},
+		NextProtos: []string{"http/1.1"},
+	})
+	defer tlsConn.Close()
+	if err := tlsConn.Handshake(); err != nil {
+		return
+	}
+
+	reader := bufio.NewReader(tlsConn)
+	for {
+		req, err := http.ReadRequest(reader)
+		if err != nil {
+			return
+		}
+		req.URL.Scheme = "https"
+		req.URL.Host = req.Host
+		if req.URL.Host == "" {
+			req.URL.Host = host
+		}
+		req.RemoteAddr = conn.RemoteAddr().String()
+
+		resp, err := p.forward(req)
+		if err != nil {
+			resp = errorResponse(req, err)
+		}
+		removeHopHeaders(resp.Header)
+		resp.Proto, resp.ProtoMajor, resp.ProtoMinor = "HTTP/1.1", 1, 1
+		capture := &limitedBuffer{max: p.maxBody}
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.TeeReader(resp.Body, capture), resp.Body}
+
+		writeErr := resp.Write(tlsConn)
+		resp.Body.Close()
+		p.inspectResponse(req, resp, capture.Bytes())
+		if writeErr != nil || req.Close || resp.Close {
+			return
+		}
+	}
+}
+
+// dial connects to host directly or through a CONNECT tunnel of the upstream proxy
+func (p *Proxy) dial(host string) (net.Conn, error) {
+	if p.upstream == nil {
+		return net.DialTimeout("tcp", host, dialTimeout)
+	}
+
+	conn, err := net.DialTimeout("tcp", p.upstream.Host, dialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach upstream proxy: %w", err)
+	}
+	request := fmt.Sprintf("CONNECT %s HTTP/1.1\r\nHost: %s\r\n", host, host)
+	if user := p.upstream.User; user != nil {
+		password, _ := user.Password()
+		request += "Proxy-Authorization: Basic " + base64.StdEncoding.EncodeToString([]byte(user.Username()+":"+password)) + "\r\n"
+	}
+	if _, err := io.WriteString(conn, request+"\r\n"); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to reach upstream proxy: %w", err)
+	}
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("invalid response from upstream proxy: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("upstream proxy refused CONNECT %s: %s", host, resp.Status)
+	}
+	return &bufferedConn{Conn: conn, r: reader}, nil
+}
+
+// tunnel copies bytes between two connections until either side closes
+func tunnel(client, server net.Conn) {
+	done := make(chan struct{})
+	go func() {
+		io.Copy(server, client)
+		server.Close()
+		close(done)
+	}()
+	io.Copy(client, server)
+	client.Close()
+	<-done
+}
+
+// errorResponse answers an intercepted request that could not be forwarded
+func errorResponse(req *http.Request, err error) *http.Response {
+	body := err.Error() + "\n"
+	return &http.Response{
+		StatusCode:    http.StatusBadGateway,
+		Status:        "502 Bad Gateway",
+		Header:        http.Header{"Content-Type": {"text/plain; charset=utf-8"}},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+		Close:         true,
+	}
+}
+
+// removeHopHeaders deletes the hop-by-hop headers, including those listed in Connection
+func removeHopHeaders(header http.Header) {
+	for _, value := range header.Values("Connection") {
+		for _, name := range strings.Split(value, ",") {
+			header.Del(strings.TrimSpace(name))
+		}
+	}
+	for _, name := range hopHeaders {
+		header.Del(name)
+	}
+}
+
+// bufferedConn reads through the buffer that may hold bytes read past a handshake
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+// limitedBuffer keeps the first max bytes written to it and discards the rest
+type limitedBuffer struct {
+	bytes.Buffer
+	max int64
+}
+
+func (b *limitedBuffer) Write(data []byte) (int, error) {
+	if room := b.max - int64(b.Len()); room > 0 {
+		if int64(len(data)) > room {
+			b.Buffer.Write(data[:room])
+		} else {
+			b.Buffer.Write(data)
+		}
+	}
+	return len(data), nil
+}
@@ -0,0 +1,233 @@
+package queue
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// natsInfo is the part of the server's INFO message the client needs
+type natsInfo struct {
+	TLSRequired bool `json:"tls_required"`
+	MaxPayload  int  `json:"max_payload"`
+}
+
+// natsConn speaks the NATS client protocol for a single subject
+type natsConn struct {
+	conn       net.Conn
+	subject    string
+	group      string
+	maxPayload int
+
+	writeMu   sync.Mutex
+	subscribe sync.Once
+	messages  chan []byte
+	done      chan struct{} // closed when the connection fails
+	closed    chan struct{} // closed by Close
+	readErr   error
+	pongs     chan struct{}
+	closeOnce sync.Once
+}
+
+func dialNATS(ctx context.Context, u *url.URL, group string) (*natsConn, error) {
+	subject := strings.TrimPrefix(u.Path, "/")
+	if subject == "" {
+		return nil, errors.New("no NATS subject in queue URL, expected nats://host/subject")
+	}
+	address := u.Host
+	if u.Port() == "" {
+		address = net.JoinHostPort(u.Hostname(), "4222")
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	// The server greets with INFO before anything else, including the TLS handshake
+	r := bufio.NewReader(conn)
+	line, err := r.ReadString('\n')
+	if err != nil || !strings.HasPrefix(line, "INFO ") {
+		conn.Close()
+		return nil, fmt.Errorf("unexpected NATS greeting %q: %v", strings.TrimSpace(line), err)
+	}
+	var info natsInfo
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(strings.TrimSpace(line), "INFO ")), &info); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("invalid NATS INFO: %w", err)
+	}
+	if info.TLSRequired {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: u.Hostname()})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("TLS handshake with NATS failed: %w", err)
+		}
+		conn = tlsConn
+		r = bufio.NewReader(conn)
+	}
+
+	options := map[string]interface{}{"verbose": false, "pedantic": false, "name": "apikeyzer", "lang": "go", "protocol": 1}
+	if u.User != nil {
+		if password, ok := u.User.Password(); ok {
+			options["user"], options["pass"] = u.User.Username(), password
+		} else {
+			options["auth_token"] = u.User.Username()
+		}
+	}
+	connect, err := json.Marshal(options)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\nPING\r\n", connect); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to send NATS CONNECT: %w", err)
+	}
+	// Authentication errors arrive as -ERR instead of the PONG
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("NATS connection failed: %w", err)
+		}
+		line = strings.TrimSpace(line)
+		if line == "PONG" {
+			break
+		}
+		if strings.HasPrefix(line, "-ERR") {
+			conn.Close()
+			return nil, fmt.Errorf("NATS refused the connection: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+	conn.SetDeadline(time.Time{})
+
+	c := &natsConn{
+		conn:       conn,
+		subject:    subject,
+		group:      group,
+		maxPayload: info.MaxPayload,
+		messages:   make(chan []byte, 64),
+		done:       make(chan struct{}),
+		closed:     make(chan struct{}),
+		pongs:      make(chan struct{}, 1),
+	}
+	go c.readLoop(r)
+	return c, nil
+}
+
+// readLoop delivers messages and answers the server's keepalive pings until the connection fails
+func (c *natsConn) readLoop(r *bufio.Reader) {
+	defer close(c.done)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			c.readErr = fmt.Errorf("NATS connection lost: %w", err)
+			return
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PING":
+			c.write("PONG\r\n")
+		case line == "PONG":
+			select {
+			case c.pongs <- struct{}{}:
+			default:
+			}
+		case strings.HasPrefix(line, "MSG "):
+			// MSG <subject> <sid> [reply-to] <size>
+			fields := strings.Fields(line)
+			size, err := strconv.Atoi(fields[len(fields)-1])
+			if err != nil || size < 0 {
+				c.readErr = fmt.Errorf("invalid NATS message header %q", line)
+				return
+			}
+			payload := make([]byte, size+2)
+			if _, err := io.ReadFull(r, payload); err != nil {
+				c.readErr = fmt.Errorf("NATS connection lost: %w", err)
+				return
+			}
+			select {
+			case c.messages <- payload[:size]:
+			case <-c.closed:
+				return
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			c.readErr = fmt.Errorf("NATS error: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+			return
+		}
+	}
+}
+
+func (c *natsConn) Receive(ctx context.Context) ([]byte, error) {
+	var err error
+	c.subscribe.Do(func() {
+		sub := "SUB " + c.subject + " 1\r\n"
+		if c.group != "" {
+			sub = "SUB " + c.subject + " " + c.group + " 1\r\n"
+		}
+		err = c.write(sub)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case message := <-c.messages:
+		return message, nil
+	case <-c.done:
+		return nil, c.readErr
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *natsConn) Publish(ctx context.Context, message []byte) error {
+	if c.maxPayload > 0 && len(message) > c.maxPayload {
+		return fmt.Errorf("message of %d bytes exceeds the NATS max payload of %d", len(message), c.maxPayload)
+	}
+	select {
+	case <-c.done:
+		return c.readErr
+	default:
+	}
+	return c.write(fmt.Sprintf("PUB %s %d\r\n%s\r\n", c.subject, len(message), message))
+}
+
+func (c *natsConn) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		// Flush pending publishes: the server answers the PING only after processing them
+		if c.write("PING\r\n") == nil {
+			select {
+			case <-c.pongs:
+			case <-c.done:
+			case <-time.After(5 * time.Second):
+			}
+		}
+		err = c.conn.Close()
+	})
+	return err
+}
+
+func (c *natsConn) write(data string) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	if _, err := io.WriteString(c.conn, data); err != nil {
+		return fmt.Errorf("failed to write to NATS: %w", err)
+	}
+	return nil
+}
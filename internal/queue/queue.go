@@ -0,0 +1,56 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// Conn is a connection to a Redis list or a NATS subject. Delivery is at most once:
+// a message received and then lost to a crash is not redelivered.
+type Conn interface {
+	// Receive blocks until a message arrives or ctx is done
+	Receive(ctx context.Context) ([]byte, error)
+
+	// Publish appends a message to the list or publishes it on the subject; it is safe for concurrent use
+	Publish(ctx context.Context, message []byte) error
+
+	Close() error
+}
+
+// Dial connects to the list or subject named by target:
+//
+//	redis://[:password@]host[:port]/[db/]list    a Redis list (rediss:// for TLS)
+//	nats://[user:password@]host[:port]/subject   a NATS subject (nats://token@host for token auth)
+//
+// group is the NATS queue group that shares the messages of the subject between workers;
+// Redis lists are always shared.
+func Dial(ctx context.Context, target, group string) (Conn, error) {
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid queue %q: expected redis://host/list or nats://host/subject", target)
+	}
+	var conn Conn
+	switch u.Scheme {
+	case "redis", "rediss":
+		conn, err = dialRedis(ctx, u)
+	case "nats":
+		conn, err = dialNATS(ctx, u, group)
+	default:
+		return nil, fmt.Errorf("unsupported queue %q: expected redis://, rediss:// or nats://", target)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// Name returns target without its credentials, to name the queue in logs and results
+func Name(target string) string {
+	u, err := url.Parse(target)
+	if err != nil {
+		return target
+	}
+	u.User = nil
+	return u.String()
+}
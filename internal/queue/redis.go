@@ -0,0 +1,181 @@
+package queue
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// redisPollSeconds bounds each BLPOP so a cancelled context is noticed promptly
+const redisPollSeconds = 1
+
+// redisConn speaks just enough RESP to pop from and push to a list
+type redisConn struct {
+	mu   sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+	list string
+}
+
+func dialRedis(ctx context.Context, u *url.URL) (*redisConn, error) {
+	address := u.Host
+	if u.Port() == "" {
+		address = net.JoinHostPort(u.Hostname(), "6379")
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+	if u.Scheme == "rediss" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: u.Hostname()})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("TLS handshake with Redis failed: %w", err)
+		}
+		conn = tlsConn
+	}
+	c := &redisConn{conn: conn, r: bufio.NewReader(conn)}
+
+	// The path is /list or /db/list; list names may contain slashes after the db
+	path := strings.TrimPrefix(u.Path, "/")
+	db := ""
+	if first, rest, ok := strings.Cut(path, "/"); ok {
+		if _, err := strconv.Atoi(first); err == nil {
+			db, path = first, rest
+		}
+	}
+	if path == "" {
+		conn.Close()
+		return nil, errors.New("no Redis list in queue URL, expected redis://host/[db/]list")
+	}
+	c.list = path
+
+	if u.User != nil {
+		args := []string{"AUTH"}
+		password, hasPassword := u.User.Password()
+		switch {
+		case hasPassword && u.User.Username() != "":
+			args = append(args, u.User.Username(), password)
+		case hasPassword:
+			args = append(args, password)
+		default:
+			args = append(args, u.User.Username())
+		}
+		if _, err := c.do(10*time.Second, args...); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("Redis authentication failed: %w", err)
+		}
+	}
+	if db != "" {
+		if _, err := c.do(10*time.Second, "SELECT", db); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to select Redis database %s: %w", db, err)
+		}
+	}
+	return c, nil
+}
+
+func (c *redisConn) Receive(ctx context.Context) ([]byte, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		reply, err := c.do((redisPollSeconds+10)*time.Second, "BLPOP", c.list, strconv.Itoa(redisPollSeconds))
+		if err != nil {
+			return nil, err
+		}
+		// A nil reply means the poll timed out with the list empty
+		if popped, ok := reply.([]interface{}); ok && len(popped) == 2 {
+			if message, ok := popped[1].([]byte); ok {
+				return message, nil
+			}
+		}
+	}
+}
+
+func (c *redisConn) Publish(ctx context.Context, message []byte) error {
+	_, err := c.do(10*time.Second, "RPUSH", c.list, string(message))
+	return err
+}
+
+func (c *redisConn) Close() error {
+	return c.conn.Close()
+}
+
+// do sends a command and reads its reply; Redis error replies are returned as errors
+func (c *redisConn) do(timeout time.Duration, args ...string) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	c.conn.SetDeadline(time.Now().Add(timeout))
+	if _, err := io.WriteString(c.conn, b.String()); err != nil {
+		return nil, fmt.Errorf("failed to send Redis command: %w", err)
+	}
+	return c.readReply()
+}
+
+// readReply reads a RESP2 reply: simple strings, errors, integers, bulk strings and arrays
+func (c *redisConn) readReply() (interface{}, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Redis reply: %w", err)
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("empty Redis reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, fmt.Errorf("Redis error: %s", line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid Redis bulk length %q", line)
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(c.r, data); err != nil {
+			return nil, fmt.Errorf("failed to read Redis reply: %w", err)
+		}
+		return data[:size], nil
+	case '*':
+		count, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid Redis array length %q", line)
+		}
+		if count < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			if items[i], err = c.readReply(); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("unexpected Redis reply %q", line)
+}
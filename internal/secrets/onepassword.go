@@ -0,0 +1,30 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// readOnePassword reads an op:// secret reference with the 1Password CLI, which must be
+// signed in or have OP_SERVICE_ACCOUNT_TOKEN set
+func readOnePassword(ctx context.Context, ref string) (string, error) {
+	path, err := exec.LookPath("op")
+	if err != nil {
+		return "", errors.New("the 1Password CLI (op) is not installed")
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, "read", "--no-newline", ref)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("op read failed: %s", message)
+		}
+		return "", fmt.Errorf("op read failed: %w", err)
+	}
+	return stdout.String(), nil
+}
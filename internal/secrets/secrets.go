@@ -0,0 +1,181 @@
+package secrets
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Status is the outcome of comparing a key with the credentials stored for its service
+type Status string
+
+const (
+	StatusCurrent Status = "current" // the key is the credential currently stored
+	StatusRotated Status = "rotated" // a different credential is stored, so the key should have been revoked
+	StatusUnknown Status = "unknown" // no stored credential could be read
+)
+
+// DetailsKey is the key of the comparison in the details of validation results
+const DetailsKey = "secret_manager"
+
+// Result is the comparison of a key with the references configured for its service
+type Result struct {
+	Status  Status
+	Matched string   // reference holding the key, for StatusCurrent
+	Checked []string // references read successfully
+	Errors  []string // references that could not be read, with the reason
+}
+
+// Details returns the result as the JSON-like map reported with validation results.
+// Stored values are never included.
+func (r *Result) Details() map[string]interface{} {
+	details := map[string]interface{}{"status": string(r.Status)}
+	if r.Matched != "" {
+		details["matched"] = r.Matched
+	}
+	if len(r.Checked) > 0 {
+		details["checked"] = r.Checked
+	}
+	if len(r.Errors) > 0 {
+		details["errors"] = r.Errors
+	}
+	return details
+}
+
+// Checker compares valid keys with the credentials stored in secret managers.
+// References are read once per run and cached.
+type Checker struct {
+	refs   map[string][]string
+	client *http.Client
+
+	mu    sync.Mutex
+	cache map[string]fetched
+}
+
+// fetched is a cached read of a reference
+type fetched struct {
+	value string
+	err   error
+}
+
+// NewChecker creates a checker for references by service ID, given as ID=REF pairs:
+//
+//	vault://secret/data/prod/maps#api_key   Vault (VAULT_ADDR, VAULT_TOKEN)
+//	aws-sm://prod/maps#api_key              AWS Secrets Manager (AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY)
+//	op://Production/Maps/credential         1Password, read with the op CLI
+func NewChecker(pairs []string) (*Checker, error) {
+	c := &Checker{
+		refs:   make(map[string][]string),
+		client: &http.Client{Timeout: 30 * time.Second},
+		cache:  make(map[string]fetched),
+	}
+	for _, pair := range pairs {
+		id, ref, ok := strings.Cut(pair, "=")
+		id, ref = strings.TrimSpace(id), strings.TrimSpace(ref)
+		if !ok || id == "" || ref == "" {
+			return nil, fmt.Errorf("invalid secret reference %q: expected SERVICE-ID=REFERENCE", pair)
+		}
+		if _, _, err := parseRef(ref); err != nil {
+			return nil, err
+		}
+		c.refs[id] = append(c.refs[id], ref)
+	}
+	return c, nil
+}
+
+// Has reports whether references are configured for the service ID
+func (c *Checker) Has(id string) bool {
+	return len(c.refs[id]) > 0
+}
+
+// Check compares key with every reference configured for the service ID
+func (c *Checker) Check(ctx context.Context, id, key string) *Result {
+	result := &Result{Status: StatusUnknown}
+	for _, ref := range c.refs[id] {
+		value, err := c.read(ctx, ref)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", ref, err))
+			continue
+		}
+		result.Checked = append(result.Checked, ref)
+		if subtle.ConstantTimeCompare([]byte(strings.TrimSpace(value)), []byte(key)) == 1 {
+			result.Status = StatusCurrent
+			result.Matched = ref
+			return result
+		}
+		result.Status = StatusRotated
+	}
+	return result
+}
+
+// read returns the value of a reference, reading it on first use.
+// The lock is held while reading so concurrent workers do not read the same reference twice.
+func (c *Checker) read(ctx context.Context, ref string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached, ok := c.cache[ref]; ok {
+		return cached.value, cached.err
+	}
+
+	scheme, location, _ := parseRef(ref)
+	var value string
+	var err error
+	switch scheme {
+	case "vault":
+		value, err = c.readVault(ctx, location)
+	case "aws-sm":
+		value, err = c.readAWS(ctx, location)
+	case "op":
+		value, err = readOnePassword(ctx, ref)
+	}
+	c.cache[ref] = fetched{value: value, err: err}
+	return value, err
+}
+
+// parseRef splits a reference into its scheme and the location that follows "://"
+func parseRef(ref string) (string, string, error) {
+	scheme, location, ok := strings.Cut(ref, "://")
+	if !ok || location == "" {
+		return "", "", fmt.Errorf("invalid secret reference %q: expected vault://, aws-sm:// or op://", ref)
+	}
+	switch scheme {
+	case "vault", "aws-sm", "op":
+		return scheme, location, nil
+	}
+	return "", "", fmt.Errorf("unsupported secret manager %q in %q: expected vault, aws-sm or op", scheme, ref)
+}
+
+// selectField returns a field of a secret holding several, or the only value there is
+func selectField(fields map[string]interface{}, field string) (string, error) {
+	if field == "" {
+		if len(fields) != 1 {
+			return "", fmt.Errorf("secret has %d fields, select one with #field", len(fields))
+		}
+		for _, value := range fields {
+			return fmt.Sprint(value), nil
+		}
+	}
+	value, ok := fields[field]
+	if !ok {
+		return "", fmt.Errorf("secret has no field %q", field)
+	}
+	return fmt.Sprint(value), nil
+}
+
+// selectJSONField selects a field from a secret string holding a JSON object, as AWS secrets
+// often do; the string is used whole when no field is asked for
+func selectJSONField(secret, field string) (string, error) {
+	if field == "" {
+		return secret, nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(secret), &fields); err != nil {
+		return "", fmt.Errorf("secret is not a JSON object, so #%s cannot be selected", field)
+	}
+	return selectField(fields, field)
+}
@@ -0,0 +1,134 @@
+package sink
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/output"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// riskColors are the attachment/embed colors used per risk level
+var riskColors = map[validator.RiskLevel]int{
+	validator.RiskLevelHigh:   0xD92D20,
+	validator.RiskLevelMedium: 0xF79009,
+	validator.RiskLevelLow:    0x2E90FA,
+}
+
+// ChatNotifier sends an alert to a Slack or Discord incoming webhook for every valid key.
+// It implements output.Writer. Keys are always masked since chat channels are widely readable.
+type ChatNotifier struct {
+	url     string
+	version string
+	client  *http.Client
+	payload func(alert chatAlert) interface{}
+}
+
+// chatAlert holds the fields shown in a chat notification
+type chatAlert struct {
+	Title     string
+	Key       string
+	Service   string
+	Risk      validator.RiskLevel
+	Location  string
+	FindingID string
+	APIs      string
+	Color     int
+}
+
+// NewSlackNotifier creates a notifier for a Slack incoming webhook URL
+func NewSlackNotifier(url, version string) *ChatNotifier {
+	return newChatNotifier(url, version, slackPayload)
+}
+
+// NewDiscordNotifier creates a notifier for a Discord webhook URL
+func NewDiscordNotifier(url, version string) *ChatNotifier {
+	return newChatNotifier(url, version, discordPayload)
+}
+
+func newChatNotifier(url, version string, payload func(chatAlert) interface{}) *ChatNotifier {
+	return &ChatNotifier{
+		url:     url,
+		version: version,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		payload: payload,
+	}
+}
+
+func (c *ChatNotifier) WriteResult(result *validator.ValidationResult) error {
+	if !result.Valid {
+		return nil
+	}
+
+	alert := chatAlert{
+		Title:     fmt.Sprintf("Valid %s found", result.Service),
+		Key:       output.MaskKey(result.Key),
+		Service:   result.Service,
+		Risk:      result.RiskLevel,
+		Location:  "unknown",
+		FindingID: result.FindingID,
+		APIs:      strings.Join(result.Permissions, "\n"),
+		Color:     riskColors[result.RiskLevel],
+	}
+	if result.Location != nil {
+		alert.Location = result.Location.String()
+	}
+
+	body, err := json.Marshal(c.payload(alert))
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+	_, err = postJSON(c.client, c.url, body, c.version, nil)
+	return err
+}
+
+func (c *ChatNotifier) Close() error {
+	return nil
+}
+
+// slackPayload renders an alert as a Slack message with a colored attachment
+func slackPayload(alert chatAlert) interface{} {
+	fields := []map[string]interface{}{
+		{"title": "Service", "value": alert.Service, "short": true},
+		{"title": "Risk", "value": string(alert.Risk), "short": true},
+		{"title": "Key", "value": "`" + alert.Key + "`", "short": true},
+		{"title": "Finding", "value": alert.FindingID, "short": true},
+		{"title": "Location", "value": alert.Location, "short": false},
+	}
+	if alert.APIs != "" {
+		fields = append(fields, map[string]interface{}{"title": "Vulnerable APIs", "value": alert.APIs, "short": false})
+	}
+	return map[string]interface{}{
+		"text": ":rotating_light: *" + alert.Title + "*",
+		"attachments": []map[string]interface{}{{
+			"color":  fmt.Sprintf("#%06X", alert.Color),
+			"fields": fields,
+			"footer": "APIKeyzer",
+		}},
+	}
+}
+
+// discordPayload renders an alert as a Discord embed
+func discordPayload(alert chatAlert) interface{} {
+	fields := []map[string]interface{}{
+		{"name": "Service", "value": alert.Service, "inline": true},
+		{"name": "Risk", "value": string(alert.Risk), "inline": true},
+		{"name": "Key", "value": "`" + alert.Key + "`", "inline": true},
+		{"name": "Finding", "value": alert.FindingID, "inline": true},
+		{"name": "Location", "value": alert.Location, "inline": false},
+	}
+	if alert.APIs != "" {
+		fields = append(fields, map[string]interface{}{"name": "Vulnerable APIs", "value": alert.APIs, "inline": false})
+	}
+	return map[string]interface{}{
+		"username": "APIKeyzer",
+		"embeds": []map[string]interface{}{{
+			"title":  "🚨 " + alert.Title,
+			"color":  alert.Color,
+			"fields": fields,
+		}},
+	}
+}
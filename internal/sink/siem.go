@@ -0,0 +1,187 @@
+package sink
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// batchSize is how many results are buffered before they are shipped in one request
+const batchSize = 100
+
+// esDocument is a result as indexed in Elasticsearch
+type esDocument struct {
+	Timestamp time.Time `json:"@timestamp"`
+	*validator.ValidationResult
+}
+
+// Elasticsearch ships every result to an index through the bulk API. It implements output.Writer.
+type Elasticsearch struct {
+	url     string
+	index   string
+	apiKey  string
+	version string
+	client  *http.Client
+	buf     bytes.Buffer
+	pending int
+}
+
+// NewElasticsearch creates a sink for the cluster at baseURL. Credentials may be embedded in
+// the URL for basic auth, or apiKey may hold an Elasticsearch API key.
+func NewElasticsearch(baseURL, index, apiKey, version string) *Elasticsearch {
+	return &Elasticsearch{
+		url:     strings.TrimSuffix(baseURL, "/") + "/_bulk",
+		index:   index,
+		apiKey:  apiKey,
+		version: version,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (e *Elasticsearch) WriteResult(result *validator.ValidationResult) error {
+	action := map[string]map[string]string{"index": {"_index": e.index}}
+	if err := json.NewEncoder(&e.buf).Encode(action); err != nil {
+		return fmt.Errorf("failed to encode bulk action: %w", err)
+	}
+	if err := json.NewEncoder(&e.buf).Encode(esDocument{Timestamp: result.ValidatedAt, ValidationResult: result}); err != nil {
+		return fmt.Errorf("failed to encode document: %w", err)
+	}
+
+	e.pending++
+	if e.pending >= batchSize {
+		return e.flush()
+	}
+	return nil
+}
+
+func (e *Elasticsearch) Close() error {
+	return e.flush()
+}
+
+// flush sends buffered documents; the bulk API reports per-item failures in a 200 response
+func (e *Elasticsearch) flush() error {
+	if e.pending == 0 {
+		return nil
+	}
+	body := append([]byte(nil), e.buf.Bytes()...)
+	e.buf.Reset()
+	e.pending = 0
+
+	headers := map[string]string{"Content-Type": "application/x-ndjson"}
+	if e.apiKey != "" {
+		headers["Authorization"] = "ApiKey " + e.apiKey
+	}
+	content, err := postJSON(e.client, e.url, body, e.version, headers)
+	if err != nil {
+		return fmt.Errorf("elasticsearch: %w", err)
+	}
+
+	var resp struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			Error *struct {
+				Reason string `json:"reason"`
+			} `json:"error"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(content, &resp); err == nil && resp.Errors {
+		for _, item := range resp.Items {
+			for _, op := range item {
+				if op.Error != nil {
+					return fmt.Errorf("elasticsearch rejected document: %s", op.Error.Reason)
+				}
+			}
+		}
+		return fmt.Errorf("elasticsearch rejected documents")
+	}
+	return nil
+}
+
+// splunkEvent is the HTTP Event Collector envelope
+type splunkEvent struct {
+	Time       float64                     `json:"time"`
+	Host       string                      `json:"host,omitempty"`
+	Source     string                      `json:"source"`
+	Sourcetype string                      `json:"sourcetype"`
+	Index      string                      `json:"index,omitempty"`
+	Event      *validator.ValidationResult `json:"event"`
+}
+
+// SplunkHEC ships every result to a Splunk HTTP Event Collector. It implements output.Writer.
+type SplunkHEC struct {
+	url        string
+	token      string
+	index      string
+	sourcetype string
+	host       string
+	version    string
+	client     *http.Client
+	buf        bytes.Buffer
+	pending    int
+}
+
+// NewSplunkHEC creates a sink for the collector at baseURL. An empty index uses the token's
+// default index; an empty sourcetype defaults to "apikeyzer".
+func NewSplunkHEC(baseURL, token, index, sourcetype, host, version string) *SplunkHEC {
+	url := strings.TrimSuffix(baseURL, "/")
+	if !strings.Contains(url, "/services/collector") {
+		url += "/services/collector/event"
+	}
+	if sourcetype == "" {
+		sourcetype = "apikeyzer"
+	}
+	return &SplunkHEC{
+		url:        url,
+		token:      token,
+		index:      index,
+		sourcetype: sourcetype,
+		host:       host,
+		version:    version,
+		client:     &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (s *SplunkHEC) WriteResult(result *validator.ValidationResult) error {
+	event := splunkEvent{
+		Time:       float64(result.ValidatedAt.UnixNano()) / 1e9,
+		Host:       s.host,
+		Source:     "apikeyzer",
+		Sourcetype: s.sourcetype,
+		Index:      s.index,
+		Event:      result,
+	}
+	// HEC accepts several events concatenated in one request body
+	if err := json.NewEncoder(&s.buf).Encode(event); err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	s.pending++
+	if s.pending >= batchSize {
+		return s.flush()
+	}
+	return nil
+}
+
+func (s *SplunkHEC) Close() error {
+	return s.flush()
+}
+
+func (s *SplunkHEC) flush() error {
+	if s.pending == 0 {
+		return nil
+	}
+	body := append([]byte(nil), s.buf.Bytes()...)
+	s.buf.Reset()
+	s.pending = 0
+
+	headers := map[string]string{"Authorization": "Splunk " + s.token}
+	if _, err := postJSON(s.client, s.url, body, s.version, headers); err != nil {
+		return fmt.Errorf("splunk: %w", err)
+	}
+	return nil
+}
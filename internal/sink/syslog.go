@@ -0,0 +1,119 @@
+package sink
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/output"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// syslogFacility is local0; findings are tagged with it so they can be routed separately
+const syslogFacility = 16
+
+// syslogEnterpriseID qualifies the structured data ID (the IANA example number)
+const syslogEnterpriseID = "32473"
+
+// localSyslogSockets are tried in order for local delivery
+var localSyslogSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// Syslog emits every valid key as an RFC 5424 message. It implements output.Writer.
+// Keys are always masked since forwarded logs are retained and widely readable.
+type Syslog struct {
+	conn     net.Conn
+	stream   bool // TCP connections need RFC 6587 octet-counting framing
+	hostname string
+	pid      int
+}
+
+// NewSyslog connects to target: "local" for the local syslog socket, or
+// udp://host:port, tcp://host:port (port defaults to 514)
+func NewSyslog(target string) (*Syslog, error) {
+	s := &Syslog{pid: os.Getpid()}
+	s.hostname, _ = os.Hostname()
+	if s.hostname == "" {
+		s.hostname = "-"
+	}
+
+	if target == "local" {
+		for _, path := range localSyslogSockets {
+			for _, network := range []string{"unixgram", "unix"} {
+				if conn, err := net.Dial(network, path); err == nil {
+					s.conn = conn
+					return s, nil
+				}
+			}
+		}
+		return nil, fmt.Errorf("no local syslog socket found")
+	}
+
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "udp" && u.Scheme != "tcp") || u.Host == "" {
+		return nil, fmt.Errorf("invalid syslog target %q (expected local, udp://host:port or tcp://host:port)", target)
+	}
+	address := u.Host
+	if u.Port() == "" {
+		address = net.JoinHostPort(u.Hostname(), "514")
+	}
+
+	conn, err := net.DialTimeout(u.Scheme, address, 10*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to syslog %s: %w", address, err)
+	}
+	s.conn = conn
+	s.stream = u.Scheme == "tcp"
+	return s, nil
+}
+
+func (s *Syslog) WriteResult(result *validator.ValidationResult) error {
+	if !result.Valid {
+		return nil
+	}
+
+	location := "-"
+	if result.Location != nil {
+		location = result.Location.String()
+	}
+
+	structured := fmt.Sprintf(`[apikeyzer@%s finding_id="%s" service="%s" risk="%s" key="%s" location="%s"]`,
+		syslogEnterpriseID, sdEscape(result.FindingID), sdEscape(result.Service), sdEscape(string(result.RiskLevel)),
+		sdEscape(output.MaskKey(result.Key)), sdEscape(location))
+	text := fmt.Sprintf("Valid %s found (risk: %s) at %s", result.Service, result.RiskLevel, location)
+
+	msg := fmt.Sprintf("<%d>1 %s %s apikeyzer %d finding %s %s",
+		syslogFacility*8+syslogSeverity(result.RiskLevel), result.ValidatedAt.UTC().Format(time.RFC3339Nano),
+		s.hostname, s.pid, structured, text)
+	if s.stream {
+		msg = fmt.Sprintf("%d %s", len(msg), msg)
+	}
+
+	if _, err := s.conn.Write([]byte(msg)); err != nil {
+		return fmt.Errorf("failed to write to syslog: %w", err)
+	}
+	return nil
+}
+
+func (s *Syslog) Close() error {
+	return s.conn.Close()
+}
+
+// syslogSeverity maps a risk level to a syslog severity
+func syslogSeverity(risk validator.RiskLevel) int {
+	switch risk {
+	case validator.RiskLevelHigh:
+		return 2 // critical
+	case validator.RiskLevelMedium:
+		return 4 // warning
+	default:
+		return 5 // notice
+	}
+}
+
+// sdEscape escapes a structured data parameter value as required by RFC 5424
+func sdEscape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(value)
+}
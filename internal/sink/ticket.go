@@ -0,0 +1,361 @@
+package sink
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/output"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// ticketLabel tags every filed ticket so they can be found in the tracker
+const ticketLabel = "apikeyzer"
+
+// ticket is the content filed for a valid key. The key is always masked since tickets are
+// widely readable and outlive the key's rotation.
+type ticket struct {
+	FindingID   string
+	Risk        validator.RiskLevel
+	Summary     string
+	Fields      [][2]string
+	PoC         []string
+	Remediation string
+}
+
+func newTicket(result *validator.ValidationResult) ticket {
+	masked := output.MaskKey(result.Key)
+	t := ticket{
+		FindingID:   result.FindingID,
+		Risk:        result.RiskLevel,
+		Summary:     fmt.Sprintf("Exposed valid %s %s (%s)", result.Service, masked, result.FindingID),
+		Remediation: output.Remediation(result.Service),
+	}
+
+	location := "unknown"
+	if result.Location != nil {
+		location = result.Location.String()
+	}
+	t.Fields = [][2]string{
+		{"Service", result.Service},
+		{"Risk", string(result.RiskLevel)},
+		{"Key", masked},
+		{"Finding", result.FindingID},
+		{"Location", location},
+		{"Validated at", result.ValidatedAt.UTC().Format(time.RFC3339)},
+	}
+	if len(result.Permissions) > 0 {
+		t.Fields = append(t.Fields, [2]string{"Vulnerable APIs", strings.Join(result.Permissions, ", ")})
+	}
+
+	endpoints := make([]string, 0, len(result.PoC))
+	for endpoint := range result.PoC {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Strings(endpoints)
+	for _, endpoint := range endpoints {
+		t.PoC = append(t.PoC, strings.ReplaceAll(result.PoC[endpoint], result.Key, "<API_KEY>"))
+	}
+	return t
+}
+
+// text renders the ticket as plain text
+func (t ticket) text() string {
+	var b strings.Builder
+	b.WriteString("APIKeyzer confirmed that an exposed API key is valid.\n\n")
+	for _, field := range t.Fields {
+		fmt.Fprintf(&b, "%s: %s\n", field[0], field[1])
+	}
+	if len(t.PoC) > 0 {
+		b.WriteString("\nEvidence (replace <API_KEY> with the key to reproduce):\n")
+		for _, command := range t.PoC {
+			b.WriteString(command + "\n")
+		}
+	}
+	b.WriteString("\nRemediation:\n" + t.Remediation + "\n")
+	return b.String()
+}
+
+// ticketTracker files tickets in an issue tracker, one per finding ID
+type ticketTracker interface {
+	// find returns the reference of an open ticket for a finding ID, or "" if there is none
+	find(findingID string) (string, error)
+	create(t ticket) (string, error)
+}
+
+// TicketFiler opens a ticket in Jira Cloud or ServiceNow for every valid key, unless an open
+// ticket for its finding ID already exists. It implements output.Writer.
+type TicketFiler struct {
+	tracker ticketTracker
+	filed   map[string]bool
+}
+
+// NewJira creates a filer for Jira Cloud at baseURL, authenticating with an email and API token.
+// Issues are created in project with issueType and labelled with the finding ID.
+func NewJira(baseURL, project, issueType, email, token, version string) *TicketFiler {
+	return newTicketFiler(&jira{
+		url:       strings.TrimSuffix(baseURL, "/"),
+		project:   project,
+		issueType: issueType,
+		headers:   map[string]string{"Authorization": basicAuth(email, token)},
+		version:   version,
+		client:    &http.Client{Timeout: 30 * time.Second},
+	})
+}
+
+// NewServiceNow creates a filer for the ServiceNow instance at instanceURL, opening incidents
+// whose correlation ID is the finding ID
+func NewServiceNow(instanceURL, username, password, version string) *TicketFiler {
+	return newTicketFiler(&serviceNow{
+		url:     strings.TrimSuffix(instanceURL, "/"),
+		headers: map[string]string{"Authorization": basicAuth(username, password)},
+		version: version,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	})
+}
+
+func newTicketFiler(tracker ticketTracker) *TicketFiler {
+	return &TicketFiler{tracker: tracker, filed: make(map[string]bool)}
+}
+
+func (f *TicketFiler) WriteResult(result *validator.ValidationResult) error {
+	// A key found in several places is one finding and gets one ticket
+	if !result.Valid || f.filed[result.FindingID] {
+		return nil
+	}
+
+	existing, err := f.tracker.find(result.FindingID)
+	if err != nil {
+		return fmt.Errorf("failed to look up ticket for %s: %w", result.FindingID, err)
+	}
+	if existing != "" {
+		slog.Info("ticket already open", "finding", result.FindingID, "ticket", existing)
+		f.filed[result.FindingID] = true
+		return nil
+	}
+
+	ref, err := f.tracker.create(newTicket(result))
+	if err != nil {
+		return fmt.Errorf("failed to file ticket for %s: %w", result.FindingID, err)
+	}
+	slog.Info("filed ticket", "finding", result.FindingID, "ticket", ref)
+	f.filed[result.FindingID] = true
+	return nil
+}
+
+func (f *TicketFiler) Close() error {
+	return nil
+}
+
+// jira files issues through the Jira Cloud REST API v3
+type jira struct {
+	url       string
+	project   string
+	issueType string
+	headers   map[string]string
+	version   string
+	client    *http.Client
+}
+
+// findingLabel is the Jira label carrying a finding ID; labels cannot contain spaces
+func findingLabel(findingID string) string {
+	return ticketLabel + "-" + strings.ToLower(findingID)
+}
+
+func (j *jira) find(findingID string) (string, error) {
+	// Resolved issues do not count: a key still valid after its issue was closed gets a new one
+	jql := fmt.Sprintf("project = %q AND labels = %q AND statusCategory != Done", j.project, findingLabel(findingID))
+	body, err := json.Marshal(map[string]interface{}{"jql": jql, "fields": []string{"key"}, "maxResults": 1})
+	if err != nil {
+		return "", err
+	}
+	content, err := postJSON(j.client, j.url+"/rest/api/3/search/jql", body, j.version, j.headers)
+	if err != nil {
+		return "", jiraError(err, content)
+	}
+	var found struct {
+		Issues []struct {
+			Key string `json:"key"`
+		} `json:"issues"`
+	}
+	if err := json.Unmarshal(content, &found); err != nil {
+		return "", fmt.Errorf("invalid Jira search response: %w", err)
+	}
+	if len(found.Issues) == 0 {
+		return "", nil
+	}
+	return found.Issues[0].Key, nil
+}
+
+func (j *jira) create(t ticket) (string, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"fields": map[string]interface{}{
+			"project":     map[string]string{"key": j.project},
+			"issuetype":   map[string]string{"name": j.issueType},
+			"summary":     t.Summary,
+			"description": jiraDocument(t),
+			"labels":      []string{ticketLabel, findingLabel(t.FindingID)},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	content, err := postJSON(j.client, j.url+"/rest/api/3/issue", body, j.version, j.headers)
+	if err != nil {
+		return "", jiraError(err, content)
+	}
+	var created struct {
+		Key string `json:"key"`
+	}
+	if err := json.Unmarshal(content, &created); err != nil {
+		return "", fmt.Errorf("invalid Jira response: %w", err)
+	}
+	return created.Key, nil
+}
+
+// jiraError adds the messages of a Jira error response, which name the rejected fields
+func jiraError(err error, content []byte) error {
+	var response struct {
+		ErrorMessages []string          `json:"errorMessages"`
+		Errors        map[string]string `json:"errors"`
+	}
+	if json.Unmarshal(content, &response) != nil {
+		return err
+	}
+	messages := response.ErrorMessages
+	for field, message := range response.Errors {
+		messages = append(messages, field+": "+message)
+	}
+	if len(messages) == 0 {
+		return err
+	}
+	sort.Strings(messages)
+	return fmt.Errorf("%w: %s", err, strings.Join(messages, "; "))
+}
+
+// jiraDocument renders the ticket in the Atlassian Document Format required by API v3
+func jiraDocument(t ticket) map[string]interface{} {
+	text := func(s string, marks ...string) map[string]interface{} {
+		node := map[string]interface{}{"type": "text", "text": s}
+		if len(marks) > 0 {
+			types := make([]map[string]string, len(marks))
+			for i, mark := range marks {
+				types[i] = map[string]string{"type": mark}
+			}
+			node["marks"] = types
+		}
+		return node
+	}
+	paragraph := func(nodes ...map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{"type": "paragraph", "content": nodes}
+	}
+	heading := func(s string) map[string]interface{} {
+		return map[string]interface{}{"type": "heading", "attrs": map[string]int{"level": 3}, "content": []map[string]interface{}{text(s)}}
+	}
+
+	var items []map[string]interface{}
+	for _, field := range t.Fields {
+		value := text(field[1])
+		if field[0] == "Key" || field[0] == "Finding" {
+			value = text(field[1], "code")
+		}
+		items = append(items, map[string]interface{}{
+			"type":    "listItem",
+			"content": []map[string]interface{}{paragraph(text(field[0]+": ", "strong"), value)},
+		})
+	}
+	content := []map[string]interface{}{
+		paragraph(text("APIKeyzer confirmed that an exposed API key is valid.")),
+		{"type": "bulletList", "content": items},
+	}
+	if len(t.PoC) > 0 {
+		content = append(content,
+			heading("Evidence"),
+			paragraph(text("Replace <API_KEY> with the key to reproduce:")),
+			map[string]interface{}{
+				"type":    "codeBlock",
+				"attrs":   map[string]string{"language": "bash"},
+				"content": []map[string]interface{}{text(strings.Join(t.PoC, "\n"))},
+			})
+	}
+	content = append(content, heading("Remediation"), paragraph(text(t.Remediation)))
+	return map[string]interface{}{"type": "doc", "version": 1, "content": content}
+}
+
+// serviceNowLevels maps risk levels to incident impact and urgency (1 is high, 3 is low)
+var serviceNowLevels = map[validator.RiskLevel]string{
+	validator.RiskLevelHigh:   "1",
+	validator.RiskLevelMedium: "2",
+	validator.RiskLevelLow:    "3",
+}
+
+// serviceNow opens incidents through the ServiceNow Table API
+type serviceNow struct {
+	url     string
+	headers map[string]string
+	version string
+	client  *http.Client
+}
+
+func (s *serviceNow) find(findingID string) (string, error) {
+	query := url.Values{
+		"sysparm_query":  {"active=true^correlation_id=" + findingID},
+		"sysparm_fields": {"number"},
+		"sysparm_limit":  {"1"},
+	}
+	content, err := sendJSON(s.client, http.MethodGet, s.url+"/api/now/table/incident?"+query.Encode(), nil, s.version, s.headers)
+	if err != nil {
+		return "", err
+	}
+	var found struct {
+		Result []struct {
+			Number string `json:"number"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(content, &found); err != nil {
+		return "", fmt.Errorf("invalid ServiceNow response: %w", err)
+	}
+	if len(found.Result) == 0 {
+		return "", nil
+	}
+	return found.Result[0].Number, nil
+}
+
+func (s *serviceNow) create(t ticket) (string, error) {
+	body, err := json.Marshal(map[string]string{
+		"short_description":   t.Summary,
+		"description":         t.text(),
+		"category":            "Security",
+		"impact":              serviceNowLevels[t.Risk],
+		"urgency":             serviceNowLevels[t.Risk],
+		"correlation_id":      t.FindingID,
+		"correlation_display": "APIKeyzer",
+	})
+	if err != nil {
+		return "", err
+	}
+	content, err := postJSON(s.client, s.url+"/api/now/table/incident", body, s.version, s.headers)
+	if err != nil {
+		return "", err
+	}
+	var created struct {
+		Result struct {
+			Number string `json:"number"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(content, &created); err != nil {
+		return "", fmt.Errorf("invalid ServiceNow response: %w", err)
+	}
+	return created.Result.Number, nil
+}
+
+// basicAuth returns an Authorization header value for HTTP basic auth
+func basicAuth(username, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+}
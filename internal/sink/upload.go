@@ -0,0 +1,254 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// UploadName holds the values available to an upload destination template
+type UploadName struct {
+	Date      string // 2006-01-02
+	Time      string // 150405
+	Timestamp int64
+	Target    string
+	Format    string
+	Hostname  string
+}
+
+// NewUploadName fills the naming fields for a run at t
+func NewUploadName(t time.Time, target, format string) UploadName {
+	host, _ := os.Hostname()
+	return UploadName{
+		Date:      t.UTC().Format("2006-01-02"),
+		Time:      t.UTC().Format("150405"),
+		Timestamp: t.Unix(),
+		Target:    sanitizeName(target),
+		Format:    format,
+		Hostname:  host,
+	}
+}
+
+// sanitizeName makes a scan target usable inside an object name
+func sanitizeName(s string) string {
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "gs://"), "s3://")
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "https://"), "http://")
+	s = strings.Trim(s, "/.")
+	if s == "" {
+		return "run"
+	}
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r == ' ' {
+			return '_'
+		}
+		return r
+	}, s)
+}
+
+// ExpandDestination applies the Go template in dest (e.g. "s3://bucket/{{.Date}}/{{.Target}}.json")
+func ExpandDestination(dest string, name UploadName) (string, error) {
+	tmpl, err := template.New("upload").Option("missingkey=error").Parse(dest)
+	if err != nil {
+		return "", fmt.Errorf("invalid upload destination: %w", err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, name); err != nil {
+		return "", fmt.Errorf("invalid upload destination: %w", err)
+	}
+	return b.String(), nil
+}
+
+// Upload copies a local file to an s3://bucket/key or gs://bucket/object destination.
+//
+// S3 uploads are signed with AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and optionally
+// AWS_SESSION_TOKEN, in AWS_REGION (default us-east-1); AWS_ENDPOINT_URL selects an
+// S3-compatible endpoint. GCS uploads use the OAuth token in GOOGLE_OAUTH_ACCESS_TOKEN.
+func Upload(ctx context.Context, filename, dest string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+
+	scheme, rest, ok := strings.Cut(dest, "://")
+	bucket, key, _ := strings.Cut(rest, "/")
+	if !ok || bucket == "" || key == "" {
+		return fmt.Errorf("invalid upload destination %q (expected s3://bucket/key or gs://bucket/object)", dest)
+	}
+
+	var req *http.Request
+	switch scheme {
+	case "s3":
+		req, err = newS3Put(ctx, bucket, key, data)
+	case "gs":
+		req, err = newGCSUpload(ctx, bucket, key, data)
+	default:
+		return fmt.Errorf("unsupported upload scheme: %s", scheme)
+	}
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: 2 * time.Minute}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("upload to %s failed: %w", dest, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("upload to %s failed: %s: %s", dest, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// newGCSUpload builds a simple media upload to the GCS JSON API
+func newGCSUpload(ctx context.Context, bucket, object string, data []byte) (*http.Request, error) {
+	token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN")
+	if token == "" {
+		return nil, fmt.Errorf("GCS upload requires GOOGLE_OAUTH_ACCESS_TOKEN (e.g. from `gcloud auth print-access-token`)")
+	}
+
+	u := "https://storage.googleapis.com/upload/storage/v1/b/" + url.PathEscape(bucket) +
+		"/o?uploadType=media&name=" + url.QueryEscape(object)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create upload request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", contentTypeFor(object))
+	return req, nil
+}
+
+// newS3Put builds a PutObject request signed with AWS Signature Version 4
+func newS3Put(ctx context.Context, bucket, key string, data []byte) (*http.Request, error) {
+	accessKey, secretKey := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if accessKey == "" || secretKey == "" {
+		return nil, fmt.Errorf("S3 upload requires AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	// Virtual-hosted style on AWS, path style on custom (S3-compatible) endpoints
+	var host, path string
+	scheme := "https"
+	if endpoint := os.Getenv("AWS_ENDPOINT_URL"); endpoint != "" {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid AWS_ENDPOINT_URL: %w", err)
+		}
+		scheme, host, path = u.Scheme, u.Host, "/"+awsEscape(bucket)+"/"+awsEscapePath(key)
+	} else {
+		host, path = bucket+".s3."+region+".amazonaws.com", "/"+awsEscapePath(key)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, scheme+"://"+host+path, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create upload request: %w", err)
+	}
+	req.URL.RawPath = path
+
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	payloadHash := sha256Hex(data)
+	req.Header.Set("Content-Type", contentTypeFor(key))
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if token := os.Getenv("AWS_SESSION_TOKEN"); token != "" {
+		req.Header.Set("X-Amz-Security-Token", token)
+	}
+
+	signed := map[string]string{"host": host}
+	for name := range req.Header {
+		signed[strings.ToLower(name)] = strings.TrimSpace(req.Header.Get(name))
+	}
+	names := make([]string, 0, len(signed))
+	for name := range signed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + signed[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		http.MethodPut, path, "", canonicalHeaders.String(), signedHeaders, payloadHash,
+	}, "\n")
+	scope := now.Format("20060102") + "/" + region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	signingKey := []byte("AWS4" + secretKey)
+	for _, part := range []string{now.Format("20060102"), region, "s3", "aws4_request"} {
+		signingKey = hmacSHA256(signingKey, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+	return req, nil
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// awsEscape percent-encodes everything except the RFC 3986 unreserved characters, as SigV4 requires
+func awsEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '.' || c == '_' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// awsEscapePath escapes each segment of an object key, keeping the slashes
+func awsEscapePath(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = awsEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// contentTypeFor guesses the content type of an uploaded report from its extension
+func contentTypeFor(name string) string {
+	switch {
+	case strings.HasSuffix(name, ".json"), strings.HasSuffix(name, ".sarif"):
+		return "application/json"
+	case strings.HasSuffix(name, ".xml"):
+		return "application/xml"
+	case strings.HasSuffix(name, ".md"):
+		return "text/markdown"
+	default:
+		return "application/octet-stream"
+	}
+}
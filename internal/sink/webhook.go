@@ -0,0 +1,162 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// Webhook delivery modes
+const (
+	ModeFinding = "finding" // one POST per valid key
+	ModeSummary = "summary" // one POST with every valid key when the run ends
+)
+
+// SignatureHeader carries the hex HMAC-SHA256 of the request body when a secret is configured
+const SignatureHeader = "X-APIKeyzer-Signature"
+
+// EventHeader names the kind of payload, "finding" or "summary"
+const EventHeader = "X-APIKeyzer-Event"
+
+// Summary is the payload posted in summary mode
+type Summary struct {
+	Tool        string                        `json:"tool"`
+	Version     string                        `json:"version,omitempty"`
+	StartedAt   time.Time                     `json:"started_at"`
+	FinishedAt  time.Time                     `json:"finished_at"`
+	KeysChecked int                           `json:"keys_checked"`
+	ValidKeys   int                           `json:"valid_keys"`
+	Findings    []*validator.ValidationResult `json:"findings"`
+}
+
+// Webhook posts findings as JSON to an HTTP endpoint. It implements output.Writer.
+type Webhook struct {
+	url     string
+	secret  []byte
+	mode    string
+	version string
+	client  *http.Client
+
+	started time.Time
+	checked int
+	found   []*validator.ValidationResult
+}
+
+// NewWebhook creates a webhook sink; secret may be empty to disable signing
+func NewWebhook(url, secret, mode, version string) (*Webhook, error) {
+	if mode == "" {
+		mode = ModeFinding
+	}
+	if mode != ModeFinding && mode != ModeSummary {
+		return nil, fmt.Errorf("unsupported webhook mode: %s (expected %s or %s)", mode, ModeFinding, ModeSummary)
+	}
+	return &Webhook{
+		url:     url,
+		secret:  []byte(secret),
+		mode:    mode,
+		version: version,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		started: time.Now().UTC(),
+	}, nil
+}
+
+func (w *Webhook) WriteResult(result *validator.ValidationResult) error {
+	w.checked++
+	if !result.Valid {
+		return nil
+	}
+	if w.mode == ModeSummary {
+		w.found = append(w.found, result)
+		return nil
+	}
+	return w.post(ModeFinding, result)
+}
+
+func (w *Webhook) Close() error {
+	if w.mode != ModeSummary {
+		return nil
+	}
+	findings := w.found
+	if findings == nil {
+		findings = []*validator.ValidationResult{}
+	}
+	return w.post(ModeSummary, &Summary{
+		Tool:        "APIKeyzer",
+		Version:     w.version,
+		StartedAt:   w.started,
+		FinishedAt:  time.Now().UTC(),
+		KeysChecked: w.checked,
+		ValidKeys:   len(findings),
+		Findings:    findings,
+	})
+}
+
+// post sends payload as JSON, signing the body when a secret is set
+func (w *Webhook) post(event string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	headers := map[string]string{EventHeader: event}
+	if len(w.secret) > 0 {
+		headers[SignatureHeader] = "sha256=" + Sign(w.secret, body)
+	}
+	_, err = postJSON(w.client, w.url, body, w.version, headers)
+	return err
+}
+
+// postJSON POSTs a JSON body with extra headers, returning the response body.
+// Headers may override the default Content-Type; non-2xx responses are errors.
+func postJSON(client *http.Client, url string, body []byte, version string, headers map[string]string) ([]byte, error) {
+	return sendJSON(client, http.MethodPost, url, body, version, headers)
+}
+
+// sendJSON sends a request with an optional JSON body, as postJSON does for any method
+func sendJSON(client *http.Client, method, url string, body []byte, version string, headers map[string]string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "APIKeyzer/"+version)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request to %s failed: %w", req.URL.Host, err)
+	}
+	defer resp.Body.Close()
+	content, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response from %s: %w", req.URL.Host, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return content, fmt.Errorf("%s returned %s", req.URL.Host, resp.Status)
+	}
+	return content, nil
+}
+
+// Sign returns the hex-encoded HMAC-SHA256 of body, as sent in the signature header
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
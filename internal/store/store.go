@@ -0,0 +1,204 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/baseline"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// schema creates the validations table; every validation is appended as a new row
+const schema = `
+CREATE TABLE IF NOT EXISTS validations (
+	id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	finding_id   TEXT NOT NULL,
+	key_hash     TEXT NOT NULL,
+	service      TEXT NOT NULL,
+	valid        INTEGER NOT NULL,
+	risk_level   TEXT,
+	permissions  TEXT,
+	error        TEXT,
+	location     TEXT,
+	validated_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_validations_key ON validations (service, key_hash, validated_at);
+CREATE INDEX IF NOT EXISTS idx_validations_finding ON validations (finding_id);
+`
+
+// Entry is one stored validation. Keys are only stored as hashes.
+type Entry struct {
+	ID          int64               `json:"id"`
+	FindingID   string              `json:"finding_id"`
+	KeyHash     string              `json:"key_hash"`
+	Service     string              `json:"service"`
+	Valid       bool                `json:"valid"`
+	RiskLevel   validator.RiskLevel `json:"risk_level"`
+	Permissions []string            `json:"permissions,omitempty"`
+	Error       string              `json:"error,omitempty"`
+	Location    string              `json:"location,omitempty"`
+	ValidatedAt time.Time           `json:"validated_at"`
+}
+
+// Store records validation results in an embedded SQLite database
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens (creating if needed) the SQLite database at path
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite3", path+"?_busy_timeout=5000&_journal_mode=WAL")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open store: %w", err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize store %s: %w", path, err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Record appends a validation result to the history
+func (s *Store) Record(ctx context.Context, result *validator.ValidationResult) error {
+	permissions, err := json.Marshal(result.Permissions)
+	if err != nil {
+		return fmt.Errorf("failed to encode permissions: %w", err)
+	}
+
+	var location string
+	if result.Location != nil {
+		location = result.Location.String()
+	}
+
+	_, err = s.db.ExecContext(ctx,
+		`INSERT INTO validations (finding_id, key_hash, service, valid, risk_level, permissions, error, location, validated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		validator.FindingID(result.Service, result.Key), baseline.HashKey(result.Key), result.Service,
+		result.Valid, string(result.RiskLevel), string(permissions), result.ErrorStr, location, result.ValidatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to record validation: %w", err)
+	}
+	return nil
+}
+
+// Latest returns the most recent validation of key for service, or nil if it was never validated
+func (s *Store) Latest(ctx context.Context, service, key string) (*Entry, error) {
+	row := s.db.QueryRowContext(ctx, selectEntry+`
+		WHERE service = ? AND key_hash = ? ORDER BY validated_at DESC, id DESC LIMIT 1`,
+		service, baseline.HashKey(key))
+
+	entry, err := scanEntry(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return entry, err
+}
+
+// History returns stored validations, newest first. A non-empty findingID restricts
+// the history to one finding; limit <= 0 returns every row.
+func (s *Store) History(ctx context.Context, findingID string, limit int) ([]*Entry, error) {
+	query := selectEntry
+	var args []interface{}
+	if findingID != "" {
+		query += ` WHERE finding_id = ?`
+		args = append(args, findingID)
+	}
+	query += ` ORDER BY validated_at DESC, id DESC`
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*Entry
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// LatestBefore returns the most recent validation of every finding recorded before t
+func (s *Store) LatestBefore(ctx context.Context, t time.Time) ([]*Entry, error) {
+	rows, err := s.db.QueryContext(ctx, selectEntry+` v
+		WHERE v.id = (
+			SELECT w.id FROM validations w
+			WHERE w.finding_id = v.finding_id AND w.validated_at < ?
+			ORDER BY w.validated_at DESC, w.id DESC LIMIT 1
+		)`, t.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query store: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*Entry
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+const selectEntry = `SELECT id, finding_id, key_hash, service, valid, risk_level, permissions, error, location, validated_at FROM validations`
+
+// scanner is implemented by both *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanEntry(row scanner) (*Entry, error) {
+	var entry Entry
+	var riskLevel, permissions, errorStr, location sql.NullString
+	err := row.Scan(&entry.ID, &entry.FindingID, &entry.KeyHash, &entry.Service, &entry.Valid,
+		&riskLevel, &permissions, &errorStr, &location, &entry.ValidatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to read validation: %w", err)
+	}
+
+	entry.RiskLevel = validator.RiskLevel(riskLevel.String)
+	entry.Error = errorStr.String
+	entry.Location = location.String
+	if permissions.Valid && permissions.String != "" {
+		json.Unmarshal([]byte(permissions.String), &entry.Permissions)
+	}
+	return &entry, nil
+}
+
+// Result rebuilds a validation result for key from a stored entry
+func (e *Entry) Result(key string) *validator.ValidationResult {
+	result := &validator.ValidationResult{
+		Key:         key,
+		Valid:       e.Valid,
+		Service:     e.Service,
+		Permissions: e.Permissions,
+		RiskLevel:   e.RiskLevel,
+		ErrorStr:    e.Error,
+		ValidatedAt: e.ValidatedAt,
+	}
+	result.Classify()
+	return result
+}
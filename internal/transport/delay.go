@@ -0,0 +1,53 @@
+package transport
+
+import (
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Delay is an http.RoundTripper that waits a randomized delay between requests,
+// across every goroutine sharing it, for low-and-slow operation
+type Delay struct {
+	next   http.RoundTripper
+	delay  time.Duration
+	jitter float64
+
+	mu     sync.Mutex
+	nextAt time.Time
+}
+
+// NewDelay wraps next so consecutive requests are sent delay apart, varied randomly
+// by up to jitter times delay in either direction (e.g. 0.5 for ±50%)
+func NewDelay(next http.RoundTripper, delay time.Duration, jitter float64) *Delay {
+	return &Delay{next: next, delay: delay, jitter: jitter}
+}
+
+// reserve claims the next slot and returns how long to wait for it
+func (d *Delay) reserve() time.Duration {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	if d.nextAt.Before(now) {
+		d.nextAt = now
+	}
+	wait := d.nextAt.Sub(now)
+	spread := (rand.Float64()*2 - 1) * d.jitter
+	d.nextAt = d.nextAt.Add(time.Duration(float64(d.delay) * (1 + spread)))
+	return wait
+}
+
+func (d *Delay) RoundTrip(req *http.Request) (*http.Response, error) {
+	if wait := d.reserve(); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+	return d.next.RoundTrip(req)
+}
@@ -0,0 +1,46 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+)
+
+type headersKey struct{}
+
+// WithHeaders returns a context whose requests get extra headers from a Headers transport,
+// applied after and overriding the transport's own
+func WithHeaders(ctx context.Context, headers http.Header) context.Context {
+	return context.WithValue(ctx, headersKey{}, headers)
+}
+
+// Headers is an http.RoundTripper that sets fixed headers on every request,
+// plus any headers attached to the request's context with WithHeaders
+type Headers struct {
+	next    http.RoundTripper
+	headers http.Header
+}
+
+// NewHeaders wraps next so every request is sent with headers
+func NewHeaders(next http.RoundTripper, headers http.Header) *Headers {
+	return &Headers{next: next, headers: headers}
+}
+
+func (h *Headers) RoundTrip(req *http.Request) (*http.Response, error) {
+	extra, _ := req.Context().Value(headersKey{}).(http.Header)
+	if len(h.headers) == 0 && len(extra) == 0 {
+		return h.next.RoundTrip(req)
+	}
+
+	// RoundTrippers must not modify the caller's request
+	req = req.Clone(req.Context())
+	for _, headers := range []http.Header{h.headers, extra} {
+		for name, values := range headers {
+			req.Header[name] = values
+		}
+	}
+	if host := req.Header.Get("Host"); host != "" {
+		// Go sends req.Host, not the Host header
+		req.Host = host
+	}
+	return h.next.RoundTrip(req)
+}
@@ -0,0 +1,36 @@
+package transport
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptrace"
+	"time"
+)
+
+// Logger is an http.RoundTripper that logs every request sent at debug level.
+// Query strings are left out since validators pass keys as query parameters
+type Logger struct {
+	next http.RoundTripper
+}
+
+// NewLogger wraps next so every request and its outcome are logged
+func NewLogger(next http.RoundTripper) *Logger {
+	return &Logger{next: next}
+}
+
+func (l *Logger) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Whether a pooled connection was reused shows how many TLS handshakes a run costs
+	var reused bool
+	trace := &httptrace.ClientTrace{GotConn: func(info httptrace.GotConnInfo) { reused = info.Reused }}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+	start := time.Now()
+	resp, err := l.next.RoundTrip(req)
+	attrs := []interface{}{"method", req.Method, "host", req.URL.Host, "path", req.URL.Path, "duration", time.Since(start)}
+	if err != nil {
+		slog.Debug("request failed", append(attrs, "error", err)...)
+		return nil, err
+	}
+	slog.Debug("request sent", append(attrs, "status", resp.StatusCode, "proto", resp.Proto, "reused_conn", reused)...)
+	return resp, nil
+}
@@ -0,0 +1,31 @@
+package transport
+
+import (
+	"net/http"
+	"time"
+)
+
+// minIdleConnsPerHost keeps a few spare connections per provider even for sequential runs
+const minIdleConnsPerHost = 4
+
+// NewPooled returns the transport validators share, tuned for bulk validation: up to
+// concurrency keep-alive connections per host stay open between requests, so keys checked
+// against the same provider reuse TLS sessions instead of handshaking for every request.
+// HTTP/2 is negotiated when the provider supports it, multiplexing requests on one connection.
+func NewPooled(concurrency int) *http.Transport {
+	perHost := max(concurrency, minIdleConnsPerHost)
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConnsPerHost = perHost
+	t.MaxIdleConns = max(t.MaxIdleConns, 4*perHost)
+	t.IdleConnTimeout = 90 * time.Second
+	t.ForceAttemptHTTP2 = true
+	return t
+}
+
+// defaultPool is shared by the clients of components that are not given one
+var defaultPool = NewPooled(minIdleConnsPerHost)
+
+// DefaultClient returns a client with timeout on the shared default transport
+func DefaultClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: defaultPool}
+}
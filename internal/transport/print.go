@@ -0,0 +1,65 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// ErrNotSent is returned for requests that were only printed, not sent
+var ErrNotSent = errors.New("request not sent (dry run)")
+
+type secretsKey struct{}
+
+// WithSecrets returns a context whose requests have these secrets masked when printed
+func WithSecrets(ctx context.Context, secrets ...string) context.Context {
+	return context.WithValue(ctx, secretsKey{}, secrets)
+}
+
+// Printer is an http.RoundTripper that writes every request, exactly as it goes on
+// the wire, to w before sending it, or instead of sending it in dry-run mode
+type Printer struct {
+	next http.RoundTripper
+	w    io.Writer
+	send bool
+	mask func(string) string
+	mu   sync.Mutex
+}
+
+// NewPrinter wraps next so every request is printed to w. Requests are only sent when
+// send is true. When mask is not nil, secrets attached with WithSecrets are masked with it
+func NewPrinter(next http.RoundTripper, w io.Writer, send bool, mask func(string) string) *Printer {
+	return &Printer{next: next, w: w, send: send, mask: mask}
+}
+
+func (p *Printer) RoundTrip(req *http.Request) (*http.Response, error) {
+	dump, err := httputil.DumpRequestOut(req, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to print request: %w", err)
+	}
+	text := strings.ReplaceAll(string(dump), "\r\n", "\n")
+	if p.mask != nil {
+		secrets, _ := req.Context().Value(secretsKey{}).([]string)
+		for _, secret := range secrets {
+			masked := p.mask(secret)
+			text = strings.ReplaceAll(text, secret, masked)
+			text = strings.ReplaceAll(text, url.QueryEscape(secret), url.QueryEscape(masked))
+		}
+	}
+
+	// Concurrent requests are printed whole, one after the other
+	p.mu.Lock()
+	fmt.Fprintf(p.w, "# %s://%s\n%s\n\n", req.URL.Scheme, req.URL.Host, strings.TrimRight(text, "\n"))
+	p.mu.Unlock()
+
+	if !p.send {
+		return nil, ErrNotSent
+	}
+	return p.next.RoundTrip(req)
+}
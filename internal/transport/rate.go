@@ -0,0 +1,52 @@
+package transport
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimiter is an http.RoundTripper that spaces requests evenly so that no more
+// than a fixed number are sent per second, across every goroutine sharing it
+type RateLimiter struct {
+	next     http.RoundTripper
+	interval time.Duration
+
+	mu     sync.Mutex
+	nextAt time.Time
+}
+
+// NewRateLimiter wraps next so it sends at most perSecond requests per second
+func NewRateLimiter(next http.RoundTripper, perSecond float64) *RateLimiter {
+	return &RateLimiter{
+		next:     next,
+		interval: time.Duration(float64(time.Second) / perSecond),
+	}
+}
+
+// reserve claims the next free slot and returns how long to wait for it
+func (r *RateLimiter) reserve() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if r.nextAt.Before(now) {
+		r.nextAt = now
+	}
+	wait := r.nextAt.Sub(now)
+	r.nextAt = r.nextAt.Add(r.interval)
+	return wait
+}
+
+func (r *RateLimiter) RoundTrip(req *http.Request) (*http.Response, error) {
+	if wait := r.reserve(); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+	return r.next.RoundTrip(req)
+}
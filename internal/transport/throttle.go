@@ -0,0 +1,221 @@
+package transport
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Bounds of the pacing a Throttle applies once a provider pushes back
+const (
+	minThrottleInterval = 250 * time.Millisecond
+	relaxedInterval     = 10 * time.Millisecond
+)
+
+type serviceKey struct{}
+
+// WithService returns a context whose requests a Throttle paces as those of service,
+// rather than by the host they are sent to
+func WithService(ctx context.Context, service string) context.Context {
+	return context.WithValue(ctx, serviceKey{}, service)
+}
+
+// pace is the adaptive pacing of one service
+type pace struct {
+	interval    time.Duration // gap kept between requests; 0 while the provider shows no pressure
+	nextAt      time.Time     // earliest time the next request may be sent
+	pausedUntil time.Time     // no request is sent before then, including those already waiting
+}
+
+// Throttle is an http.RoundTripper that slows requests to a service down when its provider
+// signals pressure: a Retry-After on 429 and 503 responses, or X-RateLimit-Remaining (and
+// RateLimit-Remaining) running low before X-RateLimit-Reset. Requests to the service are
+// then spaced out or paused until the limit resets, and the pacing relaxes again as
+// responses come back without pressure.
+type Throttle struct {
+	next       http.RoundTripper
+	maxBackoff time.Duration
+
+	mu    sync.Mutex
+	paces map[string]*pace
+}
+
+// NewThrottle wraps next with adaptive pacing per service, pausing a service for at most
+// maxBackoff at a time whatever its provider asks for
+func NewThrottle(next http.RoundTripper, maxBackoff time.Duration) *Throttle {
+	return &Throttle{next: next, maxBackoff: maxBackoff, paces: make(map[string]*pace)}
+}
+
+// reserve claims the next slot of service and returns how long to wait for it
+func (t *Throttle) reserve(service string) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p := t.paces[service]
+	if p == nil {
+		p = &pace{}
+		t.paces[service] = p
+	}
+	now := time.Now()
+	if p.nextAt.Before(now) {
+		p.nextAt = now
+	}
+	// Requests queued behind a pause are spaced out after it rather than all sent when it ends
+	if p.nextAt.Before(p.pausedUntil) {
+		p.nextAt = p.pausedUntil
+	}
+	wait := p.nextAt.Sub(now)
+	p.nextAt = p.nextAt.Add(p.interval)
+	return wait
+}
+
+// paused returns how long requests to service must still wait for a pause to end
+func (t *Throttle) paused(service string) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return time.Until(t.paces[service].pausedUntil)
+}
+
+func (t *Throttle) RoundTrip(req *http.Request) (*http.Response, error) {
+	service, _ := req.Context().Value(serviceKey{}).(string)
+	if service == "" {
+		service = req.URL.Host
+	}
+	for wait := t.reserve(service); wait > 0; {
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+		// A pause may start while waiting for the slot, when a response to an earlier request
+		// arrives; the request then takes a new slot after the pause
+		wait = 0
+		if t.paused(service) > 0 {
+			wait = t.reserve(service)
+		}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err == nil {
+		t.observe(service, resp)
+	}
+	return resp, err
+}
+
+// observe adapts the pacing of service to the rate limit signals of resp
+func (t *Throttle) observe(service string, resp *http.Response) {
+	now := time.Now()
+	limited := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable
+	retryAfter, hasRetryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), now)
+	remaining, limit, reset, hasQuota := parseRateLimit(resp.Header, now)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p := t.paces[service]
+	if p == nil {
+		return
+	}
+
+	var pause time.Duration
+	switch {
+	case limited && (hasRetryAfter || resp.StatusCode == http.StatusTooManyRequests),
+		hasQuota && remaining == 0:
+		// Back off exponentially for as long as the provider keeps refusing, or for as long
+		// as it says when it does
+		p.interval = min(max(2*p.interval, minThrottleInterval), t.maxBackoff)
+		switch {
+		case hasRetryAfter:
+			pause = retryAfter
+		case hasQuota && remaining == 0 && reset > 0:
+			pause = reset
+		default:
+			pause = p.interval
+		}
+	case hasQuota && reset > 0 && (remaining*10 <= limit || (limit == 0 && remaining <= 10)):
+		// Spread what is left of the quota over the rest of the window
+		interval := min(reset/time.Duration(remaining+1), t.maxBackoff)
+		if interval > p.interval {
+			slog.Info("provider quota running low, slowing down", "service", service, "remaining", remaining, "interval", interval.Round(time.Millisecond))
+		}
+		p.interval = interval
+		return
+	default:
+		p.interval = p.interval * 3 / 4
+		if p.interval < relaxedInterval {
+			p.interval = 0
+		}
+		return
+	}
+
+	pause = min(pause, t.maxBackoff)
+	if until := now.Add(pause); until.After(p.pausedUntil) {
+		if p.pausedUntil.Before(now) {
+			slog.Warn("provider is rate limiting, pausing requests", "service", service, "status", resp.StatusCode, "pause", pause.Round(time.Millisecond))
+		}
+		p.pausedUntil = until
+	}
+}
+
+// parseRetryAfter parses a Retry-After header, given in seconds or as an HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return max(time.Duration(seconds*float64(time.Second)), 0), true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// parseRateLimit reads the remaining requests, the limit (0 when not given) and the time until
+// the window resets from the X-RateLimit-* headers, the unprefixed RateLimit-* headers of the
+// IETF draft or the per-request variants (X-RateLimit-Remaining-Requests) some providers send
+func parseRateLimit(header http.Header, now time.Time) (remaining, limit int, reset time.Duration, ok bool) {
+	get := func(name string) string {
+		for _, prefix := range []string{"X-RateLimit-", "RateLimit-"} {
+			for _, suffix := range []string{"", "-Requests"} {
+				if value := strings.TrimSpace(header.Get(prefix + name + suffix)); value != "" {
+					return value
+				}
+			}
+		}
+		return ""
+	}
+
+	remaining, err := strconv.Atoi(get("Remaining"))
+	if err != nil || remaining < 0 {
+		return 0, 0, 0, false
+	}
+	limit, _ = strconv.Atoi(get("Limit"))
+	reset = parseReset(get("Reset"), now)
+	return remaining, limit, reset, true
+}
+
+// parseReset parses a rate limit reset given as seconds until the reset, a Unix timestamp
+// or a duration such as "6m0s"
+func parseReset(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		// Deltas are small; anything past a billion seconds is an epoch timestamp
+		if seconds > 1e9 {
+			return max(time.Unix(int64(seconds), 0).Sub(now), 0)
+		}
+		return max(time.Duration(seconds*float64(time.Second)), 0)
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return max(d, 0)
+	}
+	return 0
+}
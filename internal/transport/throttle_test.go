@@ -0,0 +1,81 @@
+package transport
+
+import (
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// limitedTransport answers the first request with a 429 and a Retry-After, and records when
+// every later request was sent
+type limitedTransport struct {
+	retryAfter string
+
+	mu   sync.Mutex
+	sent []time.Time
+}
+
+func (l *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	resp := &http.Response{StatusCode: http.StatusOK, Header: make(http.Header), Body: io.NopCloser(strings.NewReader("")), Request: req}
+	if l.retryAfter != "" {
+		resp.StatusCode = http.StatusTooManyRequests
+		resp.Header.Set("Retry-After", l.retryAfter)
+		l.retryAfter = ""
+		return resp, nil
+	}
+	l.sent = append(l.sent, time.Now())
+	return resp, nil
+}
+
+func TestThrottlePausesOnRetryAfter(t *testing.T) {
+	const retryAfter = 300 * time.Millisecond
+	next := &limitedTransport{retryAfter: "0.3"}
+	client := &http.Client{Transport: NewThrottle(next, 5*time.Second)}
+
+	start := time.Now()
+	resp, err := client.Get("https://api.example.com/limited")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", resp.StatusCode)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get("https://api.example.com/limited")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	sent := next.sent
+	if len(sent) != 3 {
+		t.Fatalf("sent %d requests after the 429, want 3", len(sent))
+	}
+	sort.Slice(sent, func(i, j int) bool { return sent[i].Before(sent[j]) })
+	if waited := sent[0].Sub(start); waited < retryAfter-10*time.Millisecond {
+		t.Errorf("first request after the 429 was sent after %v, want at least the Retry-After of %v", waited, retryAfter)
+	}
+	// The 429 also slows the service down, so the requests queued behind the pause are spaced out
+	for i := 1; i < len(sent); i++ {
+		if gap := sent[i].Sub(sent[i-1]); gap < minThrottleInterval-10*time.Millisecond {
+			t.Errorf("request %d was sent %v after the previous one, want at least %v", i+1, gap, minThrottleInterval)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package transport
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Timeout is an http.RoundTripper bounding each request, including reading its body.
+// Unlike http.Client.Timeout it only starts once the request is actually sent, so
+// time spent waiting in a rate limiter above it is not counted
+type Timeout struct {
+	next    http.RoundTripper
+	timeout time.Duration
+}
+
+// NewTimeout wraps next so every request is cancelled after timeout
+func NewTimeout(next http.RoundTripper, timeout time.Duration) *Timeout {
+	return &Timeout{next: next, timeout: timeout}
+}
+
+func (t *Timeout) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelBody releases the request's timeout once the body is closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,82 @@
+package transport
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// HTTP versions a Tuning can pin requests to; HTTPAuto negotiates HTTP/2 where supported
+const (
+	HTTPAuto = "auto"
+	HTTP1    = "1.1"
+	HTTP2    = "2"
+)
+
+// HTTPVersions lists the accepted HTTP versions
+var HTTPVersions = []string{HTTPAuto, HTTP1, HTTP2}
+
+// TLSVersions maps the accepted minimum TLS versions to their crypto/tls constants
+var TLSVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// Tuning overrides the pooled transport's defaults for networks where they cause trouble,
+// such as WAFs fingerprinting HTTP/2 or proxies that mishandle keep-alive connections.
+// Zero values keep the defaults.
+type Tuning struct {
+	HTTPVersion         string
+	MaxIdleConnsPerHost int
+	TLSMinVersion       string
+	DisableKeepAlives   bool
+}
+
+// Apply applies the tuning to t
+func (tuning Tuning) Apply(t *http.Transport) error {
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{}
+	}
+
+	switch tuning.HTTPVersion {
+	case "", HTTPAuto:
+	case HTTP1:
+		// A non-nil empty TLSNextProto disables HTTP/2 altogether
+		t.ForceAttemptHTTP2 = false
+		t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+		t.TLSClientConfig.NextProtos = []string{"http/1.1"}
+	case HTTP2:
+		// Servers ignoring ALPN would otherwise silently fall back to HTTP/1.1
+		t.ForceAttemptHTTP2 = true
+		t.TLSClientConfig.NextProtos = []string{"h2"}
+		t.TLSClientConfig.VerifyConnection = func(state tls.ConnectionState) error {
+			if state.NegotiatedProtocol != "h2" {
+				return errors.New("server does not support HTTP/2")
+			}
+			return nil
+		}
+	default:
+		return fmt.Errorf("unsupported HTTP version %q: expected %s, %s or %s", tuning.HTTPVersion, HTTPAuto, HTTP1, HTTP2)
+	}
+
+	if tuning.TLSMinVersion != "" {
+		version, ok := TLSVersions[tuning.TLSMinVersion]
+		if !ok {
+			return fmt.Errorf("unsupported TLS version %q: expected 1.0, 1.1, 1.2 or 1.3", tuning.TLSMinVersion)
+		}
+		t.TLSClientConfig.MinVersion = version
+	}
+
+	if tuning.MaxIdleConnsPerHost < 0 {
+		return fmt.Errorf("invalid idle connection limit %d: must not be negative", tuning.MaxIdleConnsPerHost)
+	}
+	if tuning.MaxIdleConnsPerHost > 0 {
+		t.MaxIdleConnsPerHost = tuning.MaxIdleConnsPerHost
+		t.MaxIdleConns = max(t.MaxIdleConns, 4*tuning.MaxIdleConnsPerHost)
+	}
+	t.DisableKeepAlives = tuning.DisableKeepAlives
+	return nil
+}
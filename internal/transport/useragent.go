@@ -0,0 +1,28 @@
+package transport
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// UserAgent is an http.RoundTripper that sets the User-Agent of every request,
+// rotating through the configured agents in turn when there are several
+type UserAgent struct {
+	next   http.RoundTripper
+	agents []string
+	count  atomic.Uint64
+}
+
+// NewUserAgent wraps next so requests are sent with the given user agents
+func NewUserAgent(next http.RoundTripper, agents []string) *UserAgent {
+	return &UserAgent{next: next, agents: agents}
+}
+
+func (u *UserAgent) RoundTrip(req *http.Request) (*http.Response, error) {
+	agent := u.agents[(u.count.Add(1)-1)%uint64(len(u.agents))]
+
+	// RoundTrippers must not modify the caller's request
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", agent)
+	return u.next.RoundTrip(req)
+}
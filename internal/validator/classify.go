@@ -0,0 +1,54 @@
+package validator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// rateLimitMarkers are error identifiers providers send in throttling responses, including those
+// that do not use a 429 (e.g. Google's 403 rateLimitExceeded or 200 OVER_QUERY_LIMIT)
+var rateLimitMarkers = [][]byte{
+	[]byte(`"OVER_QUERY_LIMIT"`),
+	[]byte(`"RESOURCE_EXHAUSTED"`),
+	[]byte(`"rateLimitExceeded"`),
+	[]byte(`"userRateLimitExceeded"`),
+	[]byte(`"rate_limit_exceeded"`),
+	[]byte(`"rate_limit_error"`),
+}
+
+// ClassifyResponse returns the error a provider response stands for when it says nothing about the
+// key: ErrRateLimited when the provider throttled the request, ErrServiceDown for server errors and
+// ErrTimeout when it gave up waiting. It returns nil for responses that accept or reject the key.
+// Validators check every response with it before judging the key, so a throttled or failed
+// request is never mistaken for a rejected key.
+func ClassifyResponse(status int, body []byte) error {
+	text := http.StatusText(status)
+	switch {
+	case status == http.StatusTooManyRequests:
+		return fmt.Errorf("%w: %d %s", ErrRateLimited, status, text)
+	case status == http.StatusRequestTimeout || status == http.StatusGatewayTimeout:
+		return fmt.Errorf("%w: %d %s", ErrTimeout, status, text)
+	case status >= 500:
+		return fmt.Errorf("%w: %d %s", ErrServiceDown, status, text)
+	}
+	for _, marker := range rateLimitMarkers {
+		if bytes.Contains(body, marker) {
+			return fmt.Errorf("%w: %d %s (%s)", ErrRateLimited, status, text, bytes.Trim(marker, `"`))
+		}
+	}
+	return nil
+}
+
+// RequestError classifies an error returned by http.Client.Do, wrapping timeouts in ErrTimeout.
+// Other failures keep their cause, which ErrorCodeFor reports as a network error.
+func RequestError(err error) error {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	}
+	return fmt.Errorf("request failed: %w", err)
+}
@@ -0,0 +1,72 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/transport"
+)
+
+// ErrorCode is a machine-readable classification of why a key could not be validated
+type ErrorCode string
+
+const (
+	ErrCodeDetectionFailed ErrorCode = "DETECTION_FAILED" // no pattern matched the key
+	ErrCodeNoValidator     ErrorCode = "NO_VALIDATOR"     // the service has no registered validator
+	ErrCodeRateLimited     ErrorCode = "RATE_LIMITED"     // the provider throttled the requests
+	ErrCodeNetwork         ErrorCode = "NETWORK_ERROR"    // the provider could not be reached in time
+	ErrCodeServiceDown     ErrorCode = "SERVICE_DOWN"     // the provider answered with a server error
+	ErrCodeSafeMode        ErrorCode = "SAFE_MODE"        // every endpoint was skipped as billable
+	ErrCodeDryRun          ErrorCode = "DRY_RUN"          // the requests were printed but not sent
+	ErrCodeDeadline        ErrorCode = "DEADLINE"         // the key's time budget or the run's deadline ran out
+	ErrCodeValidation      ErrorCode = "VALIDATION_ERROR" // any other failure
+)
+
+// Sentinel errors for failures that happen before a validator runs
+var (
+	ErrDetectionFailed = errors.New("unknown service for key")
+	ErrNoValidator     = errors.New("no validator found for service")
+	ErrBillableSkipped = errors.New("all endpoints are billable and were skipped in safe mode")
+	// ErrDeadline is the cause given to contexts ending with a time budget, so that keys
+	// they cut short are told apart from keys whose provider timed out
+	ErrDeadline = errors.New("time budget exhausted")
+)
+
+// ErrorCodeFor classifies an error returned while detecting or validating a key
+func ErrorCodeFor(err error) ErrorCode {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, ErrDetectionFailed):
+		return ErrCodeDetectionFailed
+	case errors.Is(err, ErrNoValidator):
+		return ErrCodeNoValidator
+	case errors.Is(err, ErrRateLimited):
+		return ErrCodeRateLimited
+	case errors.Is(err, ErrServiceDown):
+		return ErrCodeServiceDown
+	case errors.Is(err, ErrBillableSkipped):
+		return ErrCodeSafeMode
+	case errors.Is(err, transport.ErrNotSent):
+		return ErrCodeDryRun
+	case errors.Is(err, ErrDeadline):
+		return ErrCodeDeadline
+	case errors.Is(err, ErrTimeout), errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr):
+		return ErrCodeNetwork
+	default:
+		return ErrCodeValidation
+	}
+}
+
+// NewErrorResult builds the result reported for a key that could not be validated
+func NewErrorResult(key, service string, err error) *ValidationResult {
+	return &ValidationResult{
+		Key:         key,
+		Service:     service,
+		Error:       err,
+		ErrorStr:    err.Error(),
+		ErrorCode:   ErrorCodeFor(err),
+		ValidatedAt: time.Now(),
+	}
+}
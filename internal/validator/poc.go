@@ -0,0 +1,39 @@
+package validator
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// CurlCommand renders req as a ready-to-run curl command for reproducing a finding.
+// body is the request payload, if any, since it cannot be re-read from req.
+func CurlCommand(req *http.Request, body []byte) string {
+	parts := []string{"curl", "-sS"}
+	if req.Method != http.MethodGet {
+		parts = append(parts, "-X", req.Method)
+	}
+
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range req.Header[name] {
+			parts = append(parts, "-H", shellQuote(name+": "+value))
+		}
+	}
+
+	if len(body) > 0 {
+		parts = append(parts, "--data", shellQuote(string(body)))
+	}
+	parts = append(parts, shellQuote(req.URL.String()))
+
+	return strings.Join(parts, " ")
+}
+
+// shellQuote wraps s in single quotes so it is passed to the shell verbatim
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
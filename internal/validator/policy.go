@@ -0,0 +1,16 @@
+package validator
+
+import "context"
+
+type safeModeKey struct{}
+
+// WithSafeMode returns a context telling validators whether to skip billable endpoints
+func WithSafeMode(ctx context.Context, enabled bool) context.Context {
+	return context.WithValue(ctx, safeModeKey{}, enabled)
+}
+
+// SafeMode reports whether validators must skip endpoints that may be billed to the key owner
+func SafeMode(ctx context.Context) bool {
+	enabled, _ := ctx.Value(safeModeKey{}).(bool)
+	return enabled
+}
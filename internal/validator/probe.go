@@ -0,0 +1,27 @@
+package validator
+
+import "context"
+
+// ProbeResult is the verdict of a single endpoint probed while validating a key
+type ProbeResult struct {
+	Endpoint   Endpoint
+	StatusCode int
+	Vulnerable bool
+	Skipped    bool // billable endpoint skipped in safe mode
+	Err        error
+}
+
+type probeHookKey struct{}
+
+// WithProbeHook returns a context whose validators report every endpoint they probe to hook,
+// as soon as its verdict is known
+func WithProbeHook(ctx context.Context, hook func(ProbeResult)) context.Context {
+	return context.WithValue(ctx, probeHookKey{}, hook)
+}
+
+// ReportProbe passes a probe verdict to the hook attached to ctx, if any
+func ReportProbe(ctx context.Context, result ProbeResult) {
+	if hook, ok := ctx.Value(probeHookKey{}).(func(ProbeResult)); ok {
+		hook(result)
+	}
+}
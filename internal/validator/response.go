@@ -0,0 +1,45 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DefaultMaxResponseSize is the largest provider response read while validating (1 MiB).
+// Provider answers to validation probes are a few kilobytes at most.
+const DefaultMaxResponseSize = 1 << 20
+
+// ErrResponseTooLarge is returned when a provider response exceeds the configured maximum
+var ErrResponseTooLarge = errors.New("response too large")
+
+type maxResponseSizeKey struct{}
+
+// WithMaxResponseSize returns a context limiting the response bodies validators read to size bytes
+func WithMaxResponseSize(ctx context.Context, size int64) context.Context {
+	return context.WithValue(ctx, maxResponseSizeKey{}, size)
+}
+
+// MaxResponseSize returns the response size limit attached to ctx, or DefaultMaxResponseSize
+func MaxResponseSize(ctx context.Context) int64 {
+	if size, ok := ctx.Value(maxResponseSizeKey{}).(int64); ok && size > 0 {
+		return size
+	}
+	return DefaultMaxResponseSize
+}
+
+// ReadResponse reads a response body up to the limit attached to ctx. Validators use it
+// instead of io.ReadAll so a misbehaving endpoint cannot exhaust memory in parallel runs;
+// bodies over the limit are abandoned after limit bytes and fail with ErrResponseTooLarge.
+func ReadResponse(ctx context.Context, body io.Reader) ([]byte, error) {
+	limit := MaxResponseSize(ctx)
+	content, err := io.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) > limit {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrResponseTooLarge, limit)
+	}
+	return content, nil
+}
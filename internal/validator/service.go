@@ -0,0 +1,25 @@
+package validator
+
+import (
+	"regexp"
+	"strings"
+)
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// ServiceID returns the canonical identifier of a service, e.g. "google-safe-browsing-api-key"
+func ServiceID(service string) string {
+	return strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(service), "-"), "-")
+}
+
+// Endpoint describes a provider endpoint called during validation
+type Endpoint struct {
+	URL      string `json:"url"`
+	Method   string `json:"method"`
+	Billable bool   `json:"billable"` // requests may be charged to the key owner
+}
+
+// EndpointLister is implemented by validators that can describe the endpoints they call
+type EndpointLister interface {
+	Endpoints() []Endpoint
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/transport"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// anthropicVersion is the API version sent with every request
+const anthropicVersion = "2023-06-01"
+
+// Anthropic API endpoints called during validation. Messages are billed to the key owner,
+// even when limited to a single output token.
+var (
+	anthropicModelsEndpoint   = validator.Endpoint{URL: "https://api.anthropic.com/v1/models", Method: http.MethodGet}
+	anthropicMessagesEndpoint = validator.Endpoint{URL: "https://api.anthropic.com/v1/messages", Method: http.MethodPost, Billable: true}
+)
+
+// anthropicFamilies are the model families reported, matched against model IDs
+var anthropicFamilies = []string{"opus", "sonnet", "haiku"}
+
+// AnthropicValidator implements the Validator interface for Anthropic API keys
+type AnthropicValidator struct {
+	client *http.Client
+}
+
+// NewAnthropicValidator creates a new Anthropic validator instance using client,
+// or a client on the shared connection pool with a 10 second timeout when client is nil
+func NewAnthropicValidator(client *http.Client) *AnthropicValidator {
+	if client == nil {
+		client = transport.DefaultClient(10 * time.Second)
+	}
+	return &AnthropicValidator{client: client}
+}
+
+func (v *AnthropicValidator) GetService() string {
+	return "Anthropic API Key"
+}
+
+func (v *AnthropicValidator) GetValidationMethod() validator.ValidationMethod {
+	return validator.MethodHTTP
+}
+
+// Endpoints describes the endpoints probed during validation
+func (v *AnthropicValidator) Endpoints() []validator.Endpoint {
+	return []validator.Endpoint{anthropicModelsEndpoint, anthropicMessagesEndpoint}
+}
+
+// newAnthropicRequest builds a request for an Anthropic API endpoint authorized with key
+func newAnthropicRequest(ctx context.Context, endpoint validator.Endpoint, rawURL, key string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, endpoint.Method, rawURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-Api-Key", key)
+	req.Header.Set("Anthropic-Version", anthropicVersion)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+// Validate lists the models available to the key, then, outside safe mode, asks the newest
+// model of each family for a single token to see which families respond
+func (v *AnthropicValidator) Validate(ctx context.Context, key string) (*validator.ValidationResult, error) {
+	result := newResult(v.GetService())
+	key = strings.TrimSpace(key)
+
+	req, err := newAnthropicRequest(ctx, anthropicModelsEndpoint, anthropicModelsEndpoint.URL+"?limit=1000", key, nil)
+	if err != nil {
+		return nil, err
+	}
+	var models struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	resp, err := send(v.client, req)
+	if err == nil && resp.StatusCode == http.StatusOK {
+		err = resp.decode(&models)
+	}
+	if err != nil {
+		reportProbe(ctx, anthropicModelsEndpoint, nil, err, false)
+		return nil, err
+	}
+	reportProbe(ctx, anthropicModelsEndpoint, resp, nil, resp.StatusCode == http.StatusOK)
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return reject(result, "key rejected: invalid, disabled or deleted"), nil
+	default:
+		return nil, fmt.Errorf("%w: unexpected status %d", validator.ErrValidationError, resp.StatusCode)
+	}
+	result.Valid = true
+	result.PoC = map[string]string{anthropicModelsEndpoint.URL: validator.CurlCommand(req, nil)}
+
+	// Models are listed newest first, so the first model of a family is its latest
+	newest := make(map[string]string)
+	var families []string
+	for _, model := range models.Data {
+		result.Permissions = append(result.Permissions, model.ID)
+		for _, family := range anthropicFamilies {
+			if strings.Contains(model.ID, family) && newest[family] == "" {
+				newest[family] = model.ID
+				families = append(families, family)
+			}
+		}
+	}
+	result.Details["model_count"] = len(models.Data)
+	result.Details["model_families"] = families
+
+	result.RiskLevel = validator.RiskLevelMedium
+	if validator.SafeMode(ctx) {
+		result.Details["responding_families"] = "Skipped: billable endpoint (safe mode)"
+		validator.ReportProbe(ctx, validator.ProbeResult{Endpoint: anthropicMessagesEndpoint, Skipped: true})
+		return result, nil
+	}
+
+	var responding []string
+	for _, family := range families {
+		answered, err := v.message(ctx, key, newest[family], result)
+		switch {
+		case err != nil:
+			result.Details[family] = fmt.Sprintf("Error: %v", err)
+		case answered:
+			responding = append(responding, family)
+		}
+	}
+	result.Details["responding_families"] = responding
+
+	// A key that generates text spends the owner's credits
+	if len(responding) > 0 {
+		result.RiskLevel = validator.RiskLevelHigh
+	}
+	return result, nil
+}
+
+// message asks model for a single token and reports whether it answered. Refusals that
+// concern the account rather than the key, such as exhausted credits, are noted in result.
+func (v *AnthropicValidator) message(ctx context.Context, key, model string, result *validator.ValidationResult) (bool, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"model":      model,
+		"max_tokens": 1,
+		"messages":   []map[string]string{{"role": "user", "content": "ping"}},
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to encode request: %w", err)
+	}
+	req, err := newAnthropicRequest(ctx, anthropicMessagesEndpoint, anthropicMessagesEndpoint.URL, key, body)
+	if err != nil {
+		return false, err
+	}
+	resp, err := send(v.client, req)
+	if err != nil {
+		reportProbe(ctx, anthropicMessagesEndpoint, nil, err, false)
+		return false, err
+	}
+	reportProbe(ctx, anthropicMessagesEndpoint, resp, nil, resp.StatusCode == http.StatusOK)
+
+	if resp.StatusCode == http.StatusOK {
+		if _, ok := result.PoC[anthropicMessagesEndpoint.URL]; !ok {
+			result.PoC[anthropicMessagesEndpoint.URL] = validator.CurlCommand(req, body)
+		}
+		return true, nil
+	}
+	var failure struct {
+		Error struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := resp.decode(&failure); err != nil {
+		return false, err
+	}
+	if strings.Contains(failure.Error.Message, "credit balance") {
+		result.Details["credit_balance_low"] = true
+	}
+	return false, nil
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/transport"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+var (
+	// azureADTokenEndpoint issues app-only tokens for Microsoft Graph; {tenant} is the tenant ID or domain
+	azureADTokenEndpoint = validator.Endpoint{URL: "https://login.microsoftonline.com/{tenant}/oauth2/v2.0/token", Method: http.MethodPost}
+	// azureADOrganizationEndpoint describes the tenant the token belongs to
+	azureADOrganizationEndpoint = validator.Endpoint{URL: "https://graph.microsoft.com/v1.0/organization", Method: http.MethodGet}
+)
+
+// azureADRejections explain the AADSTS error codes that mean the credentials do not work
+var azureADRejections = map[int]string{
+	7000215: "invalid client secret",
+	7000222: "client secret expired",
+	700016:  "application not found in the tenant",
+	90002:   "tenant not found",
+	900023:  "invalid tenant identifier",
+	700023:  "application disabled",
+}
+
+// AzureADValidator implements the Validator interface for Azure AD (Microsoft Entra ID)
+// application credentials, given as "<tenant>:<client id>:<client secret>"
+type AzureADValidator struct {
+	client *http.Client
+}
+
+// NewAzureADValidator creates a new Azure AD validator instance using client,
+// or a client on the shared connection pool with a 10 second timeout when client is nil
+func NewAzureADValidator(client *http.Client) *AzureADValidator {
+	if client == nil {
+		client = transport.DefaultClient(10 * time.Second)
+	}
+	return &AzureADValidator{client: client}
+}
+
+func (v *AzureADValidator) GetService() string {
+	return "Azure AD Client Secret"
+}
+
+func (v *AzureADValidator) GetValidationMethod() validator.ValidationMethod {
+	return validator.MethodHTTP
+}
+
+// Endpoints describes the endpoints probed during validation
+func (v *AzureADValidator) Endpoints() []validator.Endpoint {
+	return []validator.Endpoint{azureADTokenEndpoint, azureADOrganizationEndpoint}
+}
+
+// azureADClaims are the claims of an app-only access token read by the validator
+type azureADClaims struct {
+	TenantID       string   `json:"tid"`
+	AppID          string   `json:"appid"`
+	AppDisplayName string   `json:"app_displayname"`
+	Roles          []string `json:"roles"`
+}
+
+// parseAzureADClaims decodes the payload of an access token without verifying it; the token
+// comes straight from the issuer
+func parseAzureADClaims(token string) (*azureADClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("access token is not a JWT")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode access token: %w", err)
+	}
+	var claims azureADClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("failed to decode access token: %w", err)
+	}
+	return &claims, nil
+}
+
+// azureADPrivileged reports whether an application permission can change the tenant or read
+// mail, files or the directory of every user
+func azureADPrivileged(role string) bool {
+	if strings.Contains(role, "ReadWrite") {
+		return true
+	}
+	for _, prefix := range []string{"RoleManagement.", "AppRoleAssignment.", "Directory.", "Mail.", "Files.", "Sites."} {
+		if strings.HasPrefix(role, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate requests a Microsoft Graph token with the client credentials grant. The
+// application permissions granted to the app are the roles of the token; the tenant is
+// then described with /organization when the app may read it.
+func (v *AzureADValidator) Validate(ctx context.Context, key string) (*validator.ValidationResult, error) {
+	result := newResult(v.GetService())
+	parts := strings.SplitN(strings.TrimSpace(key), ":", 3)
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return reject(result, "malformed credentials: expected <tenant>:<client id>:<client secret>"), nil
+	}
+	tenant, clientID, secret := parts[0], parts[1], parts[2]
+
+	form := url.Values{
+		"client_id":     {clientID},
+		"client_secret": {secret},
+		"grant_type":    {"client_credentials"},
+		"scope":         {"https://graph.microsoft.com/.default"},
+	}
+	body := []byte(form.Encode())
+	tokenURL := "https://login.microsoftonline.com/" + url.PathEscape(tenant) + "/oauth2/v2.0/token"
+	req, err := http.NewRequestWithContext(ctx, azureADTokenEndpoint.Method, tokenURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var grant struct {
+		AccessToken string `json:"access_token"`
+		Error       string `json:"error"`
+		ErrorCodes  []int  `json:"error_codes"`
+	}
+	resp, err := send(v.client, req)
+	if err == nil && (resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized) {
+		err = resp.decode(&grant)
+	}
+	if err != nil {
+		reportProbe(ctx, azureADTokenEndpoint, nil, err, false)
+		return nil, err
+	}
+	reportProbe(ctx, azureADTokenEndpoint, resp, nil, resp.StatusCode == http.StatusOK)
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusBadRequest, http.StatusUnauthorized:
+		for _, code := range grant.ErrorCodes {
+			if reason, ok := azureADRejections[code]; ok {
+				return reject(result, fmt.Sprintf("credentials rejected: %s (AADSTS%d)", reason, code)), nil
+			}
+		}
+		if grant.Error == "invalid_client" || grant.Error == "unauthorized_client" {
+			return reject(result, "credentials rejected: "+grant.Error), nil
+		}
+		return nil, fmt.Errorf("%w: token request failed with %s %v", validator.ErrValidationError, grant.Error, grant.ErrorCodes)
+	default:
+		return nil, fmt.Errorf("%w: unexpected status %d", validator.ErrValidationError, resp.StatusCode)
+	}
+
+	claims, err := parseAzureADClaims(grant.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+	result.Valid = true
+	result.PoC = map[string]string{tokenURL: validator.CurlCommand(req, body)}
+	result.Details["tenant_id"] = claims.TenantID
+	result.Details["app_id"] = claims.AppID
+	result.Details["app_name"] = claims.AppDisplayName
+	result.Permissions = append([]string{}, claims.Roles...)
+	sort.Strings(result.Permissions)
+
+	if err := v.describeTenant(ctx, grant.AccessToken, result); err != nil {
+		result.Details["organization"] = fmt.Sprintf("Error: %v", err)
+	}
+
+	// App-only tokens act on every user and resource of the tenant the permissions cover
+	result.RiskLevel = validator.RiskLevelLow
+	if len(result.Permissions) > 0 {
+		result.RiskLevel = validator.RiskLevelMedium
+	}
+	for _, role := range result.Permissions {
+		if azureADPrivileged(role) {
+			result.RiskLevel = validator.RiskLevelHigh
+			break
+		}
+	}
+	return result, nil
+}
+
+// describeTenant adds the name and verified domains of the tenant to result; apps without
+// Organization.Read.All or Directory.Read.All may not read them
+func (v *AzureADValidator) describeTenant(ctx context.Context, token string, result *validator.ValidationResult) error {
+	req, err := http.NewRequestWithContext(ctx, azureADOrganizationEndpoint.Method, azureADOrganizationEndpoint.URL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	var organizations struct {
+		Value []struct {
+			DisplayName     string `json:"displayName"`
+			VerifiedDomains []struct {
+				Name string `json:"name"`
+			} `json:"verifiedDomains"`
+		} `json:"value"`
+	}
+	resp, err := send(v.client, req)
+	if err == nil && resp.StatusCode == http.StatusOK {
+		err = resp.decode(&organizations)
+	}
+	if err != nil {
+		reportProbe(ctx, azureADOrganizationEndpoint, nil, err, false)
+		return err
+	}
+	reportProbe(ctx, azureADOrganizationEndpoint, resp, nil, resp.StatusCode == http.StatusOK)
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusForbidden:
+		result.Details["organization"] = "Not permitted: requires Organization.Read.All"
+		return nil
+	default:
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	if len(organizations.Value) == 0 {
+		return nil
+	}
+	organization := organizations.Value[0]
+	result.Details["tenant_name"] = organization.DisplayName
+	domains := make([]string, 0, len(organization.VerifiedDomains))
+	for _, domain := range organization.VerifiedDomains {
+		domains = append(domains, domain.Name)
+	}
+	result.Details["verified_domains"] = domains
+	return nil
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/transport"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// azureOpenAIDeploymentsVersion is the last API version listing deployments on the resource
+// endpoint itself; later versions only list them through Azure Resource Manager
+const azureOpenAIDeploymentsVersion = "2022-12-01"
+
+// azureOpenAIVersions are the generally available API versions probed on the models listing
+var azureOpenAIVersions = []string{"2023-05-15", "2024-02-01", "2024-06-01", "2024-10-21"}
+
+// azureOpenAIHosts are the domains of Azure OpenAI resource endpoints; keys are only sent to them
+var azureOpenAIHosts = []string{".openai.azure.com", ".cognitiveservices.azure.com"}
+
+// AzureOpenAIValidator implements the Validator interface for Azure OpenAI resource keys,
+// given as the resource endpoint and the key separated by a colon
+type AzureOpenAIValidator struct {
+	client *http.Client
+}
+
+// NewAzureOpenAIValidator creates a new Azure OpenAI validator instance using client,
+// or a client on the shared connection pool with a 10 second timeout when client is nil
+func NewAzureOpenAIValidator(client *http.Client) *AzureOpenAIValidator {
+	if client == nil {
+		client = transport.DefaultClient(10 * time.Second)
+	}
+	return &AzureOpenAIValidator{client: client}
+}
+
+func (v *AzureOpenAIValidator) GetService() string {
+	return "Azure OpenAI API Key"
+}
+
+func (v *AzureOpenAIValidator) GetValidationMethod() validator.ValidationMethod {
+	return validator.MethodHTTP
+}
+
+// Endpoints describes the endpoints probed during validation; {resource} is the resource endpoint
+func (v *AzureOpenAIValidator) Endpoints() []validator.Endpoint {
+	return []validator.Endpoint{
+		{URL: "https://{resource}/openai/deployments", Method: http.MethodGet},
+		{URL: "https://{resource}/openai/models", Method: http.MethodGet},
+	}
+}
+
+// parseAzureOpenAIKey splits key into the origin of its resource endpoint and the resource key
+func parseAzureOpenAIKey(key string) (string, string, error) {
+	i := strings.LastIndex(key, ":")
+	if i < 0 {
+		return "", "", fmt.Errorf("expected <endpoint>:<key>")
+	}
+	endpoint, err := url.Parse(strings.TrimSpace(key[:i]))
+	if err != nil || endpoint.Scheme != "https" {
+		return "", "", fmt.Errorf("endpoint is not an https URL")
+	}
+	for _, suffix := range azureOpenAIHosts {
+		if strings.HasSuffix(endpoint.Hostname(), suffix) {
+			return "https://" + endpoint.Host, strings.TrimSpace(key[i+1:]), nil
+		}
+	}
+	return "", "", fmt.Errorf("endpoint %s is not an Azure OpenAI resource", endpoint.Host)
+}
+
+// get requests a path of the resource at apiVersion and decodes a successful answer into out
+func (v *AzureOpenAIValidator) get(ctx context.Context, origin, path, apiVersion, key string, out interface{}) (*http.Request, *apiResponse, error) {
+	endpoint := validator.Endpoint{URL: "https://{resource}" + path, Method: http.MethodGet}
+	req, err := http.NewRequestWithContext(ctx, endpoint.Method, origin+path+"?api-version="+apiVersion, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Api-Key", key)
+
+	resp, err := send(v.client, req)
+	if err == nil && resp.StatusCode == http.StatusOK && out != nil {
+		err = resp.decode(out)
+	}
+	if err != nil {
+		reportProbe(ctx, endpoint, nil, err, false)
+		return nil, nil, err
+	}
+	reportProbe(ctx, endpoint, resp, nil, resp.StatusCode == http.StatusOK)
+	return req, resp, nil
+}
+
+// Validate lists the deployments of the resource, whose models the key can call, then probes
+// which API versions the resource serves
+func (v *AzureOpenAIValidator) Validate(ctx context.Context, key string) (*validator.ValidationResult, error) {
+	result := newResult(v.GetService())
+	origin, resourceKey, err := parseAzureOpenAIKey(key)
+	if err != nil {
+		return reject(result, fmt.Sprintf("malformed key: %v", err)), nil
+	}
+
+	var deployments struct {
+		Data []struct {
+			ID     string `json:"id"`
+			Model  string `json:"model"`
+			Status string `json:"status"`
+		} `json:"data"`
+	}
+	req, resp, err := v.get(ctx, origin, "/openai/deployments", azureOpenAIDeploymentsVersion, resourceKey, &deployments)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return reject(result, "key rejected by the resource: invalid or regenerated"), nil
+	default:
+		return nil, fmt.Errorf("%w: unexpected status %d", validator.ErrValidationError, resp.StatusCode)
+	}
+	result.Valid = true
+	result.Details["endpoint"] = origin
+	result.PoC = map[string]string{origin + "/openai/deployments": validator.CurlCommand(req, nil)}
+	for _, deployment := range deployments.Data {
+		result.Permissions = append(result.Permissions, fmt.Sprintf("%s (%s, %s)", deployment.ID, deployment.Model, deployment.Status))
+	}
+	result.Details["deployment_count"] = len(deployments.Data)
+
+	var versions []string
+	for _, version := range azureOpenAIVersions {
+		if _, resp, err := v.get(ctx, origin, "/openai/models", version, resourceKey, nil); err != nil {
+			result.Details[version] = fmt.Sprintf("Error: %v", err)
+		} else if resp.StatusCode == http.StatusOK {
+			versions = append(versions, version)
+		}
+	}
+	result.Details["api_versions"] = versions
+
+	// Every deployment runs completions billed to the owner's subscription
+	result.RiskLevel = validator.RiskLevelMedium
+	if len(deployments.Data) > 0 {
+		result.RiskLevel = validator.RiskLevelHigh
+	}
+	return result, nil
+}
@@ -0,0 +1,372 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/transport"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+const (
+	// azureStorageVersion is the Blob service REST version requests are made with
+	azureStorageVersion = "2021-08-06"
+	// azureStorageSuffix is the endpoint suffix of storage accounts in the public cloud
+	azureStorageSuffix = "core.windows.net"
+	// azureStorageSample is how many containers or blobs are listed
+	azureStorageSample = 20
+)
+
+var (
+	azureAccountPropertiesEndpoint = validator.Endpoint{URL: "https://{account}.blob.{suffix}/?restype=account&comp=properties", Method: http.MethodGet}
+	azureListContainersEndpoint    = validator.Endpoint{URL: "https://{account}.blob.{suffix}/?comp=list", Method: http.MethodGet}
+	azureListBlobsEndpoint         = validator.Endpoint{URL: "https://{account}.blob.{suffix}/{container}?restype=container&comp=list", Method: http.MethodGet}
+	azureBlobPropertiesEndpoint    = validator.Endpoint{URL: "https://{account}.blob.{suffix}/{container}/{blob}", Method: http.MethodHead}
+)
+
+// azureSASPermissions names the letters of the sp field of a SAS
+var azureSASPermissions = map[rune]string{
+	'r': "read", 'a': "add", 'c': "create", 'w': "write", 'd': "delete", 'x': "delete version",
+	'y': "permanent delete", 'l': "list", 't': "tags", 'f': "filter", 'm': "move", 'e': "execute",
+	'i': "set immutability policy", 'o': "ownership", 'p': "permissions", 'u': "update", 'v': "create version",
+}
+
+// azureSASWrites are the sp letters that change or destroy data
+const azureSASWrites = "acwdxymiopu"
+
+// azureEnumeration is a container or blob listing of the Blob service
+type azureEnumeration struct {
+	Containers []struct {
+		Name string `xml:"Name"`
+	} `xml:"Containers>Container"`
+	Blobs []struct {
+		Name string `xml:"Name"`
+	} `xml:"Blobs>Blob"`
+}
+
+// names returns the names of the containers and blobs listed
+func (e *azureEnumeration) names() []string {
+	names := make([]string, 0, len(e.Containers)+len(e.Blobs))
+	for _, container := range e.Containers {
+		names = append(names, container.Name)
+	}
+	for _, blob := range e.Blobs {
+		names = append(names, blob.Name)
+	}
+	return names
+}
+
+// azureStorageCall sends req and decodes a listing answered with 200 into out when out is set.
+// Blob service errors are named by the x-ms-error-code header.
+func azureStorageCall(ctx context.Context, client *http.Client, endpoint validator.Endpoint, req *http.Request, out *azureEnumeration) (*apiResponse, error) {
+	resp, err := send(client, req)
+	if err == nil && resp.StatusCode == http.StatusOK && out != nil {
+		if err = xml.Unmarshal(resp.Body, out); err != nil {
+			err = fmt.Errorf("unexpected response: %w", err)
+		}
+	}
+	if err != nil {
+		reportProbe(ctx, endpoint, nil, err, false)
+		return nil, err
+	}
+	reportProbe(ctx, endpoint, resp, nil, resp.StatusCode == http.StatusOK)
+	return resp, nil
+}
+
+// AzureStorageKeyValidator implements the Validator interface for Azure Storage account keys,
+// given as a connection string or as "<account>:<key>"
+type AzureStorageKeyValidator struct {
+	client *http.Client
+}
+
+// NewAzureStorageKeyValidator creates a new Azure Storage account key validator instance using
+// client, or a client on the shared connection pool with a 10 second timeout when client is nil
+func NewAzureStorageKeyValidator(client *http.Client) *AzureStorageKeyValidator {
+	if client == nil {
+		client = transport.DefaultClient(10 * time.Second)
+	}
+	return &AzureStorageKeyValidator{client: client}
+}
+
+func (v *AzureStorageKeyValidator) GetService() string {
+	return "Azure Storage Account Key"
+}
+
+func (v *AzureStorageKeyValidator) GetValidationMethod() validator.ValidationMethod {
+	return validator.MethodHTTP
+}
+
+// Endpoints describes the endpoints probed during validation
+func (v *AzureStorageKeyValidator) Endpoints() []validator.Endpoint {
+	return []validator.Endpoint{azureAccountPropertiesEndpoint, azureListContainersEndpoint}
+}
+
+// parseAzureStorageKey returns the account name, decoded key and endpoint suffix of a
+// connection string or "<account>:<key>" pair
+func parseAzureStorageKey(key string) (string, []byte, string, error) {
+	account, encoded, suffix := "", "", azureStorageSuffix
+	if name, secret, ok := strings.Cut(key, ":"); ok && !strings.Contains(key, ";") {
+		account, encoded = name, secret
+	} else {
+		for _, field := range strings.Split(key, ";") {
+			name, value, _ := strings.Cut(strings.TrimSpace(field), "=")
+			switch strings.ToLower(name) {
+			case "accountname":
+				account = value
+			case "accountkey":
+				encoded = value
+			case "endpointsuffix":
+				suffix = value
+			}
+		}
+	}
+	if account == "" || encoded == "" {
+		return "", nil, "", fmt.Errorf("expected a connection string or <account>:<key>")
+	}
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", nil, "", fmt.Errorf("account key is not base64: %w", err)
+	}
+	return account, decoded, suffix, nil
+}
+
+// signAzureStorage signs req with the account key using the Shared Key scheme of the Blob service
+func signAzureStorage(req *http.Request, account string, key []byte) {
+	req.Header.Set("X-Ms-Date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("X-Ms-Version", azureStorageVersion)
+
+	var headers []string
+	for name := range req.Header {
+		if name := strings.ToLower(name); strings.HasPrefix(name, "x-ms-") {
+			headers = append(headers, name+":"+strings.TrimSpace(req.Header.Get(name)))
+		}
+	}
+	sort.Strings(headers)
+
+	resource := "/" + account + req.URL.EscapedPath()
+	if req.URL.Path == "" {
+		resource += "/"
+	}
+	query := req.URL.Query()
+	params := make([]string, 0, len(query))
+	for name := range query {
+		params = append(params, name)
+	}
+	sort.Strings(params)
+	for _, name := range params {
+		values := append([]string{}, query[name]...)
+		sort.Strings(values)
+		resource += "\n" + strings.ToLower(name) + ":" + strings.Join(values, ",")
+	}
+
+	// Verb, then the standard headers from Content-Encoding to Range, none of which are sent
+	stringToSign := req.Method + strings.Repeat("\n", 12) + strings.Join(headers, "\n") + "\n" + resource
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(stringToSign))
+	req.Header.Set("Authorization", "SharedKey "+account+":"+base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+}
+
+// Validate reads the account properties with a Shared Key signed request, then lists the
+// containers of the account. A working account key is full control of the account.
+func (v *AzureStorageKeyValidator) Validate(ctx context.Context, key string) (*validator.ValidationResult, error) {
+	result := newResult(v.GetService())
+	account, accountKey, suffix, err := parseAzureStorageKey(strings.TrimSpace(key))
+	if err != nil {
+		return reject(result, fmt.Sprintf("malformed key: %v", err)), nil
+	}
+	origin := "https://" + account + ".blob." + suffix
+
+	req, err := http.NewRequestWithContext(ctx, azureAccountPropertiesEndpoint.Method, origin+"/?restype=account&comp=properties", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	signAzureStorage(req, account, accountKey)
+	resp, err := azureStorageCall(ctx, v.client, azureAccountPropertiesEndpoint, req, nil)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusForbidden:
+		return reject(result, "account key rejected: invalid or rotated ("+resp.Header.Get("X-Ms-Error-Code")+")"), nil
+	default:
+		return nil, fmt.Errorf("%w: unexpected status %d", validator.ErrValidationError, resp.StatusCode)
+	}
+
+	result.Valid = true
+	result.Permissions = []string{"full control of the blob, file, queue and table services"}
+	// The signature expires with x-ms-date, so the PoC only replays for a few minutes
+	result.PoC = map[string]string{req.URL.String(): validator.CurlCommand(req, nil)}
+	result.Details["account"] = account
+	result.Details["sku"] = resp.Header.Get("X-Ms-Sku-Name")
+	result.Details["account_kind"] = resp.Header.Get("X-Ms-Account-Kind")
+
+	req, err = http.NewRequestWithContext(ctx, azureListContainersEndpoint.Method, fmt.Sprintf("%s/?comp=list&maxresults=%d", origin, azureStorageSample), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	signAzureStorage(req, account, accountKey)
+	var containers azureEnumeration
+	if resp, err := azureStorageCall(ctx, v.client, azureListContainersEndpoint, req, &containers); err != nil {
+		result.Details["containers"] = fmt.Sprintf("Error: %v", err)
+	} else if resp.StatusCode != http.StatusOK {
+		result.Details["containers"] = fmt.Sprintf("Error: unexpected status %d", resp.StatusCode)
+	} else {
+		result.Details["containers"] = containers.names()
+	}
+
+	result.RiskLevel = validator.RiskLevelHigh
+	return result, nil
+}
+
+// AzureStorageSASValidator implements the Validator interface for Blob Storage shared access
+// signature (SAS) URLs
+type AzureStorageSASValidator struct {
+	client *http.Client
+}
+
+// NewAzureStorageSASValidator creates a new Azure Storage SAS validator instance using client,
+// or a client on the shared connection pool with a 10 second timeout when client is nil
+func NewAzureStorageSASValidator(client *http.Client) *AzureStorageSASValidator {
+	if client == nil {
+		client = transport.DefaultClient(10 * time.Second)
+	}
+	return &AzureStorageSASValidator{client: client}
+}
+
+func (v *AzureStorageSASValidator) GetService() string {
+	return "Azure Storage SAS URL"
+}
+
+func (v *AzureStorageSASValidator) GetValidationMethod() validator.ValidationMethod {
+	return validator.MethodHTTP
+}
+
+// Endpoints describes the endpoints probed during validation; one of them is called, depending
+// on what the SAS grants access to
+func (v *AzureStorageSASValidator) Endpoints() []validator.Endpoint {
+	return []validator.Endpoint{azureListContainersEndpoint, azureListBlobsEndpoint, azureBlobPropertiesEndpoint}
+}
+
+// parseSASTime parses the st and se fields of a SAS, given to the day, minute or second
+func parseSASTime(value string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04Z", "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid SAS time %q", value)
+}
+
+// Validate decodes the permissions and lifetime of the SAS, then uses it the way it is scoped:
+// listing the containers of an account SAS, the blobs of a container SAS or reading the
+// properties of a blob. Requests are made to the URL as given, so the signature is not altered.
+func (v *AzureStorageSASValidator) Validate(ctx context.Context, key string) (*validator.ValidationResult, error) {
+	result := newResult(v.GetService())
+	key = strings.TrimSpace(key)
+	sasURL, err := url.Parse(key)
+	if err != nil || sasURL.Scheme != "https" || sasURL.Query().Get("sig") == "" {
+		return reject(result, "malformed key: expected an https URL with a sig parameter"), nil
+	}
+	account, host, _ := strings.Cut(sasURL.Hostname(), ".")
+	service, _, _ := strings.Cut(host, ".")
+	if service != "blob" {
+		return nil, fmt.Errorf("%w: only Blob Storage SAS URLs can be probed, not %s", validator.ErrValidationError, service)
+	}
+
+	sas := sasURL.Query()
+	result.Details["account"] = account
+	for field, name := range map[string]string{"se": "expiry", "st": "start", "ss": "services", "srt": "resource_types", "sr": "resource", "sip": "allowed_ips", "spr": "protocols", "si": "stored_access_policy", "sv": "version"} {
+		if value := sas.Get(field); value != "" {
+			result.Details[name] = value
+		}
+	}
+	var permissions []string
+	for _, letter := range sas.Get("sp") {
+		if name, ok := azureSASPermissions[letter]; ok {
+			permissions = append(permissions, name)
+		}
+	}
+	if expiry := sas.Get("se"); expiry != "" {
+		expires, err := parseSASTime(expiry)
+		if err != nil {
+			return reject(result, fmt.Sprintf("malformed key: %v", err)), nil
+		}
+		if time.Now().After(expires) {
+			return reject(result, "SAS expired at "+expiry), nil
+		}
+	}
+
+	// The probe parameters are appended to the URL as given, which keeps it redactable
+	container, blob, _ := strings.Cut(strings.Trim(sasURL.Path, "/"), "/")
+	endpoint, method, probeURL := azureListContainersEndpoint, http.MethodGet, fmt.Sprintf("%s&comp=list&maxresults=%d", key, azureStorageSample)
+	switch {
+	case blob != "":
+		endpoint, method, probeURL = azureBlobPropertiesEndpoint, http.MethodHead, key
+	case container != "":
+		endpoint, probeURL = azureListBlobsEndpoint, fmt.Sprintf("%s&restype=container&comp=list&maxresults=%d", key, azureStorageSample)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, probeURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-Ms-Version", azureStorageVersion)
+	var listing *azureEnumeration
+	if method == http.MethodGet {
+		listing = &azureEnumeration{}
+	}
+	resp, err := azureStorageCall(ctx, v.client, endpoint, req, listing)
+	if err != nil {
+		return nil, err
+	}
+
+	switch code := resp.Header.Get("X-Ms-Error-Code"); {
+	case resp.StatusCode == http.StatusOK:
+		if listing != nil {
+			result.Details["listed"] = listing.names()
+		} else {
+			result.Details["content_type"] = resp.Header.Get("Content-Type")
+			result.Details["content_length"] = resp.Header.Get("Content-Length")
+			metadata := make(map[string]string)
+			for name := range resp.Header {
+				if field, ok := strings.CutPrefix(strings.ToLower(name), "x-ms-meta-"); ok {
+					metadata[field] = resp.Header.Get(name)
+				}
+			}
+			result.Details["metadata"] = metadata
+		}
+	case resp.StatusCode == http.StatusNotFound:
+		// The signature is checked before the resource is looked up
+		result.Details["probe"] = "resource not found: " + code
+	case resp.StatusCode == http.StatusForbidden && strings.HasPrefix(code, "Authorization"):
+		// Signed, but the probe is outside the permissions, resource types or IPs of the SAS
+		result.Details["probe"] = "not permitted: " + code
+	case resp.StatusCode == http.StatusForbidden:
+		return reject(result, "SAS rejected: signature invalid or its stored access policy revoked ("+code+")"), nil
+	default:
+		return nil, fmt.Errorf("%w: unexpected status %d", validator.ErrValidationError, resp.StatusCode)
+	}
+
+	result.Valid = true
+	result.Permissions = permissions
+	result.PoC = map[string]string{"https://" + sasURL.Host + sasURL.Path: validator.CurlCommand(req, nil)}
+
+	// Write and delete permissions let the holder plant or destroy data, reads expose it
+	result.RiskLevel = validator.RiskLevelLow
+	if strings.ContainsAny(sas.Get("sp"), "rl") {
+		result.RiskLevel = validator.RiskLevelMedium
+	}
+	if strings.ContainsAny(sas.Get("sp"), azureSASWrites) {
+		result.RiskLevel = validator.RiskLevelHigh
+	}
+	return result, nil
+}
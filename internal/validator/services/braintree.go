@@ -0,0 +1,196 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/transport"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// braintreeEnvironments maps the Braintree environments to their GraphQL API, production first
+var braintreeEnvironments = []struct {
+	Name string
+	URL  string
+}{
+	{"production", "https://payments.braintree-api.com/graphql"},
+	{"sandbox", "https://payments.sandbox.braintree-api.com/graphql"},
+}
+
+// braintreeMerchantQuery reads the merchant behind the credentials and its merchant accounts
+const braintreeMerchantQuery = `query { viewer { merchant { id companyName merchantAccounts { edges { node { id currencyCode } } } } } }`
+
+// BraintreeValidator implements the Validator interface for Braintree API credentials: key
+// pairs given as "<public key>:<private key>" and access tokens (access_token$production$...)
+type BraintreeValidator struct {
+	client *http.Client
+}
+
+// NewBraintreeValidator creates a new Braintree validator instance using client,
+// or a client on the shared connection pool with a 10 second timeout when client is nil
+func NewBraintreeValidator(client *http.Client) *BraintreeValidator {
+	if client == nil {
+		client = transport.DefaultClient(10 * time.Second)
+	}
+	return &BraintreeValidator{client: client}
+}
+
+func (v *BraintreeValidator) GetService() string {
+	return "Braintree API Key"
+}
+
+func (v *BraintreeValidator) GetValidationMethod() validator.ValidationMethod {
+	return validator.MethodHTTP
+}
+
+// Endpoints describes the endpoints probed during validation, in each environment
+func (v *BraintreeValidator) Endpoints() []validator.Endpoint {
+	endpoints := make([]validator.Endpoint, 0, len(braintreeEnvironments))
+	for _, env := range braintreeEnvironments {
+		endpoints = append(endpoints, validator.Endpoint{URL: env.URL, Method: http.MethodPost})
+	}
+	return endpoints
+}
+
+// braintreeAnswer is the envelope of a GraphQL response
+type braintreeAnswer struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// query sends a GraphQL query to url, authorizing it with authorize, and returns the request
+// with its body for PoC generation along with the answer
+func (v *BraintreeValidator) query(ctx context.Context, url, query string, authorize func(*http.Request)) (*http.Request, []byte, *apiResponse, error) {
+	endpoint := validator.Endpoint{URL: url, Method: http.MethodPost}
+	body, err := json.Marshal(map[string]string{"query": query})
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to marshal query: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, endpoint.Method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	authorize(req)
+	req.Header.Set("Braintree-Version", "2019-01-01")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := send(v.client, req)
+	if err != nil {
+		reportProbe(ctx, endpoint, nil, err, false)
+		return nil, nil, nil, err
+	}
+	reportProbe(ctx, endpoint, resp, nil, resp.StatusCode == http.StatusOK)
+	return req, body, resp, nil
+}
+
+// Validate pings the GraphQL API with the credentials, in the environment named by an access
+// token or else in production then in the sandbox, and reads the merchant behind them
+func (v *BraintreeValidator) Validate(ctx context.Context, key string) (*validator.ValidationResult, error) {
+	result := newResult(v.GetService())
+	key = strings.TrimSpace(key)
+
+	environments := braintreeEnvironments
+	var authorize func(*http.Request)
+	if strings.HasPrefix(key, "access_token$") {
+		parts := strings.Split(key, "$")
+		if len(parts) != 4 {
+			return reject(result, "malformed access token: expected access_token$<environment>$<merchant id>$<token>"), nil
+		}
+		environments = nil
+		for _, env := range braintreeEnvironments {
+			if env.Name == parts[1] {
+				environments = append(environments, env)
+			}
+		}
+		if len(environments) == 0 {
+			return reject(result, fmt.Sprintf("malformed access token: unknown environment %q", parts[1])), nil
+		}
+		authorize = func(req *http.Request) { req.Header.Set("Authorization", "Bearer "+key) }
+	} else {
+		publicKey, privateKey, ok := strings.Cut(key, ":")
+		if !ok || publicKey == "" || privateKey == "" {
+			return reject(result, "malformed credentials: expected <public key>:<private key>"), nil
+		}
+		authorize = func(req *http.Request) { req.SetBasicAuth(publicKey, privateKey) }
+	}
+
+	for _, env := range environments {
+		req, body, resp, err := v.query(ctx, env.URL, "{ ping }", authorize)
+		if err != nil {
+			return nil, err
+		}
+		switch resp.StatusCode {
+		case http.StatusOK:
+		case http.StatusUnauthorized:
+			continue
+		default:
+			return nil, fmt.Errorf("%w: unexpected status %d from %s", validator.ErrValidationError, resp.StatusCode, env.Name)
+		}
+
+		result.Valid = true
+		result.Details["environment"] = env.Name
+		result.PoC = map[string]string{env.URL: validator.CurlCommand(req, body)}
+		v.describeMerchant(ctx, env.URL, authorize, result)
+
+		// Production credentials can charge, refund and read the customers of the merchant
+		result.RiskLevel = validator.RiskLevelHigh
+		if env.Name == "sandbox" {
+			result.RiskLevel = validator.RiskLevelLow
+		}
+		return result, nil
+	}
+	return reject(result, "credentials rejected in production and the sandbox"), nil
+}
+
+// describeMerchant adds the merchant and its merchant accounts to result. Credentials
+// without the permission to read them are still valid, so failures are only noted.
+func (v *BraintreeValidator) describeMerchant(ctx context.Context, url string, authorize func(*http.Request), result *validator.ValidationResult) {
+	_, _, resp, err := v.query(ctx, url, braintreeMerchantQuery, authorize)
+	if err != nil {
+		result.Details["merchant"] = fmt.Sprintf("Error: %v", err)
+		return
+	}
+	var answer braintreeAnswer
+	var data struct {
+		Viewer struct {
+			Merchant struct {
+				ID               string `json:"id"`
+				CompanyName      string `json:"companyName"`
+				MerchantAccounts struct {
+					Edges []struct {
+						Node struct {
+							ID           string `json:"id"`
+							CurrencyCode string `json:"currencyCode"`
+						} `json:"node"`
+					} `json:"edges"`
+				} `json:"merchantAccounts"`
+			} `json:"merchant"`
+		} `json:"viewer"`
+	}
+	if err := resp.decode(&answer); err != nil {
+		result.Details["merchant"] = fmt.Sprintf("Error: %v", err)
+		return
+	}
+	if len(answer.Errors) > 0 {
+		result.Details["merchant"] = fmt.Sprintf("Error: %s", answer.Errors[0].Message)
+		return
+	}
+	if err := json.Unmarshal(answer.Data, &data); err != nil {
+		result.Details["merchant"] = fmt.Sprintf("Error: unexpected response: %v", err)
+		return
+	}
+
+	merchant := data.Viewer.Merchant
+	result.Details["merchant_id"] = merchant.ID
+	result.Details["company_name"] = merchant.CompanyName
+	for _, edge := range merchant.MerchantAccounts.Edges {
+		result.Permissions = append(result.Permissions, fmt.Sprintf("merchant account %s (%s)", edge.Node.ID, edge.Node.CurrencyCode))
+	}
+}
@@ -0,0 +1,348 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/transport"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+const cloudflareAPI = "https://api.cloudflare.com/client/v4"
+
+var (
+	cloudflareVerifyEndpoint        = validator.Endpoint{URL: cloudflareAPI + "/user/tokens/verify", Method: http.MethodGet}
+	cloudflareAccountVerifyEndpoint = validator.Endpoint{URL: cloudflareAPI + "/accounts/{account_id}/tokens/verify", Method: http.MethodGet}
+	cloudflareTokenEndpoint         = validator.Endpoint{URL: cloudflareAPI + "/user/tokens/{token_id}", Method: http.MethodGet}
+	cloudflareAccountTokenEndpoint  = validator.Endpoint{URL: cloudflareAPI + "/accounts/{account_id}/tokens/{token_id}", Method: http.MethodGet}
+	cloudflareUserEndpoint          = validator.Endpoint{URL: cloudflareAPI + "/user", Method: http.MethodGet}
+	cloudflareZonesEndpoint         = validator.Endpoint{URL: cloudflareAPI + "/zones", Method: http.MethodGet}
+	cloudflareAccountsEndpoint      = validator.Endpoint{URL: cloudflareAPI + "/accounts", Method: http.MethodGet}
+)
+
+// cloudflareMaxListed is the page size of the zone and account listings
+const cloudflareMaxListed = 50
+
+// cloudflareEnvelope is the envelope of every Cloudflare API answer
+type cloudflareEnvelope struct {
+	Success bool `json:"success"`
+	Errors  []struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+	Result json.RawMessage `json:"result"`
+}
+
+// cloudflareZone is a zone as listed by /zones; the permissions are those of the caller
+type cloudflareZone struct {
+	Name        string   `json:"name"`
+	Status      string   `json:"status"`
+	Permissions []string `json:"permissions"`
+}
+
+// cloudflareCall requests a Cloudflare API path authenticated by auth, unwrapping the result
+// of a successful answer into out
+func cloudflareCall(ctx context.Context, client *http.Client, endpoint validator.Endpoint, path string, auth func(*http.Request), out interface{}) (*http.Request, *apiResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cloudflareAPI+path, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	auth(req)
+
+	var envelope cloudflareEnvelope
+	resp, err := send(client, req)
+	if err == nil && resp.StatusCode == http.StatusOK {
+		if err = resp.decode(&envelope); err == nil && out != nil {
+			err = json.Unmarshal(envelope.Result, out)
+		}
+	}
+	if err != nil {
+		reportProbe(ctx, endpoint, nil, err, false)
+		return nil, nil, err
+	}
+	reportProbe(ctx, endpoint, resp, nil, resp.StatusCode == http.StatusOK)
+	return req, resp, nil
+}
+
+// cloudflareListError describes a listing the credentials may not read
+func cloudflareListError(resp *apiResponse) string {
+	var envelope cloudflareEnvelope
+	json.Unmarshal(resp.Body, &envelope)
+	if len(envelope.Errors) > 0 {
+		return fmt.Sprintf("Error: status %d: %s", resp.StatusCode, envelope.Errors[0].Message)
+	}
+	return fmt.Sprintf("Error: unexpected status %d", resp.StatusCode)
+}
+
+// listCloudflare adds the zones and accounts the credentials can see to result and returns
+// the zones. Failed listings are recorded in the details, since the credentials already proved valid.
+func listCloudflare(ctx context.Context, client *http.Client, auth func(*http.Request), result *validator.ValidationResult) []cloudflareZone {
+	var zones []cloudflareZone
+	if _, resp, err := cloudflareCall(ctx, client, cloudflareZonesEndpoint, fmt.Sprintf("/zones?per_page=%d", cloudflareMaxListed), auth, &zones); err != nil {
+		result.Details["zones"] = fmt.Sprintf("Error: %v", err)
+	} else if resp.StatusCode != http.StatusOK {
+		result.Details["zones"] = cloudflareListError(resp)
+	} else {
+		names := make([]string, 0, len(zones))
+		for _, zone := range zones {
+			names = append(names, fmt.Sprintf("%s (%s)", zone.Name, zone.Status))
+		}
+		result.Details["zones"] = names
+	}
+
+	var accounts []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	if _, resp, err := cloudflareCall(ctx, client, cloudflareAccountsEndpoint, fmt.Sprintf("/accounts?per_page=%d", cloudflareMaxListed), auth, &accounts); err != nil {
+		result.Details["accounts"] = fmt.Sprintf("Error: %v", err)
+	} else if resp.StatusCode != http.StatusOK {
+		result.Details["accounts"] = cloudflareListError(resp)
+	} else {
+		names := make([]string, 0, len(accounts))
+		for _, account := range accounts {
+			names = append(names, fmt.Sprintf("%s (%s)", account.Name, account.ID))
+		}
+		result.Details["accounts"] = names
+	}
+	return zones
+}
+
+// CloudflareTokenValidator implements the Validator interface for Cloudflare API tokens
+type CloudflareTokenValidator struct {
+	client *http.Client
+}
+
+// NewCloudflareTokenValidator creates a new Cloudflare API token validator instance using client,
+// or a client on the shared connection pool with a 10 second timeout when client is nil
+func NewCloudflareTokenValidator(client *http.Client) *CloudflareTokenValidator {
+	if client == nil {
+		client = transport.DefaultClient(10 * time.Second)
+	}
+	return &CloudflareTokenValidator{client: client}
+}
+
+func (v *CloudflareTokenValidator) GetService() string {
+	return "Cloudflare API Token"
+}
+
+func (v *CloudflareTokenValidator) GetValidationMethod() validator.ValidationMethod {
+	return validator.MethodHTTP
+}
+
+// Endpoints describes the endpoints probed during validation
+func (v *CloudflareTokenValidator) Endpoints() []validator.Endpoint {
+	return []validator.Endpoint{
+		cloudflareVerifyEndpoint, cloudflareAccountVerifyEndpoint, cloudflareTokenEndpoint, cloudflareAccountTokenEndpoint,
+		cloudflareZonesEndpoint, cloudflareAccountsEndpoint,
+	}
+}
+
+// cloudflareTokenStatus is the answer of a token verify endpoint
+type cloudflareTokenStatus struct {
+	ID        string `json:"id"`
+	Status    string `json:"status"`
+	ExpiresOn string `json:"expires_on"`
+	Owner     string `json:"-"` // path of the user or account owning the token, such as /user
+}
+
+// verify checks the token as a user token, then as a token owned by one of the accounts it
+// can list; account tokens are unknown to the user endpoint
+func (v *CloudflareTokenValidator) verify(ctx context.Context, auth func(*http.Request)) (*cloudflareTokenStatus, *http.Request, error) {
+	var status cloudflareTokenStatus
+	req, resp, err := cloudflareCall(ctx, v.client, cloudflareVerifyEndpoint, "/user/tokens/verify", auth, &status)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		status.Owner = "/user"
+		return &status, req, nil
+	case http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden:
+	default:
+		return nil, nil, fmt.Errorf("%w: unexpected status %d", validator.ErrValidationError, resp.StatusCode)
+	}
+
+	var accounts []struct {
+		ID string `json:"id"`
+	}
+	if _, resp, err := cloudflareCall(ctx, v.client, cloudflareAccountsEndpoint, "/accounts", auth, &accounts); err != nil {
+		return nil, nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, nil, nil
+	}
+	for _, account := range accounts {
+		req, resp, err := cloudflareCall(ctx, v.client, cloudflareAccountVerifyEndpoint, "/accounts/"+account.ID+"/tokens/verify", auth, &status)
+		if err != nil {
+			return nil, nil, err
+		}
+		if resp.StatusCode == http.StatusOK {
+			status.Owner = "/accounts/" + account.ID
+			return &status, req, nil
+		}
+	}
+	return nil, nil, nil
+}
+
+// Validate verifies the token, reads its policies when the token may, and lists the zones and
+// accounts it can see. Zone permissions and policy permission groups become the permissions of
+// the finding.
+func (v *CloudflareTokenValidator) Validate(ctx context.Context, key string) (*validator.ValidationResult, error) {
+	result := newResult(v.GetService())
+	key = strings.TrimSpace(key)
+	auth := func(req *http.Request) { req.Header.Set("Authorization", "Bearer "+key) }
+
+	status, req, err := v.verify(ctx, auth)
+	if err != nil {
+		return nil, err
+	}
+	if status == nil {
+		return reject(result, "token rejected: invalid or deleted"), nil
+	}
+	if status.Status != "active" {
+		return reject(result, "token is "+status.Status), nil
+	}
+	result.Valid = true
+	result.PoC = map[string]string{req.URL.String(): validator.CurlCommand(req, nil)}
+	result.Details["token_id"] = status.ID
+	if status.ExpiresOn != "" {
+		result.Details["expires_on"] = status.ExpiresOn
+	}
+
+	// Only tokens allowed to read API tokens can read their own policies
+	granted := make(map[string]bool)
+	var token struct {
+		Policies []struct {
+			Effect           string            `json:"effect"`
+			Resources        map[string]string `json:"resources"`
+			PermissionGroups []struct {
+				Name string `json:"name"`
+			} `json:"permission_groups"`
+		} `json:"policies"`
+	}
+	tokenEndpoint := cloudflareTokenEndpoint
+	if status.Owner != "/user" {
+		tokenEndpoint = cloudflareAccountTokenEndpoint
+	}
+	if _, resp, err := cloudflareCall(ctx, v.client, tokenEndpoint, status.Owner+"/tokens/"+status.ID, auth, &token); err != nil {
+		result.Details["policies"] = fmt.Sprintf("Error: %v", err)
+	} else if resp.StatusCode != http.StatusOK {
+		result.Details["policies"] = "Not permitted: requires the API Tokens Read permission"
+	} else {
+		var policies []string
+		for _, policy := range token.Policies {
+			var groups, resources []string
+			for _, group := range policy.PermissionGroups {
+				groups = append(groups, group.Name)
+				if policy.Effect == "allow" {
+					granted[group.Name] = true
+				}
+			}
+			for resource := range policy.Resources {
+				resources = append(resources, resource)
+			}
+			sort.Strings(resources)
+			policies = append(policies, fmt.Sprintf("%s %s on %s", policy.Effect, strings.Join(groups, ", "), strings.Join(resources, ", ")))
+		}
+		result.Details["policies"] = policies
+	}
+
+	for _, zone := range listCloudflare(ctx, v.client, auth, result) {
+		for _, permission := range zone.Permissions {
+			granted[permission] = true
+		}
+	}
+	for permission := range granted {
+		result.Permissions = append(result.Permissions, permission)
+	}
+	sort.Strings(result.Permissions)
+
+	// Edit permissions can repoint DNS, change firewall rules or deploy workers on the zones
+	result.RiskLevel = validator.RiskLevelLow
+	if len(result.Permissions) > 0 {
+		result.RiskLevel = validator.RiskLevelMedium
+	}
+	for _, permission := range result.Permissions {
+		if strings.HasSuffix(permission, ":edit") || strings.HasSuffix(permission, " Edit") || strings.HasSuffix(permission, " Write") {
+			result.RiskLevel = validator.RiskLevelHigh
+			break
+		}
+	}
+	return result, nil
+}
+
+// CloudflareGlobalKeyValidator implements the Validator interface for legacy Cloudflare Global
+// API keys, given as "<email>:<key>"
+type CloudflareGlobalKeyValidator struct {
+	client *http.Client
+}
+
+// NewCloudflareGlobalKeyValidator creates a new Cloudflare Global API key validator instance using
+// client, or a client on the shared connection pool with a 10 second timeout when client is nil
+func NewCloudflareGlobalKeyValidator(client *http.Client) *CloudflareGlobalKeyValidator {
+	if client == nil {
+		client = transport.DefaultClient(10 * time.Second)
+	}
+	return &CloudflareGlobalKeyValidator{client: client}
+}
+
+func (v *CloudflareGlobalKeyValidator) GetService() string {
+	return "Cloudflare Global API Key"
+}
+
+func (v *CloudflareGlobalKeyValidator) GetValidationMethod() validator.ValidationMethod {
+	return validator.MethodHTTP
+}
+
+// Endpoints describes the endpoints probed during validation
+func (v *CloudflareGlobalKeyValidator) Endpoints() []validator.Endpoint {
+	return []validator.Endpoint{cloudflareUserEndpoint, cloudflareZonesEndpoint, cloudflareAccountsEndpoint}
+}
+
+// Validate reads the user of the key, then lists the zones and accounts it controls. The
+// Global API key acts as the user on everything the user can reach.
+func (v *CloudflareGlobalKeyValidator) Validate(ctx context.Context, key string) (*validator.ValidationResult, error) {
+	result := newResult(v.GetService())
+	i := strings.LastIndex(strings.TrimSpace(key), ":")
+	if i <= 0 {
+		return reject(result, "malformed key: expected <email>:<key>"), nil
+	}
+	email, globalKey := strings.TrimSpace(key)[:i], strings.TrimSpace(key)[i+1:]
+	auth := func(req *http.Request) {
+		req.Header.Set("X-Auth-Email", email)
+		req.Header.Set("X-Auth-Key", globalKey)
+	}
+
+	var user struct {
+		ID                   string `json:"id"`
+		Email                string `json:"email"`
+		TwoFactorAuthEnabled bool   `json:"two_factor_authentication_enabled"`
+	}
+	req, resp, err := cloudflareCall(ctx, v.client, cloudflareUserEndpoint, "/user", auth, &user)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden:
+		return reject(result, "key rejected: unknown email or key"), nil
+	default:
+		return nil, fmt.Errorf("%w: unexpected status %d", validator.ErrValidationError, resp.StatusCode)
+	}
+
+	result.Valid = true
+	result.Permissions = []string{"full access to every account and zone of the user"}
+	result.PoC = map[string]string{req.URL.String(): validator.CurlCommand(req, nil)}
+	result.Details["user_id"] = user.ID
+	result.Details["email"] = user.Email
+	result.Details["two_factor_enabled"] = user.TwoFactorAuthEnabled
+	listCloudflare(ctx, v.client, auth, result)
+
+	result.RiskLevel = validator.RiskLevelHigh
+	return result, nil
+}
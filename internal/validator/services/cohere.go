@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/transport"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// Cohere API endpoints called during validation
+var (
+	cohereCheckEndpoint  = validator.Endpoint{URL: "https://api.cohere.com/v1/check-api-key", Method: http.MethodPost}
+	cohereModelsEndpoint = validator.Endpoint{URL: "https://api.cohere.com/v1/models", Method: http.MethodGet}
+)
+
+// cohereTrialHeader is only sent in answers to trial keys, which have a monthly call allowance
+const cohereTrialHeader = "X-Trial-Endpoint-Call-Limit"
+
+// CohereValidator implements the Validator interface for Cohere API keys
+type CohereValidator struct {
+	client *http.Client
+}
+
+// NewCohereValidator creates a new Cohere validator instance using client,
+// or a client on the shared connection pool with a 10 second timeout when client is nil
+func NewCohereValidator(client *http.Client) *CohereValidator {
+	if client == nil {
+		client = transport.DefaultClient(10 * time.Second)
+	}
+	return &CohereValidator{client: client}
+}
+
+func (v *CohereValidator) GetService() string {
+	return "Cohere API Key"
+}
+
+func (v *CohereValidator) GetValidationMethod() validator.ValidationMethod {
+	return validator.MethodHTTP
+}
+
+// Endpoints describes the endpoints probed during validation
+func (v *CohereValidator) Endpoints() []validator.Endpoint {
+	return []validator.Endpoint{cohereCheckEndpoint, cohereModelsEndpoint}
+}
+
+// newCohereRequest builds a request for a Cohere API endpoint authorized with key
+func newCohereRequest(ctx context.Context, endpoint validator.Endpoint, rawURL, key string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, endpoint.Method, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+key)
+	req.Header.Set("Accept", "application/json")
+	return req, nil
+}
+
+// do requests a Cohere API endpoint and decodes a successful answer into out
+func (v *CohereValidator) do(ctx context.Context, endpoint validator.Endpoint, rawURL, key string, out interface{}) (*http.Request, *apiResponse, error) {
+	req, err := newCohereRequest(ctx, endpoint, rawURL, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := send(v.client, req)
+	if err == nil && resp.StatusCode == http.StatusOK {
+		err = resp.decode(out)
+	}
+	if err != nil {
+		reportProbe(ctx, endpoint, nil, err, false)
+		return nil, nil, err
+	}
+	reportProbe(ctx, endpoint, resp, nil, resp.StatusCode == http.StatusOK)
+	return req, resp, nil
+}
+
+// Validate checks the key, then lists the models it can use. Trial keys are told apart from
+// production keys by the call allowance headers only trial keys are sent.
+func (v *CohereValidator) Validate(ctx context.Context, key string) (*validator.ValidationResult, error) {
+	result := newResult(v.GetService())
+	key = strings.TrimSpace(key)
+
+	var check struct {
+		Valid          bool   `json:"valid"`
+		OrganizationID string `json:"organization_id"`
+		OwnerID        string `json:"owner_id"`
+	}
+	req, resp, err := v.do(ctx, cohereCheckEndpoint, cohereCheckEndpoint.URL, key, &check)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized, resp.StatusCode == http.StatusForbidden:
+		return reject(result, "key rejected: invalid or deleted"), nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("%w: unexpected status %d", validator.ErrValidationError, resp.StatusCode)
+	case !check.Valid:
+		return reject(result, "key reported invalid by check-api-key"), nil
+	}
+	result.Valid = true
+	result.Details["organization_id"] = check.OrganizationID
+	result.Details["owner_id"] = check.OwnerID
+	result.PoC = map[string]string{cohereCheckEndpoint.URL: validator.CurlCommand(req, nil)}
+	trial := resp.Header.Get(cohereTrialHeader) != ""
+
+	var models struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if _, resp, err := v.do(ctx, cohereModelsEndpoint, cohereModelsEndpoint.URL+"?page_size=1000", key, &models); err != nil {
+		result.Details["models"] = fmt.Sprintf("Error: %v", err)
+	} else if resp.StatusCode == http.StatusOK {
+		trial = trial || resp.Header.Get(cohereTrialHeader) != ""
+		for _, model := range models.Models {
+			result.Permissions = append(result.Permissions, model.Name)
+		}
+	}
+
+	// Production keys are billed per call; trial keys are free but capped each month
+	result.Details["key_type"] = "production"
+	result.RiskLevel = validator.RiskLevelHigh
+	if trial {
+		result.Details["key_type"] = "trial"
+		result.RiskLevel = validator.RiskLevelLow
+	}
+	return result, nil
+}
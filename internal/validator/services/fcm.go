@@ -0,0 +1,104 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/transport"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// fcmSendEndpoint is the legacy FCM HTTP send endpoint, which takes server keys; dry runs are
+// checked but never delivered
+var fcmSendEndpoint = validator.Endpoint{URL: "https://fcm.googleapis.com/fcm/send", Method: http.MethodPost}
+
+// FCMServerKeyValidator implements the Validator interface for legacy Firebase Cloud Messaging
+// server keys
+type FCMServerKeyValidator struct {
+	client *http.Client
+}
+
+// NewFCMServerKeyValidator creates a new FCM server key validator instance using client,
+// or a client on the shared connection pool with a 10 second timeout when client is nil
+func NewFCMServerKeyValidator(client *http.Client) *FCMServerKeyValidator {
+	if client == nil {
+		client = transport.DefaultClient(10 * time.Second)
+	}
+	return &FCMServerKeyValidator{client: client}
+}
+
+func (v *FCMServerKeyValidator) GetService() string {
+	return "Firebase Cloud Messaging Server Key"
+}
+
+func (v *FCMServerKeyValidator) GetValidationMethod() validator.ValidationMethod {
+	return validator.MethodHTTP
+}
+
+// Endpoints describes the endpoints probed during validation
+func (v *FCMServerKeyValidator) Endpoints() []validator.Endpoint {
+	return []validator.Endpoint{fcmSendEndpoint}
+}
+
+// Validate sends a dry run message to a registration token that cannot exist. FCM checks the
+// key before the token, so a per-message InvalidRegistration error shows the key could send
+// notifications to any device or topic of the app.
+func (v *FCMServerKeyValidator) Validate(ctx context.Context, key string) (*validator.ValidationResult, error) {
+	result := newResult(v.GetService())
+	key = strings.TrimSpace(key)
+
+	body, err := json.Marshal(map[string]interface{}{
+		"registration_ids": []string{"apikeyzer"},
+		"dry_run":          true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, fcmSendEndpoint.Method, fcmSendEndpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "key="+key)
+	req.Header.Set("Content-Type", "application/json")
+
+	var sent struct {
+		Failure int `json:"failure"`
+		Results []struct {
+			Error string `json:"error"`
+		} `json:"results"`
+	}
+	resp, err := send(v.client, req)
+	if err == nil && resp.StatusCode == http.StatusOK {
+		err = resp.decode(&sent)
+	}
+	if err != nil {
+		reportProbe(ctx, fcmSendEndpoint, nil, err, false)
+		return nil, err
+	}
+	reportProbe(ctx, fcmSendEndpoint, resp, nil, resp.StatusCode == http.StatusOK)
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return reject(result, "server key rejected: invalid, deleted or the legacy API is disabled for its project"), nil
+	case http.StatusNotFound, http.StatusGone:
+		return reject(result, "the legacy FCM API is shut down, so the key can no longer send notifications"), nil
+	default:
+		return nil, fmt.Errorf("%w: unexpected status %d", validator.ErrValidationError, resp.StatusCode)
+	}
+
+	result.Valid = true
+	result.Permissions = []string{"send notifications to any device or topic of the app"}
+	result.PoC = map[string]string{fcmSendEndpoint.URL: validator.CurlCommand(req, body)}
+	if len(sent.Results) > 0 {
+		result.Details["dry_run_error"] = sent.Results[0].Error
+	}
+
+	// Notifications appear to come from the app itself, which makes them convincing phishing
+	result.RiskLevel = validator.RiskLevelHigh
+	return result, nil
+}
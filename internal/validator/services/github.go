@@ -0,0 +1,434 @@
+package services
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/transport"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// githubAPI is the base URL of the GitHub REST API
+const githubAPI = "https://api.github.com"
+
+// githubMaxInstallations bounds how many installations of an app are inspected, since each
+// one costs three requests
+const githubMaxInstallations = 20
+
+// GitHub API endpoints called during validation; {installation_id} is filled in per installation
+var (
+	githubAppEndpoint           = validator.Endpoint{URL: githubAPI + "/app", Method: http.MethodGet}
+	githubInstallationsEndpoint = validator.Endpoint{URL: githubAPI + "/app/installations", Method: http.MethodGet}
+	githubAccessTokenEndpoint   = validator.Endpoint{URL: githubAPI + "/app/installations/{installation_id}/access_tokens", Method: http.MethodPost}
+	githubRepositoriesEndpoint  = validator.Endpoint{URL: githubAPI + "/installation/repositories", Method: http.MethodGet}
+	githubRevokeEndpoint        = validator.Endpoint{URL: githubAPI + "/installation/token", Method: http.MethodDelete}
+)
+
+// githubRepositories is a page of the repositories an installation can access
+type githubRepositories struct {
+	TotalCount   int `json:"total_count"`
+	Repositories []struct {
+		FullName    string `json:"full_name"`
+		Permissions struct {
+			Admin bool `json:"admin"`
+			Push  bool `json:"push"`
+		} `json:"permissions"`
+	} `json:"repositories"`
+}
+
+// names returns the full names of the listed repositories
+func (r *githubRepositories) names() []string {
+	names := make([]string, 0, len(r.Repositories))
+	for _, repo := range r.Repositories {
+		names = append(names, repo.FullName)
+	}
+	return names
+}
+
+// writable reports whether any listed repository can be pushed to or administered
+func (r *githubRepositories) writable() bool {
+	for _, repo := range r.Repositories {
+		if repo.Permissions.Push || repo.Permissions.Admin {
+			return true
+		}
+	}
+	return false
+}
+
+// newGitHubRequest builds a GitHub API request authorized with auth
+func newGitHubRequest(ctx context.Context, method, url, auth string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", auth)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	return req, nil
+}
+
+// githubCall sends a request to url, reported as endpoint, and decodes a successful answer
+// into out when it is not nil
+func githubCall(ctx context.Context, client *http.Client, endpoint validator.Endpoint, url, auth string, out interface{}) (*apiResponse, error) {
+	req, err := newGitHubRequest(ctx, endpoint.Method, url, auth)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := send(client, req)
+	// GitHub refuses requests over the primary and secondary rate limits with a 403
+	if err == nil && resp.StatusCode == http.StatusForbidden &&
+		(resp.Header.Get("X-RateLimit-Remaining") == "0" || strings.Contains(strings.ToLower(string(resp.Body)), "rate limit")) {
+		err = fmt.Errorf("%w: %d %s", validator.ErrRateLimited, resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	if err != nil {
+		reportProbe(ctx, endpoint, nil, err, false)
+		return nil, err
+	}
+
+	accepted := resp.StatusCode >= 200 && resp.StatusCode < 300
+	reportProbe(ctx, endpoint, resp, nil, accepted)
+	if accepted && out != nil {
+		if err := resp.decode(out); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// githubStatusError describes a GitHub answer that neither accepts nor rejects a credential
+func githubStatusError(resp *apiResponse) error {
+	var body struct {
+		Message string `json:"message"`
+	}
+	json.Unmarshal(resp.Body, &body)
+	if body.Message != "" {
+		return fmt.Errorf("%w: unexpected status %d: %s", validator.ErrValidationError, resp.StatusCode, body.Message)
+	}
+	return fmt.Errorf("%w: unexpected status %d", validator.ErrValidationError, resp.StatusCode)
+}
+
+// GitHubAppValidator implements the Validator interface for GitHub App private keys. Keys are
+// given as "<app id>:<PEM private key>", the app ID or client ID being needed to sign in as the app.
+type GitHubAppValidator struct {
+	client *http.Client
+}
+
+// NewGitHubAppValidator creates a new GitHub App private key validator instance using client,
+// or a client on the shared connection pool with a 10 second timeout when client is nil
+func NewGitHubAppValidator(client *http.Client) *GitHubAppValidator {
+	if client == nil {
+		client = transport.DefaultClient(10 * time.Second)
+	}
+	return &GitHubAppValidator{client: client}
+}
+
+func (v *GitHubAppValidator) GetService() string {
+	return "GitHub App Private Key"
+}
+
+func (v *GitHubAppValidator) GetValidationMethod() validator.ValidationMethod {
+	return validator.MethodHTTP
+}
+
+// Endpoints describes the endpoints probed during validation
+func (v *GitHubAppValidator) Endpoints() []validator.Endpoint {
+	return []validator.Endpoint{
+		githubAppEndpoint,
+		githubInstallationsEndpoint,
+		githubAccessTokenEndpoint,
+		githubRepositoriesEndpoint,
+		githubRevokeEndpoint,
+	}
+}
+
+// githubInstallation is an installation of an app on a user or organization account
+type githubInstallation struct {
+	ID      int64 `json:"id"`
+	Account struct {
+		Login string `json:"login"`
+		Type  string `json:"type"`
+	} `json:"account"`
+	RepositorySelection string            `json:"repository_selection"`
+	Permissions         map[string]string `json:"permissions"`
+	SuspendedAt         *time.Time        `json:"suspended_at"`
+}
+
+// Validate signs in as the app with a JWT minted from the private key, then lists the
+// installations of the app and the repositories each of them can access
+func (v *GitHubAppValidator) Validate(ctx context.Context, key string) (*validator.ValidationResult, error) {
+	result := newResult(v.GetService())
+
+	issuer, privateKey, err := parseGitHubAppKey(key)
+	if err != nil {
+		return reject(result, err.Error()), nil
+	}
+	jwt, err := githubAppJWT(issuer, privateKey, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	auth := "Bearer " + jwt
+
+	var app struct {
+		Slug  string `json:"slug"`
+		Name  string `json:"name"`
+		Owner struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+		Permissions map[string]string `json:"permissions"`
+	}
+	resp, err := githubCall(ctx, v.client, githubAppEndpoint, githubAppEndpoint.URL, auth, &app)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusNotFound:
+		return reject(result, "GitHub rejected the app JWT: the private key was revoked or does not belong to the app ID"), nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, githubStatusError(resp)
+	}
+	result.Valid = true
+	result.Details["app"] = app.Slug
+	result.Details["app_name"] = app.Name
+	result.Details["owner"] = app.Owner.Login
+	result.Details["app_permissions"] = app.Permissions
+
+	var installations []githubInstallation
+	resp, err = githubCall(ctx, v.client, githubInstallationsEndpoint, githubInstallationsEndpoint.URL+"?per_page=100", auth, &installations)
+	if err != nil {
+		// The key is known to be valid, so the result stands without the installations
+		result.RiskLevel = validator.RiskLevelMedium
+		result.Details["installations"] = fmt.Sprintf("Error: %v", err)
+		return result, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		result.RiskLevel = validator.RiskLevelMedium
+		result.Details["installations"] = fmt.Sprintf("Error: %v", githubStatusError(resp))
+		return result, nil
+	}
+
+	details := make([]map[string]interface{}, 0, len(installations))
+	writable := false
+	for i, installation := range installations {
+		detail := map[string]interface{}{
+			"account":              installation.Account.Login,
+			"account_type":         installation.Account.Type,
+			"repository_selection": installation.RepositorySelection,
+			"permissions":          installation.Permissions,
+		}
+		details = append(details, detail)
+
+		if installation.SuspendedAt != nil {
+			detail["suspended"] = true
+			continue
+		}
+		if githubCanWrite(installation.Permissions) {
+			writable = true
+		}
+		if installation.RepositorySelection == "all" {
+			result.Permissions = append(result.Permissions, fmt.Sprintf("%s (all repositories)", installation.Account.Login))
+		}
+		if i >= githubMaxInstallations {
+			detail["repositories"] = "Skipped: installation limit reached"
+			continue
+		}
+
+		repos, err := v.installationRepositories(ctx, auth, installation.ID)
+		if err != nil {
+			detail["repositories"] = fmt.Sprintf("Error: %v", err)
+			continue
+		}
+		detail["repositories"] = repos.names()
+		detail["total_repositories"] = repos.TotalCount
+		if installation.RepositorySelection != "all" {
+			result.Permissions = append(result.Permissions, repos.names()...)
+		}
+	}
+	result.Details["installations"] = details
+
+	// Anyone holding the key can act as the app on every account it is installed on
+	result.RiskLevel = validator.RiskLevelMedium
+	if writable {
+		result.RiskLevel = validator.RiskLevelHigh
+	}
+	return result, nil
+}
+
+// installationRepositories lists the repositories an installation can access with a
+// short-lived installation token, which is revoked again afterwards
+func (v *GitHubAppValidator) installationRepositories(ctx context.Context, auth string, installationID int64) (*githubRepositories, error) {
+	var token struct {
+		Token string `json:"token"`
+	}
+	id := strconv.FormatInt(installationID, 10)
+	url := strings.Replace(githubAccessTokenEndpoint.URL, "{installation_id}", id, 1)
+	resp, err := githubCall(ctx, v.client, githubAccessTokenEndpoint, url, auth, &token)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusCreated {
+		return nil, githubStatusError(resp)
+	}
+	tokenAuth := "token " + token.Token
+	defer githubCall(ctx, v.client, githubRevokeEndpoint, githubRevokeEndpoint.URL, tokenAuth, nil)
+
+	var repos githubRepositories
+	resp, err = githubCall(ctx, v.client, githubRepositoriesEndpoint, githubRepositoriesEndpoint.URL+"?per_page=100", tokenAuth, &repos)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, githubStatusError(resp)
+	}
+	return &repos, nil
+}
+
+// githubCanWrite reports whether any of the permissions of an installation allows changes
+func githubCanWrite(permissions map[string]string) bool {
+	for _, level := range permissions {
+		if level == "write" || level == "admin" {
+			return true
+		}
+	}
+	return false
+}
+
+// parseGitHubAppKey splits a key of the form "<app id>:<PEM>" into the JWT issuer and the
+// private key. Newlines in the PEM may be escaped as \n, as in .env files and CI variables.
+func parseGitHubAppKey(key string) (interface{}, *rsa.PrivateKey, error) {
+	issuer, encoded, ok := strings.Cut(strings.TrimSpace(key), ":")
+	if !ok || issuer == "" {
+		return nil, nil, errors.New("malformed key: expected <app id>:<PEM private key>")
+	}
+	block, _ := pem.Decode([]byte(strings.ReplaceAll(encoded, `\n`, "\n")))
+	if block == nil {
+		return nil, nil, errors.New("malformed key: no PEM block found")
+	}
+
+	var privateKey *rsa.PrivateKey
+	if parsed, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		privateKey = parsed
+	} else {
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("malformed key: %w", err)
+		}
+		rsaKey, ok := parsed.(*rsa.PrivateKey)
+		if !ok {
+			return nil, nil, errors.New("malformed key: GitHub App keys are RSA keys")
+		}
+		privateKey = rsaKey
+	}
+
+	// App IDs are sent as numbers; client IDs (Iv1.…) as strings
+	if id, err := strconv.ParseInt(issuer, 10, 64); err == nil {
+		return id, privateKey, nil
+	}
+	return issuer, privateKey, nil
+}
+
+// githubAppJWT mints the RS256 JWT an app authenticates with. It is backdated a minute to
+// allow for clock drift and expires well within GitHub's ten minute maximum.
+func githubAppJWT(issuer interface{}, privateKey *rsa.PrivateKey, now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": issuer,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	encoding := base64.RawURLEncoding
+	unsigned := encoding.EncodeToString(header) + "." + encoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign app JWT: %w", err)
+	}
+	return unsigned + "." + encoding.EncodeToString(signature), nil
+}
+
+// GitHubInstallationTokenValidator implements the Validator interface for GitHub App
+// installation access tokens (ghs_)
+type GitHubInstallationTokenValidator struct {
+	client *http.Client
+}
+
+// NewGitHubInstallationTokenValidator creates a new installation token validator instance using
+// client, or a client on the shared connection pool with a 10 second timeout when client is nil
+func NewGitHubInstallationTokenValidator(client *http.Client) *GitHubInstallationTokenValidator {
+	if client == nil {
+		client = transport.DefaultClient(10 * time.Second)
+	}
+	return &GitHubInstallationTokenValidator{client: client}
+}
+
+func (v *GitHubInstallationTokenValidator) GetService() string {
+	return "GitHub App Installation Token"
+}
+
+func (v *GitHubInstallationTokenValidator) GetValidationMethod() validator.ValidationMethod {
+	return validator.MethodHTTP
+}
+
+// Endpoints describes the endpoints probed during validation
+func (v *GitHubInstallationTokenValidator) Endpoints() []validator.Endpoint {
+	return []validator.Endpoint{githubRepositoriesEndpoint}
+}
+
+// Validate lists the repositories the installation token can access
+func (v *GitHubInstallationTokenValidator) Validate(ctx context.Context, key string) (*validator.ValidationResult, error) {
+	result := newResult(v.GetService())
+
+	auth := "token " + key
+	url := githubRepositoriesEndpoint.URL + "?per_page=100"
+	var repos githubRepositories
+	resp, err := githubCall(ctx, v.client, githubRepositoriesEndpoint, url, auth, &repos)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		// Installation tokens expire after an hour, so most leaked ones are already dead
+		return reject(result, "token rejected: expired or revoked"), nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, githubStatusError(resp)
+	}
+
+	result.Valid = true
+	result.Permissions = repos.names()
+	sort.Strings(result.Permissions)
+	result.Details["total_repositories"] = repos.TotalCount
+	if req, err := newGitHubRequest(ctx, http.MethodGet, url, auth); err == nil {
+		result.PoC = map[string]string{githubRepositoriesEndpoint.URL: validator.CurlCommand(req, nil)}
+	}
+
+	// Write access lets the holder push code or change settings until the token expires
+	switch {
+	case repos.writable():
+		result.RiskLevel = validator.RiskLevelHigh
+	case repos.TotalCount > 0:
+		result.RiskLevel = validator.RiskLevelMedium
+	default:
+		result.RiskLevel = validator.RiskLevelLow
+	}
+	return result, nil
+}
@@ -8,24 +8,34 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/Xplo8E/APIKeyzer/internal/transport"
 	"github.com/Xplo8E/APIKeyzer/internal/validator"
 )
 
-// GoogleMapsValidator implements the Validator interface for Google Maps API
+// GoogleMapsValidator implements the Validator interface for Google API keys, probing them
+// against Maps Platform and other Google APIs
 type GoogleMapsValidator struct {
 	client *http.Client
+	apis   []APIEndpoint
 }
 
 // APIEndpoint represents a Google Maps API endpoint configuration
 type APIEndpoint struct {
+	ID         string // product ID used to select the APIs to probe
+	Product    string
+	Cost       string // what the owner pays for requests to the product
 	URL        string
 	Method     string
 	Parameters map[string]string
 	Headers    map[string]string
 	PostData   map[string]string
 	VulnCheck  func(*APIResponse) bool
+	Billable   bool // requests are charged to the key owner's Maps Platform account
 }
 
 // APIResponse represents the structure to check for success/failure
@@ -36,12 +46,44 @@ type APIResponse struct {
 	ErrorMessage string `json:"error_message,omitempty"`
 }
 
+// googleKeyRejections mark error answers refusing the key itself, as opposed to errors about the
+// request, which the API only reports once it has accepted the key
+var googleKeyRejections = [][]byte{
+	[]byte("API_KEY_"),
+	[]byte("API key not valid"),
+	[]byte("SERVICE_DISABLED"),
+	[]byte("PERMISSION_DENIED"),
+}
+
+// googleProject finds the number of the key's Cloud project in error answers, which name it
+// as the consumer of the API
+var googleProject = regexp.MustCompile(`projects/([0-9]+)|in project ([0-9]+)`)
+
+// googleAPIAccepted reports whether a Google API answered a request with the key: with success,
+// or by refusing the request itself rather than the key
+func googleAPIAccepted(resp *APIResponse) bool {
+	if resp.StatusCode == http.StatusOK {
+		return true
+	}
+	if resp.StatusCode != http.StatusBadRequest && resp.StatusCode != http.StatusNotFound {
+		return false
+	}
+	for _, rejection := range googleKeyRejections {
+		if bytes.Contains(resp.Content, rejection) {
+			return false
+		}
+	}
+	return true
+}
+
 // Define API endpoints for validation
 var googleMapsEndpoints = []APIEndpoint{
 	{
-		// Static Map API
-		URL:    "https://maps.googleapis.com/maps/api/staticmap",
-		Method: "GET",
+		ID:      "staticmap",
+		Product: "Maps Static API",
+		Cost:    "$2 per 1,000 requests",
+		URL:     "https://maps.googleapis.com/maps/api/staticmap",
+		Method:  "GET",
 		Parameters: map[string]string{
 			"center": "45,10",
 			"zoom":   "7",
@@ -50,14 +92,17 @@ var googleMapsEndpoints = []APIEndpoint{
 		Headers: map[string]string{
 			"Accept": "*/*",
 		},
+		Billable: true,
 		VulnCheck: func(resp *APIResponse) bool {
 			return resp.StatusCode == 200 || bytes.Contains(resp.Content, []byte("PNG"))
 		},
 	},
 	{
-		// Street View API
-		URL:    "https://maps.googleapis.com/maps/api/streetview",
-		Method: "GET",
+		ID:      "streetview",
+		Product: "Street View Static API",
+		Cost:    "$7 per 1,000 requests",
+		URL:     "https://maps.googleapis.com/maps/api/streetview",
+		Method:  "GET",
 		Parameters: map[string]string{
 			"size":     "400x400",
 			"location": "40.720032,-73.988354",
@@ -68,14 +113,17 @@ var googleMapsEndpoints = []APIEndpoint{
 		Headers: map[string]string{
 			"Accept": "*/*",
 		},
+		Billable: true,
 		VulnCheck: func(resp *APIResponse) bool {
 			return resp.StatusCode == 200 || bytes.Contains(resp.Content, []byte("PNG"))
 		},
 	},
 	{
-		// Directions API
-		URL:    "https://maps.googleapis.com/maps/api/directions/json",
-		Method: "GET",
+		ID:      "directions",
+		Product: "Directions API",
+		Cost:    "$5 per 1,000 requests",
+		URL:     "https://maps.googleapis.com/maps/api/directions/json",
+		Method:  "GET",
 		Parameters: map[string]string{
 			"origin":      "Disneyland",
 			"destination": "Universal Studios Hollywood",
@@ -83,14 +131,17 @@ var googleMapsEndpoints = []APIEndpoint{
 		Headers: map[string]string{
 			"Accept": "application/json",
 		},
+		Billable: true,
 		VulnCheck: func(resp *APIResponse) bool {
 			return resp.StatusCode == 200 && resp.ErrorMessage == ""
 		},
 	},
 	{
-		// Geolocation API
-		URL:    "https://www.googleapis.com/geolocation/v1/geolocate",
-		Method: "POST",
+		ID:      "geolocation",
+		Product: "Geolocation API",
+		Cost:    "$5 per 1,000 requests",
+		URL:     "https://www.googleapis.com/geolocation/v1/geolocate",
+		Method:  "POST",
 		PostData: map[string]string{
 			"considerIp": "true",
 		},
@@ -98,19 +149,159 @@ var googleMapsEndpoints = []APIEndpoint{
 			"Content-Type": "application/json",
 			"Accept":       "application/json",
 		},
+		Billable: true,
 		VulnCheck: func(resp *APIResponse) bool {
 			return resp.StatusCode == 200 && !bytes.Contains(resp.Content, []byte("error"))
 		},
 	},
+	{
+		ID:      "geocoding",
+		Product: "Geocoding API",
+		Cost:    "$5 per 1,000 requests",
+		URL:     "https://maps.googleapis.com/maps/api/geocode/json",
+		Method:  "GET",
+		Parameters: map[string]string{
+			"address": "1600 Amphitheatre Parkway, Mountain View, CA",
+		},
+		Headers: map[string]string{
+			"Accept": "application/json",
+		},
+		Billable: true,
+		VulnCheck: func(resp *APIResponse) bool {
+			return resp.StatusCode == 200 && resp.ErrorMessage == ""
+		},
+	},
+	{
+		ID:      "places",
+		Product: "Places API",
+		Cost:    "$17 per 1,000 requests",
+		URL:     "https://maps.googleapis.com/maps/api/place/findplacefromtext/json",
+		Method:  "GET",
+		Parameters: map[string]string{
+			"input":     "Museum of Contemporary Art Australia",
+			"inputtype": "textquery",
+		},
+		Headers: map[string]string{
+			"Accept": "application/json",
+		},
+		Billable: true,
+		VulnCheck: func(resp *APIResponse) bool {
+			return resp.StatusCode == 200 && resp.ErrorMessage == ""
+		},
+	},
+	{
+		ID:      "translate",
+		Product: "Cloud Translation API",
+		Cost:    "$20 per 1,000,000 characters",
+		URL:     "https://translation.googleapis.com/language/translate/v2",
+		Method:  "GET",
+		Parameters: map[string]string{
+			"q":      "hello",
+			"target": "fr",
+		},
+		Headers: map[string]string{
+			"Accept": "application/json",
+		},
+		Billable:  true,
+		VulnCheck: googleAPIAccepted,
+	},
+	{
+		// Without a search engine ID the query is refused before it runs, so it is not billed
+		ID:      "customsearch",
+		Product: "Custom Search JSON API",
+		Cost:    "$5 per 1,000 queries beyond 100 free per day",
+		URL:     "https://www.googleapis.com/customsearch/v1",
+		Method:  "GET",
+		Parameters: map[string]string{
+			"q": "apikeyzer",
+		},
+		Headers: map[string]string{
+			"Accept": "application/json",
+		},
+		VulnCheck: googleAPIAccepted,
+	},
+	{
+		ID:      "youtube",
+		Product: "YouTube Data API v3",
+		Cost:    "free, within a quota of 10,000 units per day",
+		URL:     "https://www.googleapis.com/youtube/v3/videos",
+		Method:  "GET",
+		Parameters: map[string]string{
+			"part": "id",
+			"id":   "dQw4w9WgXcQ",
+		},
+		Headers: map[string]string{
+			"Accept": "application/json",
+		},
+		VulnCheck: googleAPIAccepted,
+	},
+	{
+		ID:      "safebrowsing",
+		Product: "Safe Browsing API",
+		Cost:    "free, for non-commercial use",
+		URL:     "https://safebrowsing.googleapis.com/v4/threatLists",
+		Method:  "GET",
+		Headers: map[string]string{
+			"Accept": "application/json",
+		},
+		VulnCheck: googleAPIAccepted,
+	},
+	{
+		// The project of the key is learnt from the error answers of the APIs probed before
+		ID:      "remoteconfig",
+		Product: "Firebase Remote Config API",
+		Cost:    "free",
+		URL:     "https://firebaseremoteconfig.googleapis.com/v1/projects/{project}/namespaces/firebase:fetch",
+		Method:  "POST",
+		PostData: map[string]string{
+			"appInstanceId": "apikeyzer",
+		},
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+			"Accept":       "application/json",
+		},
+		VulnCheck: googleAPIAccepted,
+	},
 }
 
-// NewGoogleMapsValidator creates a new Google Maps validator instance
-func NewGoogleMapsValidator() *GoogleMapsValidator {
-	return &GoogleMapsValidator{
-		client: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+// GoogleAPIs returns the IDs of the Google APIs Google API keys can be probed against
+func GoogleAPIs() []string {
+	ids := make([]string, 0, len(googleMapsEndpoints))
+	for _, endpoint := range googleMapsEndpoints {
+		ids = append(ids, endpoint.ID)
+	}
+	return ids
+}
+
+// NewGoogleMapsValidator creates a new Google Maps validator instance using client,
+// or a client on the shared connection pool with a 10 second timeout when client is nil
+func NewGoogleMapsValidator(client *http.Client) *GoogleMapsValidator {
+	if client == nil {
+		client = transport.DefaultClient(10 * time.Second)
 	}
+	return &GoogleMapsValidator{client: client, apis: googleMapsEndpoints}
+}
+
+// SetAPIs restricts the probed APIs to those with the given IDs (see GoogleAPIs); no IDs
+// probes every API
+func (v *GoogleMapsValidator) SetAPIs(ids []string) error {
+	if len(ids) == 0 {
+		v.apis = googleMapsEndpoints
+		return nil
+	}
+	for _, id := range ids {
+		if !slices.Contains(GoogleAPIs(), id) {
+			return fmt.Errorf("unknown Google API %q (expected one of %s)", id, strings.Join(GoogleAPIs(), ", "))
+		}
+	}
+	apis := make([]APIEndpoint, 0, len(ids))
+	for _, endpoint := range googleMapsEndpoints {
+		if slices.Contains(ids, endpoint.ID) {
+			apis = append(apis, endpoint)
+		}
+	}
+	v.apis = apis
+	return nil
 }
 
 func (v *GoogleMapsValidator) GetService() string {
@@ -121,12 +312,21 @@ func (v *GoogleMapsValidator) GetValidationMethod() validator.ValidationMethod {
 	return validator.MethodHTTP
 }
 
-// validateEndpoint checks a single API endpoint
-func (v *GoogleMapsValidator) validateEndpoint(ctx context.Context, endpoint APIEndpoint, key string) (*APIResponse, error) {
+// Endpoints describes the endpoints probed during validation
+func (v *GoogleMapsValidator) Endpoints() []validator.Endpoint {
+	endpoints := make([]validator.Endpoint, 0, len(v.apis))
+	for _, endpoint := range v.apis {
+		endpoints = append(endpoints, validator.Endpoint{URL: endpoint.URL, Method: endpoint.Method, Billable: endpoint.Billable})
+	}
+	return endpoints
+}
+
+// newRequest builds the request for an endpoint, returning the body separately for PoC generation
+func (v *GoogleMapsValidator) newRequest(ctx context.Context, endpoint APIEndpoint, key string) (*http.Request, []byte, error) {
 	// Build URL with parameters
 	u, err := url.Parse(endpoint.URL)
 	if err != nil {
-		return nil, fmt.Errorf("invalid endpoint URL: %w", err)
+		return nil, nil, fmt.Errorf("invalid endpoint URL: %w", err)
 	}
 
 	// Add parameters
@@ -137,11 +337,12 @@ func (v *GoogleMapsValidator) validateEndpoint(ctx context.Context, endpoint API
 	}
 	u.RawQuery = q.Encode()
 
+	var postData []byte
 	var body io.Reader
 	if endpoint.Method == "POST" && len(endpoint.PostData) > 0 {
-		postData, err := json.Marshal(endpoint.PostData)
+		postData, err = json.Marshal(endpoint.PostData)
 		if err != nil {
-			return nil, fmt.Errorf("failed to marshal post data: %w", err)
+			return nil, nil, fmt.Errorf("failed to marshal post data: %w", err)
 		}
 		body = bytes.NewBuffer(postData)
 	}
@@ -149,7 +350,7 @@ func (v *GoogleMapsValidator) validateEndpoint(ctx context.Context, endpoint API
 	// Create request
 	req, err := http.NewRequestWithContext(ctx, endpoint.Method, u.String(), body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Add headers
@@ -157,19 +358,34 @@ func (v *GoogleMapsValidator) validateEndpoint(ctx context.Context, endpoint API
 		req.Header.Set(k, v)
 	}
 
+	return req, postData, nil
+}
+
+// validateEndpoint checks a single API endpoint
+func (v *GoogleMapsValidator) validateEndpoint(ctx context.Context, endpoint APIEndpoint, key string) (*APIResponse, error) {
+	req, _, err := v.newRequest(ctx, endpoint, key)
+	if err != nil {
+		return nil, err
+	}
+
 	// Perform request
 	resp, err := v.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, validator.RequestError(err)
 	}
 	defer resp.Body.Close()
 
 	// Read response
-	content, err := io.ReadAll(resp.Body)
+	content, err := validator.ReadResponse(ctx, resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
+	// Throttling and outages say nothing about the key, so they are reported as failures
+	if err := validator.ClassifyResponse(resp.StatusCode, content); err != nil {
+		return nil, err
+	}
+
 	apiResp := &APIResponse{
 		StatusCode: resp.StatusCode,
 		Content:    content,
@@ -189,37 +405,101 @@ func (v *GoogleMapsValidator) Validate(ctx context.Context, key string) (*valida
 		Details:     make(map[string]interface{}),
 	}
 
-	// Track vulnerable endpoints
+	// Track vulnerable endpoints, and the products they belong to
 	vulnerableAPIs := make([]string, 0)
+	unrestricted := make([]string, 0)
+	var firstErr error
+	answered, skipped, failed, billable := 0, 0, 0, 0
+	project := ""
 
 	// Check each endpoint
-	for _, endpoint := range googleMapsEndpoints {
+	for _, endpoint := range v.apis {
+		if strings.Contains(endpoint.URL, "{project}") {
+			if project == "" {
+				result.Details[endpoint.URL] = "Skipped: the project of the key was not named by the other APIs"
+				continue
+			}
+			endpoint.URL = strings.Replace(endpoint.URL, "{project}", project, 1)
+		}
+		probe := validator.ProbeResult{
+			Endpoint: validator.Endpoint{URL: endpoint.URL, Method: endpoint.Method, Billable: endpoint.Billable},
+		}
+		if endpoint.Billable && validator.SafeMode(ctx) {
+			result.Details[endpoint.URL] = "Skipped: billable endpoint (safe mode)"
+			skipped++
+			probe.Skipped = true
+			validator.ReportProbe(ctx, probe)
+			continue
+		}
+
 		resp, err := v.validateEndpoint(ctx, endpoint, key)
 		if err != nil {
 			result.Details[endpoint.URL] = fmt.Sprintf("Error: %v", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			failed++
+			probe.Err = err
+			validator.ReportProbe(ctx, probe)
 			continue
 		}
+		answered++
+		if match := googleProject.FindSubmatch(resp.Content); match != nil && project == "" {
+			project = string(append(match[1], match[2]...))
+		}
+		probe.StatusCode = resp.StatusCode
+		probe.Vulnerable = endpoint.VulnCheck(resp)
+		validator.ReportProbe(ctx, probe)
 
 		// Check if endpoint is vulnerable using its specific check
 		if endpoint.VulnCheck(resp) {
 			result.Valid = true // If any endpoint is vulnerable, the key is considered valid
 			vulnerableAPIs = append(vulnerableAPIs, endpoint.URL)
+			unrestricted = append(unrestricted, fmt.Sprintf("%s (%s)", endpoint.Product, endpoint.Cost))
+			if endpoint.Billable {
+				billable++
+			}
+
+			if req, body, err := v.newRequest(ctx, endpoint, key); err == nil {
+				if result.PoC == nil {
+					result.PoC = make(map[string]string)
+				}
+				result.PoC[endpoint.URL] = validator.CurlCommand(req, body)
+			}
 		}
 
 		// Store response details
 		result.Details[endpoint.URL] = map[string]interface{}{
 			"status_code": resp.StatusCode,
 			"vulnerable":  endpoint.VulnCheck(resp),
+			"product":     endpoint.Product,
+			"cost":        endpoint.Cost,
 		}
 	}
 
 	// Set permissions based on vulnerable APIs
 	result.Permissions = vulnerableAPIs
+	result.Details["unrestricted_products"] = unrestricted
 
-	// Set risk level based on number of vulnerable endpoints
-	result.RiskLevel = v.assessRiskLevel(vulnerableAPIs)
+	// Set risk level based on number of vulnerable endpoints billed to the owner
+	result.RiskLevel = v.assessRiskLevel(billable)
 
-	if !result.Valid {
+	switch {
+	case answered == 0 && firstErr == nil && skipped > 0:
+		result.Error = validator.ErrBillableSkipped
+		result.ErrorStr = validator.ErrBillableSkipped.Error()
+		result.ErrorCode = validator.ErrCodeSafeMode
+	case answered == 0 && firstErr != nil:
+		// No endpoint answered, so nothing is known about the key
+		result.Error = firstErr
+		result.ErrorStr = fmt.Sprintf("no endpoint could be checked: %v", firstErr)
+		result.ErrorCode = validator.ErrorCodeFor(firstErr)
+	case !result.Valid && firstErr != nil:
+		// The endpoints that failed might have accepted the key, so it is not known to be invalid
+		result.Error = firstErr
+		result.ErrorStr = fmt.Sprintf("%d of %d endpoints could not be checked: %v", failed, failed+answered, firstErr)
+		result.ErrorCode = validator.ErrorCodeFor(firstErr)
+	case !result.Valid:
 		result.Error = validator.ErrInvalidKey
 		result.ErrorStr = "API key not vulnerable for any endpoints"
 	}
@@ -227,9 +507,9 @@ func (v *GoogleMapsValidator) Validate(ctx context.Context, key string) (*valida
 	return result, nil
 }
 
-// assessRiskLevel determines the risk level based on number of vulnerable endpoints
-func (v *GoogleMapsValidator) assessRiskLevel(vulnerableAPIs []string) validator.RiskLevel {
-	switch len(vulnerableAPIs) {
+// assessRiskLevel determines the risk level based on number of vulnerable billable endpoints
+func (v *GoogleMapsValidator) assessRiskLevel(billable int) validator.RiskLevel {
+	switch billable {
 	case 0:
 		return validator.RiskLevelLow
 	case 1, 2:
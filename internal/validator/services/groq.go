@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/transport"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// groqModelsEndpoint lists the models available to a Groq key, free of charge
+var groqModelsEndpoint = validator.Endpoint{URL: "https://api.groq.com/openai/v1/models", Method: http.MethodGet}
+
+// GroqValidator implements the Validator interface for Groq API keys
+type GroqValidator struct {
+	client *http.Client
+}
+
+// NewGroqValidator creates a new Groq validator instance using client,
+// or a client on the shared connection pool with a 10 second timeout when client is nil
+func NewGroqValidator(client *http.Client) *GroqValidator {
+	if client == nil {
+		client = transport.DefaultClient(10 * time.Second)
+	}
+	return &GroqValidator{client: client}
+}
+
+func (v *GroqValidator) GetService() string {
+	return "Groq API Key"
+}
+
+func (v *GroqValidator) GetValidationMethod() validator.ValidationMethod {
+	return validator.MethodHTTP
+}
+
+// Endpoints describes the endpoints probed during validation
+func (v *GroqValidator) Endpoints() []validator.Endpoint {
+	return []validator.Endpoint{groqModelsEndpoint}
+}
+
+// Validate lists the models of the key; the active ones become the permissions of the finding
+func (v *GroqValidator) Validate(ctx context.Context, key string) (*validator.ValidationResult, error) {
+	result := newResult(v.GetService())
+	key = strings.TrimSpace(key)
+
+	req, err := http.NewRequestWithContext(ctx, groqModelsEndpoint.Method, groqModelsEndpoint.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+key)
+
+	var models struct {
+		Data []struct {
+			ID     string `json:"id"`
+			Active bool   `json:"active"`
+		} `json:"data"`
+	}
+	resp, err := send(v.client, req)
+	if err == nil && resp.StatusCode == http.StatusOK {
+		err = resp.decode(&models)
+	}
+	if err != nil {
+		reportProbe(ctx, groqModelsEndpoint, nil, err, false)
+		return nil, err
+	}
+	reportProbe(ctx, groqModelsEndpoint, resp, nil, resp.StatusCode == http.StatusOK)
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized:
+		return reject(result, "key rejected: invalid or deleted"), nil
+	case http.StatusForbidden:
+		// Keys of organizations that Groq has restricted are refused with 403
+		return reject(result, "key rejected: organization restricted"), nil
+	default:
+		return nil, fmt.Errorf("%w: unexpected status %d", validator.ErrValidationError, resp.StatusCode)
+	}
+
+	result.Valid = true
+	result.PoC = map[string]string{groqModelsEndpoint.URL: validator.CurlCommand(req, nil)}
+	inactive := 0
+	for _, model := range models.Data {
+		if !model.Active {
+			inactive++
+			continue
+		}
+		result.Permissions = append(result.Permissions, model.ID)
+	}
+	sort.Strings(result.Permissions)
+	result.Details["active_models"] = len(result.Permissions)
+	result.Details["inactive_models"] = inactive
+
+	// Free tier keys are rate limited, but paid keys run inference billed to the owner; the
+	// tier is not visible to the key
+	result.RiskLevel = validator.RiskLevelMedium
+	return result, nil
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// apiResponse is a provider response read in full
+type apiResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// send performs req and reads its response within the size limit of the request context.
+// Throttling and outages say nothing about the key, so they are returned as errors.
+func send(client *http.Client, req *http.Request) (*apiResponse, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, validator.RequestError(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := validator.ReadResponse(req.Context(), resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if err := validator.ClassifyResponse(resp.StatusCode, body); err != nil {
+		return nil, err
+	}
+	return &apiResponse{StatusCode: resp.StatusCode, Header: resp.Header, Body: body}, nil
+}
+
+// decode unmarshals the JSON body of the response into v
+func (r *apiResponse) decode(v interface{}) error {
+	if err := json.Unmarshal(r.Body, v); err != nil {
+		return fmt.Errorf("unexpected response: %w", err)
+	}
+	return nil
+}
+
+// newResult starts the result of validating a key of service
+func newResult(service string) *validator.ValidationResult {
+	return &validator.ValidationResult{
+		Service:     service,
+		ValidatedAt: time.Now(),
+		Details:     make(map[string]interface{}),
+	}
+}
+
+// reject marks result as a key the provider refused, giving reason as its error
+func reject(result *validator.ValidationResult, reason string) *validator.ValidationResult {
+	result.Valid = false
+	result.RiskLevel = validator.RiskLevelLow
+	result.Error = validator.ErrInvalidKey
+	result.ErrorStr = reason
+	return result
+}
+
+// reportProbe passes the verdict of a request to endpoint to the probe hook of ctx
+func reportProbe(ctx context.Context, endpoint validator.Endpoint, resp *apiResponse, err error, accepted bool) {
+	probe := validator.ProbeResult{Endpoint: endpoint, Vulnerable: accepted, Err: err}
+	if resp != nil {
+		probe.StatusCode = resp.StatusCode
+	}
+	validator.ReportProbe(ctx, probe)
+}
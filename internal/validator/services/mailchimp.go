@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/transport"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// mailchimpDatacenter matches the datacenter suffix of a key (e.g. "-us6"), which names the
+// API host of the account
+var mailchimpDatacenter = regexp.MustCompile(`-(us[0-9]{1,3})$`)
+
+// MailchimpValidator implements the Validator interface for Mailchimp Marketing API keys
+type MailchimpValidator struct {
+	client *http.Client
+}
+
+// NewMailchimpValidator creates a new Mailchimp validator instance using client,
+// or a client on the shared connection pool with a 10 second timeout when client is nil
+func NewMailchimpValidator(client *http.Client) *MailchimpValidator {
+	if client == nil {
+		client = transport.DefaultClient(10 * time.Second)
+	}
+	return &MailchimpValidator{client: client}
+}
+
+func (v *MailchimpValidator) GetService() string {
+	return "Mailchimp API Key"
+}
+
+func (v *MailchimpValidator) GetValidationMethod() validator.ValidationMethod {
+	return validator.MethodHTTP
+}
+
+// Endpoints describes the endpoints probed during validation; {dc} is the datacenter of the key
+func (v *MailchimpValidator) Endpoints() []validator.Endpoint {
+	return []validator.Endpoint{
+		{URL: "https://{dc}.api.mailchimp.com/3.0/ping", Method: http.MethodGet},
+		{URL: "https://{dc}.api.mailchimp.com/3.0/", Method: http.MethodGet},
+		{URL: "https://{dc}.api.mailchimp.com/3.0/lists", Method: http.MethodGet},
+	}
+}
+
+// get requests a path of the Marketing API of the key's datacenter and decodes a successful
+// answer into out
+func (v *MailchimpValidator) get(ctx context.Context, dc, path, query, key string, out interface{}) (*http.Request, *apiResponse, error) {
+	endpoint := validator.Endpoint{URL: "https://{dc}.api.mailchimp.com/3.0" + path, Method: http.MethodGet}
+	rawURL := "https://" + dc + ".api.mailchimp.com/3.0" + path
+	if query != "" {
+		rawURL += "?" + query
+	}
+	req, err := http.NewRequestWithContext(ctx, endpoint.Method, rawURL, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	// Any user name is accepted alongside the key
+	req.SetBasicAuth("apikeyzer", key)
+
+	resp, err := send(v.client, req)
+	if err == nil && resp.StatusCode == http.StatusOK && out != nil {
+		err = resp.decode(out)
+	}
+	if err != nil {
+		reportProbe(ctx, endpoint, nil, err, false)
+		return nil, nil, err
+	}
+	reportProbe(ctx, endpoint, resp, nil, resp.StatusCode == http.StatusOK)
+	return req, resp, nil
+}
+
+// Validate pings the API of the key's datacenter, then reads the account and its audiences
+func (v *MailchimpValidator) Validate(ctx context.Context, key string) (*validator.ValidationResult, error) {
+	result := newResult(v.GetService())
+	key = strings.TrimSpace(key)
+	match := mailchimpDatacenter.FindStringSubmatch(key)
+	if match == nil {
+		return reject(result, "malformed key: no datacenter suffix such as -us6"), nil
+	}
+	dc := match[1]
+
+	req, resp, err := v.get(ctx, dc, "/ping", "", key, nil)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return reject(result, "key rejected: invalid, disabled or revoked"), nil
+	default:
+		return nil, fmt.Errorf("%w: unexpected status %d", validator.ErrValidationError, resp.StatusCode)
+	}
+	result.Valid = true
+	result.Details["datacenter"] = dc
+	result.PoC = map[string]string{req.URL.String(): validator.CurlCommand(req, nil)}
+
+	var account struct {
+		AccountName      string `json:"account_name"`
+		Email            string `json:"email"`
+		TotalSubscribers int    `json:"total_subscribers"`
+	}
+	if _, resp, err := v.get(ctx, dc, "/", "fields=account_name,email,total_subscribers", key, &account); err != nil {
+		result.Details["account"] = fmt.Sprintf("Error: %v", err)
+	} else if resp.StatusCode == http.StatusOK {
+		result.Details["account_name"] = account.AccountName
+		result.Details["email"] = account.Email
+		result.Details["total_subscribers"] = account.TotalSubscribers
+	}
+
+	var lists struct {
+		TotalItems int `json:"total_items"`
+		Lists      []struct {
+			Name  string `json:"name"`
+			Stats struct {
+				MemberCount int `json:"member_count"`
+			} `json:"stats"`
+		} `json:"lists"`
+	}
+	members := 0
+	if _, resp, err := v.get(ctx, dc, "/lists", "count=100&fields=total_items,lists.name,lists.stats.member_count", key, &lists); err != nil {
+		result.Details["audiences"] = fmt.Sprintf("Error: %v", err)
+	} else if resp.StatusCode == http.StatusOK {
+		result.Details["audience_count"] = lists.TotalItems
+		for _, list := range lists.Lists {
+			result.Permissions = append(result.Permissions, fmt.Sprintf("audience %s (%d members)", list.Name, list.Stats.MemberCount))
+			members += list.Stats.MemberCount
+		}
+	}
+
+	// Audiences hold subscriber contact details and can be mailed on the owner's behalf
+	result.RiskLevel = validator.RiskLevelMedium
+	if members > 0 {
+		result.RiskLevel = validator.RiskLevelHigh
+	}
+	return result, nil
+}
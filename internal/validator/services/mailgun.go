@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/transport"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// mailgunRegions are the Mailgun API hosts; an account and its keys live in a single region
+var mailgunRegions = []struct {
+	Name string
+	Host string
+}{
+	{"us", "https://api.mailgun.net"},
+	{"eu", "https://api.eu.mailgun.net"},
+}
+
+// MailgunValidator implements the Validator interface for Mailgun private API keys
+type MailgunValidator struct {
+	client *http.Client
+}
+
+// NewMailgunValidator creates a new Mailgun validator instance using client,
+// or a client on the shared connection pool with a 10 second timeout when client is nil
+func NewMailgunValidator(client *http.Client) *MailgunValidator {
+	if client == nil {
+		client = transport.DefaultClient(10 * time.Second)
+	}
+	return &MailgunValidator{client: client}
+}
+
+func (v *MailgunValidator) GetService() string {
+	return "Mailgun API Key"
+}
+
+func (v *MailgunValidator) GetValidationMethod() validator.ValidationMethod {
+	return validator.MethodHTTP
+}
+
+// Endpoints describes the endpoints probed during validation, in each region
+func (v *MailgunValidator) Endpoints() []validator.Endpoint {
+	endpoints := make([]validator.Endpoint, 0, len(mailgunRegions))
+	for _, region := range mailgunRegions {
+		endpoints = append(endpoints, validator.Endpoint{URL: region.Host + "/v3/domains", Method: http.MethodGet})
+	}
+	return endpoints
+}
+
+// Validate lists the domains of the account in the US region, then in the EU region, since
+// keys are only accepted in the region of their account
+func (v *MailgunValidator) Validate(ctx context.Context, key string) (*validator.ValidationResult, error) {
+	result := newResult(v.GetService())
+	key = strings.TrimSpace(key)
+
+	for _, region := range mailgunRegions {
+		endpoint := validator.Endpoint{URL: region.Host + "/v3/domains", Method: http.MethodGet}
+		req, err := http.NewRequestWithContext(ctx, endpoint.Method, endpoint.URL+"?limit=100", nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.SetBasicAuth("api", key)
+
+		var domains struct {
+			TotalCount int `json:"total_count"`
+			Items      []struct {
+				Name  string `json:"name"`
+				State string `json:"state"`
+			} `json:"items"`
+		}
+		resp, err := send(v.client, req)
+		if err == nil && resp.StatusCode == http.StatusOK {
+			err = resp.decode(&domains)
+		}
+		if err != nil {
+			reportProbe(ctx, endpoint, nil, err, false)
+			return nil, err
+		}
+		reportProbe(ctx, endpoint, resp, nil, resp.StatusCode == http.StatusOK)
+		switch resp.StatusCode {
+		case http.StatusOK:
+		case http.StatusUnauthorized, http.StatusForbidden:
+			continue
+		default:
+			return nil, fmt.Errorf("%w: unexpected status %d from the %s region", validator.ErrValidationError, resp.StatusCode, region.Name)
+		}
+
+		result.Valid = true
+		result.Details["region"] = region.Name
+		result.Details["total_domains"] = domains.TotalCount
+		active := 0
+		for _, domain := range domains.Items {
+			result.Permissions = append(result.Permissions, fmt.Sprintf("%s (%s)", domain.Name, domain.State))
+			if domain.State == "active" {
+				active++
+			}
+		}
+		result.PoC = map[string]string{endpoint.URL: validator.CurlCommand(req, nil)}
+
+		// Private keys send mail from every active domain of the account
+		result.RiskLevel = validator.RiskLevelMedium
+		if active > 0 {
+			result.RiskLevel = validator.RiskLevelHigh
+		}
+		return result, nil
+	}
+	return reject(result, "key rejected in the US and EU regions"), nil
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/transport"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// mistralModelsEndpoint lists the models available to a Mistral key, free of charge
+var mistralModelsEndpoint = validator.Endpoint{URL: "https://api.mistral.ai/v1/models", Method: http.MethodGet}
+
+// mistralPremier are ID prefixes of Mistral's premier models, priced above the open models
+var mistralPremier = []string{"mistral-large", "mistral-medium", "codestral", "pixtral-large", "magistral-medium", "devstral-medium"}
+
+// MistralValidator implements the Validator interface for Mistral AI API keys
+type MistralValidator struct {
+	client *http.Client
+}
+
+// NewMistralValidator creates a new Mistral validator instance using client,
+// or a client on the shared connection pool with a 10 second timeout when client is nil
+func NewMistralValidator(client *http.Client) *MistralValidator {
+	if client == nil {
+		client = transport.DefaultClient(10 * time.Second)
+	}
+	return &MistralValidator{client: client}
+}
+
+func (v *MistralValidator) GetService() string {
+	return "Mistral API Key"
+}
+
+func (v *MistralValidator) GetValidationMethod() validator.ValidationMethod {
+	return validator.MethodHTTP
+}
+
+// Endpoints describes the endpoints probed during validation
+func (v *MistralValidator) Endpoints() []validator.Endpoint {
+	return []validator.Endpoint{mistralModelsEndpoint}
+}
+
+// mistralTier returns the tier of a model: fine-tuned, premier or open
+func mistralTier(id string) string {
+	if strings.HasPrefix(id, "ft:") {
+		return "fine-tuned"
+	}
+	for _, prefix := range mistralPremier {
+		if strings.HasPrefix(id, prefix) {
+			return "premier"
+		}
+	}
+	return "open"
+}
+
+// Validate lists the models of the key and groups them into tiers; keys reaching premier or
+// fine-tuned models are high risk
+func (v *MistralValidator) Validate(ctx context.Context, key string) (*validator.ValidationResult, error) {
+	result := newResult(v.GetService())
+	key = strings.TrimSpace(key)
+
+	req, err := http.NewRequestWithContext(ctx, mistralModelsEndpoint.Method, mistralModelsEndpoint.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+key)
+
+	var models struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	resp, err := send(v.client, req)
+	if err == nil && resp.StatusCode == http.StatusOK {
+		err = resp.decode(&models)
+	}
+	if err != nil {
+		reportProbe(ctx, mistralModelsEndpoint, nil, err, false)
+		return nil, err
+	}
+	reportProbe(ctx, mistralModelsEndpoint, resp, nil, resp.StatusCode == http.StatusOK)
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return reject(result, "key rejected: invalid or deleted"), nil
+	default:
+		return nil, fmt.Errorf("%w: unexpected status %d", validator.ErrValidationError, resp.StatusCode)
+	}
+
+	result.Valid = true
+	result.PoC = map[string]string{mistralModelsEndpoint.URL: validator.CurlCommand(req, nil)}
+	tiers := make(map[string]int)
+	for _, model := range models.Data {
+		result.Permissions = append(result.Permissions, model.ID)
+		tiers[mistralTier(model.ID)]++
+	}
+	sort.Strings(result.Permissions)
+	result.Details["model_tiers"] = tiers
+
+	// Premier models cost the most per token, and fine-tuned models hold the owner's training data
+	result.RiskLevel = validator.RiskLevelMedium
+	if tiers["premier"] > 0 || tiers["fine-tuned"] > 0 {
+		result.RiskLevel = validator.RiskLevelHigh
+	}
+	return result, nil
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/transport"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// OpenAI API endpoints called during validation. /v1/me is undocumented, but answers API keys
+// with the user and organizations behind them.
+var (
+	openaiModelsEndpoint = validator.Endpoint{URL: "https://api.openai.com/v1/models", Method: http.MethodGet}
+	openaiMeEndpoint     = validator.Endpoint{URL: "https://api.openai.com/v1/me", Method: http.MethodGet}
+)
+
+// openaiPaidModels are model ID prefixes that free tier organizations cannot use; listing one
+// of them suggests the organization has paid for credits. Their "-mini" variants are free.
+var openaiPaidModels = []string{"gpt-4", "gpt-5", "o1", "o3", "o4"}
+
+// OpenAIValidator implements the Validator interface for OpenAI API keys
+type OpenAIValidator struct {
+	client *http.Client
+}
+
+// NewOpenAIValidator creates a new OpenAI validator instance using client,
+// or a client on the shared connection pool with a 10 second timeout when client is nil
+func NewOpenAIValidator(client *http.Client) *OpenAIValidator {
+	if client == nil {
+		client = transport.DefaultClient(10 * time.Second)
+	}
+	return &OpenAIValidator{client: client}
+}
+
+func (v *OpenAIValidator) GetService() string {
+	return "OpenAI API Key"
+}
+
+func (v *OpenAIValidator) GetValidationMethod() validator.ValidationMethod {
+	return validator.MethodHTTP
+}
+
+// Endpoints describes the endpoints probed during validation
+func (v *OpenAIValidator) Endpoints() []validator.Endpoint {
+	return []validator.Endpoint{openaiModelsEndpoint, openaiMeEndpoint}
+}
+
+// newOpenAIRequest builds a GET request for an OpenAI API endpoint authorized with key
+func newOpenAIRequest(ctx context.Context, endpoint validator.Endpoint, key string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, endpoint.Method, endpoint.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+key)
+	return req, nil
+}
+
+// get requests an OpenAI API endpoint and decodes a successful answer into out
+func (v *OpenAIValidator) get(ctx context.Context, endpoint validator.Endpoint, key string, out interface{}) (*apiResponse, error) {
+	req, err := newOpenAIRequest(ctx, endpoint, key)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := send(v.client, req)
+	if err == nil && resp.StatusCode == http.StatusOK {
+		err = resp.decode(out)
+	}
+	if err != nil {
+		reportProbe(ctx, endpoint, nil, err, false)
+		return nil, err
+	}
+	reportProbe(ctx, endpoint, resp, nil, resp.StatusCode == http.StatusOK)
+	return resp, nil
+}
+
+// Validate lists the models the key can use, then reads the organizations of its user. Keys
+// of organizations that appear to have paid for credits are high risk, since anyone holding
+// them runs up the owner's bill.
+func (v *OpenAIValidator) Validate(ctx context.Context, key string) (*validator.ValidationResult, error) {
+	result := newResult(v.GetService())
+	key = strings.TrimSpace(key)
+
+	var models struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	resp, err := v.get(ctx, openaiModelsEndpoint, key, &models)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusForbidden:
+		if strings.Contains(string(resp.Body), "unsupported_country_region_territory") {
+			return nil, fmt.Errorf("%w: OpenAI refuses requests from this region", validator.ErrValidationError)
+		}
+		// Project keys may be restricted from listing models; the key itself was accepted
+		result.Details["models"] = "listing models is not permitted for this key"
+	case http.StatusUnauthorized:
+		return reject(result, "key rejected: invalid, revoked or deleted"), nil
+	default:
+		return nil, fmt.Errorf("%w: unexpected status %d", validator.ErrValidationError, resp.StatusCode)
+	}
+	result.Valid = true
+	if req, err := newOpenAIRequest(ctx, openaiModelsEndpoint, key); err == nil {
+		result.PoC = map[string]string{openaiModelsEndpoint.URL: validator.CurlCommand(req, nil)}
+	}
+	if org := resp.Header.Get("Openai-Organization"); org != "" {
+		result.Details["organization_id"] = org
+	}
+	if project := resp.Header.Get("Openai-Project"); project != "" {
+		result.Details["project_id"] = project
+	}
+
+	paid := false
+	for _, model := range models.Data {
+		result.Permissions = append(result.Permissions, model.ID)
+		for _, prefix := range openaiPaidModels {
+			paid = paid || (strings.HasPrefix(model.ID, prefix) && !strings.Contains(model.ID, "-mini"))
+		}
+	}
+	sort.Strings(result.Permissions)
+	if resp.StatusCode == http.StatusOK {
+		result.Details["model_count"] = len(models.Data)
+		result.Details["paid_account"] = paid
+	}
+
+	var me struct {
+		Email string `json:"email"`
+		Orgs  struct {
+			Data []struct {
+				ID       string `json:"id"`
+				Title    string `json:"title"`
+				Role     string `json:"role"`
+				Personal bool   `json:"personal"`
+			} `json:"data"`
+		} `json:"orgs"`
+	}
+	if resp, err := v.get(ctx, openaiMeEndpoint, key, &me); err != nil {
+		result.Details["user"] = fmt.Sprintf("Error: %v", err)
+	} else if resp.StatusCode == http.StatusOK {
+		result.Details["email"] = me.Email
+		var orgs []string
+		for _, org := range me.Orgs.Data {
+			orgs = append(orgs, fmt.Sprintf("%s (%s, %s)", org.Title, org.ID, org.Role))
+		}
+		result.Details["organizations"] = orgs
+	}
+
+	// Admin keys manage the users, projects and keys of the whole organization
+	switch {
+	case paid || strings.HasPrefix(key, "sk-admin-"):
+		result.RiskLevel = validator.RiskLevelHigh
+	default:
+		result.RiskLevel = validator.RiskLevelMedium
+	}
+	return result, nil
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/transport"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// paypalEnvironments are the PayPal REST API hosts credentials may belong to, live first
+var paypalEnvironments = []struct {
+	Name string
+	Host string
+}{
+	{"live", "https://api-m.paypal.com"},
+	{"sandbox", "https://api-m.sandbox.paypal.com"},
+}
+
+// PayPalValidator implements the Validator interface for PayPal REST app credentials,
+// given as "<client id>:<secret>"
+type PayPalValidator struct {
+	client *http.Client
+}
+
+// NewPayPalValidator creates a new PayPal validator instance using client,
+// or a client on the shared connection pool with a 10 second timeout when client is nil
+func NewPayPalValidator(client *http.Client) *PayPalValidator {
+	if client == nil {
+		client = transport.DefaultClient(10 * time.Second)
+	}
+	return &PayPalValidator{client: client}
+}
+
+func (v *PayPalValidator) GetService() string {
+	return "PayPal Client Credentials"
+}
+
+func (v *PayPalValidator) GetValidationMethod() validator.ValidationMethod {
+	return validator.MethodHTTP
+}
+
+// Endpoints describes the endpoints probed during validation, in each environment
+func (v *PayPalValidator) Endpoints() []validator.Endpoint {
+	endpoints := make([]validator.Endpoint, 0, len(paypalEnvironments))
+	for _, env := range paypalEnvironments {
+		endpoints = append(endpoints, validator.Endpoint{URL: env.Host + "/v1/oauth2/token", Method: http.MethodPost})
+	}
+	return endpoints
+}
+
+// Validate requests an access token with the client credentials grant, live then in the
+// sandbox; the token is not used, but its grant reports the app and its scopes
+func (v *PayPalValidator) Validate(ctx context.Context, key string) (*validator.ValidationResult, error) {
+	result := newResult(v.GetService())
+	clientID, secret, ok := strings.Cut(strings.TrimSpace(key), ":")
+	if !ok || clientID == "" || secret == "" {
+		return reject(result, "malformed credentials: expected <client id>:<secret>"), nil
+	}
+
+	body := []byte("grant_type=client_credentials")
+	for _, env := range paypalEnvironments {
+		endpoint := validator.Endpoint{URL: env.Host + "/v1/oauth2/token", Method: http.MethodPost}
+		req, err := http.NewRequestWithContext(ctx, endpoint.Method, endpoint.URL, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.SetBasicAuth(clientID, secret)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Accept", "application/json")
+
+		var grant struct {
+			Scope     string `json:"scope"`
+			AppID     string `json:"app_id"`
+			ExpiresIn int    `json:"expires_in"`
+		}
+		resp, err := send(v.client, req)
+		if err == nil && resp.StatusCode == http.StatusOK {
+			err = resp.decode(&grant)
+		}
+		if err != nil {
+			reportProbe(ctx, endpoint, nil, err, false)
+			return nil, err
+		}
+		reportProbe(ctx, endpoint, resp, nil, resp.StatusCode == http.StatusOK)
+		switch resp.StatusCode {
+		case http.StatusOK:
+		case http.StatusUnauthorized:
+			// invalid_client: the credentials belong to the other environment or to nobody
+			continue
+		default:
+			return nil, fmt.Errorf("%w: unexpected status %d from %s", validator.ErrValidationError, resp.StatusCode, env.Name)
+		}
+
+		result.Valid = true
+		result.Permissions = strings.Fields(grant.Scope)
+		result.Details["environment"] = env.Name
+		result.Details["app_id"] = grant.AppID
+		result.PoC = map[string]string{endpoint.URL: validator.CurlCommand(req, body)}
+		// Live apps can take payments, refund them and, with the scope, send payouts
+		result.RiskLevel = validator.RiskLevelHigh
+		if env.Name == "sandbox" {
+			result.RiskLevel = validator.RiskLevelLow
+		}
+		return result, nil
+	}
+	return reject(result, "credentials rejected live and in the sandbox"), nil
+}
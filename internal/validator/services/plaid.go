@@ -0,0 +1,173 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/transport"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// plaidEnvironments are the Plaid API hosts, each of which has its own secret for a client ID.
+// Development was retired in 2024, so failing to reach it says nothing about the credentials.
+var plaidEnvironments = []struct {
+	Name    string
+	Host    string
+	Risk    validator.RiskLevel
+	Retired bool
+}{
+	{"production", "https://production.plaid.com", validator.RiskLevelHigh, false},
+	{"development", "https://development.plaid.com", validator.RiskLevelMedium, true},
+	{"sandbox", "https://sandbox.plaid.com", validator.RiskLevelLow, false},
+}
+
+// PlaidValidator implements the Validator interface for Plaid API credentials, given as
+// "<client id>:<secret>"
+type PlaidValidator struct {
+	client *http.Client
+}
+
+// NewPlaidValidator creates a new Plaid validator instance using client,
+// or a client on the shared connection pool with a 10 second timeout when client is nil
+func NewPlaidValidator(client *http.Client) *PlaidValidator {
+	if client == nil {
+		client = transport.DefaultClient(10 * time.Second)
+	}
+	return &PlaidValidator{client: client}
+}
+
+func (v *PlaidValidator) GetService() string {
+	return "Plaid API Credentials"
+}
+
+func (v *PlaidValidator) GetValidationMethod() validator.ValidationMethod {
+	return validator.MethodHTTP
+}
+
+// Endpoints describes the endpoints probed during validation, in each environment
+func (v *PlaidValidator) Endpoints() []validator.Endpoint {
+	endpoints := make([]validator.Endpoint, 0, len(plaidEnvironments))
+	for _, env := range plaidEnvironments {
+		endpoints = append(endpoints, validator.Endpoint{URL: env.Host + "/institutions/get", Method: http.MethodPost})
+	}
+	return endpoints
+}
+
+// Validate lists a single institution in every environment, since a secret only unlocks
+// the environment it was issued for
+func (v *PlaidValidator) Validate(ctx context.Context, key string) (*validator.ValidationResult, error) {
+	result := newResult(v.GetService())
+	clientID, secret, ok := strings.Cut(strings.TrimSpace(key), ":")
+	if !ok || clientID == "" || secret == "" {
+		return reject(result, "malformed credentials: expected <client id>:<secret>"), nil
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"client_id":     clientID,
+		"secret":        secret,
+		"count":         1,
+		"offset":        0,
+		"country_codes": []string{"US"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	var firstErr error
+	answered, failed := 0, 0
+	result.RiskLevel = validator.RiskLevelLow
+	for _, env := range plaidEnvironments {
+		endpoint := validator.Endpoint{URL: env.Host + "/institutions/get", Method: http.MethodPost}
+		accepted, err := v.check(ctx, endpoint, body)
+		switch {
+		case err != nil && env.Retired:
+			result.Details[env.Name] = fmt.Sprintf("Error: %v", err)
+			continue
+		case err != nil:
+			result.Details[env.Name] = fmt.Sprintf("Error: %v", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			failed++
+			continue
+		case !accepted:
+			result.Details[env.Name] = "rejected"
+			answered++
+			continue
+		}
+
+		answered++
+		result.Valid = true
+		result.Details[env.Name] = "accepted"
+		result.Permissions = append(result.Permissions, env.Name)
+		if env.Risk.Rank() > result.RiskLevel.Rank() {
+			result.RiskLevel = env.Risk
+		}
+		// The PoC reproduces the riskiest environment that accepted the credentials
+		if result.PoC == nil {
+			if req, err := v.newRequest(ctx, endpoint, body); err == nil {
+				result.PoC = map[string]string{endpoint.URL: validator.CurlCommand(req, body)}
+			}
+		}
+	}
+
+	switch {
+	case result.Valid:
+	case answered == 0:
+		return nil, firstErr
+	case firstErr != nil:
+		// The environments that failed might have accepted the secret
+		result.Error = firstErr
+		result.ErrorStr = fmt.Sprintf("%d of %d environments could not be checked: %v", failed, failed+answered, firstErr)
+		result.ErrorCode = validator.ErrorCodeFor(firstErr)
+	default:
+		return reject(result, "credentials rejected in every environment"), nil
+	}
+	return result, nil
+}
+
+// newRequest builds the institutions/get request of an environment
+func (v *PlaidValidator) newRequest(ctx context.Context, endpoint validator.Endpoint, body []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, endpoint.Method, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+// check reports whether an environment accepts the credentials. Plaid refuses credentials
+// of other environments with INVALID_API_KEYS; any other error says nothing about them.
+func (v *PlaidValidator) check(ctx context.Context, endpoint validator.Endpoint, body []byte) (bool, error) {
+	req, err := v.newRequest(ctx, endpoint, body)
+	if err != nil {
+		return false, err
+	}
+	resp, err := send(v.client, req)
+	if err != nil {
+		reportProbe(ctx, endpoint, nil, err, false)
+		return false, err
+	}
+	reportProbe(ctx, endpoint, resp, nil, resp.StatusCode == http.StatusOK)
+	if resp.StatusCode == http.StatusOK {
+		return true, nil
+	}
+
+	var plaidErr struct {
+		ErrorType    string `json:"error_type"`
+		ErrorCode    string `json:"error_code"`
+		ErrorMessage string `json:"error_message"`
+	}
+	if err := resp.decode(&plaidErr); err != nil {
+		return false, err
+	}
+	if plaidErr.ErrorCode == "INVALID_API_KEYS" {
+		return false, nil
+	}
+	return false, fmt.Errorf("%w: %d %s: %s", validator.ErrValidationError, resp.StatusCode, plaidErr.ErrorCode, plaidErr.ErrorMessage)
+}
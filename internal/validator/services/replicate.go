@@ -0,0 +1,182 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/transport"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// Replicate API endpoints called during validation. The account endpoint proves the token
+// works; starting a prediction is the only way to learn whether billing is set up, and is
+// charged for the moments it runs before being canceled. The prediction runs the latest
+// version of replicate/hello-world, the cheapest public model, looked up first.
+var (
+	replicateAccountEndpoint     = validator.Endpoint{URL: "https://api.replicate.com/v1/account", Method: http.MethodGet}
+	replicateModelEndpoint       = validator.Endpoint{URL: "https://api.replicate.com/v1/models/replicate/hello-world", Method: http.MethodGet}
+	replicatePredictionsEndpoint = validator.Endpoint{URL: "https://api.replicate.com/v1/predictions", Method: http.MethodPost, Billable: true}
+	replicateCancelEndpoint      = validator.Endpoint{URL: "https://api.replicate.com/v1/predictions/{id}/cancel", Method: http.MethodPost}
+)
+
+// ReplicateValidator implements the Validator interface for Replicate API tokens
+type ReplicateValidator struct {
+	client *http.Client
+}
+
+// NewReplicateValidator creates a new Replicate validator instance using client,
+// or a client on the shared connection pool with a 10 second timeout when client is nil
+func NewReplicateValidator(client *http.Client) *ReplicateValidator {
+	if client == nil {
+		client = transport.DefaultClient(10 * time.Second)
+	}
+	return &ReplicateValidator{client: client}
+}
+
+func (v *ReplicateValidator) GetService() string {
+	return "Replicate API Token"
+}
+
+func (v *ReplicateValidator) GetValidationMethod() validator.ValidationMethod {
+	return validator.MethodHTTP
+}
+
+// Endpoints describes the endpoints probed during validation
+func (v *ReplicateValidator) Endpoints() []validator.Endpoint {
+	return []validator.Endpoint{replicateAccountEndpoint, replicateModelEndpoint, replicatePredictionsEndpoint, replicateCancelEndpoint}
+}
+
+// do sends body to a Replicate API URL and decodes an answer with a status in success into out
+func (v *ReplicateValidator) do(ctx context.Context, endpoint validator.Endpoint, rawURL, key string, body []byte, success int, out interface{}) (*http.Request, *apiResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, endpoint.Method, rawURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+key)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := send(v.client, req)
+	if err == nil && resp.StatusCode == success && out != nil {
+		err = resp.decode(out)
+	}
+	if err != nil {
+		reportProbe(ctx, endpoint, nil, err, false)
+		return nil, nil, err
+	}
+	reportProbe(ctx, endpoint, resp, nil, resp.StatusCode == success)
+	return req, resp, nil
+}
+
+// Validate reads the account of the token, then, outside safe mode, starts and at once cancels
+// a prediction to learn whether the account can run paid predictions
+func (v *ReplicateValidator) Validate(ctx context.Context, key string) (*validator.ValidationResult, error) {
+	result := newResult(v.GetService())
+	key = strings.TrimSpace(key)
+
+	var account struct {
+		Type     string `json:"type"`
+		Username string `json:"username"`
+		Name     string `json:"name"`
+	}
+	req, resp, err := v.do(ctx, replicateAccountEndpoint, replicateAccountEndpoint.URL, key, nil, http.StatusOK, &account)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return reject(result, "token rejected: invalid or deleted"), nil
+	default:
+		return nil, fmt.Errorf("%w: unexpected status %d", validator.ErrValidationError, resp.StatusCode)
+	}
+	result.Valid = true
+	result.Details["username"] = account.Username
+	result.Details["name"] = account.Name
+	result.Details["account_type"] = account.Type
+	result.PoC = map[string]string{replicateAccountEndpoint.URL: validator.CurlCommand(req, nil)}
+
+	result.RiskLevel = validator.RiskLevelMedium
+	if validator.SafeMode(ctx) {
+		result.Details["billing_configured"] = "Skipped: billable endpoint (safe mode)"
+		validator.ReportProbe(ctx, validator.ProbeResult{Endpoint: replicatePredictionsEndpoint, Skipped: true})
+		return result, nil
+	}
+
+	billing, err := v.probeBilling(ctx, key)
+	if err != nil {
+		result.Details["billing_configured"] = fmt.Sprintf("Error: %v", err)
+		return result, nil
+	}
+	result.Details["billing_configured"] = billing
+	// Paid predictions are charged to the owner's card
+	if billing {
+		result.RiskLevel = validator.RiskLevelHigh
+	}
+	return result, nil
+}
+
+// probeVersion looks up the latest version of the model started by the billing probe
+func (v *ReplicateValidator) probeVersion(ctx context.Context, key string) (string, error) {
+	var model struct {
+		LatestVersion *struct {
+			ID string `json:"id"`
+		} `json:"latest_version"`
+	}
+	_, resp, err := v.do(ctx, replicateModelEndpoint, replicateModelEndpoint.URL, key, nil, http.StatusOK, &model)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: probe model lookup returned status %d", validator.ErrValidationError, resp.StatusCode)
+	}
+	if model.LatestVersion == nil || model.LatestVersion.ID == "" {
+		return "", fmt.Errorf("%w: probe model has no version", validator.ErrValidationError)
+	}
+	return model.LatestVersion.ID, nil
+}
+
+// probeBilling starts a prediction, which Replicate refuses with 402 Payment Required when the
+// account has no billing set up, and cancels it if it was started
+func (v *ReplicateValidator) probeBilling(ctx context.Context, key string) (bool, error) {
+	version, err := v.probeVersion(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"version": version,
+		"input":   map[string]string{"text": "apikeyzer"},
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to encode request: %w", err)
+	}
+	var prediction struct {
+		ID string `json:"id"`
+	}
+	_, resp, err := v.do(ctx, replicatePredictionsEndpoint, replicatePredictionsEndpoint.URL, key, body, http.StatusCreated, &prediction)
+	if err != nil {
+		return false, err
+	}
+	switch resp.StatusCode {
+	case http.StatusCreated:
+	case http.StatusPaymentRequired:
+		return false, nil
+	default:
+		return false, fmt.Errorf("%w: unexpected status %d", validator.ErrValidationError, resp.StatusCode)
+	}
+
+	cancelURL := strings.Replace(replicateCancelEndpoint.URL, "{id}", prediction.ID, 1)
+	if _, resp, err := v.do(ctx, replicateCancelEndpoint, cancelURL, key, nil, http.StatusOK, nil); err != nil {
+		slog.Warn("could not cancel the billing probe prediction", "id", prediction.ID, "error", err)
+	} else if resp.StatusCode != http.StatusOK {
+		slog.Warn("could not cancel the billing probe prediction", "id", prediction.ID, "status", resp.StatusCode)
+	}
+	return true, nil
+}
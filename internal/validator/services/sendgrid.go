@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/transport"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// SendGrid API endpoints called during validation
+var (
+	sendgridScopesEndpoint  = validator.Endpoint{URL: "https://api.sendgrid.com/v3/scopes", Method: http.MethodGet}
+	sendgridAccountEndpoint = validator.Endpoint{URL: "https://api.sendgrid.com/v3/user/account", Method: http.MethodGet}
+)
+
+// SendGridValidator implements the Validator interface for SendGrid API keys
+type SendGridValidator struct {
+	client *http.Client
+}
+
+// NewSendGridValidator creates a new SendGrid validator instance using client,
+// or a client on the shared connection pool with a 10 second timeout when client is nil
+func NewSendGridValidator(client *http.Client) *SendGridValidator {
+	if client == nil {
+		client = transport.DefaultClient(10 * time.Second)
+	}
+	return &SendGridValidator{client: client}
+}
+
+func (v *SendGridValidator) GetService() string {
+	return "SendGrid API Key"
+}
+
+func (v *SendGridValidator) GetValidationMethod() validator.ValidationMethod {
+	return validator.MethodHTTP
+}
+
+// Endpoints describes the endpoints probed during validation
+func (v *SendGridValidator) Endpoints() []validator.Endpoint {
+	return []validator.Endpoint{sendgridScopesEndpoint, sendgridAccountEndpoint}
+}
+
+// newSendGridRequest builds a GET request for a SendGrid API endpoint authorized with key
+func newSendGridRequest(ctx context.Context, endpoint validator.Endpoint, key string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, endpoint.Method, endpoint.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+key)
+	req.Header.Set("Accept", "application/json")
+	return req, nil
+}
+
+// get requests a SendGrid API endpoint and decodes a successful answer into out
+func (v *SendGridValidator) get(ctx context.Context, endpoint validator.Endpoint, key string, out interface{}) (*apiResponse, error) {
+	req, err := newSendGridRequest(ctx, endpoint, key)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := send(v.client, req)
+	if err == nil && resp.StatusCode == http.StatusOK {
+		err = resp.decode(out)
+	}
+	if err != nil {
+		reportProbe(ctx, endpoint, nil, err, false)
+		return nil, err
+	}
+	reportProbe(ctx, endpoint, resp, nil, resp.StatusCode == http.StatusOK)
+	return resp, nil
+}
+
+// Validate lists the scopes granted to the key, then reads the account type when the key
+// may; keys that can send mail are high risk
+func (v *SendGridValidator) Validate(ctx context.Context, key string) (*validator.ValidationResult, error) {
+	result := newResult(v.GetService())
+	key = strings.TrimSpace(key)
+
+	var scopes struct {
+		Scopes []string `json:"scopes"`
+	}
+	resp, err := v.get(ctx, sendgridScopesEndpoint, key, &scopes)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return reject(result, "key rejected: invalid, revoked or disabled"), nil
+	default:
+		return nil, fmt.Errorf("%w: unexpected status %d", validator.ErrValidationError, resp.StatusCode)
+	}
+
+	result.Valid = true
+	result.Permissions = scopes.Scopes
+	sort.Strings(result.Permissions)
+	result.Details["scope_count"] = len(scopes.Scopes)
+	if req, err := newSendGridRequest(ctx, sendgridScopesEndpoint, key); err == nil {
+		result.PoC = map[string]string{sendgridScopesEndpoint.URL: validator.CurlCommand(req, nil)}
+	}
+
+	var account struct {
+		Type       string  `json:"type"`
+		Reputation float64 `json:"reputation"`
+	}
+	if resp, err := v.get(ctx, sendgridAccountEndpoint, key, &account); err != nil {
+		result.Details["account"] = fmt.Sprintf("Error: %v", err)
+	} else if resp.StatusCode == http.StatusOK {
+		result.Details["account_type"] = account.Type
+		result.Details["reputation"] = account.Reputation
+	}
+
+	// mail.send lets anyone send phishing from the owner's verified domains and reputation
+	canSend := false
+	for _, scope := range scopes.Scopes {
+		canSend = canSend || scope == "mail.send"
+	}
+	result.Details["mail_send"] = canSend
+	switch {
+	case canSend:
+		result.RiskLevel = validator.RiskLevelHigh
+	case len(scopes.Scopes) > 0:
+		result.RiskLevel = validator.RiskLevelMedium
+	default:
+		result.RiskLevel = validator.RiskLevelLow
+	}
+	return result, nil
+}
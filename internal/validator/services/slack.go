@@ -0,0 +1,306 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/transport"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// Slack API endpoints called during validation
+var (
+	slackAuthTestEndpoint  = validator.Endpoint{URL: "https://slack.com/api/auth.test", Method: http.MethodPost}
+	slackUsersInfoEndpoint = validator.Endpoint{URL: "https://slack.com/api/users.info", Method: http.MethodGet}
+)
+
+// slackRejections are the Web API errors of tokens that no longer authenticate
+var slackRejections = map[string]bool{
+	"invalid_auth":     true,
+	"not_authed":       true,
+	"token_revoked":    true,
+	"token_expired":    true,
+	"account_inactive": true,
+	"team_disabled":    true,
+}
+
+// slackWrongTokenType is the error of methods called with a valid token of the wrong kind
+const slackWrongTokenType = "not_allowed_token_type"
+
+// slackAnswer is the envelope of every Slack Web API response
+type slackAnswer struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+// SlackTokenValidator implements the Validator interface for Slack bot (xoxb), user (xoxp)
+// and app-level (xapp) tokens
+type SlackTokenValidator struct {
+	client *http.Client
+}
+
+// NewSlackTokenValidator creates a new Slack token validator instance using client,
+// or a client on the shared connection pool with a 10 second timeout when client is nil
+func NewSlackTokenValidator(client *http.Client) *SlackTokenValidator {
+	if client == nil {
+		client = transport.DefaultClient(10 * time.Second)
+	}
+	return &SlackTokenValidator{client: client}
+}
+
+func (v *SlackTokenValidator) GetService() string {
+	return "Slack Token"
+}
+
+func (v *SlackTokenValidator) GetValidationMethod() validator.ValidationMethod {
+	return validator.MethodHTTP
+}
+
+// Endpoints describes the endpoints probed during validation
+func (v *SlackTokenValidator) Endpoints() []validator.Endpoint {
+	return []validator.Endpoint{slackAuthTestEndpoint, slackUsersInfoEndpoint}
+}
+
+// newSlackRequest builds a Web API request authorized with token
+func newSlackRequest(ctx context.Context, endpoint validator.Endpoint, rawURL, token string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, endpoint.Method, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if endpoint.Method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	return req, nil
+}
+
+// call sends a Web API request and decodes its answer into out, which embeds slackAnswer
+func (v *SlackTokenValidator) call(ctx context.Context, endpoint validator.Endpoint, rawURL, token string, out interface{}) (*apiResponse, error) {
+	req, err := newSlackRequest(ctx, endpoint, rawURL, token)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := send(v.client, req)
+	if err == nil {
+		err = resp.decode(out)
+	}
+	if err != nil {
+		reportProbe(ctx, endpoint, nil, err, false)
+		return nil, err
+	}
+	var answer slackAnswer
+	json.Unmarshal(resp.Body, &answer)
+	reportProbe(ctx, endpoint, resp, nil, answer.OK || answer.Error == slackWrongTokenType)
+	return resp, nil
+}
+
+// Validate identifies the token with auth.test, reporting the workspace, the user or bot
+// it belongs to and its scopes
+func (v *SlackTokenValidator) Validate(ctx context.Context, key string) (*validator.ValidationResult, error) {
+	result := newResult(v.GetService())
+	token := strings.TrimSpace(key)
+
+	var identity struct {
+		slackAnswer
+		URL                 string `json:"url"`
+		Team                string `json:"team"`
+		TeamID              string `json:"team_id"`
+		User                string `json:"user"`
+		UserID              string `json:"user_id"`
+		BotID               string `json:"bot_id"`
+		EnterpriseID        string `json:"enterprise_id"`
+		IsEnterpriseInstall bool   `json:"is_enterprise_install"`
+	}
+	resp, err := v.call(ctx, slackAuthTestEndpoint, slackAuthTestEndpoint.URL, token, &identity)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case slackRejections[identity.Error]:
+		return reject(result, fmt.Sprintf("token rejected: %s", identity.Error)), nil
+	case identity.Error == slackWrongTokenType:
+		// Only a token Slack authenticated gets this far, but auth.test does not describe it
+		result.Details["note"] = "token authenticated but auth.test does not accept its type"
+	case !identity.OK:
+		return nil, fmt.Errorf("%w: auth.test failed: %s", validator.ErrValidationError, identity.Error)
+	}
+
+	result.Valid = true
+	result.Details["token_type"] = slackTokenType(token)
+	for name, value := range map[string]string{
+		"team": identity.Team, "team_id": identity.TeamID, "url": identity.URL, "user": identity.User,
+		"user_id": identity.UserID, "bot_id": identity.BotID, "enterprise_id": identity.EnterpriseID,
+	} {
+		if value != "" {
+			result.Details[name] = value
+		}
+	}
+	if identity.IsEnterpriseInstall {
+		result.Details["enterprise_install"] = true
+	}
+	for _, scope := range strings.Split(resp.Header.Get("X-OAuth-Scopes"), ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			result.Permissions = append(result.Permissions, scope)
+		}
+	}
+	if req, err := newSlackRequest(ctx, slackAuthTestEndpoint, slackAuthTestEndpoint.URL, token); err == nil {
+		result.PoC = map[string]string{slackAuthTestEndpoint.URL: validator.CurlCommand(req, nil)}
+	}
+
+	result.RiskLevel = validator.RiskLevelMedium
+	if v.isAdmin(ctx, token, identity.UserID, result) {
+		result.RiskLevel = validator.RiskLevelHigh
+	}
+	return result, nil
+}
+
+// isAdmin reports whether the token acts with workspace admin rights: it was granted admin
+// scopes, or it is the user token of a workspace admin or owner, which users.info tells when
+// the token may read users
+func (v *SlackTokenValidator) isAdmin(ctx context.Context, token, userID string, result *validator.ValidationResult) bool {
+	canReadUsers := false
+	for _, scope := range result.Permissions {
+		if scope == "admin" || strings.HasPrefix(scope, "admin.") {
+			return true
+		}
+		canReadUsers = canReadUsers || scope == "users:read"
+	}
+	if !strings.HasPrefix(token, "xoxp-") || !canReadUsers || userID == "" {
+		return false
+	}
+
+	var info struct {
+		slackAnswer
+		User struct {
+			IsAdmin        bool `json:"is_admin"`
+			IsOwner        bool `json:"is_owner"`
+			IsPrimaryOwner bool `json:"is_primary_owner"`
+		} `json:"user"`
+	}
+	rawURL := slackUsersInfoEndpoint.URL + "?" + url.Values{"user": {userID}}.Encode()
+	if _, err := v.call(ctx, slackUsersInfoEndpoint, rawURL, token, &info); err != nil || !info.OK {
+		return false
+	}
+	switch {
+	case info.User.IsPrimaryOwner:
+		result.Details["role"] = "primary owner"
+	case info.User.IsOwner:
+		result.Details["role"] = "owner"
+	case info.User.IsAdmin:
+		result.Details["role"] = "admin"
+	default:
+		result.Details["role"] = "member"
+		return false
+	}
+	return true
+}
+
+// slackTokenType names the kind of token from its prefix
+func slackTokenType(token string) string {
+	switch {
+	case strings.HasPrefix(token, "xoxb-"):
+		return "bot"
+	case strings.HasPrefix(token, "xoxp-"):
+		return "user"
+	case strings.HasPrefix(token, "xapp-"):
+		return "app"
+	default:
+		return "unknown"
+	}
+}
+
+// slackWebhookPrefix starts the URL of every Slack incoming webhook
+const slackWebhookPrefix = "https://hooks.slack.com/services/"
+
+// slackWebhookAnswers maps the plain-text answers of incoming webhooks to whether the
+// webhook still posts: a payload without text is refused only once the URL checked out
+var slackWebhookAnswers = map[string]bool{
+	"no_text": true,
+	"missing_text_or_fallback_or_attachments": true,
+	"invalid_payload":                         true,
+	"invalid_token":                           false,
+	"no_service":                              false,
+	"no_service_id":                           false,
+	"no_team":                                 false,
+	"team_disabled":                           false,
+	"channel_not_found":                       false,
+	"channel_is_archived":                     false,
+	"action_prohibited":                       false,
+}
+
+// SlackWebhookValidator implements the Validator interface for Slack incoming webhook URLs
+type SlackWebhookValidator struct {
+	client *http.Client
+}
+
+// NewSlackWebhookValidator creates a new Slack webhook validator instance using client,
+// or a client on the shared connection pool with a 10 second timeout when client is nil
+func NewSlackWebhookValidator(client *http.Client) *SlackWebhookValidator {
+	if client == nil {
+		client = transport.DefaultClient(10 * time.Second)
+	}
+	return &SlackWebhookValidator{client: client}
+}
+
+func (v *SlackWebhookValidator) GetService() string {
+	return "Slack Webhook"
+}
+
+func (v *SlackWebhookValidator) GetValidationMethod() validator.ValidationMethod {
+	return validator.MethodHTTP
+}
+
+// Endpoints describes the endpoints probed during validation
+func (v *SlackWebhookValidator) Endpoints() []validator.Endpoint {
+	return []validator.Endpoint{{URL: slackWebhookPrefix + "{team}/{bot}/{secret}", Method: http.MethodPost}}
+}
+
+// Validate posts a message without text to the webhook, which Slack refuses without posting
+// anything; the reason it gives tells a live webhook from a revoked one
+func (v *SlackWebhookValidator) Validate(ctx context.Context, key string) (*validator.ValidationResult, error) {
+	result := newResult(v.GetService())
+	endpoint := v.Endpoints()[0]
+	webhook := strings.TrimSpace(key)
+	// The URL is sent to as is, so only Slack's own webhook host is accepted
+	if !strings.HasPrefix(webhook, slackWebhookPrefix) {
+		return reject(result, "malformed webhook: expected "+slackWebhookPrefix+"..."), nil
+	}
+
+	body := []byte(`{"text":""}`)
+	req, err := http.NewRequestWithContext(ctx, endpoint.Method, webhook, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := send(v.client, req)
+	if err != nil {
+		reportProbe(ctx, endpoint, nil, err, false)
+		return nil, err
+	}
+
+	answer := strings.TrimSpace(string(resp.Body))
+	live, known := slackWebhookAnswers[answer]
+	reportProbe(ctx, endpoint, resp, nil, live)
+	switch {
+	case !known:
+		return nil, fmt.Errorf("%w: unexpected webhook answer %d %q", validator.ErrValidationError, resp.StatusCode, answer)
+	case !live:
+		return reject(result, fmt.Sprintf("webhook rejected: %s", answer)), nil
+	}
+
+	result.Valid = true
+	result.Permissions = []string{"incoming-webhook"}
+	if parts := strings.Split(strings.TrimPrefix(webhook, slackWebhookPrefix), "/"); len(parts) == 3 {
+		result.Details["team_id"] = parts[0]
+	}
+	// Anyone holding the URL can post into the channel, e.g. phishing links that look internal
+	result.RiskLevel = validator.RiskLevelMedium
+	result.PoC = map[string]string{endpoint.URL: validator.CurlCommand(req, body)}
+	return result, nil
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/transport"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// sparkpostRegions are the SparkPost API hosts; an account and its keys live in a single region
+var sparkpostRegions = []struct {
+	Name string
+	Host string
+}{
+	{"us", "https://api.sparkpost.com/api/v1"},
+	{"eu", "https://api.eu.sparkpost.com/api/v1"},
+}
+
+// sparkpostGrants maps read-only endpoints to the key grant they require. SparkPost has no
+// endpoint describing the grants of a key, so they are inferred from which endpoints answer.
+var sparkpostGrants = []struct {
+	Path  string
+	Grant string
+}{
+	{"/account", "account:read"},
+	{"/sending-domains", "sending_domains:read"},
+	{"/templates", "templates:view"},
+	{"/recipient-lists", "recipient_lists:view"},
+	{"/webhooks", "webhooks:view"},
+	{"/transmissions", "transmissions:view"},
+}
+
+// SparkPostValidator implements the Validator interface for SparkPost API keys
+type SparkPostValidator struct {
+	client *http.Client
+}
+
+// NewSparkPostValidator creates a new SparkPost validator instance using client,
+// or a client on the shared connection pool with a 10 second timeout when client is nil
+func NewSparkPostValidator(client *http.Client) *SparkPostValidator {
+	if client == nil {
+		client = transport.DefaultClient(10 * time.Second)
+	}
+	return &SparkPostValidator{client: client}
+}
+
+func (v *SparkPostValidator) GetService() string {
+	return "SparkPost API Key"
+}
+
+func (v *SparkPostValidator) GetValidationMethod() validator.ValidationMethod {
+	return validator.MethodHTTP
+}
+
+// Endpoints describes the endpoints probed during validation, in each region
+func (v *SparkPostValidator) Endpoints() []validator.Endpoint {
+	var endpoints []validator.Endpoint
+	for _, region := range sparkpostRegions {
+		for _, grant := range sparkpostGrants {
+			endpoints = append(endpoints, validator.Endpoint{URL: region.Host + grant.Path, Method: http.MethodGet})
+		}
+	}
+	return endpoints
+}
+
+// get requests a SparkPost API URL and decodes a successful answer into out
+func (v *SparkPostValidator) get(ctx context.Context, rawURL, key string, out interface{}) (*http.Request, *apiResponse, error) {
+	endpoint := validator.Endpoint{URL: rawURL, Method: http.MethodGet}
+	req, err := http.NewRequestWithContext(ctx, endpoint.Method, rawURL, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	// SparkPost takes the bare key, without a scheme
+	req.Header.Set("Authorization", key)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := send(v.client, req)
+	if err == nil && resp.StatusCode == http.StatusOK && out != nil {
+		err = resp.decode(out)
+	}
+	if err != nil {
+		reportProbe(ctx, endpoint, nil, err, false)
+		return nil, nil, err
+	}
+	reportProbe(ctx, endpoint, resp, nil, resp.StatusCode == http.StatusOK)
+	return req, resp, nil
+}
+
+// Validate finds the region that accepts the key, then infers its grants from the read-only
+// endpoints it may call there; verified sending domains show a production account
+func (v *SparkPostValidator) Validate(ctx context.Context, key string) (*validator.ValidationResult, error) {
+	result := newResult(v.GetService())
+	key = strings.TrimSpace(key)
+
+	for _, region := range sparkpostRegions {
+		var account struct {
+			Results struct {
+				CustomerID   int    `json:"customer_id"`
+				CompanyName  string `json:"company_name"`
+				Status       string `json:"status"`
+				Subscription struct {
+					Name string `json:"name"`
+				} `json:"subscription"`
+			} `json:"results"`
+		}
+		req, resp, err := v.get(ctx, region.Host+"/account", key, &account)
+		if err != nil {
+			return nil, err
+		}
+		switch resp.StatusCode {
+		case http.StatusOK:
+			result.Details["customer_id"] = account.Results.CustomerID
+			result.Details["company_name"] = account.Results.CompanyName
+			result.Details["account_status"] = account.Results.Status
+			result.Details["plan"] = account.Results.Subscription.Name
+			result.Permissions = append(result.Permissions, "account:read")
+			result.PoC = map[string]string{req.URL.String(): validator.CurlCommand(req, nil)}
+		case http.StatusForbidden:
+			// Authenticated, but without the grant to read the account
+		case http.StatusUnauthorized:
+			continue
+		default:
+			return nil, fmt.Errorf("%w: unexpected status %d from the %s region", validator.ErrValidationError, resp.StatusCode, region.Name)
+		}
+		result.Valid = true
+		result.Details["region"] = region.Name
+
+		environment := v.probeGrants(ctx, region.Host, key, result)
+		result.Details["environment"] = environment
+		switch environment {
+		case "production":
+			result.RiskLevel = validator.RiskLevelHigh
+		default:
+			result.RiskLevel = validator.RiskLevelMedium
+		}
+		return result, nil
+	}
+	return reject(result, "key rejected in the US and EU regions"), nil
+}
+
+// probeGrants adds the grants of the key to result and returns the environment of the
+// account: production once a sending domain is verified, sandbox before that, or unknown
+// when the key may not read sending domains
+func (v *SparkPostValidator) probeGrants(ctx context.Context, host, key string, result *validator.ValidationResult) string {
+	environment := "unknown"
+	for _, grant := range sparkpostGrants[1:] {
+		var domains struct {
+			Results []struct {
+				Domain string `json:"domain"`
+				Status struct {
+					OwnershipVerified bool   `json:"ownership_verified"`
+					ComplianceStatus  string `json:"compliance_status"`
+				} `json:"status"`
+			} `json:"results"`
+		}
+		var out interface{}
+		if grant.Path == "/sending-domains" {
+			out = &domains
+		}
+		_, resp, err := v.get(ctx, host+grant.Path, key, out)
+		switch {
+		case err != nil:
+			result.Details[grant.Grant] = fmt.Sprintf("Error: %v", err)
+			continue
+		case resp.StatusCode != http.StatusOK:
+			continue
+		}
+		result.Permissions = append(result.Permissions, grant.Grant)
+
+		if grant.Path != "/sending-domains" {
+			continue
+		}
+		environment = "sandbox"
+		var verified []string
+		for _, domain := range domains.Results {
+			if domain.Status.OwnershipVerified && domain.Status.ComplianceStatus == "valid" {
+				verified = append(verified, domain.Domain)
+				environment = "production"
+			}
+		}
+		result.Details["sending_domains"] = verified
+	}
+	return environment
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/transport"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// squareEnvironments are the Square API hosts a token may belong to, production first
+var squareEnvironments = []struct {
+	Name string
+	Host string
+}{
+	{"production", "https://connect.squareup.com"},
+	{"sandbox", "https://connect.squareupsandbox.com"},
+}
+
+// squarePaymentScopes are the OAuth scopes that expose payments
+var squarePaymentScopes = map[string]bool{
+	"PAYMENTS_READ":  true,
+	"PAYMENTS_WRITE": true,
+}
+
+// SquareValidator implements the Validator interface for Square access tokens, in the
+// production and sandbox environments
+type SquareValidator struct {
+	client *http.Client
+}
+
+// NewSquareValidator creates a new Square validator instance using client,
+// or a client on the shared connection pool with a 10 second timeout when client is nil
+func NewSquareValidator(client *http.Client) *SquareValidator {
+	if client == nil {
+		client = transport.DefaultClient(10 * time.Second)
+	}
+	return &SquareValidator{client: client}
+}
+
+func (v *SquareValidator) GetService() string {
+	return "Square API Key"
+}
+
+func (v *SquareValidator) GetValidationMethod() validator.ValidationMethod {
+	return validator.MethodHTTP
+}
+
+// Endpoints describes the endpoints probed during validation, in each environment
+func (v *SquareValidator) Endpoints() []validator.Endpoint {
+	var endpoints []validator.Endpoint
+	for _, env := range squareEnvironments {
+		endpoints = append(endpoints,
+			validator.Endpoint{URL: env.Host + "/oauth2/token/status", Method: http.MethodPost},
+			validator.Endpoint{URL: env.Host + "/v2/merchants/me", Method: http.MethodGet},
+			validator.Endpoint{URL: env.Host + "/v2/locations", Method: http.MethodGet},
+		)
+	}
+	return endpoints
+}
+
+// newSquareRequest builds a request to a Square API URL authorized with token
+func newSquareRequest(ctx context.Context, method, rawURL, token string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+	return req, nil
+}
+
+// call requests a Square API URL and decodes a successful answer into out
+func (v *SquareValidator) call(ctx context.Context, method, rawURL, token string, out interface{}) (*apiResponse, error) {
+	endpoint := validator.Endpoint{URL: rawURL, Method: method}
+	req, err := newSquareRequest(ctx, method, rawURL, token)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := send(v.client, req)
+	if err == nil && resp.StatusCode == http.StatusOK {
+		err = resp.decode(out)
+	}
+	if err != nil {
+		reportProbe(ctx, endpoint, nil, err, false)
+		return nil, err
+	}
+	reportProbe(ctx, endpoint, resp, nil, resp.StatusCode == http.StatusOK)
+	return resp, nil
+}
+
+// Validate finds the environment that accepts the token by retrieving its status, which
+// lists its scopes, then reads the merchant and its locations there
+func (v *SquareValidator) Validate(ctx context.Context, key string) (*validator.ValidationResult, error) {
+	result := newResult(v.GetService())
+	token := strings.TrimSpace(key)
+
+	var status struct {
+		Scopes     []string `json:"scopes"`
+		ExpiresAt  string   `json:"expires_at"`
+		ClientID   string   `json:"client_id"`
+		MerchantID string   `json:"merchant_id"`
+	}
+	var host, environment string
+	for _, env := range squareEnvironments {
+		resp, err := v.call(ctx, http.MethodPost, env.Host+"/oauth2/token/status", token, &status)
+		if err != nil {
+			return nil, err
+		}
+		switch resp.StatusCode {
+		case http.StatusOK:
+			host, environment = env.Host, env.Name
+		case http.StatusUnauthorized:
+			continue
+		default:
+			return nil, fmt.Errorf("%w: unexpected status %d from %s", validator.ErrValidationError, resp.StatusCode, env.Name)
+		}
+		break
+	}
+	if host == "" {
+		return reject(result, "token rejected in production and sandbox: invalid, expired or revoked"), nil
+	}
+
+	result.Valid = true
+	result.Details["environment"] = environment
+	result.Details["merchant_id"] = status.MerchantID
+	if status.ExpiresAt != "" {
+		result.Details["expires_at"] = status.ExpiresAt
+	}
+	payments := false
+	if len(status.Scopes) == 0 {
+		// Personal access tokens carry every permission of the account, and no scopes
+		result.Permissions = []string{"all scopes (personal access token)"}
+		payments = true
+	}
+	for _, scope := range status.Scopes {
+		result.Permissions = append(result.Permissions, scope)
+		payments = payments || squarePaymentScopes[scope]
+	}
+	result.Details["payments_scope"] = payments
+	if req, err := newSquareRequest(ctx, http.MethodPost, host+"/oauth2/token/status", token); err == nil {
+		result.PoC = map[string]string{host + "/oauth2/token/status": validator.CurlCommand(req, nil)}
+	}
+
+	// The merchant and its locations need their own scopes, so a refusal leaves the verdict as is
+	var merchant struct {
+		Merchant struct {
+			BusinessName string `json:"business_name"`
+			Country      string `json:"country"`
+			Status       string `json:"status"`
+		} `json:"merchant"`
+	}
+	if resp, err := v.call(ctx, http.MethodGet, host+"/v2/merchants/me", token, &merchant); err != nil {
+		result.Details["merchant"] = fmt.Sprintf("Error: %v", err)
+	} else if resp.StatusCode == http.StatusOK {
+		result.Details["merchant_name"] = merchant.Merchant.BusinessName
+		result.Details["country"] = merchant.Merchant.Country
+		result.Details["merchant_status"] = merchant.Merchant.Status
+	}
+
+	var locations struct {
+		Locations []struct {
+			Name string `json:"name"`
+		} `json:"locations"`
+	}
+	if resp, err := v.call(ctx, http.MethodGet, host+"/v2/locations", token, &locations); err != nil {
+		result.Details["locations"] = fmt.Sprintf("Error: %v", err)
+	} else if resp.StatusCode == http.StatusOK {
+		names := make([]string, 0, len(locations.Locations))
+		for _, location := range locations.Locations {
+			names = append(names, location.Name)
+		}
+		result.Details["locations"] = names
+	}
+
+	// Sandbox tokens only reach test data
+	switch {
+	case environment == "sandbox":
+		result.RiskLevel = validator.RiskLevelLow
+	case payments:
+		result.RiskLevel = validator.RiskLevelHigh
+	default:
+		result.RiskLevel = validator.RiskLevelMedium
+	}
+	return result, nil
+}
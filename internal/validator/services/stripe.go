@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/transport"
+	"github.com/Xplo8E/APIKeyzer/internal/validator"
+)
+
+// stripeAPI is the base URL of the Stripe API
+const stripeAPI = "https://api.stripe.com/v1"
+
+// stripeResources are the read-only list endpoints restricted keys are probed with, since
+// Stripe has no endpoint describing the permissions of a key
+var stripeResources = []string{
+	"charges",
+	"customers",
+	"payment_intents",
+	"payouts",
+	"refunds",
+	"subscriptions",
+	"invoices",
+	"products",
+	"events",
+	"webhook_endpoints",
+}
+
+// StripeValidator implements the Validator interface for Stripe secret (sk_) and
+// restricted (rk_) API keys, in live and test mode
+type StripeValidator struct {
+	client *http.Client
+}
+
+// NewStripeValidator creates a new Stripe validator instance using client,
+// or a client on the shared connection pool with a 10 second timeout when client is nil
+func NewStripeValidator(client *http.Client) *StripeValidator {
+	if client == nil {
+		client = transport.DefaultClient(10 * time.Second)
+	}
+	return &StripeValidator{client: client}
+}
+
+func (v *StripeValidator) GetService() string {
+	return "Stripe API Key"
+}
+
+func (v *StripeValidator) GetValidationMethod() validator.ValidationMethod {
+	return validator.MethodHTTP
+}
+
+// Endpoints describes the endpoints probed during validation; the resource endpoints are
+// only probed for restricted keys
+func (v *StripeValidator) Endpoints() []validator.Endpoint {
+	endpoints := []validator.Endpoint{
+		{URL: stripeAPI + "/account", Method: http.MethodGet},
+		{URL: stripeAPI + "/balance", Method: http.MethodGet},
+	}
+	for _, resource := range stripeResources {
+		endpoints = append(endpoints, validator.Endpoint{URL: stripeAPI + "/" + resource, Method: http.MethodGet})
+	}
+	return endpoints
+}
+
+// newStripeRequest builds a GET request for a Stripe API path authorized with key
+func newStripeRequest(ctx context.Context, path, query, key string) (*http.Request, error) {
+	rawURL := stripeAPI + path
+	if query != "" {
+		rawURL += "?" + query
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+key)
+	return req, nil
+}
+
+// get requests a Stripe API path and decodes a successful answer into out
+func (v *StripeValidator) get(ctx context.Context, path, query, key string, out interface{}) (*apiResponse, error) {
+	endpoint := validator.Endpoint{URL: stripeAPI + path, Method: http.MethodGet}
+	req, err := newStripeRequest(ctx, path, query, key)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := send(v.client, req)
+	if err == nil && resp.StatusCode == http.StatusOK && out != nil {
+		err = resp.decode(out)
+	}
+	if err != nil {
+		reportProbe(ctx, endpoint, nil, err, false)
+		return nil, err
+	}
+	reportProbe(ctx, endpoint, resp, nil, resp.StatusCode == http.StatusOK)
+	return resp, nil
+}
+
+// stripeStatusError describes a Stripe answer that neither accepts nor rejects a key
+func stripeStatusError(resp *apiResponse) error {
+	var body struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	resp.decode(&body)
+	if body.Error.Message != "" {
+		return fmt.Errorf("%w: unexpected status %d: %s", validator.ErrValidationError, resp.StatusCode, body.Error.Message)
+	}
+	return fmt.Errorf("%w: unexpected status %d", validator.ErrValidationError, resp.StatusCode)
+}
+
+// Validate reads the account and balance of the key, which a 401 rejects and a 403 shows to
+// be a valid key without access to them; restricted keys are then probed for the resources
+// they may read
+func (v *StripeValidator) Validate(ctx context.Context, key string) (*validator.ValidationResult, error) {
+	result := newResult(v.GetService())
+	key = strings.TrimSpace(key)
+	restricted := strings.HasPrefix(key, "rk_")
+	live := strings.Contains(key, "_live_")
+
+	var account struct {
+		ID              string `json:"id"`
+		Email           string `json:"email"`
+		Country         string `json:"country"`
+		ChargesEnabled  bool   `json:"charges_enabled"`
+		PayoutsEnabled  bool   `json:"payouts_enabled"`
+		BusinessProfile struct {
+			Name string `json:"name"`
+		} `json:"business_profile"`
+	}
+	resp, err := v.get(ctx, "/account", "", key, &account)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		return reject(result, "key rejected: invalid, expired or rolled"), nil
+	case http.StatusOK:
+		result.Permissions = append(result.Permissions, "account:read")
+		result.Details["account_id"] = account.ID
+		result.Details["business_name"] = account.BusinessProfile.Name
+		result.Details["email"] = account.Email
+		result.Details["country"] = account.Country
+		result.Details["charges_enabled"] = account.ChargesEnabled
+		result.Details["payouts_enabled"] = account.PayoutsEnabled
+	case http.StatusForbidden:
+		// Only restricted keys lack access to their own account
+	default:
+		return nil, stripeStatusError(resp)
+	}
+	result.Valid = true
+
+	var balance struct {
+		LiveMode  bool `json:"livemode"`
+		Available []struct {
+			Amount   int64  `json:"amount"`
+			Currency string `json:"currency"`
+		} `json:"available"`
+	}
+	if resp, err = v.get(ctx, "/balance", "", key, &balance); err != nil {
+		result.Details["balance"] = fmt.Sprintf("Error: %v", err)
+	} else if resp.StatusCode == http.StatusOK {
+		live = balance.LiveMode
+		available := make(map[string]int64, len(balance.Available))
+		for _, amount := range balance.Available {
+			available[amount.Currency] = amount.Amount
+		}
+		result.Permissions = append(result.Permissions, "balance:read")
+		result.Details["balance_available"] = available
+		if req, err := newStripeRequest(ctx, "/balance", "", key); err == nil {
+			result.PoC = map[string]string{stripeAPI + "/balance": validator.CurlCommand(req, nil)}
+		}
+	}
+
+	mode := "test"
+	if live {
+		mode = "live"
+	}
+	result.Details["mode"] = mode
+
+	if !restricted {
+		result.Details["key_type"] = "secret"
+		result.Permissions = []string{"all resources (read and write)"}
+	} else {
+		result.Details["key_type"] = "restricted"
+		for _, resource := range stripeResources {
+			resp, err := v.get(ctx, "/"+resource, "limit=1", key, nil)
+			switch {
+			case err != nil:
+				result.Details[resource] = fmt.Sprintf("Error: %v", err)
+			case resp.StatusCode == http.StatusOK:
+				result.Permissions = append(result.Permissions, resource+":read")
+			}
+		}
+	}
+
+	// Test mode keys only reach test data; live secret keys can move real money
+	switch {
+	case live && !restricted:
+		result.RiskLevel = validator.RiskLevelHigh
+	case live:
+		result.RiskLevel = validator.RiskLevelMedium
+	default:
+		result.RiskLevel = validator.RiskLevelLow
+	}
+	return result, nil
+}
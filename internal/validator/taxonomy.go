@@ -0,0 +1,95 @@
+package validator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Classification maps a finding onto CWE and OWASP categories with reference links
+type Classification struct {
+	CWE        []string
+	OWASP      []string
+	References []string
+}
+
+// defaultClassification applies to every exposed credential without a more specific mapping
+var defaultClassification = Classification{
+	CWE:   []string{"CWE-798"},
+	OWASP: []string{"A07:2021"},
+	References: []string{
+		"https://cwe.mitre.org/data/definitions/798.html",
+		"https://owasp.org/Top10/A07_2021-Identification_and_Authentication_Failures/",
+	},
+}
+
+// classifications holds per-service mappings keyed by service name
+var classifications = map[string]Classification{
+	"Google Safe Browsing API Key": {
+		// Unrestricted keys also let anyone call the enabled APIs on the owner's bill
+		CWE:   []string{"CWE-798", "CWE-284"},
+		OWASP: []string{"A07:2021", "A01:2021"},
+		References: []string{
+			"https://cloud.google.com/docs/authentication/api-keys#securing",
+			"https://developers.google.com/maps/api-security-best-practices",
+			"https://cwe.mitre.org/data/definitions/798.html",
+		},
+	},
+	"GitHub App Private Key": {
+		// The private key is the app's signing key, not a password or token
+		CWE:   []string{"CWE-321", "CWE-798"},
+		OWASP: []string{"A07:2021", "A02:2021"},
+		References: []string{
+			"https://docs.github.com/en/apps/creating-github-apps/authenticating-with-a-github-app/managing-private-keys-for-github-apps",
+			"https://cwe.mitre.org/data/definitions/321.html",
+		},
+	},
+	// Firebase audit findings are misconfigured access rules rather than leaked credentials
+	"Firebase Open Signup": {
+		CWE:   []string{"CWE-284", "CWE-306"},
+		OWASP: []string{"A01:2021", "A07:2021"},
+		References: []string{
+			"https://cloud.google.com/identity-platform/docs/concepts-manage-users#disable_user_sign-up",
+			"https://firebase.google.com/docs/rules/basics",
+		},
+	},
+	"Firebase Realtime Database": {
+		CWE:   []string{"CWE-284", "CWE-200"},
+		OWASP: []string{"A01:2021", "A05:2021"},
+		References: []string{
+			"https://firebase.google.com/docs/database/security",
+			"https://firebase.google.com/docs/rules/insecure-rules",
+		},
+	},
+	"Firebase Firestore": {
+		CWE:   []string{"CWE-284", "CWE-200"},
+		OWASP: []string{"A01:2021", "A05:2021"},
+		References: []string{
+			"https://firebase.google.com/docs/firestore/security/get-started",
+			"https://firebase.google.com/docs/rules/insecure-rules",
+		},
+	},
+	"Firebase Storage": {
+		CWE:   []string{"CWE-284", "CWE-200"},
+		OWASP: []string{"A01:2021", "A05:2021"},
+		References: []string{
+			"https://firebase.google.com/docs/storage/security",
+			"https://firebase.google.com/docs/rules/insecure-rules",
+		},
+	},
+}
+
+// ClassificationFor returns the CWE/OWASP mapping for a service
+func ClassificationFor(service string) Classification {
+	if c, ok := classifications[service]; ok {
+		return c
+	}
+	return defaultClassification
+}
+
+// FindingID returns a stable identifier for a key of a service, so the same exposure
+// keeps its ID across runs and locations
+func FindingID(service, key string) string {
+	sum := sha256.Sum256([]byte(service + "\x00" + key))
+	return "AKZ-" + strings.ToUpper(hex.EncodeToString(sum[:6]))
+}
@@ -3,9 +3,14 @@ package validator
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/Xplo8E/APIKeyzer/internal/transport"
 )
 
 // Common validation errors
@@ -34,8 +39,52 @@ const (
 	RiskLevelHigh   RiskLevel = "high"
 )
 
+// Rank orders risk levels from low (1) to high (3); unknown levels rank 0
+func (r RiskLevel) Rank() int {
+	switch r {
+	case RiskLevelLow:
+		return 1
+	case RiskLevelMedium:
+		return 2
+	case RiskLevelHigh:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// ParseRiskLevel converts a case-insensitive risk level name into a RiskLevel
+func ParseRiskLevel(s string) (RiskLevel, error) {
+	level := RiskLevel(strings.ToLower(strings.TrimSpace(s)))
+	if level.Rank() == 0 {
+		return "", fmt.Errorf("unknown risk level %q (expected low, medium or high)", s)
+	}
+	return level, nil
+}
+
+// Location identifies where a key was found
+type Location struct {
+	Path   string `json:"path"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+}
+
+// String formats the location as path:line:column, omitting unknown parts
+func (l *Location) String() string {
+	switch {
+	case l.Line > 0 && l.Column > 0:
+		return fmt.Sprintf("%s:%d:%d", l.Path, l.Line, l.Column)
+	case l.Line > 0:
+		return fmt.Sprintf("%s:%d", l.Path, l.Line)
+	default:
+		return l.Path
+	}
+}
+
 // ValidationResult represents the outcome of key validation
 type ValidationResult struct {
+	FindingID   string                 `json:"finding_id,omitempty"`
+	Key         string                 `json:"key"`
 	Valid       bool                   `json:"valid"`
 	Service     string                 `json:"service"`
 	Permissions []string               `json:"permissions,omitempty"`
@@ -43,7 +92,26 @@ type ValidationResult struct {
 	Details     map[string]interface{} `json:"details,omitempty"`
 	Error       error                  `json:"-"`
 	ErrorStr    string                 `json:"error,omitempty"`
+	ErrorCode   ErrorCode              `json:"error_code,omitempty"`
 	ValidatedAt time.Time              `json:"validated_at"`
+	Location    *Location              `json:"location,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	PoC         map[string]string      `json:"poc,omitempty"` // curl command per vulnerable endpoint
+	CWE         []string               `json:"cwe,omitempty"`
+	OWASP       []string               `json:"owasp,omitempty"`
+	References  []string               `json:"references,omitempty"`
+}
+
+// Classify sets the finding ID and, for valid keys, the CWE/OWASP mapping of a result
+func (r *ValidationResult) Classify() {
+	r.FindingID = FindingID(r.Service, r.Key)
+	if !r.Valid {
+		return
+	}
+	c := ClassificationFor(r.Service)
+	r.CWE = c.CWE
+	r.OWASP = c.OWASP
+	r.References = c.References
 }
 
 // Validator interface defines the contract for service-specific validators
@@ -60,21 +128,42 @@ type Validator interface {
 
 // ValidationManager handles the validation process across multiple services
 type ValidationManager struct {
-	validators map[string]Validator
-	client     *http.Client
-	mu         sync.RWMutex
+	validators      map[string]Validator
+	client          *http.Client
+	safeMode        bool
+	maxResponseSize int64
+	mu              sync.RWMutex
 }
 
 // NewValidationManager creates a new validation manager
 func NewValidationManager() *ValidationManager {
 	return &ValidationManager{
-		validators: make(map[string]Validator),
-		client: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		validators:      make(map[string]Validator),
+		client:          transport.DefaultClient(10 * time.Second),
+		maxResponseSize: DefaultMaxResponseSize,
 	}
 }
 
+// SetClient replaces the HTTP client shared by validators registered afterwards
+func (vm *ValidationManager) SetClient(client *http.Client) {
+	vm.client = client
+}
+
+// Client returns the HTTP client validators should use
+func (vm *ValidationManager) Client() *http.Client {
+	return vm.client
+}
+
+// SetSafeMode makes validators skip endpoints that may be billed to the key owner
+func (vm *ValidationManager) SetSafeMode(enabled bool) {
+	vm.safeMode = enabled
+}
+
+// SetMaxResponseSize limits the provider response bodies validators read, in bytes
+func (vm *ValidationManager) SetMaxResponseSize(size int64) {
+	vm.maxResponseSize = size
+}
+
 // RegisterValidator adds a new validator to the manager
 func (vm *ValidationManager) RegisterValidator(v Validator) {
 	vm.mu.Lock()
@@ -94,13 +183,32 @@ func (vm *ValidationManager) GetValidator(service string) (Validator, bool) {
 func (vm *ValidationManager) ValidateKey(ctx context.Context, service, key string) (*ValidationResult, error) {
 	validator, exists := vm.GetValidator(service)
 	if !exists {
-		return nil, errors.New("no validator found for service: " + service)
+		return nil, fmt.Errorf("%w: %s", ErrNoValidator, service)
 	}
 
-	result, err := validator.Validate(ctx, key)
+	start := time.Now()
+	// Safe mode can also be enabled for a single call through ctx
+	ctx = WithSafeMode(ctx, vm.safeMode || SafeMode(ctx))
+	result, err := validator.Validate(WithMaxResponseSize(ctx, vm.maxResponseSize), key)
+	// Endpoints left unchecked when a time budget ran out say nothing about the key, so only
+	// a key already confirmed valid keeps its verdict
+	if cause := context.Cause(ctx); ctx.Err() != nil && errors.Is(cause, ErrDeadline) {
+		if err != nil {
+			err = cause
+		} else if !result.Valid {
+			result.Error = cause
+			result.ErrorStr = cause.Error()
+			result.ErrorCode = ErrCodeDeadline
+		}
+	}
 	if err != nil {
+		slog.Debug("validation failed", "service", service, "finding_id", FindingID(service, key), "error", err)
 		return nil, err
 	}
+	result.Key = key
+	result.Classify()
+	slog.Debug("validated key", "service", service, "finding_id", result.FindingID, "valid", result.Valid,
+		"error_code", result.ErrorCode, "duration", time.Since(start))
 
 	return result, nil
 }
@@ -124,13 +232,7 @@ func (vm *ValidationManager) ValidateKeysParallel(ctx context.Context, service s
 
 			result, err := vm.ValidateKey(ctx, service, apiKey)
 			if err != nil {
-				results[index] = &ValidationResult{
-					Valid:       false,
-					Service:     service,
-					Error:       err,
-					ErrorStr:    err.Error(),
-					ValidatedAt: time.Now(),
-				}
+				results[index] = NewErrorResult(apiKey, service, err)
 				return
 			}
 			results[index] = result
@@ -0,0 +1,733 @@
+// gRPC interface of `apiKeyzer serve --grpc`, mirroring the HTTP API.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: apikeyzer/v1/apikeyzer.proto
+
+package apikeyzerv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type DetectRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Keys          []string               `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DetectRequest) Reset() {
+	*x = DetectRequest{}
+	mi := &file_apikeyzer_v1_apikeyzer_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DetectRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DetectRequest) ProtoMessage() {}
+
+func (x *DetectRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_apikeyzer_v1_apikeyzer_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DetectRequest.ProtoReflect.Descriptor instead.
+func (*DetectRequest) Descriptor() ([]byte, []int) {
+	return file_apikeyzer_v1_apikeyzer_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *DetectRequest) GetKeys() []string {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+type DetectResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Detections    []*Detection           `protobuf:"bytes,1,rep,name=detections,proto3" json:"detections,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DetectResponse) Reset() {
+	*x = DetectResponse{}
+	mi := &file_apikeyzer_v1_apikeyzer_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DetectResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DetectResponse) ProtoMessage() {}
+
+func (x *DetectResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_apikeyzer_v1_apikeyzer_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DetectResponse.ProtoReflect.Descriptor instead.
+func (*DetectResponse) Descriptor() ([]byte, []int) {
+	return file_apikeyzer_v1_apikeyzer_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *DetectResponse) GetDetections() []*Detection {
+	if x != nil {
+		return x.Detections
+	}
+	return nil
+}
+
+// Detection is the service a key is attributed to, as with `apiKeyzer detect`.
+type Detection struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Key   string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// Empty when no pattern matches the key.
+	Service    string   `protobuf:"bytes,2,opt,name=service,proto3" json:"service,omitempty"`
+	Confidence float64  `protobuf:"fixed64,3,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	Reasons    []string `protobuf:"bytes,4,rep,name=reasons,proto3" json:"reasons,omitempty"`
+	// Whether the service has a validator.
+	Validator     bool `protobuf:"varint,5,opt,name=validator,proto3" json:"validator,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Detection) Reset() {
+	*x = Detection{}
+	mi := &file_apikeyzer_v1_apikeyzer_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Detection) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Detection) ProtoMessage() {}
+
+func (x *Detection) ProtoReflect() protoreflect.Message {
+	mi := &file_apikeyzer_v1_apikeyzer_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Detection.ProtoReflect.Descriptor instead.
+func (*Detection) Descriptor() ([]byte, []int) {
+	return file_apikeyzer_v1_apikeyzer_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Detection) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *Detection) GetService() string {
+	if x != nil {
+		return x.Service
+	}
+	return ""
+}
+
+func (x *Detection) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+func (x *Detection) GetReasons() []string {
+	if x != nil {
+		return x.Reasons
+	}
+	return nil
+}
+
+func (x *Detection) GetValidator() bool {
+	if x != nil {
+		return x.Validator
+	}
+	return false
+}
+
+type ValidateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Keys          []string               `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateRequest) Reset() {
+	*x = ValidateRequest{}
+	mi := &file_apikeyzer_v1_apikeyzer_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateRequest) ProtoMessage() {}
+
+func (x *ValidateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_apikeyzer_v1_apikeyzer_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateRequest.ProtoReflect.Descriptor instead.
+func (*ValidateRequest) Descriptor() ([]byte, []int) {
+	return file_apikeyzer_v1_apikeyzer_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ValidateRequest) GetKeys() []string {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+// ValidationResult is the outcome of validating a key, as with --output json.
+type ValidationResult struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	FindingId   string                 `protobuf:"bytes,1,opt,name=finding_id,json=findingId,proto3" json:"finding_id,omitempty"`
+	Key         string                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	Valid       bool                   `protobuf:"varint,3,opt,name=valid,proto3" json:"valid,omitempty"`
+	Service     string                 `protobuf:"bytes,4,opt,name=service,proto3" json:"service,omitempty"`
+	Permissions []string               `protobuf:"bytes,5,rep,name=permissions,proto3" json:"permissions,omitempty"`
+	// low, medium or high.
+	RiskLevel string `protobuf:"bytes,6,opt,name=risk_level,json=riskLevel,proto3" json:"risk_level,omitempty"`
+	Error     string `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`
+	// Why the key could not be validated, e.g. NETWORK_ERROR; empty when it was.
+	ErrorCode   string                 `protobuf:"bytes,8,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"`
+	ValidatedAt *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=validated_at,json=validatedAt,proto3" json:"validated_at,omitempty"`
+	Details     *structpb.Struct       `protobuf:"bytes,10,opt,name=details,proto3" json:"details,omitempty"`
+	// curl command per vulnerable endpoint.
+	Poc           map[string]string `protobuf:"bytes,11,rep,name=poc,proto3" json:"poc,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Cwe           []string          `protobuf:"bytes,12,rep,name=cwe,proto3" json:"cwe,omitempty"`
+	Owasp         []string          `protobuf:"bytes,13,rep,name=owasp,proto3" json:"owasp,omitempty"`
+	References    []string          `protobuf:"bytes,14,rep,name=references,proto3" json:"references,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidationResult) Reset() {
+	*x = ValidationResult{}
+	mi := &file_apikeyzer_v1_apikeyzer_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidationResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidationResult) ProtoMessage() {}
+
+func (x *ValidationResult) ProtoReflect() protoreflect.Message {
+	mi := &file_apikeyzer_v1_apikeyzer_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidationResult.ProtoReflect.Descriptor instead.
+func (*ValidationResult) Descriptor() ([]byte, []int) {
+	return file_apikeyzer_v1_apikeyzer_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ValidationResult) GetFindingId() string {
+	if x != nil {
+		return x.FindingId
+	}
+	return ""
+}
+
+func (x *ValidationResult) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *ValidationResult) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *ValidationResult) GetService() string {
+	if x != nil {
+		return x.Service
+	}
+	return ""
+}
+
+func (x *ValidationResult) GetPermissions() []string {
+	if x != nil {
+		return x.Permissions
+	}
+	return nil
+}
+
+func (x *ValidationResult) GetRiskLevel() string {
+	if x != nil {
+		return x.RiskLevel
+	}
+	return ""
+}
+
+func (x *ValidationResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ValidationResult) GetErrorCode() string {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ""
+}
+
+func (x *ValidationResult) GetValidatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ValidatedAt
+	}
+	return nil
+}
+
+func (x *ValidationResult) GetDetails() *structpb.Struct {
+	if x != nil {
+		return x.Details
+	}
+	return nil
+}
+
+func (x *ValidationResult) GetPoc() map[string]string {
+	if x != nil {
+		return x.Poc
+	}
+	return nil
+}
+
+func (x *ValidationResult) GetCwe() []string {
+	if x != nil {
+		return x.Cwe
+	}
+	return nil
+}
+
+func (x *ValidationResult) GetOwasp() []string {
+	if x != nil {
+		return x.Owasp
+	}
+	return nil
+}
+
+func (x *ValidationResult) GetReferences() []string {
+	if x != nil {
+		return x.References
+	}
+	return nil
+}
+
+type ListServicesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListServicesRequest) Reset() {
+	*x = ListServicesRequest{}
+	mi := &file_apikeyzer_v1_apikeyzer_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListServicesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListServicesRequest) ProtoMessage() {}
+
+func (x *ListServicesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_apikeyzer_v1_apikeyzer_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListServicesRequest.ProtoReflect.Descriptor instead.
+func (*ListServicesRequest) Descriptor() ([]byte, []int) {
+	return file_apikeyzer_v1_apikeyzer_proto_rawDescGZIP(), []int{5}
+}
+
+type ListServicesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Services      []*Service             `protobuf:"bytes,1,rep,name=services,proto3" json:"services,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListServicesResponse) Reset() {
+	*x = ListServicesResponse{}
+	mi := &file_apikeyzer_v1_apikeyzer_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListServicesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListServicesResponse) ProtoMessage() {}
+
+func (x *ListServicesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_apikeyzer_v1_apikeyzer_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListServicesResponse.ProtoReflect.Descriptor instead.
+func (*ListServicesResponse) Descriptor() ([]byte, []int) {
+	return file_apikeyzer_v1_apikeyzer_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListServicesResponse) GetServices() []*Service {
+	if x != nil {
+		return x.Services
+	}
+	return nil
+}
+
+// Service is a service keys can be validated for, as with `apiKeyzer services`.
+type Service struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Method        string                 `protobuf:"bytes,3,opt,name=method,proto3" json:"method,omitempty"`
+	Endpoints     []*Endpoint            `protobuf:"bytes,4,rep,name=endpoints,proto3" json:"endpoints,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Service) Reset() {
+	*x = Service{}
+	mi := &file_apikeyzer_v1_apikeyzer_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Service) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Service) ProtoMessage() {}
+
+func (x *Service) ProtoReflect() protoreflect.Message {
+	mi := &file_apikeyzer_v1_apikeyzer_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Service.ProtoReflect.Descriptor instead.
+func (*Service) Descriptor() ([]byte, []int) {
+	return file_apikeyzer_v1_apikeyzer_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Service) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Service) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Service) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *Service) GetEndpoints() []*Endpoint {
+	if x != nil {
+		return x.Endpoints
+	}
+	return nil
+}
+
+type Endpoint struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Method string                 `protobuf:"bytes,1,opt,name=method,proto3" json:"method,omitempty"`
+	Url    string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	// Whether calling the endpoint may be billed to the key owner.
+	Billable      bool `protobuf:"varint,3,opt,name=billable,proto3" json:"billable,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Endpoint) Reset() {
+	*x = Endpoint{}
+	mi := &file_apikeyzer_v1_apikeyzer_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Endpoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Endpoint) ProtoMessage() {}
+
+func (x *Endpoint) ProtoReflect() protoreflect.Message {
+	mi := &file_apikeyzer_v1_apikeyzer_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Endpoint.ProtoReflect.Descriptor instead.
+func (*Endpoint) Descriptor() ([]byte, []int) {
+	return file_apikeyzer_v1_apikeyzer_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Endpoint) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *Endpoint) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Endpoint) GetBillable() bool {
+	if x != nil {
+		return x.Billable
+	}
+	return false
+}
+
+var File_apikeyzer_v1_apikeyzer_proto protoreflect.FileDescriptor
+
+const file_apikeyzer_v1_apikeyzer_proto_rawDesc = "" +
+	"\n" +
+	"\x1capikeyzer/v1/apikeyzer.proto\x12\fapikeyzer.v1\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"#\n" +
+	"\rDetectRequest\x12\x12\n" +
+	"\x04keys\x18\x01 \x03(\tR\x04keys\"I\n" +
+	"\x0eDetectResponse\x127\n" +
+	"\n" +
+	"detections\x18\x01 \x03(\v2\x17.apikeyzer.v1.DetectionR\n" +
+	"detections\"\x8f\x01\n" +
+	"\tDetection\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x18\n" +
+	"\aservice\x18\x02 \x01(\tR\aservice\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\x03 \x01(\x01R\n" +
+	"confidence\x12\x18\n" +
+	"\areasons\x18\x04 \x03(\tR\areasons\x12\x1c\n" +
+	"\tvalidator\x18\x05 \x01(\bR\tvalidator\"%\n" +
+	"\x0fValidateRequest\x12\x12\n" +
+	"\x04keys\x18\x01 \x03(\tR\x04keys\"\x96\x04\n" +
+	"\x10ValidationResult\x12\x1d\n" +
+	"\n" +
+	"finding_id\x18\x01 \x01(\tR\tfindingId\x12\x10\n" +
+	"\x03key\x18\x02 \x01(\tR\x03key\x12\x14\n" +
+	"\x05valid\x18\x03 \x01(\bR\x05valid\x12\x18\n" +
+	"\aservice\x18\x04 \x01(\tR\aservice\x12 \n" +
+	"\vpermissions\x18\x05 \x03(\tR\vpermissions\x12\x1d\n" +
+	"\n" +
+	"risk_level\x18\x06 \x01(\tR\triskLevel\x12\x14\n" +
+	"\x05error\x18\a \x01(\tR\x05error\x12\x1d\n" +
+	"\n" +
+	"error_code\x18\b \x01(\tR\terrorCode\x12=\n" +
+	"\fvalidated_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\vvalidatedAt\x121\n" +
+	"\adetails\x18\n" +
+	" \x01(\v2\x17.google.protobuf.StructR\adetails\x129\n" +
+	"\x03poc\x18\v \x03(\v2'.apikeyzer.v1.ValidationResult.PocEntryR\x03poc\x12\x10\n" +
+	"\x03cwe\x18\f \x03(\tR\x03cwe\x12\x14\n" +
+	"\x05owasp\x18\r \x03(\tR\x05owasp\x12\x1e\n" +
+	"\n" +
+	"references\x18\x0e \x03(\tR\n" +
+	"references\x1a6\n" +
+	"\bPocEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x15\n" +
+	"\x13ListServicesRequest\"I\n" +
+	"\x14ListServicesResponse\x121\n" +
+	"\bservices\x18\x01 \x03(\v2\x15.apikeyzer.v1.ServiceR\bservices\"{\n" +
+	"\aService\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x16\n" +
+	"\x06method\x18\x03 \x01(\tR\x06method\x124\n" +
+	"\tendpoints\x18\x04 \x03(\v2\x16.apikeyzer.v1.EndpointR\tendpoints\"P\n" +
+	"\bEndpoint\x12\x16\n" +
+	"\x06method\x18\x01 \x01(\tR\x06method\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x12\x1a\n" +
+	"\bbillable\x18\x03 \x01(\bR\bbillable2\xf5\x01\n" +
+	"\n" +
+	"KeyService\x12C\n" +
+	"\x06Detect\x12\x1b.apikeyzer.v1.DetectRequest\x1a\x1c.apikeyzer.v1.DetectResponse\x12K\n" +
+	"\bValidate\x12\x1d.apikeyzer.v1.ValidateRequest\x1a\x1e.apikeyzer.v1.ValidationResult0\x01\x12U\n" +
+	"\fListServices\x12!.apikeyzer.v1.ListServicesRequest\x1a\".apikeyzer.v1.ListServicesResponseB<Z:github.com/Xplo8E/APIKeyzer/proto/apikeyzer/v1;apikeyzerv1b\x06proto3"
+
+var (
+	file_apikeyzer_v1_apikeyzer_proto_rawDescOnce sync.Once
+	file_apikeyzer_v1_apikeyzer_proto_rawDescData []byte
+)
+
+func file_apikeyzer_v1_apikeyzer_proto_rawDescGZIP() []byte {
+	file_apikeyzer_v1_apikeyzer_proto_rawDescOnce.Do(func() {
+		file_apikeyzer_v1_apikeyzer_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_apikeyzer_v1_apikeyzer_proto_rawDesc), len(file_apikeyzer_v1_apikeyzer_proto_rawDesc)))
+	})
+	return file_apikeyzer_v1_apikeyzer_proto_rawDescData
+}
+
+var file_apikeyzer_v1_apikeyzer_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_apikeyzer_v1_apikeyzer_proto_goTypes = []any{
+	(*DetectRequest)(nil),         // 0: apikeyzer.v1.DetectRequest
+	(*DetectResponse)(nil),        // 1: apikeyzer.v1.DetectResponse
+	(*Detection)(nil),             // 2: apikeyzer.v1.Detection
+	(*ValidateRequest)(nil),       // 3: apikeyzer.v1.ValidateRequest
+	(*ValidationResult)(nil),      // 4: apikeyzer.v1.ValidationResult
+	(*ListServicesRequest)(nil),   // 5: apikeyzer.v1.ListServicesRequest
+	(*ListServicesResponse)(nil),  // 6: apikeyzer.v1.ListServicesResponse
+	(*Service)(nil),               // 7: apikeyzer.v1.Service
+	(*Endpoint)(nil),              // 8: apikeyzer.v1.Endpoint
+	nil,                           // 9: apikeyzer.v1.ValidationResult.PocEntry
+	(*timestamppb.Timestamp)(nil), // 10: google.protobuf.Timestamp
+	(*structpb.Struct)(nil),       // 11: google.protobuf.Struct
+}
+var file_apikeyzer_v1_apikeyzer_proto_depIdxs = []int32{
+	2,  // 0: apikeyzer.v1.DetectResponse.detections:type_name -> apikeyzer.v1.Detection
+	10, // 1: apikeyzer.v1.ValidationResult.validated_at:type_name -> google.protobuf.Timestamp
+	11, // 2: apikeyzer.v1.ValidationResult.details:type_name -> google.protobuf.Struct
+	9,  // 3: apikeyzer.v1.ValidationResult.poc:type_name -> apikeyzer.v1.ValidationResult.PocEntry
+	7,  // 4: apikeyzer.v1.ListServicesResponse.services:type_name -> apikeyzer.v1.Service
+	8,  // 5: apikeyzer.v1.Service.endpoints:type_name -> apikeyzer.v1.Endpoint
+	0,  // 6: apikeyzer.v1.KeyService.Detect:input_type -> apikeyzer.v1.DetectRequest
+	3,  // 7: apikeyzer.v1.KeyService.Validate:input_type -> apikeyzer.v1.ValidateRequest
+	5,  // 8: apikeyzer.v1.KeyService.ListServices:input_type -> apikeyzer.v1.ListServicesRequest
+	1,  // 9: apikeyzer.v1.KeyService.Detect:output_type -> apikeyzer.v1.DetectResponse
+	4,  // 10: apikeyzer.v1.KeyService.Validate:output_type -> apikeyzer.v1.ValidationResult
+	6,  // 11: apikeyzer.v1.KeyService.ListServices:output_type -> apikeyzer.v1.ListServicesResponse
+	9,  // [9:12] is the sub-list for method output_type
+	6,  // [6:9] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_apikeyzer_v1_apikeyzer_proto_init() }
+func file_apikeyzer_v1_apikeyzer_proto_init() {
+	if File_apikeyzer_v1_apikeyzer_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_apikeyzer_v1_apikeyzer_proto_rawDesc), len(file_apikeyzer_v1_apikeyzer_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_apikeyzer_v1_apikeyzer_proto_goTypes,
+		DependencyIndexes: file_apikeyzer_v1_apikeyzer_proto_depIdxs,
+		MessageInfos:      file_apikeyzer_v1_apikeyzer_proto_msgTypes,
+	}.Build()
+	File_apikeyzer_v1_apikeyzer_proto = out.File
+	file_apikeyzer_v1_apikeyzer_proto_goTypes = nil
+	file_apikeyzer_v1_apikeyzer_proto_depIdxs = nil
+}